@@ -0,0 +1,98 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MarkAttendance godoc
+// @Security ApiKeyAuth
+// @Router		/attendance [POST]
+// @Summary		mark lesson's attendance
+// @Description	This api marks attendance of the lesson's students in bulk, only the lesson's teacher can do it
+// @Tags		attendance
+// @Accept		json
+// @Produce		json
+// @Param		attendance body models.MarkAttendanceRequest true "attendance"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		401  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) MarkAttendance(c *gin.Context) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+	if authInfo.UserRole != config.TEACHER_TYPE {
+		handleResponse(c, h.Log, "only teachers can mark attendance", http.StatusForbidden, "forbidden")
+		return
+	}
+
+	req := models.MarkAttendanceRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := uuid.Validate(req.TimeTableId); err != nil {
+		handleResponse(c, h.Log, "error while validating timeTableId", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	for _, record := range req.Records {
+		if err := uuid.Validate(record.StudentId); err != nil {
+			handleResponse(c, h.Log, "error while validating studentId", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	err = h.Service.Attendance().MarkBulk(c.Request.Context(), req, authInfo.UserID)
+	if errors.Is(err, service.ErrNotLessonTeacher) {
+		handleResponse(c, h.Log, "error while marking attendance", http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		handleResponse(c, h.Log, "error while marking attendance", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Marked successfully", http.StatusOK, req.TimeTableId)
+}
+
+// GetLessonAttendance godoc
+// @Security ApiKeyAuth
+// @Router		/attendance/{id} [GET]
+// @Summary		get lesson's attendance
+// @Description	This api get attendance of the lesson by its time table id
+// @Tags		attendance
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetLessonAttendance(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating timeTableId", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.Service.Attendance().GetByLesson(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting lesson's attendance", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Got successfully", http.StatusOK, resp)
+}
@@ -48,6 +48,74 @@ func (h *Handler) Login(c *gin.Context) {
 	handleResponse(c, h.Log, "Succes", http.StatusOK, loginResp)
 }
 
+// StudentLogin godoc
+// @Router       /student/login [POST]
+// @Summary      Student login
+// @Description  Student login
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        login body models.LoginRequest true "login"
+// @Success      201  {object}  models.LoginResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h *Handler) StudentLogin(c *gin.Context) {
+	loginReq := models.LoginRequest{}
+
+	if err := c.ShouldBindJSON(&loginReq); err != nil {
+		handleResponse(c, h.Log, "error while binding body", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := check.ValidateEmail(loginReq.Login); err != nil {
+		handleResponse(c, h.Log, "error with email: ", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	loginResp, err := h.Service.Auth().StudentLogin(c.Request.Context(), loginReq)
+	if err != nil {
+		handleResponse(c, h.Log, "unauthorized", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Succes", http.StatusOK, loginResp)
+}
+
+// GuardianLogin godoc
+// @Router       /guardian/login [POST]
+// @Summary      Guardian login
+// @Description  Guardian login
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        login body models.LoginRequest true "login"
+// @Success      201  {object}  models.LoginResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h *Handler) GuardianLogin(c *gin.Context) {
+	loginReq := models.LoginRequest{}
+
+	if err := c.ShouldBindJSON(&loginReq); err != nil {
+		handleResponse(c, h.Log, "error while binding body", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := check.ValidateEmail(loginReq.Login); err != nil {
+		handleResponse(c, h.Log, "error with email: ", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	loginResp, err := h.Service.Auth().GuardianLogin(c.Request.Context(), loginReq)
+	if err != nil {
+		handleResponse(c, h.Log, "unauthorized", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Succes", http.StatusOK, loginResp)
+}
+
 // TeacherRegister godoc
 // @Router       /teacher/register [POST]
 // @Summary      Teacher register
@@ -107,15 +175,19 @@ func (h *Handler) RegisterConfirm(c *gin.Context) {
 		return
 	}
 
-	password, err := pkg.HashPassword(loginRegConfirm.AddTeacher.Password)
+	teacher, err := loginRegConfirm.AddTeacher.Domain(c.Request.Context())
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
 
+	teacher.Password, err = pkg.HashPassword(loginRegConfirm.AddTeacher.Password)
 	if err != nil {
 		handleResponse(c, h.Log, "error while hashing password", http.StatusBadRequest, err.Error())
 		return
 	}
-	loginRegConfirm.AddTeacher.Password = password
 
-	err = h.Service.Auth().TeacherRegisterConfirm(c.Request.Context(), loginRegConfirm)
+	err = h.Service.Auth().TeacherRegisterConfirm(c.Request.Context(), teacher, loginRegConfirm.Code)
 	if err != nil {
 		handleResponse(c, h.Log, "Bad request", http.StatusInternalServerError, err.Error())
 		return
@@ -0,0 +1,247 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg/export"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SetTeacherAvailability godoc
+// @Security ApiKeyAuth
+// @Router		/teacher/{id}/availability [PUT]
+// @Summary		set a teacher's availability
+// @Description	This api replaces the weekly availability windows of a teacher, weekday is 0 (Sunday) to 6 (Saturday)
+// @Tags		availability
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		availability body models.TeacherAvailability true "availability"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) SetTeacherAvailability(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating teacherId", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.TeacherAvailability{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.Service.Availability().SetWindows(c.Request.Context(), id, req); err != nil {
+		handleResponse(c, h.Log, "error while setting teacher's availability", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, id)
+}
+
+// GetTeacherAvailability godoc
+// @Security ApiKeyAuth
+// @Router		/teacher/{id}/availability [GET]
+// @Summary		get a teacher's availability
+// @Description	This api get the weekly availability windows of a teacher
+// @Tags		availability
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetTeacherAvailability(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating teacherId", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.Service.Availability().GetWindows(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting teacher's availability", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Got successfully", http.StatusOK, resp)
+}
+
+// RequestLeave godoc
+// @Security ApiKeyAuth
+// @Router		/teacher/{id}/leave [POST]
+// @Summary		request a leave
+// @Description	This api create a pending leave of a teacher and returns its id
+// @Tags		availability
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		leave body models.AddLeave true "leave"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) RequestLeave(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating teacherId", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	leave := models.AddLeave{}
+	if err := c.ShouldBindJSON(&leave); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	leave.TeacherId = id
+
+	leaveId, err := h.Service.Availability().RequestLeave(c.Request.Context(), leave)
+	if err != nil {
+		handleResponse(c, h.Log, "error while requesting leave", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Created successfully", http.StatusOK, leaveId)
+}
+
+// GetAllLeaves godoc
+// @Security ApiKeyAuth
+// @Router		/leaves [GET]
+// @Summary		get leaves
+// @Description	This api get leaves filtered by teacher and status, format=csv|xlsx or the Accept header exports all of them
+// @Tags		availability
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		teacher_id query string false "teacher_id"
+// @Param		status query string false "pending, approved or rejected"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetAllLeaves(c *gin.Context) {
+	teacherId := c.Query("teacher_id")
+	if teacherId != "" {
+		if err := uuid.Validate(teacherId); err != nil {
+			handleResponse(c, h.Log, "error while validating teacherId", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing page", http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.GetAllLeavesRequest{
+		TeacherId: teacherId,
+		Status:    c.Query("status"),
+		Page:      page,
+		Limit:     limit,
+	}
+
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "leaves", func(write func(models.Leave) error) error {
+			return h.Service.Availability().EachLeave(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting leaves", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	resp, err := h.Service.Availability().GetLeaves(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting all leaves", http.StatusInternalServerError, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// ReviewLeave godoc
+// @Security ApiKeyAuth
+// @Router		/leave/{id} [PATCH]
+// @Summary		review a leave
+// @Description	This api approves or rejects a pending leave and returns the lessons falling into it
+// @Tags		availability
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		review body models.ReviewLeave true "approved or rejected"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) ReviewLeave(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating leaveId", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.ReviewLeave{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.Service.Availability().ReviewLeave(c.Request.Context(), id, req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while reviewing leave", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, resp)
+}
+
+// GetLeaveImpact godoc
+// @Security ApiKeyAuth
+// @Router		/leave/{id}/impacted-lessons [GET]
+// @Summary		get lessons impacted by a leave
+// @Description	This api get the lessons of the teacher which fall into the leave
+// @Tags		availability
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetLeaveImpact(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating leaveId", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.Service.Availability().GetLeaveImpact(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting impacted lessons", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Got successfully", http.StatusOK, resp)
+}
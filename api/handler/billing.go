@@ -0,0 +1,754 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg/export"
+	"backend_course/lms/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// billingStatus maps billing errors to their status codes.
+func billingStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvoiceCancelled), errors.Is(err, service.ErrInvoicePaid),
+		errors.Is(err, service.ErrPaymentTooLarge):
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+// CreatePricePlan godoc
+// @Security ApiKeyAuth
+// @Router		/price-plan [POST]
+// @Summary		create a price plan
+// @Description	This api creates the monthly fee of a subject or of a group and returns its id, money is in minor units. Invoices are due on due_day of the month, 10 by default. Only teachers can create it
+// @Tags		billing
+// @Accept		json
+// @Produce		json
+// @Param		plan body models.AddPricePlan true "plan"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) CreatePricePlan(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can create price plans"); !ok {
+		return
+	}
+
+	plan := models.AddPricePlan{}
+	if err := c.ShouldBindJSON(&plan); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := plan.Validate(c.Request.Context()); err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := h.Service.Billing().CreatePlan(c.Request.Context(), plan)
+	if err != nil {
+		handleResponse(c, h.Log, "error while creating price plan", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Created successfully", http.StatusOK, id)
+}
+
+// UpdatePricePlan godoc
+// @Security ApiKeyAuth
+// @Router		/price-plan/{id} [PUT]
+// @Summary		update a price plan
+// @Description	This api updates a price plan, the changes apply to the invoices generated after. Inactive plans are not invoiced. Only teachers can update it
+// @Tags		billing
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		plan body models.UpdatePricePlan true "plan"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) UpdatePricePlan(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can update price plans"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating planId", http.StatusBadRequest, err)
+		return
+	}
+
+	plan := models.UpdatePricePlan{}
+	if err := c.ShouldBindJSON(&plan); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.Service.Billing().UpdatePlan(c.Request.Context(), id, plan); err != nil {
+		handleResponse(c, h.Log, "error while updating price plan", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, id)
+}
+
+// GetPricePlan godoc
+// @Security ApiKeyAuth
+// @Router		/price-plan/{id} [GET]
+// @Summary		get a price plan
+// @Description	This api get a price plan by its id
+// @Tags		billing
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.PricePlan
+// @Failure		400  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetPricePlan(c *gin.Context) {
+	if _, err := getAuthInfo(c); err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating planId", http.StatusBadRequest, err)
+		return
+	}
+
+	plan, err := h.Service.Billing().GetPlan(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting price plan", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "request successful", http.StatusOK, plan)
+}
+
+// GetAllPricePlans godoc
+// @Security ApiKeyAuth
+// @Router		/price-plans [GET]
+// @Summary		get price plans
+// @Description	This api get price plans filtered by subject and group, format=csv|xlsx or the Accept header exports all of them
+// @Tags		billing
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		subject_id query string false "subject_id"
+// @Param		group_id query string false "group_id"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.GetAllPricePlansResponse
+// @Failure		400  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetAllPricePlans(c *gin.Context) {
+	if _, err := getAuthInfo(c); err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+	if !h.validateQueryIds(c, "subject_id", "group_id") {
+		return
+	}
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing page", http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.GetAllPricePlansRequest{
+		SubjectId: c.Query("subject_id"),
+		GroupId:   c.Query("group_id"),
+		Page:      page,
+		Limit:     limit,
+	}
+
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "price-plans", func(write func(models.PricePlan) error) error {
+			return h.Service.Billing().EachPlan(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting price plans", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	resp, err := h.Service.Billing().GetPlans(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting all price plans", http.StatusInternalServerError, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// SetDiscount godoc
+// @Security ApiKeyAuth
+// @Router		/discount [POST]
+// @Summary		set a discount
+// @Description	This api sets the student's discount in percent of a price plan and returns its id, it replaces the discount the student had of the plan and applies to the invoices generated after. Only teachers can set it
+// @Tags		billing
+// @Accept		json
+// @Produce		json
+// @Param		discount body models.AddDiscount true "discount"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) SetDiscount(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can set discounts"); !ok {
+		return
+	}
+
+	discount := models.AddDiscount{}
+	if err := c.ShouldBindJSON(&discount); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := discount.Validate(c.Request.Context()); err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := h.Service.Billing().SetDiscount(c.Request.Context(), discount)
+	if err != nil {
+		handleResponse(c, h.Log, "error while setting discount", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Set successfully", http.StatusOK, id)
+}
+
+// DeleteDiscount godoc
+// @Security ApiKeyAuth
+// @Router		/discount/{id} [DELETE]
+// @Summary		delete a discount
+// @Description	This api delete a discount, the invoices already generated keep it. Only teachers can delete it
+// @Tags		billing
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) DeleteDiscount(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can delete discounts"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating discountId", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.Service.Billing().DeleteDiscount(c.Request.Context(), id); err != nil {
+		handleResponse(c, h.Log, "error while deleting discount", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Deleted successfully", http.StatusOK, id)
+}
+
+// GetAllDiscounts godoc
+// @Security ApiKeyAuth
+// @Router		/discounts [GET]
+// @Summary		get discounts
+// @Description	This api get discounts filtered by student and price plan, only teachers can get them, format=csv|xlsx or the Accept header exports all of them
+// @Tags		billing
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		student_id query string false "student_id"
+// @Param		plan_id query string false "plan_id"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.GetAllDiscountsResponse
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetAllDiscounts(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can get discounts"); !ok {
+		return
+	}
+	if !h.validateQueryIds(c, "student_id", "plan_id") {
+		return
+	}
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing page", http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.GetAllDiscountsRequest{
+		StudentId: c.Query("student_id"),
+		PlanId:    c.Query("plan_id"),
+		Page:      page,
+		Limit:     limit,
+	}
+
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "discounts", func(write func(models.Discount) error) error {
+			return h.Service.Billing().EachDiscount(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting discounts", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	resp, err := h.Service.Billing().GetDiscounts(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting all discounts", http.StatusInternalServerError, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// GenerateInvoices godoc
+// @Security ApiKeyAuth
+// @Router		/invoices/generate [POST]
+// @Summary		generate monthly invoices
+// @Description	This api invoices every student enrolled in the subject or belonging to the group of an active price plan for a YYYY-MM period, less the student's discount. Students already invoiced for the period are skipped, so it can be run again. Only teachers can generate them
+// @Tags		billing
+// @Accept		json
+// @Produce		json
+// @Param		period body models.GenerateInvoices true "period"
+// @Success		200  {object}  models.GenerateInvoicesResponse
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GenerateInvoices(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can generate invoices"); !ok {
+		return
+	}
+
+	req := models.GenerateInvoices{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.Service.Billing().GenerateInvoices(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while generating invoices", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Generated successfully", http.StatusOK, resp)
+}
+
+// GetInvoice godoc
+// @Security ApiKeyAuth
+// @Router		/invoice/{id} [GET]
+// @Summary		get an invoice
+// @Description	This api get an invoice with what is paid of it and its status, open, paid, overdue or cancelled. Students can only get their own invoices
+// @Tags		billing
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Invoice
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetInvoice(c *gin.Context) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating invoiceId", http.StatusBadRequest, err)
+		return
+	}
+
+	invoice, err := h.Service.Billing().GetInvoice(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting invoice", http.StatusInternalServerError, err.Error())
+		return
+	}
+	if authInfo.UserRole == config.STUDENT_TYPE && invoice.StudentId != authInfo.UserID {
+		handleResponse(c, h.Log, "students can only get their own invoices", http.StatusForbidden, "forbidden")
+		return
+	}
+
+	handleResponse(c, h.Log, "request successful", http.StatusOK, invoice)
+}
+
+// GetAllInvoices godoc
+// @Security ApiKeyAuth
+// @Router		/invoices [GET]
+// @Summary		get invoices
+// @Description	This api get invoices filtered by student, price plan, YYYY-MM period and status, one of open, paid, overdue or cancelled. Students only get their own. format=csv|xlsx or the Accept header exports all of them
+// @Tags		billing
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		student_id query string false "student_id"
+// @Param		plan_id query string false "plan_id"
+// @Param		period query string false "period"
+// @Param		status query string false "status"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.GetAllInvoicesResponse
+// @Failure		400  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetAllInvoices(c *gin.Context) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+	if !h.validateQueryIds(c, "student_id", "plan_id") {
+		return
+	}
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing page", http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.GetAllInvoicesRequest{
+		StudentId: c.Query("student_id"),
+		PlanId:    c.Query("plan_id"),
+		Period:    c.Query("period"),
+		Status:    c.Query("status"),
+		Page:      page,
+		Limit:     limit,
+	}
+	if authInfo.UserRole == config.STUDENT_TYPE {
+		req.StudentId = authInfo.UserID
+	}
+
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "invoices", func(write func(models.Invoice) error) error {
+			return h.Service.Billing().EachInvoice(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting invoices", http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	resp, err := h.Service.Billing().GetInvoices(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting all invoices", http.StatusBadRequest, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// UpdateInvoice godoc
+// @Security ApiKeyAuth
+// @Router		/invoice/{id} [PATCH]
+// @Summary		cancel an invoice
+// @Description	This api cancels an invoice by setting its status to cancelled, invoices with payments can not be cancelled. Only teachers can cancel it
+// @Tags		billing
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		invoice body models.UpdateInvoice true "invoice"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		409  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) UpdateInvoice(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can cancel invoices"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating invoiceId", http.StatusBadRequest, err)
+		return
+	}
+
+	req := models.UpdateInvoice{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.Service.Billing().UpdateInvoice(c.Request.Context(), id, req); err != nil {
+		handleResponse(c, h.Log, "error while updating invoice", billingStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, id)
+}
+
+// AddPayment godoc
+// @Security ApiKeyAuth
+// @Router		/payment [POST]
+// @Summary		record a payment
+// @Description	This api records a payment of an invoice in minor units and returns its id, method is cash, card or transfer and paid_at defaults to now. An invoice can not be paid more than what is left of it. Only teachers can record it
+// @Tags		billing
+// @Accept		json
+// @Produce		json
+// @Param		payment body models.AddPayment true "payment"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		409  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) AddPayment(c *gin.Context) {
+	teacherId, ok := h.teacherOnly(c, "only teachers can record payments")
+	if !ok {
+		return
+	}
+
+	payment := models.AddPayment{}
+	if err := c.ShouldBindJSON(&payment); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := payment.Validate(c.Request.Context()); err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
+	payment.RecordedBy = teacherId
+
+	id, err := h.Service.Billing().AddPayment(c.Request.Context(), payment)
+	if err != nil {
+		handleResponse(c, h.Log, "error while recording payment", billingStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Created successfully", http.StatusOK, id)
+}
+
+// GetAllPayments godoc
+// @Security ApiKeyAuth
+// @Router		/payments [GET]
+// @Summary		get payments
+// @Description	This api get payments filtered by student and invoice, the latest first. Students only get their own. format=csv|xlsx or the Accept header exports all of them
+// @Tags		billing
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		student_id query string false "student_id"
+// @Param		invoice_id query string false "invoice_id"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.GetAllPaymentsResponse
+// @Failure		400  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetAllPayments(c *gin.Context) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+	if !h.validateQueryIds(c, "student_id", "invoice_id") {
+		return
+	}
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing page", http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.GetAllPaymentsRequest{
+		StudentId: c.Query("student_id"),
+		InvoiceId: c.Query("invoice_id"),
+		Page:      page,
+		Limit:     limit,
+	}
+	if authInfo.UserRole == config.STUDENT_TYPE {
+		req.StudentId = authInfo.UserID
+	}
+
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "payments", func(write func(models.Payment) error) error {
+			return h.Service.Billing().EachPayment(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting payments", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	resp, err := h.Service.Billing().GetPayments(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting all payments", http.StatusInternalServerError, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// GetStudentBalance godoc
+// @Security ApiKeyAuth
+// @Router		/student/{id}/balance [GET]
+// @Summary		get a student's balance
+// @Description	This api get what the student owes with the overdue part of it, and the statement of the student's invoices and payments with the running balance after each of them. Students can only get their own balance
+// @Tags		billing
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.StudentBalance
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetStudentBalance(c *gin.Context) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating studentId", http.StatusBadRequest, err)
+		return
+	}
+	if authInfo.UserRole == config.STUDENT_TYPE && authInfo.UserID != id {
+		handleResponse(c, h.Log, "students can only get their own balance", http.StatusForbidden, "forbidden")
+		return
+	}
+
+	balance, err := h.Service.Billing().GetBalance(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting balance", http.StatusInternalServerError, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, balance)
+}
+
+// GetDebtors godoc
+// @Security ApiKeyAuth
+// @Router		/reports/debtors [GET]
+// @Summary		get debtors
+// @Description	This api get the students with overdue invoices, the ones owing the most first, with what they owe in total and the total overdue of all of them. It can be kept to a group and to the students owing at least min_overdue. Only teachers can get it. format=csv|xlsx or the Accept header exports all of them
+// @Tags		billing
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		group_id query string false "group_id"
+// @Param		min_overdue query integer false "min_overdue"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.DebtorsResponse
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetDebtors(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can get debtors"); !ok {
+		return
+	}
+	if !h.validateQueryIds(c, "group_id") {
+		return
+	}
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing page", http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.DebtorsRequest{
+		GroupId: c.Query("group_id"),
+		Page:    page,
+		Limit:   limit,
+	}
+	if value := c.Query("min_overdue"); value != "" {
+		if req.MinOverdue, err = strconv.ParseInt(value, 10, 64); err != nil {
+			handleResponse(c, h.Log, "error while parsing min_overdue", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "debtors", func(write func(models.Debtor) error) error {
+			return h.Service.Billing().EachDebtor(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting debtors", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	resp, err := h.Service.Billing().GetDebtors(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting debtors", http.StatusInternalServerError, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
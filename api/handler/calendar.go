@@ -0,0 +1,302 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg/export"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateAcademicYear godoc
+// @Security ApiKeyAuth
+// @Router		/academic-year [POST]
+// @Summary		create an academic year
+// @Description	This api create an academic year and returns its id, dates are YYYY-MM-DD
+// @Tags		calendar
+// @Accept		json
+// @Produce		json
+// @Param		academic_year body models.AddAcademicYear true "academic_year"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) CreateAcademicYear(c *gin.Context) {
+	year := models.AddAcademicYear{}
+
+	if err := c.ShouldBindJSON(&year); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.Service.Calendar().CreateAcademicYear(c.Request.Context(), year)
+	if err != nil {
+		handleResponse(c, h.Log, "error while creating academic year", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Created successfully", http.StatusOK, id)
+}
+
+// DeleteAcademicYear godoc
+// @Security ApiKeyAuth
+// @Router		/academic-year/{id} [DELETE]
+// @Summary		delete an academic year
+// @Description	This api delete an academic year with its terms
+// @Tags		calendar
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) DeleteAcademicYear(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating academicYearId", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.Service.Calendar().DeleteAcademicYear(c.Request.Context(), id); err != nil {
+		handleResponse(c, h.Log, "error while deleting academic year", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Deleted successfully", http.StatusOK, id)
+}
+
+// GetAllAcademicYears godoc
+// @Security ApiKeyAuth
+// @Router		/academic-years [GET]
+// @Summary		get academic years
+// @Description	This api get all academic years with their terms, format=csv|xlsx or the Accept header exports the years without their terms
+// @Tags		calendar
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetAllAcademicYears(c *gin.Context) {
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.Service.Calendar().GetAcademicYears(c.Request.Context())
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting academic years", http.StatusInternalServerError, err.Error())
+		return
+	}
+	if format != export.JSON {
+		if err := exportRows(h, c, format, "academic-years", resp.AcademicYears); err != nil {
+			handleResponse(c, h.Log, "error while exporting academic years", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// CreateTerm godoc
+// @Security ApiKeyAuth
+// @Router		/term [POST]
+// @Summary		create a term
+// @Description	This api create a term within its academic year and returns its id, dates are YYYY-MM-DD
+// @Tags		calendar
+// @Accept		json
+// @Produce		json
+// @Param		term body models.AddTerm true "term"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) CreateTerm(c *gin.Context) {
+	term := models.AddTerm{}
+
+	if err := c.ShouldBindJSON(&term); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := uuid.Validate(term.AcademicYearId); err != nil {
+		handleResponse(c, h.Log, "error while validating academicYearId", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.Service.Calendar().CreateTerm(c.Request.Context(), term)
+	if err != nil {
+		handleResponse(c, h.Log, "error while creating term", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Created successfully", http.StatusOK, id)
+}
+
+// GetTerm godoc
+// @Security ApiKeyAuth
+// @Router		/term/{id} [GET]
+// @Summary		get a term
+// @Description	This api get a term
+// @Tags		calendar
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetTerm(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating termId", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	term, err := h.Service.Calendar().GetTerm(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting term", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Got successfully", http.StatusOK, term)
+}
+
+// DeleteTerm godoc
+// @Security ApiKeyAuth
+// @Router		/term/{id} [DELETE]
+// @Summary		delete a term
+// @Description	This api delete a term
+// @Tags		calendar
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) DeleteTerm(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating termId", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.Service.Calendar().DeleteTerm(c.Request.Context(), id); err != nil {
+		handleResponse(c, h.Log, "error while deleting term", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Deleted successfully", http.StatusOK, id)
+}
+
+// CreateClosure godoc
+// @Security ApiKeyAuth
+// @Router		/closure [POST]
+// @Summary		create a holiday or closure
+// @Description	This api create a holiday or closure and returns its id, no lessons take place from from_date to to_date (YYYY-MM-DD)
+// @Tags		calendar
+// @Accept		json
+// @Produce		json
+// @Param		closure body models.AddClosure true "closure"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) CreateClosure(c *gin.Context) {
+	closure := models.AddClosure{}
+
+	if err := c.ShouldBindJSON(&closure); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.Service.Calendar().CreateClosure(c.Request.Context(), closure)
+	if err != nil {
+		handleResponse(c, h.Log, "error while creating closure", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Created successfully", http.StatusOK, id)
+}
+
+// DeleteClosure godoc
+// @Security ApiKeyAuth
+// @Router		/closure/{id} [DELETE]
+// @Summary		delete a closure
+// @Description	This api delete a holiday or closure
+// @Tags		calendar
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) DeleteClosure(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating closureId", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.Service.Calendar().DeleteClosure(c.Request.Context(), id); err != nil {
+		handleResponse(c, h.Log, "error while deleting closure", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Deleted successfully", http.StatusOK, id)
+}
+
+// GetAllClosures godoc
+// @Security ApiKeyAuth
+// @Router		/closures [GET]
+// @Summary		get closures
+// @Description	This api get the holidays and closures between the dates or within a term, format=csv|xlsx or the Accept header exports them
+// @Tags		calendar
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		from_date query string false "YYYY-MM-DD"
+// @Param		to_date query string false "YYYY-MM-DD"
+// @Param		term_id query string false "term_id"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetAllClosures(c *gin.Context) {
+	req := models.GetAllClosuresRequest{
+		FromDate: c.Query("from_date"),
+		ToDate:   c.Query("to_date"),
+		TermId:   c.Query("term_id"),
+	}
+	if req.TermId != "" {
+		if err := uuid.Validate(req.TermId); err != nil {
+			handleResponse(c, h.Log, "error while validating termId", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.Service.Calendar().GetClosures(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting closures", http.StatusInternalServerError, err.Error())
+		return
+	}
+	if format != export.JSON {
+		if err := exportRows(h, c, format, "closures", resp.Closures); err != nil {
+			handleResponse(c, h.Log, "error while exporting closures", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
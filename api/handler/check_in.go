@@ -0,0 +1,188 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/skip2/go-qrcode"
+)
+
+// GenerateCheckInCode godoc
+// @Security ApiKeyAuth
+// @Router		/check-in/code [POST]
+// @Summary		generate a check-in code
+// @Description	This api generates a short-lived check-in code for the teacher's lesson in progress
+// @Tags		check_in
+// @Accept		json
+// @Produce		json
+// @Param		check_in body models.CheckInCodeRequest false "check_in"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		401  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GenerateCheckInCode(c *gin.Context) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+	if authInfo.UserRole != config.TEACHER_TYPE {
+		handleResponse(c, h.Log, "only teachers can generate check-in codes", http.StatusForbidden, "forbidden")
+		return
+	}
+
+	req := models.CheckInCodeRequest{}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	code, err := h.Service.CheckIn().GenerateCode(c.Request.Context(), authInfo.UserID, req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while generating check-in code", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Generated successfully", http.StatusOK, code)
+}
+
+// GetCheckInQR godoc
+// @Security ApiKeyAuth
+// @Router		/check-in/code.png [GET]
+// @Summary		generate a check-in QR code
+// @Description	This api generates a short-lived check-in code for the teacher's lesson in progress and renders it as a PNG QR code
+// @Tags		check_in
+// @Produce		png
+// @Param		latitude query number false "room latitude"
+// @Param		longitude query number false "room longitude"
+// @Param		radius query number false "geofence radius in meters"
+// @Param		size query integer false "image size in pixels"
+// @Success		200  {file}  file
+// @Failure		400  {object}  models.Response
+// @Failure		401  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetCheckInQR(c *gin.Context) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+	if authInfo.UserRole != config.TEACHER_TYPE {
+		handleResponse(c, h.Log, "only teachers can generate check-in codes", http.StatusForbidden, "forbidden")
+		return
+	}
+
+	req := models.CheckInCodeRequest{}
+	if c.Query("latitude") != "" || c.Query("longitude") != "" || c.Query("radius") != "" {
+		geofence, err := parseGeofenceQuery(c)
+		if err != nil {
+			handleResponse(c, h.Log, "error while parsing geofence", http.StatusBadRequest, err.Error())
+			return
+		}
+		req.Geofence = &geofence
+	}
+
+	size := 256
+	if sizeStr := c.Query("size"); sizeStr != "" {
+		size, err = strconv.Atoi(sizeStr)
+		if err != nil || size < 64 || size > 1024 {
+			handleResponse(c, h.Log, "error while parsing size", http.StatusBadRequest, "size must be between 64 and 1024")
+			return
+		}
+	}
+
+	code, err := h.Service.CheckIn().GenerateCode(c.Request.Context(), authInfo.UserID, req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while generating check-in code", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	png, err := qrcode.Encode(code.QRPayload, qrcode.Medium, size)
+	if err != nil {
+		handleResponse(c, h.Log, "error while rendering QR code", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Check-In-Code", code.Code)
+	c.Header("X-Expires-In", strconv.Itoa(code.ExpiresIn))
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// CheckIn godoc
+// @Security ApiKeyAuth
+// @Router		/check-in [POST]
+// @Summary		check in to a lesson
+// @Description	This api marks the student present in the lesson the check-in code was issued for
+// @Tags		check_in
+// @Accept		json
+// @Produce		json
+// @Param		check_in body models.CheckInRequest true "check_in"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		401  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		409  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) CheckIn(c *gin.Context) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+	if authInfo.UserRole != config.STUDENT_TYPE {
+		handleResponse(c, h.Log, "only students can check in", http.StatusForbidden, "forbidden")
+		return
+	}
+
+	req := models.CheckInRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	timeTableId, err := h.Service.CheckIn().Redeem(c.Request.Context(), authInfo.UserID, req)
+	if errors.Is(err, service.ErrAlreadyCheckedIn) {
+		handleResponse(c, h.Log, "error while checking in", http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		handleResponse(c, h.Log, "error while checking in", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Checked in successfully", http.StatusOK, timeTableId)
+}
+
+func parseGeofenceQuery(c *gin.Context) (models.Geofence, error) {
+	geofence := models.Geofence{}
+
+	latitude, err := strconv.ParseFloat(c.Query("latitude"), 64)
+	if err != nil {
+		return geofence, err
+	}
+	longitude, err := strconv.ParseFloat(c.Query("longitude"), 64)
+	if err != nil {
+		return geofence, err
+	}
+	radius, err := strconv.ParseFloat(c.Query("radius"), 64)
+	if err != nil {
+		return geofence, err
+	}
+
+	geofence.Latitude = latitude
+	geofence.Longitude = longitude
+	geofence.RadiusMeters = radius
+
+	return geofence, nil
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDashboard godoc
+// @Security ApiKeyAuth
+// @Router		/dashboard [GET]
+// @Summary		get the dashboard
+// @Description	This api gets the active and inactive students, the new students per month, today's lessons, the attendance rate, the revenue and the subjects with the most lessons. Without from_date and to_date the new students are the ones of the last 12 months, the attendance is the one of this week and the revenue and top subjects are the ones of this month. The figures are cached for a few minutes and refreshed on writes changing them. Only teachers can get it
+// @Tags		dashboard
+// @Accept		json
+// @Produce		json
+// @Param		from_date query string false "from_date"
+// @Param		to_date query string false "to_date"
+// @Success		200  {object}  models.Dashboard
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetDashboard(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can get the dashboard"); !ok {
+		return
+	}
+
+	dashboard, err := h.Service.Dashboard().Get(c.Request.Context(), models.DashboardRequest{
+		FromDate: c.Query("from_date"),
+		ToDate:   c.Query("to_date"),
+	})
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting dashboard", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "request successful", http.StatusOK, dashboard)
+}
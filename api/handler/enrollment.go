@@ -0,0 +1,190 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg/export"
+	"backend_course/lms/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// enrollmentStatus maps enrollment errors to their status codes.
+func enrollmentStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrNotSubjectTeacher), errors.Is(err, service.ErrNotYourEnrollment):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrPrerequisitesNotMet), errors.Is(err, service.ErrAlreadyEnrolled):
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+// Enroll godoc
+// @Security ApiKeyAuth
+// @Router		/subject/{id}/enrollment [POST]
+// @Summary		enroll in a subject
+// @Description	This api enrolls a student in a subject and returns the enrollment's id, it is rejected until every prerequisite of the subject is completed. Students enroll themselves, teachers give the student_id
+// @Tags		enrollment
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		enrollment body models.EnrollRequest false "enrollment"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		409  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) Enroll(c *gin.Context) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating subjectId", http.StatusBadRequest, err)
+		return
+	}
+
+	studentId := authInfo.UserID
+	if authInfo.UserRole == config.TEACHER_TYPE {
+		req := models.EnrollRequest{}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := req.Validate(c.Request.Context()); err != nil {
+			handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+			return
+		}
+		studentId = req.StudentId
+	}
+
+	enrollmentId, err := h.Service.Enrollment().Enroll(c.Request.Context(), studentId, id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while enrolling", enrollmentStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Enrolled successfully", http.StatusOK, enrollmentId)
+}
+
+// GetAllEnrollments godoc
+// @Security ApiKeyAuth
+// @Router		/enrollments [GET]
+// @Summary		get enrollments
+// @Description	This api get enrollments filtered by student, subject and status, students only get their own, format=csv|xlsx or the Accept header exports all of them
+// @Tags		enrollment
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		student_id query string false "student_id"
+// @Param		subject_id query string false "subject_id"
+// @Param		status query string false "status"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetAllEnrollments(c *gin.Context) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+	if !h.validateQueryIds(c, "student_id", "subject_id") {
+		return
+	}
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing page", http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.GetAllEnrollmentsRequest{
+		StudentId: c.Query("student_id"),
+		SubjectId: c.Query("subject_id"),
+		Status:    c.Query("status"),
+		Page:      page,
+		Limit:     limit,
+	}
+	if authInfo.UserRole == config.STUDENT_TYPE {
+		req.StudentId = authInfo.UserID
+	}
+
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "enrollments", func(write func(models.Enrollment) error) error {
+			return h.Service.Enrollment().EachEnrollment(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting enrollments", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	resp, err := h.Service.Enrollment().GetEnrollments(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting all enrollments", http.StatusInternalServerError, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// UpdateEnrollment godoc
+// @Security ApiKeyAuth
+// @Router		/enrollment/{id} [PATCH]
+// @Summary		update an enrollment
+// @Description	This api sets the status of an enrollment to active, completed or dropped. Students can only drop their own enrollments, the subject's teachers can set any status
+// @Tags		enrollment
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		enrollment body models.UpdateEnrollment true "enrollment"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) UpdateEnrollment(c *gin.Context) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating enrollmentId", http.StatusBadRequest, err)
+		return
+	}
+
+	req := models.UpdateEnrollment{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.Service.Enrollment().UpdateEnrollment(c.Request.Context(), authInfo, id, req); err != nil {
+		handleResponse(c, h.Log, "error while updating enrollment", enrollmentStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, id)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"backend_course/lms/pkg/export"
+	"backend_course/lms/pkg/logger"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportFormat is the format a list is asked in by the format query parameter or the
+// Accept header, it responds 400 to a format which is not supported.
+func (h Handler) exportFormat(c *gin.Context) (string, bool) {
+	format, err := export.Format(c.Query("format"), c.GetHeader("Accept"))
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating format", http.StatusBadRequest, err.Error())
+		return "", false
+	}
+	return format, true
+}
+
+// writeExport streams the rows each hands to write as the file name in format. The error
+// is returned when nothing has been sent yet, a failure after that ends the response.
+func writeExport[T any](h Handler, c *gin.Context, format, name string, each func(write func(T) error) error) error {
+	header := c.Writer.Header()
+	header.Set("Content-Type", export.ContentType(format))
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+
+	var row T
+	w, err := export.NewWriter(format, c.Writer, row)
+	if err == nil {
+		err = each(func(row T) error {
+			return w.Write(row)
+		})
+		if err == nil {
+			err = w.Close()
+		} else {
+			w.Discard()
+		}
+	}
+	if err == nil {
+		return nil
+	}
+
+	if !c.Writer.Written() {
+		header.Del("Content-Type")
+		header.Del("Content-Disposition")
+		return err
+	}
+	h.Log.Error("failed to export "+name+": ", logger.Error(err))
+	c.Abort()
+	return nil
+}
+
+// exportRows writes rows which are already read. Only short lists, which are read whole
+// anyway, are exported this way, the others stream from the repositories.
+func exportRows[T any](h Handler, c *gin.Context, format, name string, rows []T) error {
+	return writeExport(h, c, format, name, func(write func(T) error) error {
+		for _, row := range rows {
+			if err := write(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
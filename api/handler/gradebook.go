@@ -0,0 +1,289 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg/export"
+	"backend_course/lms/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// classworkStatus maps the gradebook, homework and quiz errors to their status code.
+func classworkStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrNotSubjectTeacher), errors.Is(err, service.ErrNotInGroup),
+		errors.Is(err, service.ErrNotYourAttempt):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrSubmissionGraded), errors.Is(err, service.ErrQuizClosed),
+		errors.Is(err, service.ErrNoAttemptsLeft), errors.Is(err, service.ErrAttemptSubmitted),
+		errors.Is(err, service.ErrAttemptExpired):
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+// CreateAssessment godoc
+// @Security ApiKeyAuth
+// @Router		/assessment [POST]
+// @Summary		create an assessment
+// @Description	This api create an assessment of a group in a subject and returns its id, kind is quiz, exam or homework. Only the subject's teachers can create it
+// @Tags		gradebook
+// @Accept		json
+// @Produce		json
+// @Param		assessment body models.AddAssessment true "assessment"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) CreateAssessment(c *gin.Context) {
+	teacherId, ok := h.teacherOnly(c, "only teachers can create assessments")
+	if !ok {
+		return
+	}
+
+	assessment := models.AddAssessment{}
+	if err := c.ShouldBindJSON(&assessment); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := assessment.Validate(c.Request.Context()); err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := h.Service.Gradebook().CreateAssessment(c.Request.Context(), teacherId, assessment)
+	if err != nil {
+		handleResponse(c, h.Log, "error while creating assessment", classworkStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Created successfully", http.StatusOK, id)
+}
+
+// GetAllAssessments godoc
+// @Security ApiKeyAuth
+// @Router		/assessments [GET]
+// @Summary		get assessments
+// @Description	This api get assessments filtered by group, subject and term, format=csv|xlsx or the Accept header exports all of them
+// @Tags		gradebook
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		group_id query string false "group_id"
+// @Param		subject_id query string false "subject_id"
+// @Param		term_id query string false "term_id"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetAllAssessments(c *gin.Context) {
+	if !h.validateQueryIds(c, "group_id", "subject_id", "term_id") {
+		return
+	}
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing page", http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.GetAllAssessmentsRequest{
+		GroupId:   c.Query("group_id"),
+		SubjectId: c.Query("subject_id"),
+		TermId:    c.Query("term_id"),
+		Page:      page,
+		Limit:     limit,
+	}
+
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "assessments", func(write func(models.Assessment) error) error {
+			return h.Service.Gradebook().EachAssessment(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting assessments", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	resp, err := h.Service.Gradebook().GetAssessments(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting all assessments", http.StatusInternalServerError, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// DeleteAssessment godoc
+// @Security ApiKeyAuth
+// @Router		/assessment/{id} [DELETE]
+// @Summary		delete an assessment
+// @Description	This api delete an assessment with its scores, only the subject's teachers can delete it
+// @Tags		gradebook
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) DeleteAssessment(c *gin.Context) {
+	teacherId, ok := h.teacherOnly(c, "only teachers can delete assessments")
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating assessmentId", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.Service.Gradebook().DeleteAssessment(c.Request.Context(), teacherId, id); err != nil {
+		handleResponse(c, h.Log, "error while deleting assessment", classworkStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Deleted successfully", http.StatusOK, id)
+}
+
+// SetScores godoc
+// @Security ApiKeyAuth
+// @Router		/assessment/{id}/scores [PUT]
+// @Summary		set scores of an assessment
+// @Description	This api records the scores of students of the assessment's group, a student's earlier score is replaced. Only the subject's teachers can set them
+// @Tags		gradebook
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		scores body models.SetScoresRequest true "scores"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) SetScores(c *gin.Context) {
+	teacherId, ok := h.teacherOnly(c, "only teachers can set scores")
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating assessmentId", http.StatusBadRequest, err)
+		return
+	}
+
+	req := models.SetScoresRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := req.Validate(c.Request.Context()); err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.Service.Gradebook().SetScores(c.Request.Context(), teacherId, id, req); err != nil {
+		handleResponse(c, h.Log, "error while setting scores", classworkStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, id)
+}
+
+// GetMyGrades godoc
+// @Security ApiKeyAuth
+// @Router		/my-grades [GET]
+// @Summary		get my grades
+// @Description	This api get the logged in student's weighted grade of every subject, scale is 5-point, 100-point or letter and defaults to the school's one
+// @Tags		gradebook
+// @Accept		json
+// @Produce		json
+// @Param		subject_id query string false "subject_id"
+// @Param		term_id query string false "term_id"
+// @Param		scale query string false "scale"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetMyGrades(c *gin.Context) {
+	studentId, ok := h.studentOnly(c, "only students have grades")
+	if !ok {
+		return
+	}
+
+	if !h.validateQueryIds(c, "subject_id", "term_id") {
+		return
+	}
+
+	resp, err := h.Service.Gradebook().GetStudentGrades(c.Request.Context(), models.GradesRequest{
+		StudentId: studentId,
+		SubjectId: c.Query("subject_id"),
+		TermId:    c.Query("term_id"),
+		Scale:     c.Query("scale"),
+	})
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting grades", http.StatusBadRequest, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// GetClassGrades godoc
+// @Security ApiKeyAuth
+// @Router		/group/{id}/grades [GET]
+// @Summary		get a group's grades
+// @Description	This api get the weighted grade of every student of the group in a subject with the class average
+// @Tags		gradebook
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		subject_id query string true "subject_id"
+// @Param		term_id query string false "term_id"
+// @Param		scale query string false "scale"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetClassGrades(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can see a group's grades"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating groupId", http.StatusBadRequest, err)
+		return
+	}
+	if !h.validateQueryIds(c, "subject_id", "term_id") {
+		return
+	}
+
+	resp, err := h.Service.Gradebook().GetClassGrades(c.Request.Context(), models.GradesRequest{
+		GroupId:   id,
+		SubjectId: c.Query("subject_id"),
+		TermId:    c.Query("term_id"),
+		Scale:     c.Query("scale"),
+	})
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting grades", http.StatusBadRequest, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
@@ -0,0 +1,238 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg/export"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateGroup godoc
+// @Security ApiKeyAuth
+// @Router		/group [POST]
+// @Summary		create a group
+// @Description	This api create a group and returns its id
+// @Tags		group
+// @Accept		json
+// @Produce		json
+// @Param		group body models.AddGroup true "group"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) CreateGroup(c *gin.Context) {
+	group := models.AddGroup{}
+
+	if err := c.ShouldBindJSON(&group); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.Service.Group().Create(c.Request.Context(), group)
+	if err != nil {
+		handleResponse(c, h.Log, "error while creating group", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Created successfully", http.StatusOK, id)
+}
+
+// UpdateGroup godoc
+// @Security ApiKeyAuth
+// @Router		/group/{id} [PUT]
+// @Summary		update a group
+// @Description	This api update a group and returns its id
+// @Tags		group
+// @Accept		json
+// @Produce		json
+// @Param		group body models.AddGroup true "group"
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) UpdateGroup(c *gin.Context) {
+	group := models.Group{}
+
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating groupId", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.ShouldBindJSON(&group); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	group.Id = id
+
+	id, err := h.Service.Group().Update(c.Request.Context(), group)
+	if err != nil {
+		handleResponse(c, h.Log, "error while updating group", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, id)
+}
+
+// DeleteGroup godoc
+// @Security ApiKeyAuth
+// @Router		/group/{id} [DELETE]
+// @Summary		delete a group
+// @Description	This api delete a group
+// @Tags		group
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) DeleteGroup(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating groupId", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.Service.Group().Delete(c.Request.Context(), id); err != nil {
+		handleResponse(c, h.Log, "error while deleting group", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Deleted successfully", http.StatusOK, id)
+}
+
+// GetGroup godoc
+// @Security ApiKeyAuth
+// @Router		/group/{id} [GET]
+// @Summary		get a group
+// @Description	This api get a group with its students' ids
+// @Tags		group
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetGroup(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating groupId", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	group, err := h.Service.Group().GetGroup(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting group", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Got successfully", http.StatusOK, group)
+}
+
+// GetAllGroups godoc
+// @Security ApiKeyAuth
+// @Router		/groups [GET]
+// @Summary		get groups
+// @Description	This api get all groups, format=csv|xlsx or the Accept header exports all of them
+// @Tags		group
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		search query string false "search"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetAllGroups(c *gin.Context) {
+	search := c.Query("search")
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing page", http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.GetAllGroupsRequest{
+		Search: search,
+		Page:   page,
+		Limit:  limit,
+	}
+
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "groups", func(write func(models.Group) error) error {
+			return h.Service.Group().Each(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting groups", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	resp, err := h.Service.Group().GetAll(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting all groups", http.StatusInternalServerError, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// SetGroupStudents godoc
+// @Security ApiKeyAuth
+// @Router		/group/{id}/students [PUT]
+// @Summary		set a group's students
+// @Description	This api replaces the students of a group
+// @Tags		group
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		students body models.GroupStudents true "students"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) SetGroupStudents(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating groupId", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.GroupStudents{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, studentId := range req.StudentIds {
+		if err := uuid.Validate(studentId); err != nil {
+			handleResponse(c, h.Log, "error while validating studentId", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if err := h.Service.Group().SetStudents(c.Request.Context(), id, req); err != nil {
+		handleResponse(c, h.Log, "error while setting group's students", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, id)
+}
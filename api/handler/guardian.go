@@ -0,0 +1,439 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg"
+	"backend_course/lms/pkg/check"
+	"backend_course/lms/pkg/export"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateGuardian godoc
+// @Security ApiKeyAuth
+// @Router		/guardian [POST]
+// @Summary		create a guardian
+// @Description	This api create a guardian account and returns its id, only teachers can create it
+// @Tags		guardian
+// @Accept		json
+// @Produce		json
+// @Param		guardian body models.AddGuardian true "guardian"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) CreateGuardian(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can create guardians"); !ok {
+		return
+	}
+
+	guardian := models.AddGuardian{}
+	if err := c.ShouldBindJSON(&guardian); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if guardian.Phone != "" {
+		if err := check.ValidatePhone(guardian.Phone); err != nil {
+			handleResponse(c, h.Log, "error with phone number: ", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if err := check.ValidatePassword(guardian.Password); err != nil {
+		handleResponse(c, h.Log, "error with password : ", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := check.ValidateEmail(guardian.Email); err != nil {
+		handleResponse(c, h.Log, "error with email: ", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var err error
+	guardian.Password, err = pkg.HashPassword(guardian.Password)
+	if err != nil {
+		handleResponse(c, h.Log, "error while hashing password", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.Service.Guardian().Create(c.Request.Context(), guardian)
+	if err != nil {
+		handleResponse(c, h.Log, "error while creating guardian", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Created successfully", http.StatusOK, id)
+}
+
+// UpdateGuardian godoc
+// @Security ApiKeyAuth
+// @Router		/guardian/{id} [PUT]
+// @Summary		update a guardian
+// @Description	This api update a guardian, the password is kept when it is empty. Only teachers can update it
+// @Tags		guardian
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		guardian body models.AddGuardian true "guardian"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) UpdateGuardian(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can update guardians"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating guardianId", http.StatusBadRequest, err)
+		return
+	}
+
+	guardian := models.AddGuardian{}
+	if err := c.ShouldBindJSON(&guardian); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if guardian.Phone != "" {
+		if err := check.ValidatePhone(guardian.Phone); err != nil {
+			handleResponse(c, h.Log, "error with phone number: ", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if err := check.ValidateEmail(guardian.Email); err != nil {
+		handleResponse(c, h.Log, "error with email: ", http.StatusBadRequest, err.Error())
+		return
+	}
+	if guardian.Password != "" {
+		if err := check.ValidatePassword(guardian.Password); err != nil {
+			handleResponse(c, h.Log, "error with password : ", http.StatusBadRequest, err.Error())
+			return
+		}
+		var err error
+		if guardian.Password, err = pkg.HashPassword(guardian.Password); err != nil {
+			handleResponse(c, h.Log, "error while hashing password", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if err := h.Service.Guardian().Update(c.Request.Context(), id, guardian); err != nil {
+		handleResponse(c, h.Log, "error while updating guardian", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, id)
+}
+
+// DeleteGuardian godoc
+// @Security ApiKeyAuth
+// @Router		/guardian/{id} [DELETE]
+// @Summary		delete a guardian
+// @Description	This api delete a guardian, only teachers can delete it
+// @Tags		guardian
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) DeleteGuardian(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can delete guardians"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating guardianId", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.Service.Guardian().Delete(c.Request.Context(), id); err != nil {
+		handleResponse(c, h.Log, "error while deleting guardian", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Deleted successfully", http.StatusOK, id)
+}
+
+// GetGuardian godoc
+// @Security ApiKeyAuth
+// @Router		/guardian/{id} [GET]
+// @Summary		get a guardian
+// @Description	This api get a guardian with the linked students, for teachers and the guardian
+// @Tags		guardian
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetGuardian(c *gin.Context) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating guardianId", http.StatusBadRequest, err)
+		return
+	}
+	if authInfo.UserRole != config.TEACHER_TYPE && !(authInfo.UserRole == config.GUARDIAN_TYPE && authInfo.UserID == id) {
+		handleResponse(c, h.Log, "guardians can only get their own account", http.StatusForbidden, "forbidden")
+		return
+	}
+
+	resp, err := h.Service.Guardian().GetGuardian(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting guardian", http.StatusNotFound, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Got successfully", http.StatusOK, resp)
+}
+
+// GetAllGuardians godoc
+// @Security ApiKeyAuth
+// @Router		/guardians [GET]
+// @Summary		get guardians
+// @Description	This api get guardians filtered by name and student, only teachers can see them, format=csv|xlsx or the Accept header exports all of them
+// @Tags		guardian
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		search query string false "search"
+// @Param		student_id query string false "student_id"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetAllGuardians(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can see guardians"); !ok {
+		return
+	}
+	if !h.validateQueryIds(c, "student_id") {
+		return
+	}
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing page", http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.GetAllGuardiansRequest{
+		Search:    c.Query("search"),
+		StudentId: c.Query("student_id"),
+		Page:      page,
+		Limit:     limit,
+	}
+
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "guardians", func(write func(models.Guardian) error) error {
+			return h.Service.Guardian().Each(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting guardians", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	resp, err := h.Service.Guardian().GetAll(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting all guardians", http.StatusInternalServerError, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// SetGuardianStudents godoc
+// @Security ApiKeyAuth
+// @Router		/guardian/{id}/students [PUT]
+// @Summary		link students to a guardian
+// @Description	This api replaces the students linked to a guardian, relation is mother, father, guardian or other. Only teachers can link them
+// @Tags		guardian
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		students body models.SetGuardianStudents true "students"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) SetGuardianStudents(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can link students to guardians"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating guardianId", http.StatusBadRequest, err)
+		return
+	}
+
+	req := models.SetGuardianStudents{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := req.Validate(c.Request.Context()); err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.Service.Guardian().SetStudents(c.Request.Context(), id, req); err != nil {
+		handleResponse(c, h.Log, "error while setting guardian's students", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, id)
+}
+
+// GetMyChildren godoc
+// @Security ApiKeyAuth
+// @Router		/my-children [GET]
+// @Summary		get my children
+// @Description	This api get the students linked to the guardian
+// @Tags		guardian
+// @Accept		json
+// @Produce		json
+// @Success		200  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetMyChildren(c *gin.Context) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+	if authInfo.UserRole != config.GUARDIAN_TYPE {
+		handleResponse(c, h.Log, "only guardians have children", http.StatusForbidden, "forbidden")
+		return
+	}
+
+	resp, err := h.Service.Guardian().GetChildren(c.Request.Context(), authInfo.UserID)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting children", http.StatusInternalServerError, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// GetChildTimetable godoc
+// @Security ApiKeyAuth
+// @Router		/child/{id}/timetable [GET]
+// @Summary		get a child's timetable
+// @Description	This api get the lessons of a linked student from from_date to to_date with their attendance
+// @Tags		guardian
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		from_date query string true "from_date"
+// @Param		to_date query string true "to_date"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetChildTimetable(c *gin.Context) {
+	studentId, ok := h.childOnly(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.Service.Time().GetStudentTimetable(c.Request.Context(), studentId, c.Query("from_date"), c.Query("to_date"))
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting timetable", http.StatusBadRequest, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// GetChildAttendance godoc
+// @Security ApiKeyAuth
+// @Router		/child/{id}/attendance [GET]
+// @Summary		get a child's attendance
+// @Description	This api get the attendance summary and the past lessons of a linked student from from_date to to_date
+// @Tags		guardian
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		from_date query string true "from_date"
+// @Param		to_date query string true "to_date"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetChildAttendance(c *gin.Context) {
+	studentId, ok := h.childOnly(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.Service.Attendance().GetStudentAttendance(c.Request.Context(), studentId, c.Query("from_date"), c.Query("to_date"))
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting attendance", http.StatusBadRequest, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// GetChildGrades godoc
+// @Security ApiKeyAuth
+// @Router		/child/{id}/grades [GET]
+// @Summary		get a child's grades
+// @Description	This api get the weighted grade of every subject of a linked student, scale is 5-point, 100-point or letter
+// @Tags		guardian
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		subject_id query string false "subject_id"
+// @Param		term_id query string false "term_id"
+// @Param		scale query string false "scale"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetChildGrades(c *gin.Context) {
+	studentId, ok := h.childOnly(c)
+	if !ok {
+		return
+	}
+	if !h.validateQueryIds(c, "subject_id", "term_id") {
+		return
+	}
+
+	resp, err := h.Service.Gradebook().GetStudentGrades(c.Request.Context(), models.GradesRequest{
+		StudentId: studentId,
+		SubjectId: c.Query("subject_id"),
+		TermId:    c.Query("term_id"),
+		Scale:     c.Query("scale"),
+	})
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting grades", http.StatusBadRequest, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
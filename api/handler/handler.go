@@ -8,6 +8,7 @@ import (
 	"backend_course/lms/service"
 	"backend_course/lms/storage"
 	"errors"
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -80,6 +81,20 @@ func ParseLimitQueryParam(c *gin.Context) (uint64, error) {
 	return limit, nil
 }
 
+// optionalIntQuery parses an integer query parameter, it is nil when the parameter is not
+// given.
+func optionalIntQuery(c *gin.Context, name string) (*int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
 func getAuthInfo(c *gin.Context) (models.AuthInfo, error) {
 	accessToken := c.GetHeader("Authorization")
 	if accessToken == "" {
@@ -92,7 +107,7 @@ func getAuthInfo(c *gin.Context) (models.AuthInfo, error) {
 	}
 
 	role := m["user_role"].(string)
-	if !(role == config.TEACHER_TYPE || role == config.STUDENT_TYPE) {
+	if !(role == config.TEACHER_TYPE || role == config.STUDENT_TYPE || role == config.GUARDIAN_TYPE) {
 		return models.AuthInfo{}, errors.New("unauthorized")
 	}
 
@@ -101,3 +116,77 @@ func getAuthInfo(c *gin.Context) (models.AuthInfo, error) {
 		UserRole: role,
 	}, nil
 }
+
+// teacherOnly answers 403 unless the request comes from a teacher, it returns the
+// teacher's id.
+func (h Handler) teacherOnly(c *gin.Context, msg string) (string, bool) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return "", false
+	}
+	if authInfo.UserRole != config.TEACHER_TYPE {
+		handleResponse(c, h.Log, msg, http.StatusForbidden, "forbidden")
+		return "", false
+	}
+	return authInfo.UserID, true
+}
+
+// studentOnly answers 403 unless the request comes from a student, it returns the
+// student's id.
+func (h Handler) studentOnly(c *gin.Context, msg string) (string, bool) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return "", false
+	}
+	if authInfo.UserRole != config.STUDENT_TYPE {
+		handleResponse(c, h.Log, msg, http.StatusForbidden, "forbidden")
+		return "", false
+	}
+	return authInfo.UserID, true
+}
+
+// childOnly answers 403 unless the request comes from a guardian of the student in the id
+// path parameter, it returns the student's id.
+func (h Handler) childOnly(c *gin.Context) (string, bool) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return "", false
+	}
+	if authInfo.UserRole != config.GUARDIAN_TYPE {
+		handleResponse(c, h.Log, "only guardians can see their children", http.StatusForbidden, "forbidden")
+		return "", false
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating studentId", http.StatusBadRequest, err)
+		return "", false
+	}
+
+	if err := h.Service.Guardian().CheckChild(c.Request.Context(), authInfo.UserID, id); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrNotYourChild) {
+			status = http.StatusForbidden
+		}
+		handleResponse(c, h.Log, "error while checking guardian's children", status, err.Error())
+		return "", false
+	}
+	return id, true
+}
+
+// validateQueryIds checks the optional id query parameters, it answers 400 on the
+// first invalid one.
+func (h Handler) validateQueryIds(c *gin.Context, names ...string) bool {
+	for _, name := range names {
+		if id := c.Query(name); id != "" {
+			if _, err := models.ParseId(name, id); err != nil {
+				handleResponse(c, h.Log, "error while validating "+name, http.StatusBadRequest, err)
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,361 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg/export"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAssignment godoc
+// @Security ApiKeyAuth
+// @Router		/assignment [POST]
+// @Summary		create an assignment
+// @Description	This api create a homework assignment of a group in a subject with optional attachments and returns its id. Only the subject's teachers can create it
+// @Tags		homework
+// @Accept		multipart/form-data
+// @Produce		json
+// @Param		subject_id formData string true "subject_id"
+// @Param		group_id formData string true "group_id"
+// @Param		title formData string true "title"
+// @Param		description formData string false "description"
+// @Param		due_at formData string true "due_at"
+// @Param		files formData file false "attachments"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) CreateAssignment(c *gin.Context) {
+	teacherId, ok := h.teacherOnly(c, "only teachers can create assignments")
+	if !ok {
+		return
+	}
+
+	assignment := models.AddAssignment{
+		SubjectId:   c.PostForm("subject_id"),
+		GroupId:     c.PostForm("group_id"),
+		Title:       c.PostForm("title"),
+		Description: c.PostForm("description"),
+		DueAt:       c.PostForm("due_at"),
+	}
+	if err := assignment.Validate(c.Request.Context()); err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
+
+	files, err := uploadedFiles(c, "files")
+	if err != nil {
+		handleResponse(c, h.Log, "error while uploading attachments", http.StatusBadRequest, err.Error())
+		return
+	}
+	assignment.Attachments, err = saveUploads(c, "media/assignments/", files)
+	if err != nil {
+		handleResponse(c, h.Log, "couldn't save attachments", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := h.Service.Homework().CreateAssignment(c.Request.Context(), teacherId, assignment)
+	if err != nil {
+		removeUploads(assignment.Attachments)
+		handleResponse(c, h.Log, "error while creating assignment", classworkStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Created successfully", http.StatusOK, id)
+}
+
+// GetAssignment godoc
+// @Security ApiKeyAuth
+// @Router		/assignment/{id} [GET]
+// @Summary		get an assignment
+// @Description	This api get an assignment
+// @Tags		homework
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetAssignment(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating assignmentId", http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.Service.Homework().GetAssignment(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting assignment", http.StatusNotFound, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Got successfully", http.StatusOK, resp)
+}
+
+// GetAllAssignments godoc
+// @Security ApiKeyAuth
+// @Router		/assignments [GET]
+// @Summary		get assignments
+// @Description	This api get assignments filtered by group and subject, students only get the assignments of their groups, format=csv|xlsx or the Accept header exports all of them
+// @Tags		homework
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		group_id query string false "group_id"
+// @Param		subject_id query string false "subject_id"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetAllAssignments(c *gin.Context) {
+	if !h.validateQueryIds(c, "group_id", "subject_id") {
+		return
+	}
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing page", http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.GetAllAssignmentsRequest{
+		GroupId:   c.Query("group_id"),
+		SubjectId: c.Query("subject_id"),
+		Page:      page,
+		Limit:     limit,
+	}
+	if authInfo, err := getAuthInfo(c); err == nil && authInfo.UserRole == config.STUDENT_TYPE {
+		req.StudentId = authInfo.UserID
+	}
+
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "assignments", func(write func(models.Assignment) error) error {
+			return h.Service.Homework().EachAssignment(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting assignments", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	resp, err := h.Service.Homework().GetAssignments(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting all assignments", http.StatusInternalServerError, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// DeleteAssignment godoc
+// @Security ApiKeyAuth
+// @Router		/assignment/{id} [DELETE]
+// @Summary		delete an assignment
+// @Description	This api delete an assignment with its submissions, only the subject's teachers can delete it
+// @Tags		homework
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) DeleteAssignment(c *gin.Context) {
+	teacherId, ok := h.teacherOnly(c, "only teachers can delete assignments")
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating assignmentId", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.Service.Homework().DeleteAssignment(c.Request.Context(), teacherId, id); err != nil {
+		handleResponse(c, h.Log, "error while deleting assignment", classworkStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Deleted successfully", http.StatusOK, id)
+}
+
+// SubmitAssignment godoc
+// @Security ApiKeyAuth
+// @Router		/assignment/{id}/submission [POST]
+// @Summary		submit an assignment
+// @Description	This api hands in a text and files for the assignment, it is late after the due date. A submission can be resubmitted until it is graded
+// @Tags		homework
+// @Accept		multipart/form-data
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		text formData string false "text"
+// @Param		files formData file false "files"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		409  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) SubmitAssignment(c *gin.Context) {
+	studentId, ok := h.studentOnly(c, "only students can submit assignments")
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating assignmentId", http.StatusBadRequest, err)
+		return
+	}
+
+	files, err := uploadedFiles(c, "files")
+	if err != nil {
+		handleResponse(c, h.Log, "error while uploading files", http.StatusBadRequest, err.Error())
+		return
+	}
+	submission := models.AddSubmission{
+		AssignmentId: id,
+		StudentId:    studentId,
+		Text:         c.PostForm("text"),
+	}
+	submission.Files, err = saveUploads(c, "media/submissions/", files)
+	if err != nil {
+		handleResponse(c, h.Log, "couldn't save files", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	submissionId, replaced, err := h.Service.Homework().Submit(c.Request.Context(), submission)
+	if err != nil {
+		removeUploads(submission.Files)
+		handleResponse(c, h.Log, "error while submitting assignment", classworkStatus(err), err.Error())
+		return
+	}
+	removeUploads(replaced)
+
+	handleResponse(c, h.Log, "Submitted successfully", http.StatusOK, submissionId)
+}
+
+// GetMySubmission godoc
+// @Security ApiKeyAuth
+// @Router		/assignment/{id}/my-submission [GET]
+// @Summary		get my submission
+// @Description	This api get the logged in student's submission of the assignment with its status and the teacher's comment
+// @Tags		homework
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		404  {object}  models.Response
+func (h Handler) GetMySubmission(c *gin.Context) {
+	studentId, ok := h.studentOnly(c, "only students have submissions")
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating assignmentId", http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.Service.Homework().GetMySubmission(c.Request.Context(), id, studentId)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting submission", http.StatusNotFound, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Got successfully", http.StatusOK, resp)
+}
+
+// GetAssignmentSubmissions godoc
+// @Security ApiKeyAuth
+// @Router		/assignment/{id}/submissions [GET]
+// @Summary		get submissions of an assignment
+// @Description	This api get every submission of the assignment, only the subject's teachers can see them
+// @Tags		homework
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetAssignmentSubmissions(c *gin.Context) {
+	teacherId, ok := h.teacherOnly(c, "only teachers can see submissions")
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating assignmentId", http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.Service.Homework().GetSubmissions(c.Request.Context(), teacherId, id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting submissions", classworkStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// ReviewSubmission godoc
+// @Security ApiKeyAuth
+// @Router		/submission/{id} [PATCH]
+// @Summary		review a submission
+// @Description	This api grades a submission or returns it with a comment so that the student can resubmit it, status is graded or returned
+// @Tags		homework
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		review body models.ReviewSubmission true "review"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) ReviewSubmission(c *gin.Context) {
+	teacherId, ok := h.teacherOnly(c, "only teachers can review submissions")
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating submissionId", http.StatusBadRequest, err)
+		return
+	}
+
+	review := models.ReviewSubmission{}
+	if err := c.ShouldBindJSON(&review); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.Service.Homework().ReviewSubmission(c.Request.Context(), teacherId, id, review); err != nil {
+		handleResponse(c, h.Log, "error while reviewing submission", classworkStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, id)
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"backend_course/lms/pkg/export"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// importRecords reads the records of the CSV or XLSX file of an import and whether it is
+// a dry run, it responds 400 to a file which can not be read. The columns form field maps
+// columns to other headers of the file as a JSON object.
+func (h Handler) importRecords(c *gin.Context, columns []string) ([]export.Record, bool, bool) {
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			handleResponse(c, h.Log, "error while parsing dry_run", http.StatusBadRequest, err.Error())
+			return nil, false, false
+		}
+	}
+
+	files, err := uploadedFiles(c, "file")
+	if err != nil {
+		handleResponse(c, h.Log, "error while uploading file", http.StatusBadRequest, err.Error())
+		return nil, false, false
+	}
+	if len(files) == 0 {
+		handleResponse(c, h.Log, "error while uploading file", http.StatusBadRequest, errNoFile.Error())
+		return nil, false, false
+	}
+
+	var mapping map[string]string
+	if value := c.PostForm("columns"); value != "" {
+		if err := json.Unmarshal([]byte(value), &mapping); err != nil {
+			handleResponse(c, h.Log, "error while reading columns", http.StatusBadRequest, err.Error())
+			return nil, false, false
+		}
+	}
+
+	format, err := export.FileFormat(files[0].Filename)
+	if err != nil {
+		handleResponse(c, h.Log, "error while reading file", http.StatusBadRequest, err.Error())
+		return nil, false, false
+	}
+	file, err := files[0].Open()
+	if err != nil {
+		handleResponse(c, h.Log, "error while reading file", http.StatusBadRequest, err.Error())
+		return nil, false, false
+	}
+	defer file.Close()
+
+	rows, err := export.Read(format, file)
+	if err != nil {
+		handleResponse(c, h.Log, "error while reading file", http.StatusBadRequest, err.Error())
+		return nil, false, false
+	}
+	records, err := export.Records(rows, columns, mapping)
+	if err != nil {
+		handleResponse(c, h.Log, "error while reading file", http.StatusBadRequest, err.Error())
+		return nil, false, false
+	}
+	return records, dryRun, true
+}
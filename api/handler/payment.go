@@ -0,0 +1,94 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg/payment"
+	"backend_course/lms/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateCheckout godoc
+// @Security ApiKeyAuth
+// @Router		/invoice/{id}/checkout [POST]
+// @Summary		pay an invoice online
+// @Description	This api starts paying what is left of an invoice through a payment provider and returns the page to pay it on, the provider calls back once it is paid. Students can only pay their own invoices
+// @Tags		billing
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		checkout body models.CreateCheckout true "checkout"
+// @Success		200  {object}  models.Checkout
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		409  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) CreateCheckout(c *gin.Context) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating invoiceId", http.StatusBadRequest, err)
+		return
+	}
+
+	req := models.CreateCheckout{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	checkout, err := h.Service.Payment().Checkout(c.Request.Context(), authInfo, id, req)
+	if err != nil {
+		status := billingStatus(err)
+		switch {
+		case errors.Is(err, service.ErrNotYourInvoice):
+			status = http.StatusForbidden
+		case errors.Is(err, service.ErrNothingToPay):
+			status = http.StatusConflict
+		}
+		handleResponse(c, h.Log, "error while creating checkout", status, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Created successfully", http.StatusOK, checkout)
+}
+
+// PaymentCallback godoc
+// @Router		/payment-callback/{provider} [POST]
+// @Summary		payment provider callback
+// @Description	This api is called by the payment provider to prepare and then complete a transaction, the callback is signed by the provider and answered in its own format. A completed transaction records the payment of its invoice, callbacks sent again get the same answer
+// @Tags		billing
+// @Accept		json
+// @Produce		json
+// @Param		provider path string true "provider"
+// @Success		200  {object}  object
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) PaymentCallback(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reply, err := h.Service.Payment().Callback(c.Request.Context(), c.Param("provider"), c.Request.Header, body)
+	if errors.Is(err, payment.ErrUnknownProvider) {
+		handleResponse(c, h.Log, "error while handling payment callback", http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		handleResponse(c, h.Log, "error while handling payment callback", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, reply)
+}
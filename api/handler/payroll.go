@@ -0,0 +1,378 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg/export"
+	"backend_course/lms/service"
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// payrollStatus maps payroll errors to their status codes.
+func payrollStatus(err error) int {
+	if errors.Is(err, service.ErrPayrollOverlap) {
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+// SetTeacherRate godoc
+// @Security ApiKeyAuth
+// @Router		/teacher/{id}/rate [PUT]
+// @Summary		set a teacher's hourly rate
+// @Description	This api sets the teacher's hourly rate in minor units, or the teacher's rate of a subject when subject_id is given. Subject rates override the teacher's rate. Only teachers can set it
+// @Tags		payroll
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		rate body models.SetRate true "rate"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) SetTeacherRate(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can set rates"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating teacherId", http.StatusBadRequest, err)
+		return
+	}
+	rate := models.SetRate{}
+	if err := c.ShouldBindJSON(&rate); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := rate.Validate(c.Request.Context()); err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.Service.Payroll().SetRate(c.Request.Context(), id, rate); err != nil {
+		handleResponse(c, h.Log, "error while setting rate", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, id)
+}
+
+// DeleteTeacherRate godoc
+// @Security ApiKeyAuth
+// @Router		/teacher/{id}/rate/{subject_id} [DELETE]
+// @Summary		delete a teacher's subject rate
+// @Description	This api deletes the teacher's rate of a subject, its lessons are paid at the teacher's rate again. Only teachers can delete it
+// @Tags		payroll
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		subject_id path string true "subject_id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) DeleteTeacherRate(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can delete rates"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating teacherId", http.StatusBadRequest, err)
+		return
+	}
+	subjectId := c.Param("subject_id")
+	if _, err := models.ParseId("subject_id", subjectId); err != nil {
+		handleResponse(c, h.Log, "error while validating subjectId", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.Service.Payroll().DeleteRate(c.Request.Context(), id, subjectId); err != nil {
+		handleResponse(c, h.Log, "error while deleting rate", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Deleted successfully", http.StatusOK, id)
+}
+
+// GetTeacherRates godoc
+// @Security ApiKeyAuth
+// @Router		/teacher/{id}/rates [GET]
+// @Summary		get a teacher's hourly rates
+// @Description	This api gets the teacher's hourly rate and the subjects paid at another rate. Only teachers can get it
+// @Tags		payroll
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.TeacherRates
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		404  {object}  models.Response
+func (h Handler) GetTeacherRates(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can get rates"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating teacherId", http.StatusBadRequest, err)
+		return
+	}
+
+	rates, err := h.Service.Payroll().GetRates(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting rates", http.StatusNotFound, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "request successful", http.StatusOK, rates)
+}
+
+// PreviewPayroll godoc
+// @Security ApiKeyAuth
+// @Router		/payroll/preview [GET]
+// @Summary		preview a payroll
+// @Description	This api pays the lessons delivered from from_date to to_date without saving it. Lessons on closures, lessons not over yet and lessons whose teacher was on leave without a substitute are left out, substituted lessons are paid to the substitute. Only teachers can get it. format=csv|xlsx or the Accept header exports its lines
+// @Tags		payroll
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		from_date query string true "from_date"
+// @Param		to_date query string true "to_date"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.PayrollRun
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) PreviewPayroll(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can preview payrolls"); !ok {
+		return
+	}
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	run, err := h.Service.Payroll().Preview(c.Request.Context(), c.Query("from_date"), c.Query("to_date"))
+	if err != nil {
+		handleResponse(c, h.Log, "error while previewing payroll", http.StatusBadRequest, err.Error())
+		return
+	}
+	if format != export.JSON {
+		if err := exportRows(h, c, format, "payroll", run.Lines); err != nil {
+			handleResponse(c, h.Log, "error while exporting payroll", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	handleResponse(c, h.Log, "request successful", http.StatusOK, run)
+}
+
+// CreatePayrollRun godoc
+// @Security ApiKeyAuth
+// @Router		/payroll-run [POST]
+// @Summary		run a payroll
+// @Description	This api saves the pay of the lessons delivered from from_date to to_date as a locked statement and returns its id. The period must be over and can not overlap the one of another run. Only teachers can run it
+// @Tags		payroll
+// @Accept		json
+// @Produce		json
+// @Param		run body models.CreatePayrollRun true "run"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		409  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) CreatePayrollRun(c *gin.Context) {
+	teacherId, ok := h.teacherOnly(c, "only teachers can run payrolls")
+	if !ok {
+		return
+	}
+
+	run := models.CreatePayrollRun{}
+	if err := c.ShouldBindJSON(&run); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := run.Validate(c.Request.Context()); err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
+	run.CreatedBy = teacherId
+
+	id, err := h.Service.Payroll().CreateRun(c.Request.Context(), run)
+	if err != nil {
+		handleResponse(c, h.Log, "error while running payroll", payrollStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Created successfully", http.StatusOK, id)
+}
+
+// GetPayrollRun godoc
+// @Security ApiKeyAuth
+// @Router		/payroll-run/{id} [GET]
+// @Summary		get a payroll run
+// @Description	This api gets a payroll run with its lines. Only teachers can get it
+// @Tags		payroll
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.PayrollRun
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		404  {object}  models.Response
+func (h Handler) GetPayrollRun(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can get payroll runs"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating payrollRunId", http.StatusBadRequest, err)
+		return
+	}
+
+	run, err := h.Service.Payroll().GetRun(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting payroll run", http.StatusNotFound, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "request successful", http.StatusOK, run)
+}
+
+// GetAllPayrollRuns godoc
+// @Security ApiKeyAuth
+// @Router		/payroll-runs [GET]
+// @Summary		get payroll runs
+// @Description	This api gets payroll runs without their lines, the latest period first. Only teachers can get them. format=csv|xlsx or the Accept header exports all of them
+// @Tags		payroll
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.GetAllPayrollRunsResponse
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetAllPayrollRuns(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can get payroll runs"); !ok {
+		return
+	}
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing page", http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.GetAllPayrollRunsRequest{
+		Page:  page,
+		Limit: limit,
+	}
+
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "payroll-runs", func(write func(models.PayrollRun) error) error {
+			return h.Service.Payroll().EachRun(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting payroll runs", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	resp, err := h.Service.Payroll().GetRuns(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting all payroll runs", http.StatusInternalServerError, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// GetPayrollStatementCSV godoc
+// @Security ApiKeyAuth
+// @Router		/payroll-run/{id}/statement.csv [GET]
+// @Summary		get a payroll statement as CSV
+// @Description	This api exports the lines of a payroll run and its total as CSV, money is in minor units. Only teachers can get it
+// @Tags		payroll
+// @Accept		json
+// @Produce		text/csv
+// @Param		id path string true "id"
+// @Success		200  {file}  file
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		404  {object}  models.Response
+func (h Handler) GetPayrollStatementCSV(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can get payroll statements"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating payrollRunId", http.StatusBadRequest, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.Service.Payroll().StatementCSV(c.Request.Context(), id, &buf); err != nil {
+		handleResponse(c, h.Log, "error while exporting payroll statement", http.StatusNotFound, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="payroll.csv"`)
+	c.Data(http.StatusOK, "text/csv", buf.Bytes())
+}
+
+// GetPayrollStatementPDF godoc
+// @Security ApiKeyAuth
+// @Router		/payroll-run/{id}/statement.pdf [GET]
+// @Summary		get a payroll statement as PDF
+// @Description	This api renders the lines of a payroll run and its total as PDF. Only teachers can get it
+// @Tags		payroll
+// @Accept		json
+// @Produce		application/pdf
+// @Param		id path string true "id"
+// @Success		200  {file}  file
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		404  {object}  models.Response
+func (h Handler) GetPayrollStatementPDF(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can get payroll statements"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating payrollRunId", http.StatusBadRequest, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.Service.Payroll().StatementPDF(c.Request.Context(), id, &buf); err != nil {
+		handleResponse(c, h.Log, "error while rendering payroll statement", http.StatusNotFound, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="payroll.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
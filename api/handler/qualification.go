@@ -0,0 +1,131 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"backend_course/lms/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTeacherSubjects godoc
+// @Security ApiKeyAuth
+// @Router		/teacher/{id}/subjects [GET]
+// @Summary		get a teacher's subjects
+// @Description	This api get the subjects a teacher is qualified for with the proficiency and the date since the teacher teaches them
+// @Tags		qualification
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetTeacherSubjects(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating teacherId", http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.Service.Teacher().GetSubjects(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting teacher's subjects", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Got successfully", http.StatusOK, resp)
+}
+
+// SetTeacherSubject godoc
+// @Security ApiKeyAuth
+// @Router		/teacher/{id}/subject/{subject_id} [PUT]
+// @Summary		qualify a teacher for a subject
+// @Description	This api qualifies a teacher for a subject or updates the qualification, proficiency is basic, proficient or expert and since is a date defaulting to today
+// @Tags		qualification
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		subject_id path string true "subject_id"
+// @Param		qualification body models.SetTeacherSubject true "qualification"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) SetTeacherSubject(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can qualify teachers"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating teacherId", http.StatusBadRequest, err)
+		return
+	}
+	subjectId := c.Param("subject_id")
+	if _, err := models.ParseId("subject_id", subjectId); err != nil {
+		handleResponse(c, h.Log, "error while validating subjectId", http.StatusBadRequest, err)
+		return
+	}
+
+	req := models.SetTeacherSubject{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := req.Validate(c.Request.Context()); err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.Service.Teacher().SetSubject(c.Request.Context(), id, subjectId, req); err != nil {
+		handleResponse(c, h.Log, "error while setting teacher's subject", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, subjectId)
+}
+
+// RemoveTeacherSubject godoc
+// @Security ApiKeyAuth
+// @Router		/teacher/{id}/subject/{subject_id} [DELETE]
+// @Summary		remove a teacher's qualification
+// @Description	This api takes away a teacher's qualification for a subject, lessons already scheduled are kept
+// @Tags		qualification
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		subject_id path string true "subject_id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) RemoveTeacherSubject(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can remove qualifications"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating teacherId", http.StatusBadRequest, err)
+		return
+	}
+	subjectId := c.Param("subject_id")
+	if _, err := models.ParseId("subject_id", subjectId); err != nil {
+		handleResponse(c, h.Log, "error while validating subjectId", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.Service.Teacher().RemoveSubject(c.Request.Context(), id, subjectId); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrNoQualification) {
+			status = http.StatusNotFound
+		}
+		handleResponse(c, h.Log, "error while removing teacher's subject", status, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Deleted successfully", http.StatusOK, subjectId)
+}
@@ -0,0 +1,332 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg/export"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateQuiz godoc
+// @Security ApiKeyAuth
+// @Router		/quiz [POST]
+// @Summary		create a quiz
+// @Description	This api create a quiz of a group in a subject and returns its id. Question kinds are single, multiple, numeric and text, correct holds the indexes of the right options. Its scores go to a quiz assessment of the gradebook. Only the subject's teachers can create it
+// @Tags		quiz
+// @Accept		json
+// @Produce		json
+// @Param		quiz body models.AddQuiz true "quiz"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) CreateQuiz(c *gin.Context) {
+	teacherId, ok := h.teacherOnly(c, "only teachers can create quizzes")
+	if !ok {
+		return
+	}
+
+	quiz := models.AddQuiz{}
+	if err := c.ShouldBindJSON(&quiz); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := quiz.Validate(c.Request.Context()); err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := h.Service.Quiz().CreateQuiz(c.Request.Context(), teacherId, quiz)
+	if err != nil {
+		handleResponse(c, h.Log, "error while creating quiz", classworkStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Created successfully", http.StatusOK, id)
+}
+
+// GetQuiz godoc
+// @Security ApiKeyAuth
+// @Router		/quiz/{id} [GET]
+// @Summary		get a quiz
+// @Description	This api get a quiz, teachers also get its questions with their answers
+// @Tags		quiz
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetQuiz(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating quizId", http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.Service.Quiz().GetQuiz(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting quiz", http.StatusNotFound, err.Error())
+		return
+	}
+	if authInfo, err := getAuthInfo(c); err != nil || authInfo.UserRole != config.TEACHER_TYPE {
+		resp.Questions = nil
+	}
+
+	handleResponse(c, h.Log, "Got successfully", http.StatusOK, resp)
+}
+
+// GetAllQuizzes godoc
+// @Security ApiKeyAuth
+// @Router		/quizzes [GET]
+// @Summary		get quizzes
+// @Description	This api get quizzes filtered by group and subject, students only get the quizzes of their groups, format=csv|xlsx or the Accept header exports all of them
+// @Tags		quiz
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		group_id query string false "group_id"
+// @Param		subject_id query string false "subject_id"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetAllQuizzes(c *gin.Context) {
+	if !h.validateQueryIds(c, "group_id", "subject_id") {
+		return
+	}
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing page", http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.GetAllQuizzesRequest{
+		GroupId:   c.Query("group_id"),
+		SubjectId: c.Query("subject_id"),
+		Page:      page,
+		Limit:     limit,
+	}
+	if authInfo, err := getAuthInfo(c); err == nil && authInfo.UserRole == config.STUDENT_TYPE {
+		req.StudentId = authInfo.UserID
+	}
+
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "quizzes", func(write func(models.Quiz) error) error {
+			return h.Service.Quiz().EachQuiz(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting quizzes", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	resp, err := h.Service.Quiz().GetQuizzes(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting all quizzes", http.StatusInternalServerError, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// DeleteQuiz godoc
+// @Security ApiKeyAuth
+// @Router		/quiz/{id} [DELETE]
+// @Summary		delete a quiz
+// @Description	This api delete a quiz with its attempts and gradebook scores, only the subject's teachers can delete it
+// @Tags		quiz
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) DeleteQuiz(c *gin.Context) {
+	teacherId, ok := h.teacherOnly(c, "only teachers can delete quizzes")
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating quizId", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.Service.Quiz().DeleteQuiz(c.Request.Context(), teacherId, id); err != nil {
+		handleResponse(c, h.Log, "error while deleting quiz", classworkStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Deleted successfully", http.StatusOK, id)
+}
+
+// StartQuizAttempt godoc
+// @Security ApiKeyAuth
+// @Router		/quiz/{id}/attempt [POST]
+// @Summary		start a quiz attempt
+// @Description	This api starts an attempt of an open quiz and returns its questions in a random order with the attempt's deadline, an attempt still running is returned instead
+// @Tags		quiz
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		409  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) StartQuizAttempt(c *gin.Context) {
+	studentId, ok := h.studentOnly(c, "only students can take quizzes")
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating quizId", http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.Service.Quiz().StartAttempt(c.Request.Context(), id, studentId)
+	if err != nil {
+		handleResponse(c, h.Log, "error while starting quiz attempt", classworkStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Started successfully", http.StatusOK, resp)
+}
+
+// SubmitQuizAttempt godoc
+// @Security ApiKeyAuth
+// @Router		/quiz-attempt/{id}/submit [POST]
+// @Summary		submit a quiz attempt
+// @Description	This api grades the answers of an attempt and returns them, answers after the time limit are not graded
+// @Tags		quiz
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		answers body models.SubmitQuizRequest true "answers"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		409  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) SubmitQuizAttempt(c *gin.Context) {
+	studentId, ok := h.studentOnly(c, "only students can take quizzes")
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating attemptId", http.StatusBadRequest, err)
+		return
+	}
+
+	req := models.SubmitQuizRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := req.Validate(c.Request.Context()); err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.Service.Quiz().SubmitAttempt(c.Request.Context(), studentId, id, req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while submitting quiz attempt", classworkStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Submitted successfully", http.StatusOK, resp)
+}
+
+// GetQuizAttempts godoc
+// @Security ApiKeyAuth
+// @Router		/quiz/{id}/attempts [GET]
+// @Summary		get attempts of a quiz
+// @Description	This api get every attempt of the quiz, only the subject's teachers can see them
+// @Tags		quiz
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetQuizAttempts(c *gin.Context) {
+	teacherId, ok := h.teacherOnly(c, "only teachers can see quiz attempts")
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating quizId", http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.Service.Quiz().GetAttempts(c.Request.Context(), teacherId, id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting quiz attempts", classworkStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// GetQuizAttempt godoc
+// @Security ApiKeyAuth
+// @Router		/quiz-attempt/{id} [GET]
+// @Summary		get a quiz attempt
+// @Description	This api get the log of an attempt with its graded answers, for the subject's teachers and the student who made it
+// @Tags		quiz
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetQuizAttempt(c *gin.Context) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating attemptId", http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.Service.Quiz().GetAttempt(c.Request.Context(), authInfo, id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting quiz attempt", classworkStatus(err), err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Got successfully", http.StatusOK, resp)
+}
@@ -0,0 +1,280 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg/export"
+	"backend_course/lms/pkg/grading"
+	"backend_course/lms/service"
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func reportRequest(c *gin.Context) models.ReportRequest {
+	return models.ReportRequest{
+		TermId:   c.Query("term_id"),
+		FromDate: c.Query("from_date"),
+		ToDate:   c.Query("to_date"),
+		Scale:    c.Query("scale"),
+	}
+}
+
+// progressReportStatus answers 400 to an invalid period or scale and 404 to an unknown
+// student, group or term.
+func progressReportStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidReportPeriod), errors.Is(err, grading.ErrUnknownScale):
+		return http.StatusBadRequest
+	case errors.Is(err, pgx.ErrNoRows):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// GetStudentReportPDF godoc
+// @Security ApiKeyAuth
+// @Router		/student/{id}/report.pdf [GET]
+// @Summary		get a student's progress report
+// @Description	This api renders the student's profile, attendance summary, grades per subject and teacher comments of a term or of from_date to to_date as PDF. Students can only get their own report and guardians the ones of their children
+// @Tags		report
+// @Accept		json
+// @Produce		application/pdf
+// @Param		id path string true "id"
+// @Param		term_id query string false "term_id"
+// @Param		from_date query string false "from_date"
+// @Param		to_date query string false "to_date"
+// @Param		scale query string false "scale"
+// @Success		200  {file}  file
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetStudentReportPDF(c *gin.Context) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating studentId", http.StatusBadRequest, err.Error())
+		return
+	}
+	switch authInfo.UserRole {
+	case config.STUDENT_TYPE:
+		if authInfo.UserID != id.String() {
+			handleResponse(c, h.Log, "students can only get their own report", http.StatusForbidden, "forbidden")
+			return
+		}
+	case config.GUARDIAN_TYPE:
+		if err := h.Service.Guardian().CheckChild(c.Request.Context(), authInfo.UserID, id.String()); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, service.ErrNotYourChild) {
+				status = http.StatusForbidden
+			}
+			handleResponse(c, h.Log, "error while checking guardian's children", status, err.Error())
+			return
+		}
+	}
+	if !h.validateQueryIds(c, "term_id") {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.Service.Report().StudentPDF(c.Request.Context(), id, reportRequest(c), &buf); err != nil {
+		handleResponse(c, h.Log, "error while rendering report", progressReportStatus(err), err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="report.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// GetGroupReportsZIP godoc
+// @Security ApiKeyAuth
+// @Router		/group/{id}/reports.zip [GET]
+// @Summary		get a group's progress reports
+// @Description	This api renders the progress report of every student of the group and returns them as a ZIP of PDFs
+// @Tags		report
+// @Accept		json
+// @Produce		application/zip
+// @Param		id path string true "id"
+// @Param		term_id query string false "term_id"
+// @Param		from_date query string false "from_date"
+// @Param		to_date query string false "to_date"
+// @Param		scale query string false "scale"
+// @Success		200  {file}  file
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetGroupReportsZIP(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can get a group's reports"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating groupId", http.StatusBadRequest, err)
+		return
+	}
+	if !h.validateQueryIds(c, "term_id") {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.Service.Report().GroupZIP(c.Request.Context(), id, reportRequest(c), &buf); err != nil {
+		handleResponse(c, h.Log, "error while rendering reports", progressReportStatus(err), err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="reports.zip"`)
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// GetTeacherWorkload godoc
+// @Security ApiKeyAuth
+// @Router		/reports/teacher-workload [GET]
+// @Summary		get teachers' workload
+// @Description	This api sums the scheduled lessons of every teacher in a term or from from_date to to_date, with the distinct students taught, the gaps between lessons of a day and the lessons per week or month (interval), subject, room and group. Holidays and closures are left out. Only teachers can get it. format=csv|xlsx or the Accept header exports every teacher without the breakdowns
+// @Tags		report
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		term_id query string false "term_id"
+// @Param		from_date query string false "from_date"
+// @Param		to_date query string false "to_date"
+// @Param		subject_id query string false "subject_id"
+// @Param		teacher_id query string false "teacher_id"
+// @Param		interval query string false "week or month"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.TeacherWorkloadResponse
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetTeacherWorkload(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can get teachers' workload"); !ok {
+		return
+	}
+	if !h.validateQueryIds(c, "term_id", "subject_id", "teacher_id") {
+		return
+	}
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing page", http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.TeacherWorkloadRequest{
+		TermId:    c.Query("term_id"),
+		FromDate:  c.Query("from_date"),
+		ToDate:    c.Query("to_date"),
+		SubjectId: c.Query("subject_id"),
+		TeacherId: c.Query("teacher_id"),
+		Interval:  c.Query("interval"),
+		Page:      page,
+		Limit:     limit,
+	}
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "teacher-workload", func(write func(models.TeacherWorkload) error) error {
+			return h.Service.Report().EachTeacherWorkload(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting teachers' workload", http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	resp, err := h.Service.Report().TeacherWorkload(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting teachers' workload", http.StatusBadRequest, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// GetRoomUtilization godoc
+// @Security ApiKeyAuth
+// @Router		/reports/room-utilization [GET]
+// @Summary		get rooms' utilization
+// @Description	This api computes how much of their opening hours the rooms are booked for lessons in a term or from from_date to to_date, per day or week (interval) and per hour of the week as a heatmap. Rooms below under_used percent (25 by default, 0 for none) are listed as under-used. Holidays and closures are left out. format=csv|xlsx or the Accept header exports the rooms' periods and totals. Only teachers can get it
+// @Tags		report
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		term_id query string false "term_id"
+// @Param		from_date query string false "from_date"
+// @Param		to_date query string false "to_date"
+// @Param		room_id query string false "room_id"
+// @Param		interval query string false "day or week"
+// @Param		under_used query integer false "under_used"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.RoomUtilizationResponse
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetRoomUtilization(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can get rooms' utilization"); !ok {
+		return
+	}
+	if !h.validateQueryIds(c, "term_id", "room_id") {
+		return
+	}
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	req := models.RoomUtilizationRequest{
+		TermId:   c.Query("term_id"),
+		FromDate: c.Query("from_date"),
+		ToDate:   c.Query("to_date"),
+		RoomId:   c.Query("room_id"),
+		Interval: c.Query("interval"),
+	}
+	underUsed, err := optionalIntQuery(c, "under_used")
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing under_used", http.StatusBadRequest, err.Error())
+		return
+	}
+	req.UnderUsed = underUsed
+
+	if format != export.JSON {
+		err := writeExport(h, c, format, "room-utilization", func(write func(models.RoomUtilizationRow) error) error {
+			return h.Service.Report().EachRoomUtilization(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting rooms' utilization", http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	resp, err := h.Service.Report().RoomUtilization(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting rooms' utilization", http.StatusBadRequest, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
@@ -0,0 +1,267 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg/export"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateRoom godoc
+// @Security ApiKeyAuth
+// @Router		/room [POST]
+// @Summary		create a room
+// @Description	This api create a room and returns its id
+// @Tags		room
+// @Accept		json
+// @Produce		json
+// @Param		room body models.AddRoom true "room"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) CreateRoom(c *gin.Context) {
+	room := models.AddRoom{}
+
+	if err := c.ShouldBindJSON(&room); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	if room.Capacity < 0 {
+		handleResponse(c, h.Log, "error while validating capacity", http.StatusBadRequest, "capacity can't be negative")
+		return
+	}
+
+	id, err := h.Service.Room().Create(c.Request.Context(), room)
+	if err != nil {
+		handleResponse(c, h.Log, "error while creating room", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Created successfully", http.StatusOK, id)
+}
+
+// UpdateRoom godoc
+// @Security ApiKeyAuth
+// @Router		/room/{id} [PUT]
+// @Summary		update a room
+// @Description	This api update a room and returns its id
+// @Tags		room
+// @Accept		json
+// @Produce		json
+// @Param		room body models.AddRoom true "room"
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) UpdateRoom(c *gin.Context) {
+	room := models.Room{}
+
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating roomId", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.ShouldBindJSON(&room); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	if room.Capacity < 0 {
+		handleResponse(c, h.Log, "error while validating capacity", http.StatusBadRequest, "capacity can't be negative")
+		return
+	}
+	room.Id = id
+
+	id, err := h.Service.Room().Update(c.Request.Context(), room)
+	if err != nil {
+		handleResponse(c, h.Log, "error while updating room", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, id)
+}
+
+// DeleteRoom godoc
+// @Security ApiKeyAuth
+// @Router		/room/{id} [DELETE]
+// @Summary		delete a room
+// @Description	This api delete a room
+// @Tags		room
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) DeleteRoom(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating roomId", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.Service.Room().Delete(c.Request.Context(), id); err != nil {
+		handleResponse(c, h.Log, "error while deleting room", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Deleted successfully", http.StatusOK, id)
+}
+
+// GetRoom godoc
+// @Security ApiKeyAuth
+// @Router		/room/{id} [GET]
+// @Summary		get a room
+// @Description	This api get a room
+// @Tags		room
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetRoom(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating roomId", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	room, err := h.Service.Room().GetRoom(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting room", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Got successfully", http.StatusOK, room)
+}
+
+// GetAllRooms godoc
+// @Security ApiKeyAuth
+// @Router		/rooms [GET]
+// @Summary		get rooms
+// @Description	This api get all rooms, format=csv|xlsx or the Accept header exports all of them
+// @Tags		room
+// @Accept		json
+// @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		search query string false "search"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetAllRooms(c *gin.Context) {
+	search := c.Query("search")
+
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing page", http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.GetAllRoomsRequest{
+		Search: search,
+		Page:   page,
+		Limit:  limit,
+	}
+
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "rooms", func(write func(models.Room) error) error {
+			return h.Service.Room().Each(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting rooms", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	resp, err := h.Service.Room().GetAll(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting all rooms", http.StatusInternalServerError, err.Error())
+		return
+	}
+	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+}
+
+// SetRoomHours godoc
+// @Security ApiKeyAuth
+// @Router		/room/{id}/hours [PUT]
+// @Summary		set a room's opening hours
+// @Description	This api replaces the weekly opening hours of a room, weekday is 0 (Sunday) to 6 (Saturday). A room without any is open from 08:00 to 20:00 every day
+// @Tags		room
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		hours body models.RoomHours true "hours"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) SetRoomHours(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating roomId", http.StatusBadRequest, err)
+		return
+	}
+
+	req := models.RoomHours{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.Service.Room().SetHours(c.Request.Context(), id, req); err != nil {
+		handleResponse(c, h.Log, "error while setting room's opening hours", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, id)
+}
+
+// GetRoomHours godoc
+// @Security ApiKeyAuth
+// @Router		/room/{id}/hours [GET]
+// @Summary		get a room's opening hours
+// @Description	This api get the weekly opening hours of a room, empty when the room is open for the default hours
+// @Tags		room
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.RoomHours
+// @Failure		400  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetRoomHours(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating roomId", http.StatusBadRequest, err)
+		return
+	}
+
+	hours, err := h.Service.Room().GetHours(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting room's opening hours", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "request successful", http.StatusOK, hours)
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GenerateSchedule godoc
+// @Security ApiKeyAuth
+// @Router		/schedule-draft [POST]
+// @Summary		generate a timetable draft
+// @Description	This api generates a conflict-free weekly timetable from teachers' subjects, groups, weekly hours, rooms and availability, and saves it as a draft for review
+// @Tags		schedule
+// @Accept		json
+// @Produce		json
+// @Param		schedule body models.GenerateScheduleRequest true "schedule"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GenerateSchedule(c *gin.Context) {
+	req := models.GenerateScheduleRequest{}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	for _, r := range req.Requirements {
+		if err := uuid.Validate(r.GroupId); err != nil {
+			handleResponse(c, h.Log, "error while validating groupId", http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := uuid.Validate(r.SubjectId); err != nil {
+			handleResponse(c, h.Log, "error while validating subjectId", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	draft, err := h.Service.Schedule().Generate(c.Request.Context(), req)
+	if err != nil {
+		handleResponse(c, h.Log, "error while generating schedule", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Generated successfully", http.StatusOK, draft)
+}
+
+// GetScheduleDraft godoc
+// @Security ApiKeyAuth
+// @Router		/schedule-draft/{id} [GET]
+// @Summary		get a timetable draft
+// @Description	This api get a generated timetable draft
+// @Tags		schedule
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetScheduleDraft(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating draftId", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	draft, err := h.Service.Schedule().GetDraft(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting schedule draft", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Got successfully", http.StatusOK, draft)
+}
+
+// CommitScheduleDraft godoc
+// @Security ApiKeyAuth
+// @Router		/schedule-draft/{id}/commit [POST]
+// @Summary		commit a timetable draft
+// @Description	This api writes a reviewed draft into the time table in one transaction
+// @Tags		schedule
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) CommitScheduleDraft(c *gin.Context) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleResponse(c, h.Log, "error while validating draftId", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.Service.Schedule().Commit(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while committing schedule draft", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Committed successfully", http.StatusOK, resp)
+}
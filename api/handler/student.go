@@ -3,8 +3,13 @@ package handler
 import (
 	_ "backend_course/lms/api/docs"
 	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/domain"
 	"backend_course/lms/pkg"
 	"backend_course/lms/pkg/check"
+	"backend_course/lms/pkg/export"
+	"backend_course/lms/service"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -53,16 +58,19 @@ func (h Handler) CreateStudent(c *gin.Context) {
 		return
 	}
 
-	password, err := pkg.HashPassword(student.Password)
+	newStudent, err := student.Domain(c.Request.Context())
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
 
+	newStudent.Password, err = pkg.HashPassword(student.Password)
 	if err != nil {
 		handleResponse(c, h.Log, "error while hashing password", http.StatusBadRequest, err.Error())
 		return
 	}
 
-	student.Password = password
-
-	id, err := h.Service.Student().Create(c.Request.Context(), student)
+	id, err := h.Service.Student().Create(c.Request.Context(), newStudent)
 	if err != nil {
 		handleResponse(c, h.Log, "error while creating student", http.StatusBadRequest, err.Error())
 		return
@@ -86,21 +94,26 @@ func (h Handler) CreateStudent(c *gin.Context) {
 // @Failure		404  {object}  models.Response
 // @Failure		500  {object}  models.Response
 func (h Handler) UpdateStudent(c *gin.Context) {
-
-	student := models.Student{}
-
-	id := c.Param("id")
-	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.Log, "error while validating studentId", http.StatusBadRequest, err.Error())
+	id, err := models.ParseId("id", c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating studentId", http.StatusBadRequest, err)
 		return
 	}
-	student.Id = id
 
+	student := models.AddStudent{}
 	if err := c.ShouldBindJSON(&student); err != nil {
 		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
 		return
 	}
-	id, err := h.Service.Student().Update(c.Request.Context(), student)
+
+	updatedStudent, err := student.Domain(c.Request.Context())
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
+	updatedStudent.Id = id
+
+	id, err = h.Service.Student().Update(c.Request.Context(), updatedStudent)
 	if err != nil {
 		handleResponse(c, h.Log, "error while updating student", http.StatusInternalServerError, err.Error())
 		return
@@ -123,21 +136,19 @@ func (h Handler) UpdateStudent(c *gin.Context) {
 // @Failure		404  {object}  models.Response
 // @Failure		500  {object}  models.Response
 func (h Handler) UpdateStudentStatus(c *gin.Context) {
-
-	student := models.Student{}
-
-	id := c.Param("id")
-	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.Log, "error while validating studentId", http.StatusBadRequest, err.Error())
+	id, err := models.ParseId("id", c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating studentId", http.StatusBadRequest, err)
 		return
 	}
-	student.Id = id
 
+	student := models.Student{}
 	if err := c.ShouldBindJSON(&student); err != nil {
 		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
 		return
 	}
-	id, err := h.Service.Student().UpdateStatus(c.Request.Context(), student)
+
+	id, err = h.Service.Student().UpdateStatus(c.Request.Context(), domain.Student{Id: id, IsActive: student.IsActive})
 	if err != nil {
 		handleResponse(c, h.Log, "error while updating student", http.StatusInternalServerError, err.Error())
 		return
@@ -160,9 +171,9 @@ func (h Handler) UpdateStudentStatus(c *gin.Context) {
 // @Failure		404  {object}  models.Response
 // @Failure		500  {object}  models.Response
 func (h Handler) DeleteStudent(c *gin.Context) {
-	id := c.Param("id")
-	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.Log, "error while validating studentId", http.StatusBadRequest, err.Error())
+	id, err := models.ParseId("id", c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating studentId", http.StatusBadRequest, err)
 		return
 	}
 	if err := h.Service.Student().Delete(c.Request.Context(), id); err != nil {
@@ -187,10 +198,9 @@ func (h Handler) DeleteStudent(c *gin.Context) {
 // @Failure		404  {object}  models.Response
 // @Failure		500  {object}  models.Response
 func (h Handler) GetStudent(c *gin.Context) {
-
-	id := c.Param("id")
-	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.Log, "error while validating studentId", http.StatusBadRequest, err.Error())
+	id, err := models.ParseId("id", c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating studentId", http.StatusBadRequest, err)
 		return
 	}
 
@@ -200,23 +210,33 @@ func (h Handler) GetStudent(c *gin.Context) {
 		return
 	}
 
-	handleResponse(c, h.Log, "Got successfully", http.StatusOK, std)
+	handleResponse(c, h.Log, "Got successfully", http.StatusOK, models.NewGetStudent(c.Request.Context(), std))
 }
 
 // GetAllStudents godoc
 // @Security ApiKeyAuth
 // @Router		/students [GET]
 // @Summary		get  students
-// @Description	This api get all students
+// @Description	This api get all students, format=csv|xlsx or the Accept header exports all of them
 // @Tags		student
 // @Accept		json
 // @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		search query string false "search"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
 // @Success		200  {object}  models.Response
 // @Failure		400  {object}  models.Response
 // @Failure		404  {object}  models.Response
 // @Failure		500  {object}  models.Response
 func (h Handler) GetAllStudents(c *gin.Context) {
 	search := c.Query("search")
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
 
 	page, err := ParsePageQueryParam(c)
 	if err != nil {
@@ -229,16 +249,30 @@ func (h Handler) GetAllStudents(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.Service.Student().GetAll(c.Request.Context(), models.GetAllStudentsRequest{
+	req := models.GetAllStudentsRequest{
 		Search: search,
 		Page:   page,
 		Limit:  limit,
-	})
+	}
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "students", func(write func(models.GetStudent) error) error {
+			return h.Service.Student().Each(c.Request.Context(), req, func(student domain.Student) error {
+				return write(models.NewGetStudent(c.Request.Context(), student))
+			})
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting students", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	students, count, err := h.Service.Student().GetAll(c.Request.Context(), req)
 	if err != nil {
 		handleResponse(c, h.Log, "error while getting all students", http.StatusInternalServerError, err.Error())
 		return
 	}
-	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+	handleResponse(c, h.Log, "request successful", http.StatusOK, models.NewGetAllStudentsResponse(c.Request.Context(), students, count))
 }
 
 // CheckStudentLesson godoc
@@ -273,38 +307,99 @@ func (h Handler) CheckStudentLesson(c *gin.Context) {
 
 // GetAllStudentsAttandenceReport godoc
 // @Security ApiKeyAuth
-// @Router		/student-attendence/{id} [GET]
-// @Summary		get a student's lesson
-// @Description	This api get a student's attendance
+// @Router		/student-attendence [GET]
+// @Summary		get students' attendance
+// @Description	This api sums the started lessons of every student in a term or from start_date to end_date: the lessons, hours, hours studied, average lesson length in minutes and attendance rate, with the totals of all the students. group_by=teacher|subject|week splits every student's lessons. Students can only get their own attendance. format=csv|xlsx or the Accept header exports every student without the split
 // @Tags		student
 // @Accept		json
 // @Produce		json
-// Param 		page query integer true "page"
-// Param 		limit query integer true "limit"
-// Param 		all query bool true "all"
-// Param 		search query string true "search"
-// @Param		student body models.GetAllStudentsAttandenceReportRequest true "student"
-// @Success		200  {object}  models.Response
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		student_id query string false "student_id"
+// @Param		teacher_id query string false "teacher_id"
+// @Param		subject_id query string false "subject_id"
+// @Param		term_id query string false "term_id"
+// @Param		start_date query string false "start_date"
+// @Param		end_date query string false "end_date"
+// @Param		group_by query string false "teacher, subject or week"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
+// @Success		200  {object}  models.GetAllStudentsAttandenceReportResponse
 // @Failure		400  {object}  models.Response
-// @Failure		404  {object}  models.Response
+// @Failure		403  {object}  models.Response
 // @Failure		500  {object}  models.Response
 func (h Handler) GetAllStudentsAttandenceReport(c *gin.Context) {
+	authInfo, err := getAuthInfo(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting auth info", http.StatusUnauthorized, err.Error())
+		return
+	}
+	if !h.validateQueryIds(c, "student_id", "teacher_id", "subject_id", "term_id") {
+		return
+	}
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
 
-	student := models.GetAllStudentsAttandenceReportRequest{}
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing page", http.StatusBadRequest, err.Error())
+		return
+	}
+	limit, err := ParseLimitQueryParam(c)
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		return
+	}
 
-	if err := c.ShouldBindJSON(&student); err != nil {
-		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+	req := models.GetAllStudentsAttandenceReportRequest{
+		StudentId: c.Query("student_id"),
+		TeacherId: c.Query("teacher_id"),
+		SubjectId: c.Query("subject_id"),
+		TermId:    c.Query("term_id"),
+		StartDate: c.Query("start_date"),
+		EndDate:   c.Query("end_date"),
+		GroupBy:   c.Query("group_by"),
+		Page:      page,
+		Limit:     limit,
+	}
+	if authInfo.UserRole == config.STUDENT_TYPE {
+		if req.StudentId != "" && req.StudentId != authInfo.UserID {
+			handleResponse(c, h.Log, "students can only get their own attendance", http.StatusForbidden, "forbidden")
+			return
+		}
+		req.StudentId = authInfo.UserID
+	}
+	if format != export.JSON {
+		req.GroupBy, req.Page, req.Limit = "", 1, export.All
+		err := writeExport(h, c, format, "student-attendance", func(write func(models.StudentAttandenceReport) error) error {
+			return h.Service.Student().EachAttendanceReport(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting students' attendance", attendanceReportStatus(err), err.Error())
+		}
 		return
 	}
-	resp, err := h.Service.Student().GetAllStudentsAttandenceReport(c.Request.Context(), student)
+
+	resp, err := h.Service.Student().GetAllStudentsAttandenceReport(c.Request.Context(), req)
 	if err != nil {
-		handleResponse(c, h.Log, "error while getting student's attendance", http.StatusInternalServerError, err.Error())
+		handleResponse(c, h.Log, "error while getting students' attendance", attendanceReportStatus(err), err.Error())
 		return
 	}
 
 	handleResponse(c, h.Log, "Got successfully", http.StatusOK, resp)
 }
 
+// attendanceReportStatus is the status of an error of the attendance report.
+func attendanceReportStatus(err error) int {
+	if errors.Is(err, pkg.ErrInvalidTime) || errors.Is(err, service.ErrInvalidGroupBy) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
 // UploadStudentPhoto godoc
 // @Security ApiKeyAuth
 // @Router		/student-photo/{id} [PATCH]
@@ -326,21 +421,17 @@ func (h Handler) UploadStudentPhoto(c *gin.Context) {
 		return
 	}
 
-	file, err := c.FormFile("file")
-
+	files, err := uploadedFiles(c, "file", "image/jpeg")
 	if err != nil {
 		handleResponse(c, h.Log, "error while uploding student's image", http.StatusBadRequest, err.Error())
 		return
 	}
-	if file.Header.Get("Content-Type") != "image/jpeg" {
-		handleResponse(c, h.Log, "file type is not valid", http.StatusBadRequest, "File type is not valid")
+	if len(files) == 0 {
+		handleResponse(c, h.Log, "error while uploding student's image", http.StatusBadRequest, errNoFile.Error())
 		return
 	}
 
-	uploadPath := "media/studentsPhotos/"
-
-	err = c.SaveUploadedFile(file, uploadPath+file.Filename)
-
+	paths, err := saveUploads(c, "media/studentsPhotos/", files[:1])
 	if err != nil {
 		handleResponse(c, h.Log, "could't save student's image", http.StatusInternalServerError, err.Error())
 		return
@@ -348,14 +439,54 @@ func (h Handler) UploadStudentPhoto(c *gin.Context) {
 
 	path := models.UploadStudentImage{
 		Id:   id,
-		Path: uploadPath + file.Filename,
+		Path: paths[0],
 	}
 	err = h.Service.Student().UploadImage(c.Request.Context(), path)
 
 	if err != nil {
+		removeUploads(paths)
 		handleResponse(c, h.Log, "could't save to database", http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	handleResponse(c, h.Log, "Student's image saved successfully", http.StatusOK, uploadPath+file.Filename)
-}
\ No newline at end of file
+	handleResponse(c, h.Log, "Student's image saved successfully", http.StatusOK, paths[0])
+}
+
+// ImportStudents godoc
+// @Security ApiKeyAuth
+// @Router		/students/import [POST]
+// @Summary		import students
+// @Description	This api imports the students of a CSV or XLSX file and reports the errors of every row. The headers are the fields of a student unless columns maps them, e.g. {"mail":"E-mail"}. The valid rows are stored in one transaction, dry_run only validates them. Only teachers can import.
+// @Tags		student
+// @Accept		multipart/form-data
+// @Produce		json
+// @Param		file formData file true "csv or xlsx file"
+// @Param		columns formData string false "columns mapped to headers as a JSON object"
+// @Param		dry_run query boolean false "dry run"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		409  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) ImportStudents(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can import students"); !ok {
+		return
+	}
+
+	records, dryRun, ok := h.importRecords(c, models.StudentImportColumns)
+	if !ok {
+		return
+	}
+
+	resp, err := h.Service.Student().Import(c.Request.Context(), records, dryRun)
+	if errors.Is(err, service.ErrImportConflict) {
+		handleResponse(c, h.Log, "error while importing students", http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		handleResponse(c, h.Log, "error while importing students", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Imported successfully", http.StatusOK, resp)
+}
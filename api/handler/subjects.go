@@ -3,6 +3,9 @@ package handler
 import (
 	_ "backend_course/lms/api/docs"
 	"backend_course/lms/api/models"
+	"backend_course/lms/pkg/export"
+	"backend_course/lms/service"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -13,7 +16,7 @@ import (
 // @Security ApiKeyAuth
 // @Router		/subject [POST]
 // @Summary		create a subject
-// @Description	This api create a subject and returns its id
+// @Description	This api create a subject of the course catalog and returns its id, level is beginner, intermediate or advanced
 // @Tags		subject
 // @Accept		json
 // @Produce		json
@@ -70,7 +73,7 @@ func (h Handler) UpdateSubject(c *gin.Context) {
 	}
 	id, err := h.Service.Subjects().Update(c.Request.Context(), subject)
 	if err != nil {
-		handleResponse(c, h.Log, "error while updating subject", http.StatusInternalServerError, err.Error())
+		handleResponse(c, h.Log, "error while updating subject", http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -108,7 +111,7 @@ func (h Handler) DeleteSubject(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Router		/subject/{id} [GET]
 // @Summary		get a subject
-// @Description	This api get a subject
+// @Description	This api get a subject with its prerequisites and syllabus
 // @Tags		subject
 // @Accept		json
 // @Produce		json
@@ -138,10 +141,20 @@ func (h Handler) GetSubject(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Router		/subjects [GET]
 // @Summary		get  subjects
-// @Description	This api get all subjects
+// @Description	This api browses the course catalog, search matches the code, name and description. format=csv|xlsx or the Accept header exports all of them
 // @Tags		subject
 // @Accept		json
 // @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		search query string false "search"
+// @Param		type query string false "type"
+// @Param		level query string false "level"
+// @Param		min_credits query integer false "min_credits"
+// @Param		max_credits query integer false "max_credits"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
 // @Success		200  {object}  models.Response
 // @Failure		400  {object}  models.Response
 // @Failure		404  {object}  models.Response
@@ -149,6 +162,11 @@ func (h Handler) GetSubject(c *gin.Context) {
 func (h Handler) GetAllSubjects(c *gin.Context) {
 	search := c.Query("search")
 
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
+
 	page, err := ParsePageQueryParam(c)
 	if err != nil {
 		handleResponse(c, h.Log, "error while parsing page", http.StatusBadRequest, err.Error())
@@ -160,14 +178,129 @@ func (h Handler) GetAllSubjects(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.Service.Subjects().GetAll(c.Request.Context(), models.GetAllSubjectsRequest{
-		Search: search,
-		Page:   page,
-		Limit:  limit,
-	})
+	minCredits, err := optionalIntQuery(c, "min_credits")
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing min_credits", http.StatusBadRequest, err.Error())
+		return
+	}
+	maxCredits, err := optionalIntQuery(c, "max_credits")
+	if err != nil {
+		handleResponse(c, h.Log, "error while parsing max_credits", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req := models.GetAllSubjectsRequest{
+		Search:     search,
+		Type:       c.Query("type"),
+		Level:      c.Query("level"),
+		MinCredits: minCredits,
+		MaxCredits: maxCredits,
+		Page:       page,
+		Limit:      limit,
+	}
+
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "subjects", func(write func(models.Subjects) error) error {
+			return h.Service.Subjects().Each(c.Request.Context(), req, write)
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting subjects", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	resp, err := h.Service.Subjects().GetAll(c.Request.Context(), req)
 	if err != nil {
 		handleResponse(c, h.Log, "error while getting all subjects", http.StatusInternalServerError, err.Error())
 		return
 	}
 	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
 }
+
+// SetSyllabus godoc
+// @Security ApiKeyAuth
+// @Router		/subject/{id}/syllabus [PUT]
+// @Summary		set a subject's syllabus
+// @Description	This api replaces the syllabus of a subject, units and their lessons are taught in the given order
+// @Tags		subject
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		syllabus body models.SetSyllabusRequest true "syllabus"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) SetSyllabus(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can edit syllabuses"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating subjectId", http.StatusBadRequest, err)
+		return
+	}
+
+	req := models.SetSyllabusRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.Service.Subjects().SetSyllabus(c.Request.Context(), id, req); err != nil {
+		handleResponse(c, h.Log, "error while setting syllabus", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, id)
+}
+
+// SetPrerequisites godoc
+// @Security ApiKeyAuth
+// @Router		/subject/{id}/prerequisites [PUT]
+// @Summary		set a subject's prerequisites
+// @Description	This api replaces the subjects a student must complete before enrolling in the subject, they must not depend on the subject themselves
+// @Tags		subject
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "id"
+// @Param		prerequisites body models.SetPrerequisitesRequest true "prerequisites"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		409  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) SetPrerequisites(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can edit prerequisites"); !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := models.ParseId("id", id); err != nil {
+		handleResponse(c, h.Log, "error while validating subjectId", http.StatusBadRequest, err)
+		return
+	}
+
+	req := models.SetPrerequisitesRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := req.Validate(c.Request.Context()); err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.Service.Subjects().SetPrerequisites(c.Request.Context(), id, req); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrPrerequisiteCycle) {
+			status = http.StatusConflict
+		}
+		handleResponse(c, h.Log, "error while setting prerequisites", status, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, id)
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	_ "backend_course/lms/api/docs"
+	"backend_course/lms/api/models"
+	"backend_course/lms/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSubstitutes godoc
+// @Security ApiKeyAuth
+// @Router		/time/{id}/substitutes [GET]
+// @Summary		suggest substitutes
+// @Description	This api lists teachers of the same subject who are free at the lesson's time and under their weekly cap
+// @Tags		time_table
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "time table id"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) GetSubstitutes(c *gin.Context) {
+	id, err := models.ParseId("id", c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating timeId", http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.Service.Time().GetSubstitutes(c.Request.Context(), id)
+	if err != nil {
+		handleResponse(c, h.Log, "error while getting substitutes", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Got successfully", http.StatusOK, resp)
+}
+
+// AssignSubstitute godoc
+// @Security ApiKeyAuth
+// @Router		/time/{id}/substitute [POST]
+// @Summary		assign a substitute
+// @Description	This api hands the whole lesson to a qualified substitute keeping the original teacher on record, assigning the original teacher back ends the substitution
+// @Tags		time_table
+// @Accept		json
+// @Produce		json
+// @Param		id path string true "time table id"
+// @Param		substitute body models.AssignSubstitute true "substitute"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		404  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) AssignSubstitute(c *gin.Context) {
+	id, err := models.ParseId("id", c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating timeId", http.StatusBadRequest, err)
+		return
+	}
+
+	req := models.AssignSubstitute{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	teacherId, err := models.ParseId("teacher_id", req.TeacherId)
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating teacherId", http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.Service.Time().AssignSubstitute(c.Request.Context(), id, teacherId)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrNotQualified) {
+			status = http.StatusBadRequest
+		}
+		handleResponse(c, h.Log, "error while assigning substitute", status, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Updated successfully", http.StatusOK, resp)
+}
@@ -3,8 +3,12 @@ package handler
 import (
 	_ "backend_course/lms/api/docs"
 	"backend_course/lms/api/models"
+	"backend_course/lms/domain"
 	"backend_course/lms/pkg"
 	"backend_course/lms/pkg/check"
+	"backend_course/lms/pkg/export"
+	"backend_course/lms/service"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -47,16 +51,19 @@ func (h Handler) CreateTeacher(c *gin.Context) {
 		return
 	}
 
-	password, err := pkg.HashPassword(teacher.Password)
+	newTeacher, err := teacher.Domain(c.Request.Context())
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
 
+	newTeacher.Password, err = pkg.HashPassword(teacher.Password)
 	if err != nil {
 		handleResponse(c, h.Log, "error while hashing password", http.StatusBadRequest, err.Error())
 		return
 	}
 
-	teacher.Password = password
-
-	id, err := h.Service.Teacher().Create(c.Request.Context(), teacher)
+	id, err := h.Service.Teacher().Create(c.Request.Context(), newTeacher)
 	if err != nil {
 		handleResponse(c, h.Log, "error while creating teacher", http.StatusBadRequest, err.Error())
 		return
@@ -81,27 +88,31 @@ func (h Handler) CreateTeacher(c *gin.Context) {
 // @Failure		404  {object}  models.Response
 // @Failure		500  {object}  models.Response
 func (h Handler) UpdateTeacher(c *gin.Context) {
-
-	teacher := models.Teacher{}
-
-	_, err := getAuthInfo(c)
-	if err != nil {
+	if _, err := getAuthInfo(c); err != nil {
 		handleResponse(c, h.Log, "unauthorized", http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	id := c.Param("id")
-	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.Log, "error while validating teacherId", http.StatusBadRequest, err.Error())
+	id, err := models.ParseId("id", c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating teacherId", http.StatusBadRequest, err)
 		return
 	}
-	teacher.Id = id
 
+	teacher := models.AddTeacher{}
 	if err := c.ShouldBindJSON(&teacher); err != nil {
 		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
 		return
 	}
-	id, err = h.Service.Teacher().Update(c.Request.Context(), teacher)
+
+	updatedTeacher, err := teacher.Domain(c.Request.Context())
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
+	updatedTeacher.Id = id
+
+	id, err = h.Service.Teacher().Update(c.Request.Context(), updatedTeacher)
 	if err != nil {
 		handleResponse(c, h.Log, "error while updating teacher", http.StatusInternalServerError, err.Error())
 		return
@@ -124,9 +135,9 @@ func (h Handler) UpdateTeacher(c *gin.Context) {
 // @Failure		404  {object}  models.Response
 // @Failure		500  {object}  models.Response
 func (h Handler) DeleteTeacher(c *gin.Context) {
-	id := c.Param("id")
-	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.Log, "error while validating teacherId", http.StatusBadRequest, err.Error())
+	id, err := models.ParseId("id", c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating teacherId", http.StatusBadRequest, err)
 		return
 	}
 	if err := h.Service.Teacher().Delete(c.Request.Context(), id); err != nil {
@@ -151,10 +162,9 @@ func (h Handler) DeleteTeacher(c *gin.Context) {
 // @Failure		404  {object}  models.Response
 // @Failure		500  {object}  models.Response
 func (h Handler) GetTeacher(c *gin.Context) {
-
-	id := c.Param("id")
-	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.Log, "error while validating teacherId", http.StatusBadRequest, err.Error())
+	id, err := models.ParseId("id", c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating teacherId", http.StatusBadRequest, err)
 		return
 	}
 
@@ -164,23 +174,33 @@ func (h Handler) GetTeacher(c *gin.Context) {
 		return
 	}
 
-	handleResponse(c, h.Log, "Got successfully", http.StatusOK, std)
+	handleResponse(c, h.Log, "Got successfully", http.StatusOK, models.NewTeacher(c.Request.Context(), std))
 }
 
 // GetAllTeachers godoc
 // @Security ApiKeyAuth
 // @Router		/teachers [GET]
 // @Summary		get  all teachers
-// @Description	This api get all teachers
+// @Description	This api get all teachers, format=csv|xlsx or the Accept header exports all of them
 // @Tags		teacher
 // @Accept		json
 // @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		search query string false "search"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
 // @Success		200  {object}  models.Response
 // @Failure		400  {object}  models.Response
 // @Failure		404  {object}  models.Response
 // @Failure		500  {object}  models.Response
 func (h Handler) GetAllTeachers(c *gin.Context) {
 	search := c.Query("search")
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
 
 	page, err := ParsePageQueryParam(c)
 	if err != nil {
@@ -193,16 +213,30 @@ func (h Handler) GetAllTeachers(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.Service.Teacher().GetAll(c.Request.Context(), models.GetAllTeachersRequest{
+	req := models.GetAllTeachersRequest{
 		Limit:  limit,
 		Page:   page,
 		Search: search,
-	})
+	}
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "teachers", func(write func(models.Teacher) error) error {
+			return h.Service.Teacher().Each(c.Request.Context(), req, func(teacher domain.Teacher) error {
+				return write(models.NewTeacher(c.Request.Context(), teacher))
+			})
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting teachers", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	teachers, count, err := h.Service.Teacher().GetAll(c.Request.Context(), req)
 	if err != nil {
 		handleResponse(c, h.Log, "error while getting all teachers", http.StatusInternalServerError, err.Error())
 		return
 	}
-	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+	handleResponse(c, h.Log, "request successful", http.StatusOK, models.NewGetAllTeachersResponse(c.Request.Context(), teachers, count))
 }
 
 // GetTeacherLesson godoc
@@ -234,3 +268,42 @@ func (h Handler) GetTeacherLesson(c *gin.Context) {
 
 	handleResponse(c, h.Log, "Got successfully", http.StatusOK, std)
 }
+
+// ImportTeachers godoc
+// @Security ApiKeyAuth
+// @Router		/teachers/import [POST]
+// @Summary		import teachers
+// @Description	This api imports the teachers of a CSV or XLSX file and reports the errors of every row. The headers are the fields of a teacher unless columns maps them, e.g. {"mail":"E-mail"}. The valid rows are stored in one transaction, dry_run only validates them. Only teachers can import.
+// @Tags		teacher
+// @Accept		multipart/form-data
+// @Produce		json
+// @Param		file formData file true "csv or xlsx file"
+// @Param		columns formData string false "columns mapped to headers as a JSON object"
+// @Param		dry_run query boolean false "dry run"
+// @Success		200  {object}  models.Response
+// @Failure		400  {object}  models.Response
+// @Failure		403  {object}  models.Response
+// @Failure		409  {object}  models.Response
+// @Failure		500  {object}  models.Response
+func (h Handler) ImportTeachers(c *gin.Context) {
+	if _, ok := h.teacherOnly(c, "only teachers can import teachers"); !ok {
+		return
+	}
+
+	records, dryRun, ok := h.importRecords(c, models.TeacherImportColumns)
+	if !ok {
+		return
+	}
+
+	resp, err := h.Service.Teacher().Import(c.Request.Context(), records, dryRun)
+	if errors.Is(err, service.ErrImportConflict) {
+		handleResponse(c, h.Log, "error while importing teachers", http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		handleResponse(c, h.Log, "error while importing teachers", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, h.Log, "Imported successfully", http.StatusOK, resp)
+}
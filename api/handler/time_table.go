@@ -3,17 +3,20 @@ package handler
 import (
 	_ "backend_course/lms/api/docs"
 	"backend_course/lms/api/models"
+	"backend_course/lms/domain"
+	"backend_course/lms/pkg/export"
+	"backend_course/lms/service"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 // CreateTime godoc
 // @Security ApiKeyAuth
 // @Router		/time [POST]
 // @Summary		create a time table
-// @Description	This api create a time table and returns its id
+// @Description	This api create a time table and returns its id, the teacher must be qualified for its subject
 // @Tags		time_table
 // @Accept		json
 // @Produce		json
@@ -23,13 +26,19 @@ import (
 // @Failure		404  {object}  models.Response
 // @Failure		500  {object}  models.Response
 func (h Handler) CreateTime(c *gin.Context) {
-	time := models.Time{}
+	time := models.AddTime{}
 	if err := c.ShouldBindJSON(&time); err != nil {
 		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
 		return
 	}
 
-	id, err := h.Service.Time().Create(c.Request.Context(), time)
+	lesson, err := time.Domain(c.Request.Context())
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := h.Service.Time().Create(c.Request.Context(), lesson)
 	if err != nil {
 		handleResponse(c, h.Log, "error while creating time table", http.StatusBadRequest, err.Error())
 		return
@@ -53,23 +62,33 @@ func (h Handler) CreateTime(c *gin.Context) {
 // @Failure		404  {object}  models.Response
 // @Failure		500  {object}  models.Response
 func (h Handler) UpdateTime(c *gin.Context) {
-
-	time := models.Time{}
-
-	id := c.Param("id")
-	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.Log, "error while validating studentId", http.StatusBadRequest, err.Error())
+	id, err := models.ParseId("id", c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating timeId", http.StatusBadRequest, err)
 		return
 	}
-	time.Id = id
 
+	time := models.AddTime{}
 	if err := c.ShouldBindJSON(&time); err != nil {
 		handleResponse(c, h.Log, "error while reading request body", http.StatusBadRequest, err.Error())
 		return
 	}
-	id, err := h.Service.Time().Update(c.Request.Context(), time)
+
+	lesson, err := time.Domain(c.Request.Context())
 	if err != nil {
-		handleResponse(c, h.Log, "error while updating time table", http.StatusInternalServerError, err.Error())
+		handleResponse(c, h.Log, "error while validating request body", http.StatusBadRequest, err)
+		return
+	}
+	lesson.Id = id
+
+	id, err = h.Service.Time().Update(c.Request.Context(), lesson)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrSchoolClosed) || errors.Is(err, service.ErrTeacherOnLeave) || errors.Is(err, service.ErrTeacherNotAvailable) ||
+			errors.Is(err, service.ErrNoQualification) {
+			status = http.StatusBadRequest
+		}
+		handleResponse(c, h.Log, "error while updating time table", status, err.Error())
 		return
 	}
 
@@ -90,9 +109,9 @@ func (h Handler) UpdateTime(c *gin.Context) {
 // @Failure		404  {object}  models.Response
 // @Failure		500  {object}  models.Response
 func (h Handler) DeleteTime(c *gin.Context) {
-	id := c.Param("id")
-	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.Log, "error while validating timeId", http.StatusBadRequest, err.Error())
+	id, err := models.ParseId("id", c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating timeId", http.StatusBadRequest, err)
 		return
 	}
 	if err := h.Service.Time().Delete(c.Request.Context(), id); err != nil {
@@ -117,10 +136,9 @@ func (h Handler) DeleteTime(c *gin.Context) {
 // @Failure		404  {object}  models.Response
 // @Failure		500  {object}  models.Response
 func (h Handler) GetTime(c *gin.Context) {
-
-	id := c.Param("id")
-	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.Log, "error while validating timeId", http.StatusBadRequest, err.Error())
+	id, err := models.ParseId("id", c.Param("id"))
+	if err != nil {
+		handleResponse(c, h.Log, "error while validating timeId", http.StatusBadRequest, err)
 		return
 	}
 
@@ -130,23 +148,33 @@ func (h Handler) GetTime(c *gin.Context) {
 		return
 	}
 
-	handleResponse(c, h.Log, "Got successfully", http.StatusOK, std)
+	handleResponse(c, h.Log, "Got successfully", http.StatusOK, models.NewTime(c.Request.Context(), std))
 }
 
 // GetAllTimeTables godoc
 // @Security ApiKeyAuth
 // @Router		/time-tables [GET]
 // @Summary		get  time tables
-// @Description	This api get all time tables
+// @Description	This api get all time tables, format=csv|xlsx or the Accept header exports all of them
 // @Tags		time_table
 // @Accept		json
 // @Produce		json
+// @Produce		text/csv
+// @Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		search query string false "search"
+// @Param		page query integer false "page"
+// @Param		limit query integer false "limit"
+// @Param		format query string false "json, csv or xlsx"
 // @Success		200  {object}  models.Response
 // @Failure		400  {object}  models.Response
 // @Failure		404  {object}  models.Response
 // @Failure		500  {object}  models.Response
 func (h Handler) GetAllTimeTables(c *gin.Context) {
 	search := c.Query("search")
+	format, ok := h.exportFormat(c)
+	if !ok {
+		return
+	}
 
 	page, err := ParsePageQueryParam(c)
 	if err != nil {
@@ -159,14 +187,28 @@ func (h Handler) GetAllTimeTables(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.Service.Time().GetAll(c.Request.Context(), models.GetAllTimeRequest{
+	req := models.GetAllTimeRequest{
 		Search: search,
 		Page:   page,
 		Limit:  limit,
-	})
+	}
+	if format != export.JSON {
+		req.Page, req.Limit = 1, export.All
+		err := writeExport(h, c, format, "time-tables", func(write func(models.Time) error) error {
+			return h.Service.Time().Each(c.Request.Context(), req, func(time domain.TimeTable) error {
+				return write(models.NewTime(c.Request.Context(), time))
+			})
+		})
+		if err != nil {
+			handleResponse(c, h.Log, "error while exporting time tables", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	times, count, err := h.Service.Time().GetAll(c.Request.Context(), req)
 	if err != nil {
 		handleResponse(c, h.Log, "error while getting all time tables", http.StatusInternalServerError, err.Error())
 		return
 	}
-	handleResponse(c, h.Log, "request successful", http.StatusOK, resp)
+	handleResponse(c, h.Log, "request successful", http.StatusOK, models.NewGetAllTimeResponse(c.Request.Context(), times, count))
 }
\ No newline at end of file
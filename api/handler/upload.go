@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"backend_course/lms/config"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var errNoFile = errors.New("no file uploaded")
+
+// uploadedFiles returns the files of the multipart field, each one has to be at most
+// config.MaxUploadSize and of one of the content types, no types accept any file.
+func uploadedFiles(c *gin.Context, field string, contentTypes ...string) ([]*multipart.FileHeader, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+
+	files := form.File[field]
+	for _, file := range files {
+		if file.Size > config.MaxUploadSize {
+			return nil, fmt.Errorf("%s is larger than %d bytes", file.Filename, config.MaxUploadSize)
+		}
+		if len(contentTypes) > 0 && !slices.Contains(contentTypes, file.Header.Get("Content-Type")) {
+			return nil, fmt.Errorf("%s: file type is not valid", file.Filename)
+		}
+	}
+	return files, nil
+}
+
+// saveUploads stores the files under dir and returns their paths. Every file gets a
+// unique name so that uploads with the same name do not overwrite each other.
+func saveUploads(c *gin.Context, dir string, files []*multipart.FileHeader) ([]string, error) {
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		path := dir + uuid.NewString() + "_" + filepath.Base(file.Filename)
+		if err := c.SaveUploadedFile(file, path); err != nil {
+			removeUploads(paths)
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// removeUploads deletes saved files whose request failed afterwards or which were replaced.
+func removeUploads(paths []string) {
+	for _, path := range paths {
+		os.Remove(path)
+	}
+}
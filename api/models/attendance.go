@@ -0,0 +1,42 @@
+package models
+
+type Attendance struct {
+	Id          string `json:"id"`
+	TimeTableId string `json:"time_table_id"`
+	StudentId   string `json:"student_id"`
+	StudentName string `json:"student_name"`
+	Status      string `json:"status"`
+	CheckInTime string `json:"check_in_time"`
+	Note        string `json:"note"`
+	MarkedBy    string `json:"marked_by"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type AttendanceRecord struct {
+	StudentId   string `json:"student_id"`
+	Status      string `json:"status"`
+	CheckInTime string `json:"check_in_time"`
+	Note        string `json:"note"`
+}
+
+type MarkAttendanceRequest struct {
+	TimeTableId string             `json:"time_table_id"`
+	Records     []AttendanceRecord `json:"records"`
+}
+
+type GetLessonAttendanceResponse struct {
+	Attendance []Attendance `json:"attendance"`
+	Count      int64        `json:"count"`
+}
+
+// AttendanceSummary counts a student's past lessons by attendance status, Unmarked lessons
+// have no attendance.
+type AttendanceSummary struct {
+	Lessons  int `json:"lessons"`
+	Present  int `json:"present"`
+	Late     int `json:"late"`
+	Absent   int `json:"absent"`
+	Excused  int `json:"excused"`
+	Unmarked int `json:"unmarked"`
+}
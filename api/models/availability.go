@@ -0,0 +1,62 @@
+package models
+
+type TeacherAvailability struct {
+	Windows []AvailabilityWindow `json:"windows"`
+}
+
+type Leave struct {
+	Id         string `json:"id"`
+	TeacherId  string `json:"teacher_id"`
+	FromDate   string `json:"from_date"`
+	ToDate     string `json:"to_date"`
+	Reason     string `json:"reason"`
+	Status     string `json:"status"`
+	ReviewedAt string `json:"reviewed_at"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type AddLeave struct {
+	TeacherId string `json:"-"`
+	FromDate  string `json:"from_date"`
+	ToDate    string `json:"to_date"`
+	Reason    string `json:"reason"`
+}
+
+type ReviewLeave struct {
+	Status string `json:"status"`
+}
+
+type GetAllLeavesRequest struct {
+	TeacherId string `json:"teacher_id"`
+	Status    string `json:"status"`
+	Page      uint64 `json:"page"`
+	Limit     uint64 `json:"limit"`
+}
+
+type GetAllLeavesResponse struct {
+	Leaves []Leave `json:"leaves"`
+	Count  int64   `json:"count"`
+}
+
+// ImpactedLesson is a lesson of a teacher which falls into the teacher's leave.
+type ImpactedLesson struct {
+	SubjectId     string   `json:"subject_id"`
+	SubjectName   string   `json:"subject_name"`
+	RoomName      string   `json:"room_name"`
+	FromDate      string   `json:"from_date"`
+	ToDate        string   `json:"to_date"`
+	StudentsCount int64    `json:"students_count"`
+	TimeTableIds  []string `json:"time_table_ids"`
+}
+
+type LeaveImpactResponse struct {
+	Leave   Leave            `json:"leave"`
+	Lessons []ImpactedLesson `json:"lessons"`
+	Count   int64            `json:"count"`
+}
+
+// LessonAvailability tells whether a lesson's time suits its teacher.
+type LessonAvailability struct {
+	WithinAvailability bool `json:"within_availability"`
+	OnLeave            bool `json:"on_leave"`
+}
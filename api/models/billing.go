@@ -0,0 +1,267 @@
+package models
+
+import (
+	"context"
+)
+
+// Money is given in minor units of the school's currency, like cents.
+
+// PricePlan is the monthly fee of a subject or of a group, exactly one of SubjectId and
+// GroupId is set.
+type PricePlan struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	SubjectId   string `json:"subject_id"`
+	SubjectName string `json:"subject_name"`
+	GroupId     string `json:"group_id"`
+	GroupName   string `json:"group_name"`
+	MonthlyFee  int64  `json:"monthly_fee"`
+	DueDay      int    `json:"due_day"`
+	Active      bool   `json:"active"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type AddPricePlan struct {
+	Name       string `json:"name"`
+	SubjectId  string `json:"subject_id"`
+	GroupId    string `json:"group_id"`
+	MonthlyFee int64  `json:"monthly_fee"`
+	DueDay     int    `json:"due_day"`
+}
+
+// Validate reports a malformed subject or group id of the plan.
+func (a AddPricePlan) Validate(ctx context.Context) error {
+	p := fieldParser{ctx: ctx}
+	p.optionalUUID("subject_id", a.SubjectId)
+	p.optionalUUID("group_id", a.GroupId)
+	return p.err()
+}
+
+type UpdatePricePlan struct {
+	Name       string `json:"name"`
+	MonthlyFee int64  `json:"monthly_fee"`
+	DueDay     int    `json:"due_day"`
+	Active     bool   `json:"active"`
+}
+
+type GetAllPricePlansRequest struct {
+	SubjectId string `json:"subject_id"`
+	GroupId   string `json:"group_id"`
+	Page      uint64 `json:"page"`
+	Limit     uint64 `json:"limit"`
+}
+
+type GetAllPricePlansResponse struct {
+	PricePlans []PricePlan `json:"price_plans"`
+	Count      int64       `json:"count"`
+}
+
+// Discount lowers the student's invoices of a plan by Percent, it applies to the invoices
+// generated after it is set.
+type Discount struct {
+	Id          string `json:"id"`
+	StudentId   string `json:"student_id"`
+	StudentName string `json:"student_name"`
+	PlanId      string `json:"plan_id"`
+	PlanName    string `json:"plan_name"`
+	Percent     int    `json:"percent"`
+	Reason      string `json:"reason"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type AddDiscount struct {
+	StudentId string `json:"student_id"`
+	PlanId    string `json:"plan_id"`
+	Percent   int    `json:"percent"`
+	Reason    string `json:"reason"`
+}
+
+// Validate reports every malformed id of the request.
+func (a AddDiscount) Validate(ctx context.Context) error {
+	p := fieldParser{ctx: ctx}
+	p.uuid("student_id", a.StudentId)
+	p.uuid("plan_id", a.PlanId)
+	return p.err()
+}
+
+type GetAllDiscountsRequest struct {
+	StudentId string `json:"student_id"`
+	PlanId    string `json:"plan_id"`
+	Page      uint64 `json:"page"`
+	Limit     uint64 `json:"limit"`
+}
+
+type GetAllDiscountsResponse struct {
+	Discounts []Discount `json:"discounts"`
+	Count     int64      `json:"count"`
+}
+
+// Invoice is a student's monthly fee of a plan, Total is Amount less Discount and
+// Outstanding is what is left of it to pay. Status is open, paid, overdue or cancelled.
+type Invoice struct {
+	Id          string `json:"id"`
+	Number      string `json:"number"`
+	StudentId   string `json:"student_id"`
+	StudentName string `json:"student_name"`
+	PlanId      string `json:"plan_id"`
+	PlanName    string `json:"plan_name"`
+	Period      string `json:"period"`
+	Amount      int64  `json:"amount"`
+	Discount    int64  `json:"discount"`
+	Total       int64  `json:"total"`
+	Paid        int64  `json:"paid"`
+	Outstanding int64  `json:"outstanding"`
+	DueDate     string `json:"due_date"`
+	Status      string `json:"status"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// GenerateInvoices asks for the invoices of a YYYY-MM period.
+type GenerateInvoices struct {
+	Period string `json:"period"`
+}
+
+type GenerateInvoicesResponse struct {
+	Period  string `json:"period"`
+	Created int64  `json:"created"`
+}
+
+type UpdateInvoice struct {
+	Status string `json:"status"`
+}
+
+// GetAllInvoicesRequest filters invoices, Today is the date overdue invoices are
+// computed on.
+type GetAllInvoicesRequest struct {
+	StudentId string `json:"student_id"`
+	PlanId    string `json:"plan_id"`
+	Period    string `json:"period"`
+	Status    string `json:"status"`
+	Today     string `json:"-"`
+	Page      uint64 `json:"page"`
+	Limit     uint64 `json:"limit"`
+}
+
+type GetAllInvoicesResponse struct {
+	Invoices []Invoice `json:"invoices"`
+	Count    int64     `json:"count"`
+}
+
+type Payment struct {
+	Id            string `json:"id"`
+	StudentId     string `json:"student_id"`
+	StudentName   string `json:"student_name"`
+	InvoiceId     string `json:"invoice_id"`
+	InvoiceNumber string `json:"invoice_number"`
+	Amount        int64  `json:"amount"`
+	Method        string `json:"method"`
+	Reference     string `json:"reference"`
+	RecordedBy    string `json:"recorded_by"`
+	PaidAt        string `json:"paid_at"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// AddPayment records a payment of an invoice, method is cash, card or transfer. PaidAt
+// defaults to now.
+type AddPayment struct {
+	InvoiceId  string `json:"invoice_id"`
+	Amount     int64  `json:"amount"`
+	Method     string `json:"method"`
+	Reference  string `json:"reference"`
+	PaidAt     string `json:"paid_at"`
+	RecordedBy string `json:"-"`
+}
+
+// Validate reports a malformed invoice id or payment time, an empty paid_at is now.
+func (a AddPayment) Validate(ctx context.Context) error {
+	p := fieldParser{ctx: ctx}
+	p.uuid("invoice_id", a.InvoiceId)
+	p.optionalTime("paid_at", a.PaidAt)
+	return p.err()
+}
+
+type GetAllPaymentsRequest struct {
+	StudentId string `json:"student_id"`
+	InvoiceId string `json:"invoice_id"`
+	Page      uint64 `json:"page"`
+	Limit     uint64 `json:"limit"`
+}
+
+type GetAllPaymentsResponse struct {
+	Payments []Payment `json:"payments"`
+	Count    int64     `json:"count"`
+}
+
+// BalanceEntry is an invoice or a payment of a student's statement, invoices add their
+// total and payments take their amount off the running Balance.
+type BalanceEntry struct {
+	Date      string `json:"date"`
+	Kind      string `json:"kind"`
+	Id        string `json:"id"`
+	Reference string `json:"reference"`
+	Amount    int64  `json:"amount"`
+	Balance   int64  `json:"balance"`
+}
+
+// StudentBalance is what the student owes, Overdue is the part of it past its due date.
+type StudentBalance struct {
+	StudentId string         `json:"student_id"`
+	Invoiced  int64          `json:"invoiced"`
+	Paid      int64          `json:"paid"`
+	Balance   int64          `json:"balance"`
+	Overdue   int64          `json:"overdue"`
+	Entries   []BalanceEntry `json:"entries"`
+}
+
+type Debtor struct {
+	StudentId       string `json:"student_id"`
+	StudentName     string `json:"student_name"`
+	Phone           string `json:"phone"`
+	Balance         int64  `json:"balance"`
+	Overdue         int64  `json:"overdue"`
+	OverdueInvoices int64  `json:"overdue_invoices"`
+	OldestDueDate   string `json:"oldest_due_date"`
+}
+
+// DebtorsRequest filters the students with overdue invoices, Today is the date overdue
+// invoices are computed on.
+type DebtorsRequest struct {
+	GroupId    string `json:"group_id"`
+	MinOverdue int64  `json:"min_overdue"`
+	Today      string `json:"-"`
+	Page       uint64 `json:"page"`
+	Limit      uint64 `json:"limit"`
+}
+
+type DebtorsResponse struct {
+	Debtors      []Debtor `json:"debtors"`
+	Count        int64    `json:"count"`
+	TotalOverdue int64    `json:"total_overdue"`
+}
+
+// PaymentTransaction is a checkout of an invoice through a payment provider, State is
+// created, prepared, completed or cancelled.
+type PaymentTransaction struct {
+	Id           string `json:"id"`
+	InvoiceId    string `json:"invoice_id"`
+	Provider     string `json:"provider"`
+	ProviderTxId string `json:"provider_tx_id"`
+	Amount       int64  `json:"amount"`
+	State        string `json:"state"`
+	PaymentId    string `json:"payment_id"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+type CreateCheckout struct {
+	Provider string `json:"provider"`
+}
+
+// Checkout is where the payer pays the outstanding amount of an invoice.
+type Checkout struct {
+	TransactionId string `json:"transaction_id"`
+	Provider      string `json:"provider"`
+	Amount        int64  `json:"amount"`
+	CheckoutURL   string `json:"checkout_url"`
+}
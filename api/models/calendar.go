@@ -0,0 +1,65 @@
+package models
+
+type AcademicYear struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Terms     []Term `json:"terms"`
+	CreatedAt string `json:"created_at"`
+}
+
+type AddAcademicYear struct {
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+type GetAllAcademicYearsResponse struct {
+	AcademicYears []AcademicYear `json:"academic_years"`
+	Count         int64          `json:"count"`
+}
+
+type Term struct {
+	Id             string `json:"id"`
+	AcademicYearId string `json:"academic_year_id"`
+	Name           string `json:"name"`
+	StartDate      string `json:"start_date"`
+	EndDate        string `json:"end_date"`
+	CreatedAt      string `json:"created_at"`
+}
+
+type AddTerm struct {
+	AcademicYearId string `json:"academic_year_id"`
+	Name           string `json:"name"`
+	StartDate      string `json:"start_date"`
+	EndDate        string `json:"end_date"`
+}
+
+// Closure is a holiday or closure, the school is closed on every day from FromDate to ToDate.
+type Closure struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	FromDate  string `json:"from_date"`
+	ToDate    string `json:"to_date"`
+	CreatedAt string `json:"created_at"`
+}
+
+type AddClosure struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	FromDate string `json:"from_date"`
+	ToDate   string `json:"to_date"`
+}
+
+type GetAllClosuresRequest struct {
+	FromDate string `json:"from_date"`
+	ToDate   string `json:"to_date"`
+	TermId   string `json:"term_id"`
+}
+
+type GetAllClosuresResponse struct {
+	Closures []Closure `json:"closures"`
+	Count    int64     `json:"count"`
+}
@@ -0,0 +1,26 @@
+package models
+
+type Geofence struct {
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	RadiusMeters float64 `json:"radius_meters"`
+}
+
+type CheckInCodeRequest struct {
+	Geofence *Geofence `json:"geofence,omitempty"`
+}
+
+type CheckInCode struct {
+	Code        string    `json:"code"`
+	QRPayload   string    `json:"qr_payload"`
+	TimeTableId string    `json:"time_table_id"`
+	TeacherId   string    `json:"teacher_id"`
+	Geofence    *Geofence `json:"geofence,omitempty"`
+	ExpiresIn   int       `json:"expires_in_seconds"`
+}
+
+type CheckInRequest struct {
+	Code      string   `json:"code"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
@@ -0,0 +1,61 @@
+package models
+
+// DashboardRequest narrows the dashboard to FromDate to ToDate, without them each figure
+// covers its default period.
+type DashboardRequest struct {
+	FromDate string `json:"from_date"`
+	ToDate   string `json:"to_date"`
+}
+
+type Dashboard struct {
+	FromDate     string         `json:"from_date"`
+	ToDate       string         `json:"to_date"`
+	Students     StudentCounts  `json:"students"`
+	NewStudents  []MonthCount   `json:"new_students"`
+	LessonsToday int64          `json:"lessons_today"`
+	Attendance   AttendanceRate `json:"attendance"`
+	Revenue      Revenue        `json:"revenue"`
+	TopSubjects  []SubjectStat  `json:"top_subjects"`
+	GeneratedAt  string         `json:"generated_at"`
+}
+
+type StudentCounts struct {
+	Active   int64 `json:"active"`
+	Inactive int64 `json:"inactive"`
+	Total    int64 `json:"total"`
+}
+
+// MonthCount is a count of the YYYY-MM Month.
+type MonthCount struct {
+	Month string `json:"month"`
+	Count int64  `json:"count"`
+}
+
+// AttendanceRate sums the attendance of the lessons started from FromDate to ToDate, Rate
+// is the percentage of the marked ones the student was present or late at.
+type AttendanceRate struct {
+	FromDate string  `json:"from_date"`
+	ToDate   string  `json:"to_date"`
+	Present  int64   `json:"present"`
+	Late     int64   `json:"late"`
+	Absent   int64   `json:"absent"`
+	Excused  int64   `json:"excused"`
+	Unmarked int64   `json:"unmarked"`
+	Rate     float64 `json:"rate"`
+}
+
+// Revenue sums the payments received from FromDate to ToDate, in minor units.
+type Revenue struct {
+	FromDate string `json:"from_date"`
+	ToDate   string `json:"to_date"`
+	Amount   int64  `json:"amount"`
+	Payments int64  `json:"payments"`
+}
+
+// SubjectStat is a subject's lessons and the distinct students taught in them.
+type SubjectStat struct {
+	SubjectId   string `json:"subject_id"`
+	SubjectName string `json:"subject_name"`
+	Lessons     int64  `json:"lessons"`
+	Students    int64  `json:"students"`
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"context"
+	"fmt"
+)
+
+type Assessment struct {
+	Id        string  `json:"id"`
+	SubjectId string  `json:"subject_id"`
+	GroupId   string  `json:"group_id"`
+	TeacherId string  `json:"teacher_id"`
+	Name      string  `json:"name"`
+	Kind      string  `json:"kind"`
+	MaxScore  float64 `json:"max_score"`
+	Weight    float64 `json:"weight"`
+	HeldOn    string  `json:"held_on"`
+	CreatedAt string  `json:"created_at"`
+}
+
+type AddAssessment struct {
+	SubjectId string  `json:"subject_id"`
+	GroupId   string  `json:"group_id"`
+	Name      string  `json:"name"`
+	Kind      string  `json:"kind"`
+	MaxScore  float64 `json:"max_score"`
+	Weight    float64 `json:"weight"`
+	HeldOn    string  `json:"held_on"`
+}
+
+// Validate reports every malformed id and date of the request.
+func (a AddAssessment) Validate(ctx context.Context) error {
+	p := fieldParser{ctx: ctx}
+	p.uuid("subject_id", a.SubjectId)
+	p.uuid("group_id", a.GroupId)
+	p.date("held_on", a.HeldOn)
+	return p.err()
+}
+
+type GetAllAssessmentsRequest struct {
+	GroupId   string `json:"group_id"`
+	SubjectId string `json:"subject_id"`
+	TermId    string `json:"term_id"`
+	Page      uint64 `json:"page"`
+	Limit     uint64 `json:"limit"`
+}
+
+type GetAllAssessmentsResponse struct {
+	Assessments []Assessment `json:"assessments"`
+	Count       int64        `json:"count"`
+}
+
+type Score struct {
+	StudentId string  `json:"student_id"`
+	Score     float64 `json:"score"`
+}
+
+type SetScoresRequest struct {
+	Scores []Score `json:"scores"`
+}
+
+// Validate reports every malformed student id of the scores.
+func (r SetScoresRequest) Validate(ctx context.Context) error {
+	p := fieldParser{ctx: ctx}
+	for i, score := range r.Scores {
+		p.uuid(fmt.Sprintf("scores[%d].student_id", i), score.StudentId)
+	}
+	return p.err()
+}
+
+// GradesRequest filters the scores a grade is computed from, Scale is the grading scale
+// the grade is rendered on. FromDate and ToDate bound the days the assessments are held on.
+type GradesRequest struct {
+	StudentId string `json:"student_id"`
+	GroupId   string `json:"group_id"`
+	SubjectId string `json:"subject_id"`
+	TermId    string `json:"term_id"`
+	FromDate  string `json:"from_date"`
+	ToDate    string `json:"to_date"`
+	Scale     string `json:"scale"`
+}
+
+// GradeRow is one assessment of a student, Score is nil until it is scored.
+type GradeRow struct {
+	StudentId   string
+	StudentName string
+	SubjectId   string
+	SubjectName string
+	Assessment  Assessment
+	Score       *float64
+}
+
+type AssessmentScore struct {
+	AssessmentId string   `json:"assessment_id"`
+	Name         string   `json:"name"`
+	Kind         string   `json:"kind"`
+	MaxScore     float64  `json:"max_score"`
+	Weight       float64  `json:"weight"`
+	HeldOn       string   `json:"held_on"`
+	Score        *float64 `json:"score"`
+}
+
+type SubjectGrade struct {
+	SubjectId   string            `json:"subject_id"`
+	SubjectName string            `json:"subject_name"`
+	Percent     float64           `json:"percent"`
+	Grade       string            `json:"grade"`
+	Assessments []AssessmentScore `json:"assessments"`
+}
+
+type StudentGradesResponse struct {
+	StudentId string         `json:"student_id"`
+	Scale     string         `json:"scale"`
+	Subjects  []SubjectGrade `json:"subjects"`
+}
+
+type StudentGrade struct {
+	StudentId   string  `json:"student_id"`
+	StudentName string  `json:"student_name"`
+	Scored      int     `json:"scored"`
+	Percent     float64 `json:"percent"`
+	Grade       string  `json:"grade"`
+}
+
+type ClassGradesResponse struct {
+	GroupId     string         `json:"group_id"`
+	SubjectId   string         `json:"subject_id"`
+	Scale       string         `json:"scale"`
+	Assessments int            `json:"assessments"`
+	Average     float64        `json:"average"`
+	Students    []StudentGrade `json:"students"`
+}
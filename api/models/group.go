@@ -0,0 +1,29 @@
+package models
+
+type Group struct {
+	Id            string   `json:"id"`
+	Name          string   `json:"name"`
+	StudentsCount int64    `json:"students_count"`
+	StudentIds    []string `json:"student_ids,omitempty"`
+	CreatedAt     string   `json:"created_at"`
+	UpdatedAt     string   `json:"updated_at"`
+}
+
+type AddGroup struct {
+	Name string `json:"name"`
+}
+
+type GroupStudents struct {
+	StudentIds []string `json:"student_ids"`
+}
+
+type GetAllGroupsRequest struct {
+	Search string `json:"search"`
+	Page   uint64 `json:"page"`
+	Limit  uint64 `json:"limit"`
+}
+
+type GetAllGroupsResponse struct {
+	Groups []Group `json:"groups"`
+	Count  int64   `json:"count"`
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"context"
+	"fmt"
+)
+
+type Guardian struct {
+	Id        string            `json:"id"`
+	FirstName string            `json:"first_name"`
+	LastName  string            `json:"last_name"`
+	Phone     string            `json:"phone"`
+	Email     string            `json:"mail"`
+	Timezone  string            `json:"timezone"`
+	Students  []GuardianStudent `json:"students,omitempty"`
+	CreatedAt string            `json:"created_at"`
+	UpdatedAt string            `json:"updated_at"`
+	Password  string            `json:"-"`
+}
+
+type AddGuardian struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Phone     string `json:"phone"`
+	Email     string `json:"mail"`
+	Password  string `json:"password,omitempty"`
+	Timezone  string `json:"timezone"`
+}
+
+// GuardianStudent is a student linked to a guardian, relation is mother, father, guardian
+// or other.
+type GuardianStudent struct {
+	StudentId   string `json:"student_id"`
+	StudentName string `json:"student_name,omitempty"`
+	Relation    string `json:"relation"`
+}
+
+type SetGuardianStudents struct {
+	Students []GuardianStudent `json:"students"`
+}
+
+// Validate reports every malformed student id of the request.
+func (r SetGuardianStudents) Validate(ctx context.Context) error {
+	p := fieldParser{ctx: ctx}
+	for i, student := range r.Students {
+		p.uuid(fmt.Sprintf("students[%d].student_id", i), student.StudentId)
+	}
+	return p.err()
+}
+
+type GetAllGuardiansRequest struct {
+	Search    string `json:"search"`
+	StudentId string `json:"student_id"`
+	Page      uint64 `json:"page"`
+	Limit     uint64 `json:"limit"`
+}
+
+type GetAllGuardiansResponse struct {
+	Guardians []Guardian `json:"guardians"`
+	Count     int64      `json:"count"`
+}
+
+// StudentLesson is a lesson of a student's timetable, Attendance is empty until it is
+// marked.
+type StudentLesson struct {
+	TimeTableId string `json:"time_table_id"`
+	SubjectName string `json:"subject_name"`
+	TeacherName string `json:"teacher_name"`
+	RoomName    string `json:"room_name"`
+	FromDate    string `json:"from_date"`
+	ToDate      string `json:"to_date"`
+	Attendance  string `json:"attendance"`
+}
+
+type StudentTimetableResponse struct {
+	Lessons []StudentLesson `json:"lessons"`
+	Count   int64           `json:"count"`
+}
+
+// StudentAttendanceResponse is a student's attendance over a period, Lessons are the ones
+// which have already started.
+type StudentAttendanceResponse struct {
+	Summary AttendanceSummary `json:"summary"`
+	Lessons []StudentLesson   `json:"lessons"`
+}
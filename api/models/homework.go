@@ -0,0 +1,97 @@
+package models
+
+import (
+	"context"
+)
+
+type Assignment struct {
+	Id          string   `json:"id"`
+	SubjectId   string   `json:"subject_id"`
+	GroupId     string   `json:"group_id"`
+	TeacherId   string   `json:"teacher_id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Attachments []string `json:"attachments"`
+	DueAt       string   `json:"due_at"`
+	CreatedAt   string   `json:"created_at"`
+}
+
+// AddAssignment is read from a multipart form, the attachments are the paths of its
+// uploaded files.
+type AddAssignment struct {
+	SubjectId   string   `json:"subject_id"`
+	GroupId     string   `json:"group_id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	DueAt       string   `json:"due_at"`
+	Attachments []string `json:"attachments"`
+}
+
+// Validate reports every malformed id and time of the request.
+func (a AddAssignment) Validate(ctx context.Context) error {
+	p := fieldParser{ctx: ctx}
+	p.uuid("subject_id", a.SubjectId)
+	p.uuid("group_id", a.GroupId)
+	p.time("due_at", a.DueAt)
+	return p.err()
+}
+
+type GetAllAssignmentsRequest struct {
+	GroupId   string `json:"group_id"`
+	SubjectId string `json:"subject_id"`
+	StudentId string `json:"student_id"`
+	Page      uint64 `json:"page"`
+	Limit     uint64 `json:"limit"`
+}
+
+type GetAllAssignmentsResponse struct {
+	Assignments []Assignment `json:"assignments"`
+	Count       int64        `json:"count"`
+}
+
+type Submission struct {
+	Id           string   `json:"id"`
+	AssignmentId string   `json:"assignment_id"`
+	StudentId    string   `json:"student_id"`
+	StudentName  string   `json:"student_name"`
+	Text         string   `json:"text"`
+	Files        []string `json:"files"`
+	Status       string   `json:"status"`
+	Attempt      int      `json:"attempt"`
+	Score        *float64 `json:"score"`
+	Comment      string   `json:"comment"`
+	ReviewedBy   string   `json:"reviewed_by"`
+	SubmittedAt  string   `json:"submitted_at"`
+	ReviewedAt   string   `json:"reviewed_at"`
+}
+
+// AddSubmission is read from a multipart form, the files are the paths of its uploaded
+// files. Status is set by the service from the assignment's due date.
+type AddSubmission struct {
+	AssignmentId string   `json:"assignment_id"`
+	StudentId    string   `json:"student_id"`
+	Text         string   `json:"text"`
+	Files        []string `json:"files"`
+	Status       string   `json:"status"`
+}
+
+// ReviewSubmission grades a submission or returns it to the student for another attempt,
+// Status is graded or returned.
+type ReviewSubmission struct {
+	Status  string   `json:"status"`
+	Score   *float64 `json:"score"`
+	Comment string   `json:"comment"`
+}
+
+type GetAssignmentSubmissionsResponse struct {
+	Submissions []Submission `json:"submissions"`
+}
+
+// TeacherComment is a teacher's comment on a student's submission.
+type TeacherComment struct {
+	SubjectName string `json:"subject_name"`
+	TeacherName string `json:"teacher_name"`
+	Assignment  string `json:"assignment"`
+	Comment     string `json:"comment"`
+	ReviewedAt  string `json:"reviewed_at"`
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"backend_course/lms/pkg/export"
+	"strconv"
+	"strings"
+)
+
+// StudentImportColumns and TeacherImportColumns are the columns an import reads, the
+// headers of a file are these unless the columns are mapped to other headers.
+var (
+	StudentImportColumns = export.Columns(AddStudent{})
+	TeacherImportColumns = export.Columns(AddTeacher{})
+)
+
+// ImportResponse reports an import, Rows are the rows of the file and Valid the ones
+// without errors. Imported is 0 for a dry run, nothing is stored then.
+type ImportResponse struct {
+	Rows     int              `json:"rows"`
+	Valid    int              `json:"valid"`
+	Imported int64            `json:"imported"`
+	DryRun   bool             `json:"dry_run"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// ImportRowError is an invalid field of a row, Row is the line of the file.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ImportContacts are mails and phones, which are unique among students and among
+// teachers.
+type ImportContacts struct {
+	Mails  []string
+	Phones []string
+}
+
+// NewImportStudent reads a row of an import into a student, is_active is true unless the
+// row says otherwise.
+func NewImportStudent(values map[string]string) (AddStudent, error) {
+	p := fieldParser{}
+	student := AddStudent{
+		FirstName:  values["first_name"],
+		LastName:   values["last_name"],
+		Age:        p.int("age", values["age"]),
+		ExternalId: values["external_id"],
+		Phone:      values["phone"],
+		Email:      values["mail"],
+		IsActive:   p.bool("is_active", values["is_active"], true),
+		Password:   values["password"],
+		Timezone:   values["timezone"],
+	}
+	return student, p.err()
+}
+
+// NewImportTeacher reads a row of an import into a teacher.
+func NewImportTeacher(values map[string]string) (AddTeacher, error) {
+	p := fieldParser{}
+	teacher := AddTeacher{
+		FirstName:        values["first_name"],
+		LastName:         values["last_name"],
+		StartWorking:     values["start_working"],
+		Phone:            values["phone"],
+		Email:            values["mail"],
+		Password:         values["password"],
+		MaxWeeklyLessons: p.optionalInt("max_weekly_lessons", values["max_weekly_lessons"]),
+		Timezone:         values["timezone"],
+	}
+	return teacher, p.err()
+}
+
+func (p *fieldParser) int(field, s string) int {
+	if s == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		p.fail(field, "must be a whole number")
+	}
+	return n
+}
+
+// optionalInt reads an empty cell as an absent number.
+func (p *fieldParser) optionalInt(field, s string) *int {
+	if s == "" {
+		return nil
+	}
+	n := p.int(field, s)
+	return &n
+}
+
+func (p *fieldParser) bool(field, s string, empty bool) bool {
+	switch strings.ToLower(s) {
+	case "":
+		return empty
+	case "true", "yes", "1":
+		return true
+	case "false", "no", "0":
+		return false
+	}
+	p.fail(field, "must be true or false")
+	return empty
+}
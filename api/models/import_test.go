@@ -0,0 +1,51 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportColumns(t *testing.T) {
+	assert.Equal(t, []string{"first_name", "last_name", "age", "external_id", "phone", "mail", "is_active", "password", "timezone"}, StudentImportColumns)
+	assert.Contains(t, TeacherImportColumns, "max_weekly_lessons")
+}
+
+func TestNewImportStudent(t *testing.T) {
+	student, err := NewImportStudent(map[string]string{"first_name": "Ali", "age": "2008", "mail": "ali@gmail.com"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, AddStudent{FirstName: "Ali", Age: 2008, Email: "ali@gmail.com", IsActive: true}, student)
+	}
+
+	student, err = NewImportStudent(map[string]string{"age": "2008", "is_active": "No"})
+	if assert.NoError(t, err) {
+		assert.False(t, student.IsActive)
+	}
+
+	_, err = NewImportStudent(map[string]string{"age": "old", "is_active": "maybe"})
+	var fields FieldErrors
+	if assert.True(t, errors.As(err, &fields)) {
+		assert.Equal(t, []string{"age", "is_active"}, []string{fields[0].Field, fields[1].Field})
+	}
+}
+
+func TestNewImportTeacher(t *testing.T) {
+	teacher, err := NewImportTeacher(map[string]string{"first_name": "Olim", "max_weekly_lessons": "12"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, AddTeacher{FirstName: "Olim", MaxWeeklyLessons: &[]int{12}[0]}, teacher)
+	}
+
+	// an empty cell keeps the default cap, 0 is a cap of its own
+	teacher, err = NewImportTeacher(map[string]string{"max_weekly_lessons": ""})
+	if assert.NoError(t, err) {
+		assert.Nil(t, teacher.MaxWeeklyLessons)
+	}
+	teacher, err = NewImportTeacher(map[string]string{"max_weekly_lessons": "0"})
+	if assert.NoError(t, err) && assert.NotNil(t, teacher.MaxWeeklyLessons) {
+		assert.Equal(t, 0, *teacher.MaxWeeklyLessons)
+	}
+
+	_, err = NewImportTeacher(map[string]string{"max_weekly_lessons": "1.5"})
+	assert.Error(t, err)
+}
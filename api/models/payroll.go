@@ -0,0 +1,84 @@
+package models
+
+import (
+	"context"
+)
+
+// Money is given in minor units of the school's currency, like cents.
+
+// TeacherRates is a teacher's hourly rate and the subjects paid at another rate.
+type TeacherRates struct {
+	TeacherId  string        `json:"teacher_id"`
+	HourlyRate int64         `json:"hourly_rate"`
+	Subjects   []SubjectRate `json:"subjects"`
+}
+
+type SubjectRate struct {
+	SubjectId   string `json:"subject_id"`
+	SubjectName string `json:"subject_name"`
+	HourlyRate  int64  `json:"hourly_rate"`
+}
+
+// SetRate sets a teacher's hourly rate, or the rate of a subject when SubjectId is given.
+type SetRate struct {
+	SubjectId  string `json:"subject_id"`
+	HourlyRate int64  `json:"hourly_rate"`
+}
+
+// Validate reports a malformed subject id, an empty one sets the teacher's default rate.
+func (r SetRate) Validate(ctx context.Context) error {
+	p := fieldParser{ctx: ctx}
+	p.optionalUUID("subject_id", r.SubjectId)
+	return p.err()
+}
+
+// PayrollLine is the pay of a teacher for the lessons of a subject, SubstituteLessons are
+// the ones given in place of another teacher.
+type PayrollLine struct {
+	TeacherId         string  `json:"teacher_id"`
+	TeacherName       string  `json:"teacher_name"`
+	SubjectId         string  `json:"subject_id"`
+	SubjectName       string  `json:"subject_name"`
+	Lessons           int64   `json:"lessons"`
+	SubstituteLessons int64   `json:"substitute_lessons"`
+	Minutes           int64   `json:"minutes"`
+	Hours             float64 `json:"hours"`
+	HourlyRate        int64   `json:"hourly_rate"`
+	Amount            int64   `json:"amount"`
+}
+
+// PayrollRun is the pay of the lessons delivered from FromDate to ToDate, a saved run is
+// locked and has an Id.
+type PayrollRun struct {
+	Id        string        `json:"id"`
+	FromDate  string        `json:"from_date"`
+	ToDate    string        `json:"to_date"`
+	Total     int64         `json:"total"`
+	CreatedBy string        `json:"created_by"`
+	LockedAt  string        `json:"locked_at"`
+	Lines     []PayrollLine `json:"lines"`
+}
+
+type CreatePayrollRun struct {
+	FromDate  string `json:"from_date"`
+	ToDate    string `json:"to_date"`
+	CreatedBy string `json:"-"`
+}
+
+// Validate reports every malformed date of the run.
+func (r CreatePayrollRun) Validate(ctx context.Context) error {
+	p := fieldParser{ctx: ctx}
+	p.date("from_date", r.FromDate)
+	p.date("to_date", r.ToDate)
+	return p.err()
+}
+
+type GetAllPayrollRunsRequest struct {
+	Page  uint64 `json:"page"`
+	Limit uint64 `json:"limit"`
+}
+
+type GetAllPayrollRunsResponse struct {
+	PayrollRuns []PayrollRun `json:"payroll_runs"`
+	Count       int64        `json:"count"`
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"context"
+	"fmt"
+)
+
+type Quiz struct {
+	Id               string         `json:"id"`
+	AssessmentId     string         `json:"assessment_id"`
+	SubjectId        string         `json:"subject_id"`
+	GroupId          string         `json:"group_id"`
+	TeacherId        string         `json:"teacher_id"`
+	Title            string         `json:"title"`
+	OpensAt          string         `json:"opens_at"`
+	ClosesAt         string         `json:"closes_at"`
+	TimeLimitMinutes int            `json:"time_limit_minutes"`
+	MaxAttempts      int            `json:"max_attempts"`
+	MaxScore         float64        `json:"max_score"`
+	CreatedAt        string         `json:"created_at"`
+	Questions        []QuizQuestion `json:"questions,omitempty"`
+}
+
+// QuizQuestion is a question with its right answer, Correct holds the indexes of the right
+// options of single and multiple choice questions, Number and Tolerance the answer of numeric
+// ones and Accepted the accepted answers of text ones.
+type QuizQuestion struct {
+	Id        string   `json:"id"`
+	Position  int      `json:"position"`
+	Kind      string   `json:"kind"`
+	Text      string   `json:"text"`
+	Options   []string `json:"options"`
+	Correct   []int    `json:"correct"`
+	Number    *float64 `json:"number"`
+	Tolerance float64  `json:"tolerance"`
+	Accepted  []string `json:"accepted"`
+	Points    float64  `json:"points"`
+}
+
+// AddQuiz creates a quiz with the assessment its scores are written to, Weight is the
+// assessment's weight.
+type AddQuiz struct {
+	SubjectId        string            `json:"subject_id"`
+	GroupId          string            `json:"group_id"`
+	Title            string            `json:"title"`
+	OpensAt          string            `json:"opens_at"`
+	ClosesAt         string            `json:"closes_at"`
+	TimeLimitMinutes int               `json:"time_limit_minutes"`
+	MaxAttempts      int               `json:"max_attempts"`
+	Weight           float64           `json:"weight"`
+	Questions        []AddQuizQuestion `json:"questions"`
+}
+
+// Validate reports every malformed id and time of the request.
+func (q AddQuiz) Validate(ctx context.Context) error {
+	p := fieldParser{ctx: ctx}
+	p.uuid("subject_id", q.SubjectId)
+	p.uuid("group_id", q.GroupId)
+	p.time("opens_at", q.OpensAt)
+	p.time("closes_at", q.ClosesAt)
+	return p.err()
+}
+
+type AddQuizQuestion struct {
+	Kind      string   `json:"kind"`
+	Text      string   `json:"text"`
+	Options   []string `json:"options"`
+	Correct   []int    `json:"correct"`
+	Number    *float64 `json:"number"`
+	Tolerance float64  `json:"tolerance"`
+	Accepted  []string `json:"accepted"`
+	Points    float64  `json:"points"`
+}
+
+type GetAllQuizzesRequest struct {
+	GroupId   string `json:"group_id"`
+	SubjectId string `json:"subject_id"`
+	StudentId string `json:"student_id"`
+	Page      uint64 `json:"page"`
+	Limit     uint64 `json:"limit"`
+}
+
+type GetAllQuizzesResponse struct {
+	Quizzes []Quiz `json:"quizzes"`
+	Count   int64  `json:"count"`
+}
+
+// AttemptQuestion is a question as a student sees it, without its answer.
+type AttemptQuestion struct {
+	Id      string   `json:"id"`
+	Kind    string   `json:"kind"`
+	Text    string   `json:"text"`
+	Options []string `json:"options"`
+	Points  float64  `json:"points"`
+}
+
+// QuizAttempt is a student's attempt of a quiz. Questions are given in the attempt's own
+// order while it is open, Answers once it is submitted.
+type QuizAttempt struct {
+	Id            string            `json:"id"`
+	QuizId        string            `json:"quiz_id"`
+	StudentId     string            `json:"student_id"`
+	StudentName   string            `json:"student_name"`
+	StartedAt     string            `json:"started_at"`
+	Deadline      string            `json:"deadline"`
+	SubmittedAt   string            `json:"submitted_at"`
+	Expired       bool              `json:"expired"`
+	Score         *float64          `json:"score"`
+	QuestionOrder []string          `json:"-"`
+	Questions     []AttemptQuestion `json:"questions,omitempty"`
+	Answers       []QuizAnswerLog   `json:"answers,omitempty"`
+}
+
+// QuizAnswer is a student's answer of a question, only the field of the question's kind
+// is read.
+type QuizAnswer struct {
+	QuestionId string   `json:"question_id"`
+	Choices    []int    `json:"choices"`
+	Number     *float64 `json:"number"`
+	Text       string   `json:"text"`
+}
+
+// QuizAnswerLog is a graded answer of an attempt.
+type QuizAnswerLog struct {
+	QuestionId string   `json:"question_id"`
+	Question   string   `json:"question"`
+	Choices    []int    `json:"choices"`
+	Number     *float64 `json:"number"`
+	Text       string   `json:"text"`
+	Correct    bool     `json:"correct"`
+	Points     float64  `json:"points"`
+}
+
+type SubmitQuizRequest struct {
+	Answers []QuizAnswer `json:"answers"`
+}
+
+// Validate reports every malformed question id of the answers.
+func (r SubmitQuizRequest) Validate(ctx context.Context) error {
+	p := fieldParser{ctx: ctx}
+	for i, answer := range r.Answers {
+		p.uuid(fmt.Sprintf("answers[%d].question_id", i), answer.QuestionId)
+	}
+	return p.err()
+}
+
+type GetQuizAttemptsResponse struct {
+	Attempts []QuizAttempt `json:"attempts"`
+}
@@ -0,0 +1,61 @@
+package models
+
+// ReportRequest is the period of a progress report, a term or the dates FromDate to ToDate,
+// Scale is the grading scale its grades are rendered on.
+type ReportRequest struct {
+	TermId   string `json:"term_id"`
+	FromDate string `json:"from_date"`
+	ToDate   string `json:"to_date"`
+	Scale    string `json:"scale"`
+}
+
+// TeacherWorkloadRequest filters the scheduled lessons of the workload report, the period
+// is a term or FromDate to ToDate and Interval is week or month. From and To bound the
+// period's days.
+type TeacherWorkloadRequest struct {
+	TermId    string `json:"term_id"`
+	FromDate  string `json:"from_date"`
+	ToDate    string `json:"to_date"`
+	SubjectId string `json:"subject_id"`
+	TeacherId string `json:"teacher_id"`
+	Interval  string `json:"interval"`
+	From      string `json:"-"`
+	To        string `json:"-"`
+	Page      uint64 `json:"page"`
+	Limit     uint64 `json:"limit"`
+}
+
+type TeacherWorkloadResponse struct {
+	FromDate string            `json:"from_date"`
+	ToDate   string            `json:"to_date"`
+	Interval string            `json:"interval"`
+	Teachers []TeacherWorkload `json:"teachers"`
+	Count    int64             `json:"count"`
+}
+
+// TeacherWorkload is the teacher's scheduled lessons of the period, GapMinutes are the
+// minutes between consecutive lessons of a day.
+type TeacherWorkload struct {
+	TeacherId   string          `json:"teacher_id"`
+	TeacherName string          `json:"teacher_name"`
+	Lessons     int64           `json:"lessons"`
+	Minutes     int64           `json:"minutes"`
+	Hours       float64         `json:"hours"`
+	Days        int64           `json:"days"`
+	Students    int64           `json:"students"`
+	GapMinutes  int64           `json:"gap_minutes"`
+	Periods     []WorkloadShare `json:"periods"`
+	Subjects    []WorkloadShare `json:"subjects"`
+	Rooms       []WorkloadShare `json:"rooms"`
+	Groups      []WorkloadShare `json:"groups"`
+}
+
+// WorkloadShare is the part of a teacher's workload in a week or month, subject, room or
+// group. Id is the first day of a period, empty for rooms and for lessons without a group.
+type WorkloadShare struct {
+	Id      string  `json:"id"`
+	Name    string  `json:"name"`
+	Lessons int64   `json:"lessons"`
+	Minutes int64   `json:"minutes"`
+	Hours   float64 `json:"hours"`
+}
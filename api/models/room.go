@@ -0,0 +1,110 @@
+package models
+
+type Room struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Capacity  int    `json:"capacity"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type AddRoom struct {
+	Name     string `json:"name"`
+	Capacity int    `json:"capacity"`
+}
+
+type GetAllRoomsRequest struct {
+	Search string `json:"search"`
+	Page   uint64 `json:"page"`
+	Limit  uint64 `json:"limit"`
+}
+
+type GetAllRoomsResponse struct {
+	Rooms []Room `json:"rooms"`
+	Count int64  `json:"count"`
+}
+
+// RoomHours are a room's weekly opening hours, a room without any is open from
+// config.RoomOpensAt to config.RoomClosesAt every day.
+type RoomHours struct {
+	Windows []OpeningWindow `json:"windows"`
+}
+
+// OpeningWindow is the time a room is open on a weekday, 0 is Sunday.
+type OpeningWindow struct {
+	Weekday int    `json:"weekday"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+// RoomUtilizationRequest asks for the occupancy of the rooms in a term or from FromDate to
+// ToDate per day or week (Interval). Rooms below UnderUsed percent are under-used, nil
+// is the default and 0 marks no room. From and To bound the period's days.
+type RoomUtilizationRequest struct {
+	TermId    string `json:"term_id"`
+	FromDate  string `json:"from_date"`
+	ToDate    string `json:"to_date"`
+	RoomId    string `json:"room_id"`
+	Interval  string `json:"interval"`
+	UnderUsed *int   `json:"under_used"`
+	From      string `json:"-"`
+	To        string `json:"-"`
+	OpensAt   string `json:"-"`
+	ClosesAt  string `json:"-"`
+}
+
+type RoomUtilizationResponse struct {
+	FromDate  string            `json:"from_date"`
+	ToDate    string            `json:"to_date"`
+	Interval  string            `json:"interval"`
+	Rooms     []RoomUtilization `json:"rooms"`
+	UnderUsed []string          `json:"under_used"`
+	Heatmap   []HeatmapCell     `json:"heatmap"`
+}
+
+// RoomUtilization is how much of its opening hours a room is booked for lessons, Occupancy
+// is a percentage.
+type RoomUtilization struct {
+	RoomId      string          `json:"room_id"`
+	RoomName    string          `json:"room_name"`
+	Capacity    int             `json:"capacity"`
+	Lessons     int64           `json:"lessons"`
+	OpenMinutes int64           `json:"open_minutes"`
+	UsedMinutes int64           `json:"used_minutes"`
+	Occupancy   float64         `json:"occupancy"`
+	UnderUsed   bool            `json:"under_used"`
+	Periods     []RoomOccupancy `json:"periods"`
+}
+
+// RoomOccupancy is a room's occupancy of the day or week starting on Period.
+type RoomOccupancy struct {
+	Period      string  `json:"period"`
+	Lessons     int64   `json:"lessons"`
+	OpenMinutes int64   `json:"open_minutes"`
+	UsedMinutes int64   `json:"used_minutes"`
+	Occupancy   float64 `json:"occupancy"`
+}
+
+// RoomUtilizationRow is a row of an exported utilization report, a room's periods are
+// followed by its total, the only row with UnderUsed.
+type RoomUtilizationRow struct {
+	Room        string  `json:"room"`
+	Capacity    int     `json:"capacity"`
+	Period      string  `json:"period"`
+	Lessons     int64   `json:"lessons"`
+	OpenMinutes int64   `json:"open_minutes"`
+	UsedMinutes int64   `json:"used_minutes"`
+	Occupancy   float64 `json:"occupancy"`
+	UnderUsed   *bool   `json:"under_used"`
+}
+
+// HeatmapCell is the part of the rooms' opening hours booked in an hour of a weekday, 0
+// is Sunday.
+type HeatmapCell struct {
+	Weekday     int     `json:"weekday"`
+	Hour        int     `json:"hour"`
+	Lessons     int64   `json:"lessons"`
+	OpenMinutes int64   `json:"open_minutes"`
+	UsedMinutes int64   `json:"used_minutes"`
+	Occupancy   float64 `json:"occupancy"`
+}
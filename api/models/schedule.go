@@ -0,0 +1,85 @@
+package models
+
+type SchedulePeriod struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// ScheduleRequirement asks for HoursPerWeek lessons (one period each) of a subject for a group,
+// TeacherId is optional and pins the teacher.
+type ScheduleRequirement struct {
+	GroupId      string `json:"group_id"`
+	SubjectId    string `json:"subject_id"`
+	TeacherId    string `json:"teacher_id,omitempty"`
+	HoursPerWeek int    `json:"hours_per_week"`
+}
+
+// AvailabilityWindow is a weekly time range, Weekday is 0 for Sunday through 6 for Saturday.
+type AvailabilityWindow struct {
+	TeacherId string `json:"teacher_id"`
+	Weekday   int    `json:"weekday"`
+	Start     string `json:"start"`
+	End       string `json:"end"`
+}
+
+type GenerateScheduleRequest struct {
+	TermId       string                `json:"term_id,omitempty"`
+	WeekStart    string                `json:"week_start"`
+	Weeks        int                   `json:"weeks"`
+	Weekdays     []int                 `json:"weekdays"`
+	Periods      []SchedulePeriod      `json:"periods"`
+	Requirements []ScheduleRequirement `json:"requirements"`
+	Rooms        []string              `json:"rooms,omitempty"`
+	Availability []AvailabilityWindow  `json:"availability,omitempty"`
+	Seed         int64                 `json:"seed,omitempty"`
+}
+
+type ScheduledLesson struct {
+	GroupId   string `json:"group_id"`
+	SubjectId string `json:"subject_id"`
+	TeacherId string `json:"teacher_id"`
+	RoomName  string `json:"room_name"`
+	Weekday   int    `json:"weekday"`
+	Period    int    `json:"period"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+type ScheduleCost struct {
+	TeacherGaps    int `json:"teacher_gaps"`
+	GroupGaps      int `json:"group_gaps"`
+	Imbalance      int `json:"imbalance"`
+	SubjectRepeats int `json:"subject_repeats"`
+	Total          int `json:"total"`
+}
+
+type ScheduleDraft struct {
+	Id          string                  `json:"id"`
+	Status      string                  `json:"status"`
+	WeekStart   string                  `json:"week_start"`
+	Weeks       int                     `json:"weeks"`
+	Request     GenerateScheduleRequest `json:"request"`
+	Lessons     []ScheduledLesson       `json:"lessons"`
+	Cost        ScheduleCost            `json:"cost"`
+	CreatedAt   string                  `json:"created_at"`
+	CommittedAt string                  `json:"committed_at"`
+}
+
+type TeacherSubjects struct {
+	TeacherId  string   `json:"teacher_id"`
+	SubjectIds []string `json:"subject_ids"`
+}
+
+// BusyInterval is an existing time_table lesson which a new schedule must not clash with.
+type BusyInterval struct {
+	TeacherId string `json:"teacher_id"`
+	GroupId   string `json:"group_id"`
+	RoomName  string `json:"room_name"`
+	FromDate  string `json:"from_date"`
+	ToDate    string `json:"to_date"`
+}
+
+type CommitScheduleResponse struct {
+	DraftId string `json:"draft_id"`
+	Lessons int64  `json:"time_table_rows"`
+}
@@ -1,5 +1,11 @@
 package models
 
+import (
+	"backend_course/lms/domain"
+	"backend_course/lms/pkg"
+	"context"
+)
+
 type Student struct {
 	Id         string `json:"id"`
 	FirstName  string `json:"first_name"`
@@ -11,15 +17,18 @@ type Student struct {
 	CreatedAt  string `json:"created_at"`
 	UpdatedAt  string `json:"updated_at"`
 	IsActive   bool   `json:"is_active"`
+	Password   string `json:"password,omitempty"`
+	Timezone   string `json:"timezone"`
 }
 
 type CheckLessonStudent struct {
-	StudentName string  `json:"student_name"`
-	StudentAge  uint16  `json:"student_age"`
-	SubjectName string  `json:"subject_name"`
-	TeacherName string  `json:"teacher_name"`
-	RoomName    string  `json:"room_name"`
-	TimeLeft    float64 `json:"time_left_in_minutes"`
+	StudentName string         `json:"student_name"`
+	StudentAge  uint16         `json:"student_age"`
+	Status      string         `json:"status"`
+	TimeElapsed float64        `json:"time_elapsed_in_minutes"`
+	TimeLeft    float64        `json:"time_left_in_minutes"`
+	StartsIn    float64        `json:"starts_in_minutes"`
+	Lesson      *LessonDetails `json:"lesson,omitempty"`
 }
 
 type AddStudent struct {
@@ -31,6 +40,7 @@ type AddStudent struct {
 	Email      string `json:"mail"`
 	IsActive   bool   `json:"is_active"`
 	Password   string `json:"password,omitempty"`
+	Timezone   string `json:"timezone"`
 }
 
 type GetStudent struct {
@@ -44,6 +54,7 @@ type GetStudent struct {
 	CreatedAt  string `json:"created_at"`
 	UpdatedAt  string `json:"updated_at"`
 	IsActive   bool   `json:"is_active"`
+	Timezone   string `json:"timezone,omitempty"`
 }
 
 type GetAllStudentsRequest struct {
@@ -57,30 +68,102 @@ type GetAllStudentsResponse struct {
 	Count    int64        `json:"count"`
 }
 
+// GetAllStudentsAttandenceReportRequest filters the lessons of the attendance report, the
+// period is a term or StartDate to EndDate. GroupBy is teacher, subject or week.
 type GetAllStudentsAttandenceReportRequest struct {
 	StudentId string `json:"student_id"`
+	TeacherId string `json:"teacher_id"`
+	SubjectId string `json:"subject_id"`
+	TermId    string `json:"term_id"`
 	StartDate string `json:"start_date"`
 	EndDate   string `json:"end_date"`
-	TeacherId string `json:"teacher_id"`
+	GroupBy   string `json:"group_by"`
 	Page      uint64 `json:"page"`
 	Limit     uint64 `json:"limit"`
 }
 
 type GetAllStudentsAttandenceReportResponse struct {
 	Students []StudentAttandenceReport `json:"students"`
+	Totals   AttendanceStats           `json:"totals"`
 	Count    int64                     `json:"count"`
 }
 
+// AttendanceStats sums the lessons of a student. TotalHours are the hours of the lessons
+// and StudyTime the hours attended, a late student studied from check-in until the end.
+// AvgLessonLength is in minutes, AvgStudyTime in hours per lesson and AttendanceRate is
+// the percentage of the marked lessons the student was present or late at.
+type AttendanceStats struct {
+	Lessons         int64   `json:"lessons"`
+	Present         int64   `json:"present"`
+	Late            int64   `json:"late"`
+	Absent          int64   `json:"absent"`
+	Excused         int64   `json:"excused"`
+	Unmarked        int64   `json:"unmarked"`
+	TotalHours      float64 `json:"total_hours"`
+	StudyTime       float64 `json:"study_time"`
+	AvgLessonLength float64 `json:"avg_lesson_length"`
+	AvgStudyTime    float64 `json:"avg_study_time"`
+	AttendanceRate  float64 `json:"attendance_rate"`
+}
+
 type StudentAttandenceReport struct {
-	StudentId        string  `json:"student_id"`
-	StudentName      string  `json:"student_name"`
-	StudentCreatedAt string  `json:"student_created_at"`
-	TeacherName      string  `json:"teacher_name"`
-	StudyTime        float64 `json:"study_time"`
-	AvgStudyTime     float64 `json:"avg_study_time"`
+	StudentId        string `json:"student_id"`
+	StudentName      string `json:"student_name"`
+	StudentCreatedAt string `json:"student_created_at"`
+	AttendanceStats
+	Groups []AttendanceGroup `json:"groups,omitempty"`
+}
+
+// AttendanceGroup is the part of a student's attendance of a teacher, subject or week, Id
+// is the first day of a week.
+type AttendanceGroup struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	AttendanceStats
 }
 
 type UploadStudentImage struct {
 	Id   string `json:"id"`
 	Path string `json:"image"`
-}
\ No newline at end of file
+}
+
+// Domain reads the request into a student.
+func (s AddStudent) Domain(ctx context.Context) (domain.Student, error) {
+	p := fieldParser{ctx: ctx}
+	student := domain.Student{
+		FirstName:  s.FirstName,
+		LastName:   s.LastName,
+		Age:        s.Age,
+		ExternalId: s.ExternalId,
+		Phone:      s.Phone,
+		Email:      s.Email,
+		IsActive:   s.IsActive,
+		Password:   s.Password,
+		Timezone:   p.timezone("timezone", s.Timezone),
+	}
+	return student, p.err()
+}
+
+func NewGetStudent(ctx context.Context, s domain.Student) GetStudent {
+	return GetStudent{
+		Id:         s.Id.String(),
+		FirstName:  s.FirstName,
+		LastName:   s.LastName,
+		Age:        s.Age,
+		ExternalId: s.ExternalId,
+		Phone:      s.Phone,
+		Email:      s.Email,
+		CreatedAt:  pkg.FormatTime(ctx, s.CreatedAt),
+		UpdatedAt:  formatOptionalTime(ctx, s.UpdatedAt),
+		IsActive:   s.IsActive,
+		Timezone:   s.Timezone.OrZero(),
+	}
+}
+
+func NewGetAllStudentsResponse(ctx context.Context, students []domain.Student, count int64) GetAllStudentsResponse {
+	resp := GetAllStudentsResponse{Count: count}
+	for _, s := range students {
+		resp.Students = append(resp.Students, NewGetStudent(ctx, s))
+	}
+	return resp
+}
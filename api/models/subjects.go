@@ -1,31 +1,132 @@
 package models
 
+import (
+	"context"
+	"fmt"
+)
+
 type Subjects struct {
-	Id        string `json:"id"`
-	Name      string `json:"name"`
-	Type      string `json:"type"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	Id            string         `json:"id"`
+	Code          string         `json:"code"`
+	Name          string         `json:"name"`
+	Type          string         `json:"type"`
+	Description   string         `json:"description"`
+	Level         string         `json:"level"`
+	CreditHours   int            `json:"credit_hours"`
+	Prerequisites []SubjectRef   `json:"prerequisites,omitempty"`
+	Syllabus      []SyllabusUnit `json:"syllabus,omitempty"`
+	CreatedAt     string         `json:"created_at"`
+	UpdatedAt     string         `json:"updated_at"`
 }
 
 type AddSubject struct {
-	Name      string `json:"name"`
-	Type      string `json:"type"`
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Level       string `json:"level"`
+	CreditHours int    `json:"credit_hours"`
 }
 
 type UpdateSubjects struct {
-	Name      string `json:"name"`
-	Type      string `json:"type"`
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Level       string `json:"level"`
+	CreditHours int    `json:"credit_hours"`
+}
+
+// SubjectRef names a subject, such as a prerequisite of another one.
+type SubjectRef struct {
+	Id   string `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// SyllabusUnit is a unit of a subject's syllabus, units and their lessons are taught in
+// the order they are listed.
+type SyllabusUnit struct {
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	Lessons     []SyllabusLesson `json:"lessons"`
+}
+
+type SyllabusLesson struct {
+	Title string  `json:"title"`
+	Hours float64 `json:"hours"`
+}
+
+type SetSyllabusRequest struct {
+	Units []SyllabusUnit `json:"units"`
 }
 
+type SetPrerequisitesRequest struct {
+	SubjectIds []string `json:"subject_ids"`
+}
+
+// Validate reports every malformed subject id of the request.
+func (r SetPrerequisitesRequest) Validate(ctx context.Context) error {
+	p := fieldParser{ctx: ctx}
+	for i, id := range r.SubjectIds {
+		p.uuid(fmt.Sprintf("subject_ids[%d]", i), id)
+	}
+	return p.err()
+}
 
+// GetAllSubjectsRequest filters the catalog, search matches the code, name and description
+// and credit hours are bounded by MinCredits and MaxCredits when they are not nil.
 type GetAllSubjectsRequest struct {
-	Search string `json:"search"`
-	Page   uint64 `json:"page"`
-	Limit  uint64 `json:"limit"`
+	Search     string `json:"search"`
+	Type       string `json:"type"`
+	Level      string `json:"level"`
+	MinCredits *int   `json:"min_credits"`
+	MaxCredits *int   `json:"max_credits"`
+	Page       uint64 `json:"page"`
+	Limit      uint64 `json:"limit"`
 }
 
 type GetAllSubjectsResponse struct {
 	Subjects []Subjects `json:"subjects"`
 	Count    int64        `json:"count"`
 }
+
+type Enrollment struct {
+	Id          string `json:"id"`
+	StudentId   string `json:"student_id"`
+	StudentName string `json:"student_name"`
+	SubjectId   string `json:"subject_id"`
+	SubjectName string `json:"subject_name"`
+	Status      string `json:"status"`
+	EnrolledAt  string `json:"enrolled_at"`
+	CompletedAt string `json:"completed_at"`
+}
+
+// EnrollRequest enrolls a student in a subject, students can only enroll themselves.
+type EnrollRequest struct {
+	StudentId string `json:"student_id"`
+}
+
+// Validate reports a malformed student id.
+func (r EnrollRequest) Validate(ctx context.Context) error {
+	p := fieldParser{ctx: ctx}
+	p.uuid("student_id", r.StudentId)
+	return p.err()
+}
+
+type UpdateEnrollment struct {
+	Status string `json:"status"`
+}
+
+type GetAllEnrollmentsRequest struct {
+	StudentId string `json:"student_id"`
+	SubjectId string `json:"subject_id"`
+	Status    string `json:"status"`
+	Page      uint64 `json:"page"`
+	Limit     uint64 `json:"limit"`
+}
+
+type GetAllEnrollmentsResponse struct {
+	Enrollments []Enrollment `json:"enrollments"`
+	Count       int64        `json:"count"`
+}
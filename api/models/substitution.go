@@ -0,0 +1,25 @@
+package models
+
+// Substitute is a teacher qualified to take over a lesson.
+type Substitute struct {
+	TeacherId        string `json:"teacher_id"`
+	TeacherName      string `json:"teacher_name"`
+	WeeklyLessons    int    `json:"weekly_lessons"`
+	MaxWeeklyLessons int    `json:"max_weekly_lessons"`
+}
+
+type GetSubstitutesResponse struct {
+	Substitutes []Substitute `json:"substitutes"`
+	Count       int64        `json:"count"`
+}
+
+type AssignSubstitute struct {
+	TeacherId string `json:"teacher_id"`
+}
+
+type AssignSubstituteResponse struct {
+	TimeTableId       string `json:"time_table_id"`
+	TeacherId         string `json:"teacher_id"`
+	OriginalTeacherId string `json:"original_teacher_id"`
+	Rows              int64  `json:"rows"`
+}
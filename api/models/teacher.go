@@ -1,34 +1,47 @@
 package models
 
+import (
+	"backend_course/lms/domain"
+	"backend_course/lms/pkg"
+	"context"
+)
+
 type Teacher struct {
-	Id           string `json:"id"`
-	FirstName    string `json:"first_name"`
-	LastName     string `json:"last_name"`
-	SubjectId    string `json:"subject_id"`
-	StartWorking string `json:"start_working"`
-	Phone        string `json:"phone"`
-	Email        string `json:"mail"`
-	CreatedAt    string `json:"created_at"`
-	UpdatedAt    string `json:"updated_at"`
-	Password     string `json:"password,omitempty"`
+	Id               string   `json:"id"`
+	FirstName        string   `json:"first_name"`
+	LastName         string   `json:"last_name"`
+	SubjectIds       []string `json:"subject_ids"`
+	StartWorking     string   `json:"start_working"`
+	Phone            string   `json:"phone"`
+	Email            string   `json:"mail"`
+	MaxWeeklyLessons int      `json:"max_weekly_lessons"`
+	CreatedAt        string   `json:"created_at"`
+	UpdatedAt        string   `json:"updated_at"`
+	Password         string   `json:"password,omitempty"`
+	Timezone         string   `json:"timezone"`
 }
 
+// AddTeacher leaves MaxWeeklyLessons out for the default cap on create and the current
+// one on update, a cap of 0 blocks substitutions.
 type AddTeacher struct {
-	FirstName    string `json:"first_name"`
-	LastName     string `json:"last_name"`
-	SubjectId    string `json:"subject_id"`
-	StartWorking string `json:"start_working"`
-	Phone        string `json:"phone"`
-	Email        string `json:"mail"`
-	Password     string `json:"password,omitempty"`
+	FirstName        string `json:"first_name"`
+	LastName         string `json:"last_name"`
+	StartWorking     string `json:"start_working"`
+	Phone            string `json:"phone"`
+	Email            string `json:"mail"`
+	Password         string `json:"password,omitempty"`
+	MaxWeeklyLessons *int   `json:"max_weekly_lessons"`
+	Timezone         string `json:"timezone"`
 }
 
 type CheckLessonTeacher struct {
-	TeacherName string       `json:"teacher_name"`
-	SubjectName string       `json:"subject_name"`
-	Students    []MyStudents `json:"students"`
-	RoomName    string       `json:"room_name"`
-	TimeLeft    float64       `json:"time_left_in_minutes"`
+	TeacherName string         `json:"teacher_name"`
+	Status      string         `json:"status"`
+	TimeElapsed float64        `json:"time_elapsed_in_minutes"`
+	TimeLeft    float64        `json:"time_left_in_minutes"`
+	StartsIn    float64        `json:"starts_in_minutes"`
+	Lesson      *LessonDetails `json:"lesson,omitempty"`
+	Students    []MyStudents   `json:"students"`
 }
 
 type MyStudents struct {
@@ -49,3 +62,82 @@ type GetAllTeachersResponse struct {
 	Teachers []Teacher `json:"teachers"`
 	Count    int64     `json:"count"`
 }
+
+// Domain reads the request into a teacher.
+func (t AddTeacher) Domain(ctx context.Context) (domain.Teacher, error) {
+	p := fieldParser{ctx: ctx}
+	teacher := domain.Teacher{
+		FirstName:    t.FirstName,
+		LastName:     t.LastName,
+		StartWorking: p.optionalTime("start_working", t.StartWorking),
+		Phone:        t.Phone,
+		Email:        t.Email,
+		Password:     t.Password,
+		Timezone:     p.timezone("timezone", t.Timezone),
+	}
+	if t.MaxWeeklyLessons != nil {
+		if *t.MaxWeeklyLessons < 0 {
+			p.fail("max_weekly_lessons", "must not be negative")
+		}
+		teacher.MaxWeeklyLessons = domain.Some(*t.MaxWeeklyLessons)
+	}
+	return teacher, p.err()
+}
+
+func NewTeacher(ctx context.Context, t domain.Teacher) Teacher {
+	subjectIds := make([]string, 0, len(t.SubjectIds))
+	for _, id := range t.SubjectIds {
+		subjectIds = append(subjectIds, id.String())
+	}
+	return Teacher{
+		Id:               t.Id.String(),
+		FirstName:        t.FirstName,
+		LastName:         t.LastName,
+		SubjectIds:       subjectIds,
+		StartWorking:     formatOptionalTime(ctx, t.StartWorking),
+		Phone:            t.Phone,
+		Email:            t.Email,
+		MaxWeeklyLessons: t.MaxWeeklyLessons.OrZero(),
+		CreatedAt:        pkg.FormatTime(ctx, t.CreatedAt),
+		UpdatedAt:        formatOptionalTime(ctx, t.UpdatedAt),
+		Timezone:         t.Timezone.OrZero(),
+	}
+}
+
+func NewGetAllTeachersResponse(ctx context.Context, teachers []domain.Teacher, count int64) GetAllTeachersResponse {
+	resp := GetAllTeachersResponse{Count: count}
+	for _, t := range teachers {
+		resp.Teachers = append(resp.Teachers, NewTeacher(ctx, t))
+	}
+	return resp
+}
+
+// TeacherSubject is a subject the teacher is qualified for, Since is the date the teacher
+// started teaching it.
+type TeacherSubject struct {
+	SubjectId   string `json:"subject_id"`
+	SubjectName string `json:"subject_name"`
+	Proficiency string `json:"proficiency"`
+	Since       string `json:"since"`
+}
+
+// SetTeacherSubject qualifies a teacher for a subject, proficiency is basic, proficient or
+// expert and since defaults to today.
+type SetTeacherSubject struct {
+	Proficiency string `json:"proficiency"`
+	Since       string `json:"since"`
+}
+
+// Validate reports a malformed since date, an empty one is today.
+func (r SetTeacherSubject) Validate(ctx context.Context) error {
+	p := fieldParser{ctx: ctx}
+	if r.Since != "" {
+		p.date("since", r.Since)
+	}
+	return p.err()
+}
+
+type GetTeacherSubjectsResponse struct {
+	Subjects []TeacherSubject `json:"subjects"`
+	Count    int64            `json:"count"`
+}
@@ -1,13 +1,20 @@
 package models
 
+import (
+	"backend_course/lms/domain"
+	"backend_course/lms/pkg"
+	"context"
+)
+
 type Time struct {
-	Id        string `json:"id"`
-	TeacherId string `json:"teacher_id"`
-	StudentId string `json:"student_id"`
-	SubjectId string `json:"subject_id"`
-	FromDate  string `json:"from_date"`
-	ToDate    string `json:"to_date"`
-	RoomName  string `json:"room_name"`
+	Id                string `json:"id"`
+	TeacherId         string `json:"teacher_id"`
+	StudentId         string `json:"student_id"`
+	SubjectId         string `json:"subject_id"`
+	FromDate          string `json:"from_date"`
+	ToDate            string `json:"to_date"`
+	RoomName          string `json:"room_name"`
+	OriginalTeacherId string `json:"original_teacher_id,omitempty"`
 }
 
 type AddTime struct {
@@ -19,6 +26,19 @@ type AddTime struct {
 	RoomName  string `json:"room_name"`
 }
 
+type LessonDetails struct {
+	TimeTableId         string `json:"time_table_id"`
+	SubjectId           string `json:"subject_id"`
+	SubjectName         string `json:"subject_name"`
+	TeacherId           string `json:"teacher_id"`
+	TeacherName         string `json:"teacher_name"`
+	RoomName            string `json:"room_name"`
+	FromDate            string `json:"from_date"`
+	ToDate              string `json:"to_date"`
+	OriginalTeacherId   string `json:"original_teacher_id,omitempty"`
+	OriginalTeacherName string `json:"original_teacher_name,omitempty"`
+}
+
 type GetAllTimeRequest struct {
 	Search string `json:"search"`
 	Page   uint64 `json:"page"`
@@ -29,3 +49,41 @@ type GetAllTimeResponse struct {
 	Time  []Time `json:"time_tables"`
 	Count int64  `json:"count"`
 }
+
+// Domain reads the request into a time table row.
+func (t AddTime) Domain(ctx context.Context) (domain.TimeTable, error) {
+	p := fieldParser{ctx: ctx}
+	time := domain.TimeTable{
+		TeacherId: p.uuid("teacher_id", t.TeacherId),
+		StudentId: p.uuid("student_id", t.StudentId),
+		SubjectId: p.uuid("subject_id", t.SubjectId),
+		FromDate:  p.time("from_date", t.FromDate),
+		ToDate:    p.time("to_date", t.ToDate),
+		RoomName:  t.RoomName,
+	}
+	if len(p.errors) == 0 && !time.ToDate.After(time.FromDate) {
+		p.fail("to_date", "must be after from_date")
+	}
+	return time, p.err()
+}
+
+func NewTime(ctx context.Context, t domain.TimeTable) Time {
+	return Time{
+		Id:                t.Id.String(),
+		TeacherId:         t.TeacherId.String(),
+		StudentId:         t.StudentId.String(),
+		SubjectId:         t.SubjectId.String(),
+		FromDate:          pkg.FormatTime(ctx, t.FromDate),
+		ToDate:            pkg.FormatTime(ctx, t.ToDate),
+		RoomName:          t.RoomName,
+		OriginalTeacherId: formatOptionalUUID(t.OriginalTeacherId),
+	}
+}
+
+func NewGetAllTimeResponse(ctx context.Context, times []domain.TimeTable, count int64) GetAllTimeResponse {
+	resp := GetAllTimeResponse{Count: count}
+	for _, t := range times {
+		resp.Time = append(resp.Time, NewTime(ctx, t))
+	}
+	return resp
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"backend_course/lms/domain"
+	"backend_course/lms/pkg"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// FieldErrors lists every invalid field of a request, handlers answer it with 400.
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, f := range e {
+		messages = append(messages, f.Field+": "+f.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ParseId reads the id of a path or query parameter.
+func ParseId(field, s string) (uuid.UUID, error) {
+	p := fieldParser{}
+	id := p.uuid(field, s)
+	return id, p.err()
+}
+
+// fieldParser turns the string fields of a request into domain values, collecting the
+// errors of every field instead of stopping at the first one.
+type fieldParser struct {
+	ctx    context.Context
+	errors FieldErrors
+}
+
+func (p *fieldParser) fail(field, message string) {
+	p.errors = append(p.errors, FieldError{Field: field, Message: message})
+}
+
+func (p *fieldParser) err() error {
+	if len(p.errors) == 0 {
+		return nil
+	}
+	return p.errors
+}
+
+func (p *fieldParser) uuid(field, s string) uuid.UUID {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		p.fail(field, "must be a UUID")
+	}
+	return id
+}
+
+// optionalUUID reads an empty id as an absent one.
+func (p *fieldParser) optionalUUID(field, s string) domain.Optional[uuid.UUID] {
+	if s == "" {
+		return domain.Optional[uuid.UUID]{}
+	}
+	return domain.Some(p.uuid(field, s))
+}
+
+// date reads a day like 2006-01-02.
+func (p *fieldParser) date(field, s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		p.fail(field, "must be a date like 2006-01-02")
+	}
+	return t
+}
+
+func (p *fieldParser) time(field, s string) time.Time {
+	t, err := pkg.ParseTime(p.ctx, s)
+	if err != nil {
+		p.fail(field, pkg.ErrInvalidTime.Error())
+	}
+	return t
+}
+
+func (p *fieldParser) optionalTime(field, s string) domain.Optional[time.Time] {
+	if s == "" {
+		return domain.Optional[time.Time]{}
+	}
+	return domain.Some(p.time(field, s))
+}
+
+func (p *fieldParser) timezone(field, s string) domain.Optional[string] {
+	if s == "" {
+		return domain.Optional[string]{}
+	}
+	if err := pkg.CheckTimezone(s); err != nil {
+		p.fail(field, pkg.ErrInvalidTimezone.Error())
+	}
+	return domain.Some(s)
+}
+
+// formatOptionalTime renders an absent time as an empty string.
+func formatOptionalTime(ctx context.Context, t domain.Optional[time.Time]) string {
+	if v, ok := t.Get(); ok {
+		return pkg.FormatTime(ctx, v)
+	}
+	return ""
+}
+
+// formatOptionalUUID renders an absent id as an empty string.
+func formatOptionalUUID(id domain.Optional[uuid.UUID]) string {
+	if v, ok := id.Get(); ok {
+		return v.String()
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddTimeDomain(t *testing.T) {
+	req := AddTime{
+		TeacherId: uuid.NewString(),
+		StudentId: "not-an-id",
+		SubjectId: uuid.NewString(),
+		FromDate:  "2024-09-02 09:00",
+		ToDate:    "tomorrow",
+	}
+
+	_, err := req.Domain(context.Background())
+	var fields FieldErrors
+	if assert.True(t, errors.As(err, &fields)) {
+		assert.Equal(t, []string{"student_id", "to_date"}, []string{fields[0].Field, fields[1].Field})
+	}
+
+	req.StudentId = uuid.NewString()
+	req.ToDate = "2024-09-02 08:00"
+	_, err = req.Domain(context.Background())
+	if assert.True(t, errors.As(err, &fields)) {
+		assert.Equal(t, "to_date", fields[0].Field)
+	}
+
+	req.ToDate = "2024-09-02 10:00"
+	lesson, err := req.Domain(context.Background())
+	if assert.NoError(t, err) {
+		assert.Equal(t, req.TeacherId, lesson.TeacherId.String())
+		assert.True(t, lesson.ToDate.After(lesson.FromDate))
+	}
+}
+
+func TestRequestValidate(t *testing.T) {
+	fieldsOf := func(err error) []string {
+		var fields FieldErrors
+		if !errors.As(err, &fields) {
+			return nil
+		}
+		names := make([]string, len(fields))
+		for i, field := range fields {
+			names[i] = field.Field
+		}
+		return names
+	}
+	ctx := context.Background()
+
+	scores := SetScoresRequest{Scores: []Score{{StudentId: uuid.NewString()}, {StudentId: "1"}}}
+	assert.Equal(t, []string{"scores[1].student_id"}, fieldsOf(scores.Validate(ctx)))
+
+	// empty optional ids and dates are left to their defaults
+	assert.NoError(t, AddPricePlan{}.Validate(ctx))
+	assert.NoError(t, SetTeacherSubject{}.Validate(ctx))
+	assert.Equal(t, []string{"group_id"}, fieldsOf(AddPricePlan{GroupId: "group"}.Validate(ctx)))
+
+	run := CreatePayrollRun{FromDate: "2024-09-01", ToDate: "2024-09-31"}
+	assert.Equal(t, []string{"to_date"}, fieldsOf(run.Validate(ctx)))
+	run.ToDate = "2024-09-30"
+	assert.NoError(t, run.Validate(ctx))
+}
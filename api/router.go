@@ -2,10 +2,14 @@ package api
 
 import (
 	"backend_course/lms/api/handler"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg"
+	"backend_course/lms/pkg/jwt"
 	"backend_course/lms/pkg/logger"
 	"backend_course/lms/service"
 	"backend_course/lms/storage"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -32,15 +36,18 @@ func New(store storage.IStorage, service service.IServiceManager, log logger.ILo
 	r.PUT("/student/:id", h.UpdateStudent)
 	r.PATCH("/student/:id", h.UpdateStudentStatus)
 	r.GET("/students", h.GetAllStudents)
+	r.POST("/students/import", h.ImportStudents)
 	r.DELETE("/student/:id", h.DeleteStudent)
 	r.GET("/student/:id", h.GetStudent)
 	r.GET("/check-student/:id", h.CheckStudentLesson)
 	r.GET("/student-attendence", h.GetAllStudentsAttandenceReport)
+	r.POST("/student/login", h.StudentLogin)
 	r.PATCH("/student-photo/:id", h.UploadStudentPhoto)
 
 	r.POST("/teacher", h.CreateTeacher)
 	r.PUT("/teacher/:id", h.UpdateTeacher)
 	r.GET("/teachers", h.GetAllTeachers)
+	r.POST("/teachers/import", h.ImportTeachers)
 	r.DELETE("/teacher/:id", h.DeleteTeacher)
 	r.GET("/teacher/:id", h.GetTeacher)
 	r.POST("/teacher/login", h.Login)
@@ -54,20 +61,177 @@ func New(store storage.IStorage, service service.IServiceManager, log logger.ILo
 	r.DELETE("/subject/:id", h.DeleteSubject)
 	r.GET("/subject/:id", h.GetSubject)
 	r.GET("/subjects", h.GetAllSubjects)
+	r.PUT("/subject/:id/syllabus", h.SetSyllabus)
+	r.PUT("/subject/:id/prerequisites", h.SetPrerequisites)
+
+	r.POST("/subject/:id/enrollment", h.Enroll)
+	r.GET("/enrollments", h.GetAllEnrollments)
+	r.PATCH("/enrollment/:id", h.UpdateEnrollment)
 
 	r.POST("/time", h.CreateTime)
 	r.PUT("/time/:id", h.UpdateTime)
 	r.DELETE("/time/:id", h.DeleteTime)
 	r.GET("/time/:id", h.GetTime)
 	r.GET("/time-tables", h.GetAllTimeTables)
+	r.GET("/time/:id/substitutes", h.GetSubstitutes)
+	r.POST("/time/:id/substitute", h.AssignSubstitute)
+
+	r.POST("/group", h.CreateGroup)
+	r.PUT("/group/:id", h.UpdateGroup)
+	r.DELETE("/group/:id", h.DeleteGroup)
+	r.GET("/group/:id", h.GetGroup)
+	r.GET("/groups", h.GetAllGroups)
+	r.PUT("/group/:id/students", h.SetGroupStudents)
+
+	r.POST("/room", h.CreateRoom)
+	r.PUT("/room/:id", h.UpdateRoom)
+	r.DELETE("/room/:id", h.DeleteRoom)
+	r.GET("/room/:id", h.GetRoom)
+	r.GET("/rooms", h.GetAllRooms)
+	r.PUT("/room/:id/hours", h.SetRoomHours)
+	r.GET("/room/:id/hours", h.GetRoomHours)
+
+	r.POST("/schedule-draft", h.GenerateSchedule)
+	r.GET("/schedule-draft/:id", h.GetScheduleDraft)
+	r.POST("/schedule-draft/:id/commit", h.CommitScheduleDraft)
+
+	r.PUT("/teacher/:id/availability", h.SetTeacherAvailability)
+	r.GET("/teacher/:id/availability", h.GetTeacherAvailability)
+	r.GET("/teacher/:id/subjects", h.GetTeacherSubjects)
+	r.PUT("/teacher/:id/subject/:subject_id", h.SetTeacherSubject)
+	r.DELETE("/teacher/:id/subject/:subject_id", h.RemoveTeacherSubject)
+	r.POST("/teacher/:id/leave", h.RequestLeave)
+	r.GET("/leaves", h.GetAllLeaves)
+	r.PATCH("/leave/:id", h.ReviewLeave)
+	r.GET("/leave/:id/impacted-lessons", h.GetLeaveImpact)
+
+	r.POST("/academic-year", h.CreateAcademicYear)
+	r.DELETE("/academic-year/:id", h.DeleteAcademicYear)
+	r.GET("/academic-years", h.GetAllAcademicYears)
+	r.POST("/term", h.CreateTerm)
+	r.GET("/term/:id", h.GetTerm)
+	r.DELETE("/term/:id", h.DeleteTerm)
+	r.POST("/closure", h.CreateClosure)
+	r.DELETE("/closure/:id", h.DeleteClosure)
+	r.GET("/closures", h.GetAllClosures)
+
+	r.POST("/attendance", h.MarkAttendance)
+	r.GET("/attendance/:id", h.GetLessonAttendance)
+
+	r.POST("/assessment", h.CreateAssessment)
+	r.GET("/assessments", h.GetAllAssessments)
+	r.DELETE("/assessment/:id", h.DeleteAssessment)
+	r.PUT("/assessment/:id/scores", h.SetScores)
+	r.GET("/my-grades", h.GetMyGrades)
+	r.GET("/group/:id/grades", h.GetClassGrades)
+
+	r.POST("/assignment", h.CreateAssignment)
+	r.GET("/assignment/:id", h.GetAssignment)
+	r.GET("/assignments", h.GetAllAssignments)
+	r.DELETE("/assignment/:id", h.DeleteAssignment)
+	r.POST("/assignment/:id/submission", h.SubmitAssignment)
+	r.GET("/assignment/:id/my-submission", h.GetMySubmission)
+	r.GET("/assignment/:id/submissions", h.GetAssignmentSubmissions)
+	r.PATCH("/submission/:id", h.ReviewSubmission)
+
+	r.POST("/quiz", h.CreateQuiz)
+	r.GET("/quiz/:id", h.GetQuiz)
+	r.GET("/quizzes", h.GetAllQuizzes)
+	r.DELETE("/quiz/:id", h.DeleteQuiz)
+	r.POST("/quiz/:id/attempt", h.StartQuizAttempt)
+	r.GET("/quiz/:id/attempts", h.GetQuizAttempts)
+	r.POST("/quiz-attempt/:id/submit", h.SubmitQuizAttempt)
+	r.GET("/quiz-attempt/:id", h.GetQuizAttempt)
+
+	r.GET("/student/:id/report.pdf", h.GetStudentReportPDF)
+	r.GET("/group/:id/reports.zip", h.GetGroupReportsZIP)
+
+	r.POST("/guardian", h.CreateGuardian)
+	r.PUT("/guardian/:id", h.UpdateGuardian)
+	r.DELETE("/guardian/:id", h.DeleteGuardian)
+	r.GET("/guardian/:id", h.GetGuardian)
+	r.GET("/guardians", h.GetAllGuardians)
+	r.POST("/guardian/login", h.GuardianLogin)
+	r.PUT("/guardian/:id/students", h.SetGuardianStudents)
+	r.GET("/my-children", h.GetMyChildren)
+	r.GET("/child/:id/timetable", h.GetChildTimetable)
+	r.GET("/child/:id/attendance", h.GetChildAttendance)
+	r.GET("/child/:id/grades", h.GetChildGrades)
+
+	r.POST("/price-plan", h.CreatePricePlan)
+	r.PUT("/price-plan/:id", h.UpdatePricePlan)
+	r.GET("/price-plan/:id", h.GetPricePlan)
+	r.GET("/price-plans", h.GetAllPricePlans)
+	r.POST("/discount", h.SetDiscount)
+	r.DELETE("/discount/:id", h.DeleteDiscount)
+	r.GET("/discounts", h.GetAllDiscounts)
+	r.POST("/invoices/generate", h.GenerateInvoices)
+	r.GET("/invoice/:id", h.GetInvoice)
+	r.GET("/invoices", h.GetAllInvoices)
+	r.PATCH("/invoice/:id", h.UpdateInvoice)
+	r.POST("/payment", h.AddPayment)
+	r.GET("/payments", h.GetAllPayments)
+	r.POST("/invoice/:id/checkout", h.CreateCheckout)
+	r.POST("/payment-callback/:provider", h.PaymentCallback)
+	r.GET("/student/:id/balance", h.GetStudentBalance)
+	r.GET("/reports/debtors", h.GetDebtors)
+	r.GET("/reports/teacher-workload", h.GetTeacherWorkload)
+	r.GET("/reports/room-utilization", h.GetRoomUtilization)
+	r.GET("/dashboard", h.GetDashboard)
+
+	r.PUT("/teacher/:id/rate", h.SetTeacherRate)
+	r.DELETE("/teacher/:id/rate/:subject_id", h.DeleteTeacherRate)
+	r.GET("/teacher/:id/rates", h.GetTeacherRates)
+	r.GET("/payroll/preview", h.PreviewPayroll)
+	r.POST("/payroll-run", h.CreatePayrollRun)
+	r.GET("/payroll-run/:id", h.GetPayrollRun)
+	r.GET("/payroll-runs", h.GetAllPayrollRuns)
+	r.GET("/payroll-run/:id/statement.csv", h.GetPayrollStatementCSV)
+	r.GET("/payroll-run/:id/statement.pdf", h.GetPayrollStatementPDF)
+
+	r.POST("/check-in/code", h.GenerateCheckInCode)
+	r.GET("/check-in/code.png", h.GetCheckInQR)
+	r.POST("/check-in", h.CheckIn)
 
 	return r
 }
 
+// guardianRoutes are the only routes a guardian token may reach, all of them
+// read-only. Handlers still check the guardian is linked to the student.
+var guardianRoutes = map[string]bool{
+	"/guardian/:id":           true,
+	"/my-children":            true,
+	"/child/:id/timetable":    true,
+	"/child/:id/attendance":   true,
+	"/child/:id/grades":       true,
+	"/student/:id/report.pdf": true,
+}
+
+// authMiddleware rejects requests carrying an invalid token, handlers which need
+// to know the user check the role themselves. Guardians are kept to
+// guardianRoutes. Timestamps are rendered in the user's own timezone when the
+// token carries one. Payment callbacks are signed by the provider and may carry
+// its own Authorization.
 func authMiddleware(c *gin.Context) {
-	if c.GetHeader("Authorization") != "" {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	if c.FullPath() == "/payment-callback/:provider" {
+		c.Next()
 		return
 	}
+	if accessToken := c.GetHeader("Authorization"); accessToken != "" {
+		claims, err := jwt.ExtractClaims(accessToken)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		if claims["user_role"] == config.GUARDIAN_TYPE && (c.Request.Method != http.MethodGet || !guardianRoutes[c.FullPath()]) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
+		if name, ok := claims["timezone"].(string); ok {
+			if loc, err := time.LoadLocation(name); err == nil {
+				c.Request = c.Request.WithContext(pkg.WithLocation(c.Request.Context(), loc))
+			}
+		}
+	}
 	c.Next()
 }
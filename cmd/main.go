@@ -4,16 +4,33 @@ import (
 	"backend_course/lms/api"
 	"backend_course/lms/config"
 	"backend_course/lms/pkg/logger"
+	"backend_course/lms/pkg/payment"
 	"backend_course/lms/service"
 	"backend_course/lms/storage/postgres"
 	"backend_course/lms/storage/redis"
 	"context"
+	"time"
 )
 
 
 func main() {
 	cfg := config.Load()
 	log := logger.New(cfg.ServiceName)
+
+	// times without an explicit offset are taken in the school's timezone
+	if loc, err := time.LoadLocation(cfg.Timezone); err != nil {
+		log.Error("error while loading timezone, err: ", logger.Error(err))
+	} else {
+		time.Local = loc
+	}
+	if cfg.FakePayEnabled {
+		if cfg.FakePaySecret == "" {
+			log.Error("FAKE_PAY_SECRET is required when FAKE_PAY_ENABLED is on")
+			return
+		}
+		payment.Register(payment.NewFake(cfg.FakePaySecret))
+	}
+
 	newRedis := redis.New(cfg)
 
 	store, err := postgres.New(context.Background(), cfg, newRedis)
@@ -18,6 +18,9 @@ type Config struct {
 	RedisHost        string
 	RedisPort        string
 	RedisPassword    string
+	Timezone         string
+	FakePayEnabled   bool
+	FakePaySecret    string
 }
 
 func Load() Config {
@@ -36,6 +39,11 @@ func Load() Config {
 	cfg.RedisPort = cast.ToString(getOrReturnDefault("REDIS_PORT", ""))
 	cfg.RedisPassword = cast.ToString(getOrReturnDefault("REDIS_PASSWORD", "password"))
 	cfg.ServiceName = cast.ToString(getOrReturnDefault("SERVICE_NAME", ""))
+	cfg.Timezone = cast.ToString(getOrReturnDefault("TIMEZONE", "Asia/Tashkent"))
+	// the fake payment provider is for development and tests only, its callbacks can
+	// mark invoices paid so it is off unless asked for
+	cfg.FakePayEnabled = cast.ToBool(getOrReturnDefault("FAKE_PAY_ENABLED", false))
+	cfg.FakePaySecret = cast.ToString(getOrReturnDefault("FAKE_PAY_SECRET", ""))
 
 	return cfg
 }
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 const (
 	ERR_INFORMATION     = "The server has received the request and is continuing the process"
 	SUCCESS             = "The request was successful"
@@ -12,6 +14,69 @@ const (
 	SmtpPassword        = "pntm dene uuvh qavx"
 	TEACHER_TYPE        = "teacher"
 	STUDENT_TYPE        = "student"
+	GUARDIAN_TYPE       = "guardian"
+	ATTENDANCE_PRESENT  = "present"
+	ATTENDANCE_LATE     = "late"
+	ATTENDANCE_ABSENT   = "absent"
+	ATTENDANCE_EXCUSED  = "excused"
+	LESSON_IN_PROGRESS  = "in_progress"
+	LESSON_UPCOMING     = "upcoming"
+	LESSON_NONE         = "none"
+	SCHEDULE_DRAFT      = "draft"
+	SCHEDULE_COMMITTED  = "committed"
+	LEAVE_PENDING       = "pending"
+	LEAVE_APPROVED      = "approved"
+	LEAVE_REJECTED      = "rejected"
+	CLOSURE_HOLIDAY     = "holiday"
+	CLOSURE_CLOSURE     = "closure"
+	ASSESSMENT_QUIZ     = "quiz"
+	ASSESSMENT_EXAM     = "exam"
+	ASSESSMENT_HOMEWORK = "homework"
+	GradingScale        = "5-point"
+	CheckInCodeLength   = 6
+	MaxWeeklyLessons    = 20
+	CheckInCodeTTL      = 30 * time.Second
+	MaxUploadSize       = 10 << 20
+	QuizSubmitGrace     = 30 * time.Second
+	RoomOpensAt         = "08:00"
+	RoomClosesAt        = "20:00"
+	UnderUsedOccupancy  = 25
+	DashboardCacheTTL   = 5 * time.Minute
+	DashboardSubjects   = 5
+
+	SUBMISSION_SUBMITTED = "submitted"
+	SUBMISSION_LATE      = "late"
+	SUBMISSION_GRADED    = "graded"
+	SUBMISSION_RETURNED  = "returned"
+
+	ENROLLMENT_ACTIVE    = "active"
+	ENROLLMENT_COMPLETED = "completed"
+	ENROLLMENT_DROPPED   = "dropped"
+
+	PROFICIENCY_BASIC      = "basic"
+	PROFICIENCY_PROFICIENT = "proficient"
+	PROFICIENCY_EXPERT     = "expert"
+
+	RELATION_MOTHER   = "mother"
+	RELATION_FATHER   = "father"
+	RELATION_GUARDIAN = "guardian"
+	RELATION_OTHER    = "other"
+
+	INVOICE_ISSUED    = "issued"
+	INVOICE_CANCELLED = "cancelled"
+	INVOICE_OPEN      = "open"
+	INVOICE_PAID      = "paid"
+	INVOICE_OVERDUE   = "overdue"
+
+	PAYMENT_CASH     = "cash"
+	PAYMENT_CARD     = "card"
+	PAYMENT_TRANSFER = "transfer"
+	PAYMENT_ONLINE   = "online"
+
+	TRANSACTION_CREATED   = "created"
+	TRANSACTION_PREPARED  = "prepared"
+	TRANSACTION_COMPLETED = "completed"
+	TRANSACTION_CANCELLED = "cancelled"
 )
 
 var SignedKey = []byte("AtRdbumqoPjbcNjNhBgtmdAnRJyPQVXjwMPNYNbv")
\ No newline at end of file
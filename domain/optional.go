@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// Optional holds a value which may be absent, the zero Optional is absent. It scans
+// NULL as absent and is written as NULL when absent.
+type Optional[T any] struct {
+	value T
+	ok    bool
+}
+
+// Some returns an Optional holding v.
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{value: v, ok: true}
+}
+
+// Get returns the value and whether it is present.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.ok
+}
+
+// OrZero returns the value, or the zero value of T when it is absent.
+func (o Optional[T]) OrZero() T {
+	return o.value
+}
+
+// Valid reports whether the value is present.
+func (o Optional[T]) Valid() bool {
+	return o.ok
+}
+
+func (o *Optional[T]) Scan(src any) error {
+	if src == nil {
+		*o = Optional[T]{}
+		return nil
+	}
+	if v, ok := src.(T); ok {
+		*o = Some(v)
+		return nil
+	}
+	if scanner, ok := any(&o.value).(sql.Scanner); ok {
+		if err := scanner.Scan(src); err != nil {
+			return err
+		}
+		o.ok = true
+		return nil
+	}
+	var zero T
+	return fmt.Errorf("cannot scan %T into %T", src, zero)
+}
+
+func (o Optional[T]) Value() (driver.Value, error) {
+	if !o.ok {
+		return nil, nil
+	}
+	if valuer, ok := any(o.value).(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	return o.value, nil
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionalScan(t *testing.T) {
+	var at Optional[time.Time]
+	now := time.Now()
+	if assert.NoError(t, at.Scan(now)) {
+		got, ok := at.Get()
+		assert.True(t, ok)
+		assert.Equal(t, now, got)
+	}
+	if assert.NoError(t, at.Scan(nil)) {
+		assert.False(t, at.Valid())
+	}
+
+	var id Optional[uuid.UUID]
+	want := uuid.New()
+	if assert.NoError(t, id.Scan(want.String())) {
+		assert.Equal(t, want, id.OrZero())
+	}
+	assert.Error(t, id.Scan(42))
+}
+
+func TestOptionalValue(t *testing.T) {
+	v, err := Optional[string]{}.Value()
+	if assert.NoError(t, err) {
+		assert.Nil(t, v)
+	}
+
+	id := uuid.New()
+	v, err = Some(id).Value()
+	if assert.NoError(t, err) {
+		assert.Equal(t, id.String(), v)
+	}
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Student struct {
+	Id         uuid.UUID
+	FirstName  string
+	LastName   string
+	Age        int
+	ExternalId string
+	Phone      string
+	Email      string
+	IsActive   bool
+	Password   string
+	Timezone   Optional[string]
+	CreatedAt  time.Time
+	UpdatedAt  Optional[time.Time]
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Teacher struct {
+	Id               uuid.UUID
+	FirstName        string
+	LastName         string
+	SubjectIds       []uuid.UUID
+	StartWorking     Optional[time.Time]
+	Phone            string
+	Email            string
+	Password         string
+	MaxWeeklyLessons Optional[int]
+	Timezone         Optional[string]
+	CreatedAt        time.Time
+	UpdatedAt        Optional[time.Time]
+}
@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TimeTable is one student's row of a lesson, the rows sharing teacher, subject and
+// times make up the lesson.
+type TimeTable struct {
+	Id                uuid.UUID
+	TeacherId         uuid.UUID
+	StudentId         uuid.UUID
+	SubjectId         uuid.UUID
+	FromDate          time.Time
+	ToDate            time.Time
+	RoomName          string
+	OriginalTeacherId Optional[uuid.UUID]
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "attendance";
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "attendance" (
+  "id" UUID PRIMARY KEY,
+  "time_table_id" UUID NOT NULL REFERENCES "time_table" ("id") ON DELETE CASCADE,
+  "student_id" UUID NOT NULL REFERENCES "students" ("id") ON DELETE CASCADE,
+  "status" VARCHAR(20) NOT NULL CHECK ("status" IN ('present', 'late', 'absent', 'excused')),
+  "check_in_time" TIMESTAMP,
+  "note" TEXT,
+  "marked_by" UUID REFERENCES "teachers" ("id"),
+  "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+  "updated_at" TIMESTAMP,
+  UNIQUE ("time_table_id", "student_id")
+);
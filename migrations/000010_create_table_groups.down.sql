@@ -0,0 +1,5 @@
+ALTER TABLE "time_table"
+DROP COLUMN "group_id";
+
+DROP TABLE IF EXISTS "group_students";
+DROP TABLE IF EXISTS "groups";
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS "groups" (
+  "id" UUID PRIMARY KEY,
+  "name" VARCHAR(50) NOT NULL UNIQUE,
+  "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+  "updated_at" TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS "group_students" (
+  "group_id" UUID NOT NULL REFERENCES "groups" ("id") ON DELETE CASCADE,
+  "student_id" UUID NOT NULL REFERENCES "students" ("id") ON DELETE CASCADE,
+  PRIMARY KEY ("group_id", "student_id")
+);
+
+ALTER TABLE "time_table"
+ADD COLUMN "group_id" UUID REFERENCES "groups" ("id") ON DELETE SET NULL;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "rooms";
\ No newline at end of file
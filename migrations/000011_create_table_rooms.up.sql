@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS "rooms" (
+  "id" UUID PRIMARY KEY,
+  "name" VARCHAR(100) NOT NULL UNIQUE,
+  "capacity" INTEGER NOT NULL DEFAULT 0,
+  "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+  "updated_at" TIMESTAMP
+);
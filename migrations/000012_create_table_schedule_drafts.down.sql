@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "schedule_drafts";
\ No newline at end of file
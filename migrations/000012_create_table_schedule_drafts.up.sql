@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS "schedule_drafts" (
+  "id" UUID PRIMARY KEY,
+  "status" VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK ("status" IN ('draft', 'committed')),
+  "week_start" DATE NOT NULL,
+  "weeks" INTEGER NOT NULL,
+  "request" JSONB NOT NULL,
+  "lessons" JSONB NOT NULL,
+  "cost" JSONB NOT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+  "committed_at" TIMESTAMP
+);
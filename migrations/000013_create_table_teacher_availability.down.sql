@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "teacher_availability";
\ No newline at end of file
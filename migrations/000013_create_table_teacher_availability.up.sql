@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS "teacher_availability" (
+  "id" UUID PRIMARY KEY,
+  "teacher_id" UUID NOT NULL REFERENCES "teachers" ("id") ON DELETE CASCADE,
+  "weekday" SMALLINT NOT NULL CHECK ("weekday" BETWEEN 0 AND 6),
+  "start_time" TIME NOT NULL,
+  "end_time" TIME NOT NULL,
+  CHECK ("start_time" < "end_time")
+);
+
+CREATE INDEX IF NOT EXISTS "teacher_availability_teacher_id_idx" ON "teacher_availability" ("teacher_id");
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "teacher_leaves";
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS "teacher_leaves" (
+  "id" UUID PRIMARY KEY,
+  "teacher_id" UUID NOT NULL REFERENCES "teachers" ("id") ON DELETE CASCADE,
+  "from_date" TIMESTAMP NOT NULL,
+  "to_date" TIMESTAMP NOT NULL,
+  "reason" TEXT,
+  "status" VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK ("status" IN ('pending', 'approved', 'rejected')),
+  "reviewed_at" TIMESTAMP,
+  "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+  CHECK ("from_date" < "to_date")
+);
+
+CREATE INDEX IF NOT EXISTS "teacher_leaves_teacher_id_idx" ON "teacher_leaves" ("teacher_id");
@@ -0,0 +1,5 @@
+ALTER TABLE "teachers"
+DROP COLUMN "max_weekly_lessons";
+
+ALTER TABLE "time_table"
+DROP COLUMN "original_teacher_id";
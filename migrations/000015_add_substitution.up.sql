@@ -0,0 +1,5 @@
+ALTER TABLE "time_table"
+ADD COLUMN "original_teacher_id" UUID REFERENCES "teachers" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "teachers"
+ADD COLUMN "max_weekly_lessons" INT NOT NULL DEFAULT 20 CHECK ("max_weekly_lessons" >= 0);
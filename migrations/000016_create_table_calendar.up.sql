@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS "academic_years" (
+  "id" UUID PRIMARY KEY,
+  "name" VARCHAR(50) NOT NULL UNIQUE,
+  "start_date" DATE NOT NULL,
+  "end_date" DATE NOT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+  CHECK ("start_date" <= "end_date")
+);
+
+CREATE TABLE IF NOT EXISTS "terms" (
+  "id" UUID PRIMARY KEY,
+  "academic_year_id" UUID NOT NULL REFERENCES "academic_years" ("id") ON DELETE CASCADE,
+  "name" VARCHAR(50) NOT NULL,
+  "start_date" DATE NOT NULL,
+  "end_date" DATE NOT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+  UNIQUE ("academic_year_id", "name"),
+  CHECK ("start_date" <= "end_date")
+);
+
+CREATE TABLE IF NOT EXISTS "closures" (
+  "id" UUID PRIMARY KEY,
+  "name" VARCHAR(100) NOT NULL,
+  "kind" VARCHAR(20) NOT NULL DEFAULT 'holiday' CHECK ("kind" IN ('holiday', 'closure')),
+  "from_date" DATE NOT NULL,
+  "to_date" DATE NOT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+  CHECK ("from_date" <= "to_date")
+);
+
+CREATE INDEX IF NOT EXISTS "closures_dates_idx" ON "closures" ("from_date", "to_date");
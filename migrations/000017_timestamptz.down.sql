@@ -0,0 +1,60 @@
+-- values are written back in the zone of the session, run it with the session zone set
+-- to the school's TIMEZONE as for the up migration.
+
+ALTER TABLE "teachers"
+DROP COLUMN "timezone";
+
+ALTER TABLE "students"
+DROP COLUMN "timezone";
+
+ALTER TABLE "students"
+ALTER COLUMN "created_at" TYPE TIMESTAMP USING "created_at" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "updated_at" TYPE TIMESTAMP USING "updated_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "teachers"
+ALTER COLUMN "start_working" TYPE TIMESTAMP USING "start_working" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "created_at" TYPE TIMESTAMP USING "created_at" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "updated_at" TYPE TIMESTAMP USING "updated_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "subjects"
+ALTER COLUMN "created_at" TYPE TIMESTAMP USING "created_at" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "updated_at" TYPE TIMESTAMP USING "updated_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "time_table"
+ALTER COLUMN "from_date" TYPE TIMESTAMP USING "from_date" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "to_date" TYPE TIMESTAMP USING "to_date" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "attendance"
+ALTER COLUMN "check_in_time" TYPE TIMESTAMP USING "check_in_time" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "created_at" TYPE TIMESTAMP USING "created_at" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "updated_at" TYPE TIMESTAMP USING "updated_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "groups"
+ALTER COLUMN "created_at" TYPE TIMESTAMP USING "created_at" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "updated_at" TYPE TIMESTAMP USING "updated_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "rooms"
+ALTER COLUMN "created_at" TYPE TIMESTAMP USING "created_at" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "updated_at" TYPE TIMESTAMP USING "updated_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "schedule_drafts"
+ALTER COLUMN "created_at" TYPE TIMESTAMP USING "created_at" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "committed_at" TYPE TIMESTAMP USING "committed_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "teacher_leaves"
+ALTER COLUMN "from_date" TYPE TIMESTAMP USING "from_date" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "to_date" TYPE TIMESTAMP USING "to_date" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "reviewed_at" TYPE TIMESTAMP USING "reviewed_at" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "created_at" TYPE TIMESTAMP USING "created_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "academic_years"
+ALTER COLUMN "created_at" TYPE TIMESTAMP USING "created_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "terms"
+ALTER COLUMN "created_at" TYPE TIMESTAMP USING "created_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "closures"
+ALTER COLUMN "created_at" TYPE TIMESTAMP USING "created_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "students"
+RENAME COLUMN "updated_at" TO "updated";
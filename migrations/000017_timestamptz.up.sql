@@ -0,0 +1,62 @@
+-- existing values were written in the school's local time and are read in the zone of the
+-- session. Run this migration with the session zone set to the school's TIMEZONE, e.g.
+-- PGTZ=Asia/Tashkent or ALTER ROLE ... SET TimeZone = 'Asia/Tashkent', otherwise every
+-- value is shifted by the difference of the zones.
+
+ALTER TABLE "students"
+RENAME COLUMN "updated" TO "updated_at";
+
+ALTER TABLE "students"
+ALTER COLUMN "created_at" TYPE TIMESTAMPTZ USING "created_at" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "updated_at" TYPE TIMESTAMPTZ USING "updated_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "teachers"
+ALTER COLUMN "start_working" TYPE TIMESTAMPTZ USING "start_working" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "created_at" TYPE TIMESTAMPTZ USING "created_at" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "updated_at" TYPE TIMESTAMPTZ USING "updated_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "subjects"
+ALTER COLUMN "created_at" TYPE TIMESTAMPTZ USING "created_at" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "updated_at" TYPE TIMESTAMPTZ USING "updated_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "time_table"
+ALTER COLUMN "from_date" TYPE TIMESTAMPTZ USING "from_date" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "to_date" TYPE TIMESTAMPTZ USING "to_date" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "attendance"
+ALTER COLUMN "check_in_time" TYPE TIMESTAMPTZ USING "check_in_time" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "created_at" TYPE TIMESTAMPTZ USING "created_at" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "updated_at" TYPE TIMESTAMPTZ USING "updated_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "groups"
+ALTER COLUMN "created_at" TYPE TIMESTAMPTZ USING "created_at" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "updated_at" TYPE TIMESTAMPTZ USING "updated_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "rooms"
+ALTER COLUMN "created_at" TYPE TIMESTAMPTZ USING "created_at" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "updated_at" TYPE TIMESTAMPTZ USING "updated_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "schedule_drafts"
+ALTER COLUMN "created_at" TYPE TIMESTAMPTZ USING "created_at" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "committed_at" TYPE TIMESTAMPTZ USING "committed_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "teacher_leaves"
+ALTER COLUMN "from_date" TYPE TIMESTAMPTZ USING "from_date" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "to_date" TYPE TIMESTAMPTZ USING "to_date" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "reviewed_at" TYPE TIMESTAMPTZ USING "reviewed_at" AT TIME ZONE current_setting('TimeZone'),
+ALTER COLUMN "created_at" TYPE TIMESTAMPTZ USING "created_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "academic_years"
+ALTER COLUMN "created_at" TYPE TIMESTAMPTZ USING "created_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "terms"
+ALTER COLUMN "created_at" TYPE TIMESTAMPTZ USING "created_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "closures"
+ALTER COLUMN "created_at" TYPE TIMESTAMPTZ USING "created_at" AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE "students"
+ADD COLUMN "timezone" VARCHAR(64);
+
+ALTER TABLE "teachers"
+ADD COLUMN "timezone" VARCHAR(64);
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS "assessments" (
+  "id" UUID PRIMARY KEY,
+  "subject_id" UUID NOT NULL REFERENCES "subjects" ("id") ON DELETE CASCADE,
+  "group_id" UUID NOT NULL REFERENCES "groups" ("id") ON DELETE CASCADE,
+  "teacher_id" UUID REFERENCES "teachers" ("id") ON DELETE SET NULL,
+  "name" VARCHAR(100) NOT NULL,
+  "kind" VARCHAR(20) NOT NULL CHECK ("kind" IN ('quiz', 'exam', 'homework')),
+  "max_score" NUMERIC(7, 2) NOT NULL CHECK ("max_score" > 0),
+  "weight" NUMERIC(7, 2) NOT NULL DEFAULT 1 CHECK ("weight" > 0),
+  "held_on" DATE NOT NULL,
+  "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  "updated_at" TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS "assessments_group_subject_idx" ON "assessments" ("group_id", "subject_id", "held_on");
+
+CREATE TABLE IF NOT EXISTS "scores" (
+  "assessment_id" UUID NOT NULL REFERENCES "assessments" ("id") ON DELETE CASCADE,
+  "student_id" UUID NOT NULL REFERENCES "students" ("id") ON DELETE CASCADE,
+  "score" NUMERIC(7, 2) NOT NULL CHECK ("score" >= 0),
+  "graded_by" UUID REFERENCES "teachers" ("id") ON DELETE SET NULL,
+  "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  "updated_at" TIMESTAMPTZ,
+  PRIMARY KEY ("assessment_id", "student_id")
+);
+
+CREATE INDEX IF NOT EXISTS "scores_student_id_idx" ON "scores" ("student_id");
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS "assignments" (
+  "id" UUID PRIMARY KEY,
+  "subject_id" UUID NOT NULL REFERENCES "subjects" ("id") ON DELETE CASCADE,
+  "group_id" UUID NOT NULL REFERENCES "groups" ("id") ON DELETE CASCADE,
+  "teacher_id" UUID REFERENCES "teachers" ("id") ON DELETE SET NULL,
+  "title" VARCHAR(200) NOT NULL,
+  "description" TEXT NOT NULL DEFAULT '',
+  "attachments" TEXT[] NOT NULL DEFAULT '{}',
+  "due_at" TIMESTAMPTZ NOT NULL,
+  "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  "updated_at" TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS "assignments_group_subject_idx" ON "assignments" ("group_id", "subject_id", "due_at");
+
+CREATE TABLE IF NOT EXISTS "submissions" (
+  "id" UUID PRIMARY KEY,
+  "assignment_id" UUID NOT NULL REFERENCES "assignments" ("id") ON DELETE CASCADE,
+  "student_id" UUID NOT NULL REFERENCES "students" ("id") ON DELETE CASCADE,
+  "text" TEXT NOT NULL DEFAULT '',
+  "files" TEXT[] NOT NULL DEFAULT '{}',
+  "status" VARCHAR(20) NOT NULL CHECK ("status" IN ('submitted', 'late', 'graded', 'returned')),
+  "attempt" INT NOT NULL DEFAULT 1,
+  "score" NUMERIC(7, 2) CHECK ("score" >= 0),
+  "comment" TEXT NOT NULL DEFAULT '',
+  "reviewed_by" UUID REFERENCES "teachers" ("id") ON DELETE SET NULL,
+  "submitted_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  "reviewed_at" TIMESTAMPTZ,
+  UNIQUE ("assignment_id", "student_id")
+);
+
+CREATE INDEX IF NOT EXISTS "submissions_student_id_idx" ON "submissions" ("student_id");
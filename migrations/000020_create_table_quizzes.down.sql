@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "quiz_answers";
+DROP TABLE IF EXISTS "quiz_attempts";
+DROP TABLE IF EXISTS "quiz_questions";
+DROP TABLE IF EXISTS "quizzes";
@@ -0,0 +1,56 @@
+CREATE TABLE IF NOT EXISTS "quizzes" (
+  "id" UUID PRIMARY KEY,
+  "assessment_id" UUID NOT NULL UNIQUE REFERENCES "assessments" ("id") ON DELETE CASCADE,
+  "subject_id" UUID NOT NULL REFERENCES "subjects" ("id") ON DELETE CASCADE,
+  "group_id" UUID NOT NULL REFERENCES "groups" ("id") ON DELETE CASCADE,
+  "teacher_id" UUID REFERENCES "teachers" ("id") ON DELETE SET NULL,
+  "title" VARCHAR(200) NOT NULL,
+  "opens_at" TIMESTAMPTZ NOT NULL,
+  "closes_at" TIMESTAMPTZ NOT NULL,
+  "time_limit_minutes" INT NOT NULL CHECK ("time_limit_minutes" > 0),
+  "max_attempts" INT NOT NULL DEFAULT 1 CHECK ("max_attempts" > 0),
+  "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CHECK ("closes_at" > "opens_at")
+);
+
+CREATE INDEX IF NOT EXISTS "quizzes_group_subject_idx" ON "quizzes" ("group_id", "subject_id", "opens_at");
+
+CREATE TABLE IF NOT EXISTS "quiz_questions" (
+  "id" UUID PRIMARY KEY,
+  "quiz_id" UUID NOT NULL REFERENCES "quizzes" ("id") ON DELETE CASCADE,
+  "position" INT NOT NULL,
+  "kind" VARCHAR(20) NOT NULL CHECK ("kind" IN ('single', 'multiple', 'numeric', 'text')),
+  "text" TEXT NOT NULL,
+  "options" TEXT[] NOT NULL DEFAULT '{}',
+  "correct" INT[] NOT NULL DEFAULT '{}',
+  "number" NUMERIC,
+  "tolerance" NUMERIC NOT NULL DEFAULT 0,
+  "accepted" TEXT[] NOT NULL DEFAULT '{}',
+  "points" NUMERIC(7, 2) NOT NULL CHECK ("points" > 0),
+  UNIQUE ("quiz_id", "position")
+);
+
+CREATE TABLE IF NOT EXISTS "quiz_attempts" (
+  "id" UUID PRIMARY KEY,
+  "quiz_id" UUID NOT NULL REFERENCES "quizzes" ("id") ON DELETE CASCADE,
+  "student_id" UUID NOT NULL REFERENCES "students" ("id") ON DELETE CASCADE,
+  "question_order" UUID[] NOT NULL,
+  "started_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  "deadline" TIMESTAMPTZ NOT NULL,
+  "submitted_at" TIMESTAMPTZ,
+  "expired" BOOLEAN NOT NULL DEFAULT FALSE,
+  "score" NUMERIC(7, 2)
+);
+
+CREATE INDEX IF NOT EXISTS "quiz_attempts_quiz_student_idx" ON "quiz_attempts" ("quiz_id", "student_id");
+
+CREATE TABLE IF NOT EXISTS "quiz_answers" (
+  "attempt_id" UUID NOT NULL REFERENCES "quiz_attempts" ("id") ON DELETE CASCADE,
+  "question_id" UUID NOT NULL REFERENCES "quiz_questions" ("id") ON DELETE CASCADE,
+  "choices" INT[] NOT NULL DEFAULT '{}',
+  "number" NUMERIC,
+  "text" TEXT NOT NULL DEFAULT '',
+  "correct" BOOLEAN NOT NULL,
+  "points" NUMERIC(7, 2) NOT NULL,
+  PRIMARY KEY ("attempt_id", "question_id")
+);
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS "enrollments";
+DROP TABLE IF EXISTS "subject_prerequisites";
+DROP TABLE IF EXISTS "syllabus_lessons";
+DROP TABLE IF EXISTS "syllabus_units";
+
+ALTER TABLE "subjects"
+DROP COLUMN IF EXISTS "credit_hours",
+DROP COLUMN IF EXISTS "level",
+DROP COLUMN IF EXISTS "description",
+DROP COLUMN IF EXISTS "code";
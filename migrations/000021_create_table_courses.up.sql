@@ -0,0 +1,42 @@
+ALTER TABLE "subjects"
+ADD COLUMN IF NOT EXISTS "code" VARCHAR(20) UNIQUE,
+ADD COLUMN IF NOT EXISTS "description" TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS "level" VARCHAR(20) NOT NULL DEFAULT 'beginner' CHECK ("level" IN ('beginner', 'intermediate', 'advanced')),
+ADD COLUMN IF NOT EXISTS "credit_hours" INT NOT NULL DEFAULT 0 CHECK ("credit_hours" >= 0);
+
+CREATE TABLE IF NOT EXISTS "syllabus_units" (
+  "id" UUID PRIMARY KEY,
+  "subject_id" UUID NOT NULL REFERENCES "subjects" ("id") ON DELETE CASCADE,
+  "position" INT NOT NULL,
+  "title" VARCHAR(200) NOT NULL,
+  "description" TEXT NOT NULL DEFAULT '',
+  UNIQUE ("subject_id", "position")
+);
+
+CREATE TABLE IF NOT EXISTS "syllabus_lessons" (
+  "id" UUID PRIMARY KEY,
+  "unit_id" UUID NOT NULL REFERENCES "syllabus_units" ("id") ON DELETE CASCADE,
+  "position" INT NOT NULL,
+  "title" VARCHAR(200) NOT NULL,
+  "hours" NUMERIC(5, 2) NOT NULL DEFAULT 0 CHECK ("hours" >= 0),
+  UNIQUE ("unit_id", "position")
+);
+
+CREATE TABLE IF NOT EXISTS "subject_prerequisites" (
+  "subject_id" UUID NOT NULL REFERENCES "subjects" ("id") ON DELETE CASCADE,
+  "prerequisite_id" UUID NOT NULL REFERENCES "subjects" ("id") ON DELETE CASCADE,
+  PRIMARY KEY ("subject_id", "prerequisite_id"),
+  CHECK ("subject_id" <> "prerequisite_id")
+);
+
+CREATE TABLE IF NOT EXISTS "enrollments" (
+  "id" UUID PRIMARY KEY,
+  "student_id" UUID NOT NULL REFERENCES "students" ("id") ON DELETE CASCADE,
+  "subject_id" UUID NOT NULL REFERENCES "subjects" ("id") ON DELETE CASCADE,
+  "status" VARCHAR(20) NOT NULL DEFAULT 'active' CHECK ("status" IN ('active', 'completed', 'dropped')),
+  "enrolled_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  "completed_at" TIMESTAMPTZ,
+  UNIQUE ("student_id", "subject_id")
+);
+
+CREATE INDEX IF NOT EXISTS "enrollments_subject_id_idx" ON "enrollments" ("subject_id");
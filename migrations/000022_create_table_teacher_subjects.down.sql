@@ -0,0 +1,13 @@
+ALTER TABLE "teachers"
+ADD COLUMN IF NOT EXISTS "subject_id" UUID;
+
+-- a teacher keeps only the subject taught the longest.
+UPDATE "teachers" t
+SET "subject_id" = (
+  SELECT ts."subject_id" FROM "teacher_subjects" ts
+  WHERE ts."teacher_id" = t."id"
+  ORDER BY ts."since", ts."created_at"
+  LIMIT 1
+);
+
+DROP TABLE IF EXISTS "teacher_subjects";
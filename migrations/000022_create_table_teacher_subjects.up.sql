@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS "teacher_subjects" (
+  "teacher_id" UUID NOT NULL REFERENCES "teachers" ("id") ON DELETE CASCADE,
+  "subject_id" UUID NOT NULL REFERENCES "subjects" ("id") ON DELETE CASCADE,
+  "proficiency" VARCHAR(20) NOT NULL DEFAULT 'proficient' CHECK ("proficiency" IN ('basic', 'proficient', 'expert')),
+  "since" DATE NOT NULL DEFAULT CURRENT_DATE,
+  "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY ("teacher_id", "subject_id")
+);
+
+CREATE INDEX IF NOT EXISTS "teacher_subjects_subject_id_idx" ON "teacher_subjects" ("subject_id");
+
+-- subject ids pointing to deleted subjects are dropped, the column had no foreign key.
+INSERT INTO "teacher_subjects" ("teacher_id", "subject_id", "since")
+SELECT t."id", t."subject_id", COALESCE(t."start_working"::date, CURRENT_DATE)
+FROM "teachers" t
+INNER JOIN "subjects" sb ON sb."id" = t."subject_id"
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE "teachers"
+DROP COLUMN IF EXISTS "subject_id";
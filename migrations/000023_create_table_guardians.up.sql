@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS "guardians" (
+  "id" UUID PRIMARY KEY,
+  "first_name" VARCHAR(50) NOT NULL,
+  "last_name" VARCHAR(50) NOT NULL,
+  "phone" VARCHAR(50) NOT NULL DEFAULT '',
+  "mail" VARCHAR(50) NOT NULL UNIQUE,
+  "password" VARCHAR(100) NOT NULL,
+  "timezone" VARCHAR(64),
+  "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  "updated_at" TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS "guardian_students" (
+  "guardian_id" UUID NOT NULL REFERENCES "guardians" ("id") ON DELETE CASCADE,
+  "student_id" UUID NOT NULL REFERENCES "students" ("id") ON DELETE CASCADE,
+  "relation" VARCHAR(20) NOT NULL CHECK ("relation" IN ('mother', 'father', 'guardian', 'other')),
+  PRIMARY KEY ("guardian_id", "student_id")
+);
+
+CREATE INDEX IF NOT EXISTS "guardian_students_student_id_idx" ON "guardian_students" ("student_id");
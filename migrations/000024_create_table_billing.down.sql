@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "payments";
+DROP TABLE IF EXISTS "invoices";
+DROP TABLE IF EXISTS "invoice_counters";
+DROP TABLE IF EXISTS "discounts";
+DROP TABLE IF EXISTS "price_plans";
@@ -0,0 +1,62 @@
+-- Money is stored in minor units of the school's currency.
+CREATE TABLE IF NOT EXISTS "price_plans" (
+  "id" UUID PRIMARY KEY,
+  "name" VARCHAR(100) NOT NULL,
+  "subject_id" UUID REFERENCES "subjects" ("id") ON DELETE CASCADE,
+  "group_id" UUID REFERENCES "groups" ("id") ON DELETE CASCADE,
+  "monthly_fee" BIGINT NOT NULL CHECK ("monthly_fee" >= 0),
+  "due_day" INT NOT NULL DEFAULT 10 CHECK ("due_day" BETWEEN 1 AND 28),
+  "active" BOOLEAN NOT NULL DEFAULT TRUE,
+  "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  "updated_at" TIMESTAMPTZ,
+  CHECK (("subject_id" IS NULL) <> ("group_id" IS NULL))
+);
+
+CREATE TABLE IF NOT EXISTS "discounts" (
+  "id" UUID PRIMARY KEY,
+  "student_id" UUID NOT NULL REFERENCES "students" ("id") ON DELETE CASCADE,
+  "plan_id" UUID NOT NULL REFERENCES "price_plans" ("id") ON DELETE CASCADE,
+  "percent" INT NOT NULL CHECK ("percent" BETWEEN 1 AND 100),
+  "reason" TEXT NOT NULL DEFAULT '',
+  "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  UNIQUE ("student_id", "plan_id")
+);
+
+-- invoice_counters hands out gapless invoice numbers per year, its row is locked by the
+-- transaction generating invoices.
+CREATE TABLE IF NOT EXISTS "invoice_counters" (
+  "year" INT PRIMARY KEY,
+  "last_number" INT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS "invoices" (
+  "id" UUID PRIMARY KEY,
+  "number" VARCHAR(20) NOT NULL UNIQUE,
+  "student_id" UUID NOT NULL REFERENCES "students" ("id") ON DELETE CASCADE,
+  "plan_id" UUID NOT NULL REFERENCES "price_plans" ("id"),
+  "period" DATE NOT NULL,
+  "amount" BIGINT NOT NULL CHECK ("amount" >= 0),
+  "discount" BIGINT NOT NULL DEFAULT 0 CHECK ("discount" >= 0 AND "discount" <= "amount"),
+  "due_date" DATE NOT NULL,
+  "status" VARCHAR(20) NOT NULL DEFAULT 'issued' CHECK ("status" IN ('issued', 'cancelled')),
+  "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  "cancelled_at" TIMESTAMPTZ,
+  UNIQUE ("student_id", "plan_id", "period")
+);
+
+CREATE INDEX IF NOT EXISTS "invoices_due_date_idx" ON "invoices" ("due_date");
+
+CREATE TABLE IF NOT EXISTS "payments" (
+  "id" UUID PRIMARY KEY,
+  "student_id" UUID NOT NULL REFERENCES "students" ("id") ON DELETE CASCADE,
+  "invoice_id" UUID NOT NULL REFERENCES "invoices" ("id") ON DELETE CASCADE,
+  "amount" BIGINT NOT NULL CHECK ("amount" > 0),
+  "method" VARCHAR(20) NOT NULL CHECK ("method" IN ('cash', 'card', 'transfer')),
+  "reference" VARCHAR(100) NOT NULL DEFAULT '',
+  "recorded_by" UUID REFERENCES "teachers" ("id") ON DELETE SET NULL,
+  "paid_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS "payments_student_id_idx" ON "payments" ("student_id");
+CREATE INDEX IF NOT EXISTS "payments_invoice_id_idx" ON "payments" ("invoice_id");
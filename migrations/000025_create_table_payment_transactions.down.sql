@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "payment_transactions";
+
+UPDATE "payments" SET "method" = 'transfer' WHERE "method" = 'online';
+ALTER TABLE "payments" DROP CONSTRAINT IF EXISTS "payments_method_check";
+ALTER TABLE "payments" ADD CONSTRAINT "payments_method_check" CHECK ("method" IN ('cash', 'card', 'transfer'));
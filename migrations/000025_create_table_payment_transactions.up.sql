@@ -0,0 +1,20 @@
+ALTER TABLE "payments" DROP CONSTRAINT IF EXISTS "payments_method_check";
+ALTER TABLE "payments" ADD CONSTRAINT "payments_method_check" CHECK ("method" IN ('cash', 'card', 'transfer', 'online'));
+
+-- payment_transactions are the checkouts of invoices through a payment provider, the
+-- provider's callbacks move them from created to prepared and then to completed, which
+-- records the payment, or to cancelled.
+CREATE TABLE IF NOT EXISTS "payment_transactions" (
+  "id" UUID PRIMARY KEY,
+  "invoice_id" UUID NOT NULL REFERENCES "invoices" ("id") ON DELETE CASCADE,
+  "provider" VARCHAR(20) NOT NULL,
+  "provider_tx_id" VARCHAR(100),
+  "amount" BIGINT NOT NULL CHECK ("amount" > 0),
+  "state" VARCHAR(20) NOT NULL DEFAULT 'created' CHECK ("state" IN ('created', 'prepared', 'completed', 'cancelled')),
+  "payment_id" UUID REFERENCES "payments" ("id") ON DELETE SET NULL,
+  "created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  "updated_at" TIMESTAMPTZ,
+  UNIQUE ("provider", "provider_tx_id")
+);
+
+CREATE INDEX IF NOT EXISTS "payment_transactions_invoice_id_idx" ON "payment_transactions" ("invoice_id");
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "payroll_lines";
+DROP TABLE IF EXISTS "payroll_runs";
+DROP TABLE IF EXISTS "teacher_subject_rates";
+
+ALTER TABLE "teachers"
+DROP COLUMN IF EXISTS "hourly_rate";
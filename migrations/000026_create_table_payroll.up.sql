@@ -0,0 +1,41 @@
+-- Hourly rates are in minor units of the school's currency, a teacher's subject rate
+-- overrides the teacher's rate for the lessons of that subject.
+ALTER TABLE "teachers"
+ADD COLUMN "hourly_rate" BIGINT NOT NULL DEFAULT 0 CHECK ("hourly_rate" >= 0);
+
+CREATE TABLE IF NOT EXISTS "teacher_subject_rates" (
+  "teacher_id" UUID NOT NULL REFERENCES "teachers" ("id") ON DELETE CASCADE,
+  "subject_id" UUID NOT NULL REFERENCES "subjects" ("id") ON DELETE CASCADE,
+  "hourly_rate" BIGINT NOT NULL CHECK ("hourly_rate" >= 0),
+  "updated_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY ("teacher_id", "subject_id")
+);
+
+-- payroll_runs are locked statements of the lessons delivered from from_date to to_date,
+-- the periods of runs can not overlap so no lesson is paid twice.
+CREATE TABLE IF NOT EXISTS "payroll_runs" (
+  "id" UUID PRIMARY KEY,
+  "from_date" DATE NOT NULL,
+  "to_date" DATE NOT NULL,
+  "total" BIGINT NOT NULL,
+  "created_by" UUID REFERENCES "teachers" ("id") ON DELETE SET NULL,
+  "locked_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CHECK ("from_date" <= "to_date"),
+  EXCLUDE USING gist (daterange("from_date", "to_date", '[]') WITH &&)
+);
+
+-- payroll_lines keep the names and rates of the run, they do not change with the
+-- teachers and subjects.
+CREATE TABLE IF NOT EXISTS "payroll_lines" (
+  "run_id" UUID NOT NULL REFERENCES "payroll_runs" ("id") ON DELETE CASCADE,
+  "teacher_id" UUID NOT NULL,
+  "teacher_name" VARCHAR(101) NOT NULL,
+  "subject_id" UUID NOT NULL,
+  "subject_name" VARCHAR(50) NOT NULL,
+  "lessons" INT NOT NULL,
+  "substitute_lessons" INT NOT NULL,
+  "minutes" INT NOT NULL,
+  "hourly_rate" BIGINT NOT NULL,
+  "amount" BIGINT NOT NULL,
+  PRIMARY KEY ("run_id", "teacher_id", "subject_id")
+);
@@ -0,0 +1,12 @@
+-- room_hours are the weekly opening hours of rooms, a room without any is open for the
+-- school's default hours every day.
+CREATE TABLE IF NOT EXISTS "room_hours" (
+  "id" UUID PRIMARY KEY,
+  "room_id" UUID NOT NULL REFERENCES "rooms" ("id") ON DELETE CASCADE,
+  "weekday" SMALLINT NOT NULL CHECK ("weekday" BETWEEN 0 AND 6),
+  "open_time" TIME NOT NULL,
+  "close_time" TIME NOT NULL,
+  CHECK ("open_time" < "close_time")
+);
+
+CREATE INDEX IF NOT EXISTS "room_hours_room_id_idx" ON "room_hours" ("room_id");
@@ -0,0 +1,24 @@
+// Package billing reads billing periods and formats invoice numbers.
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidPeriod = errors.New("period is not valid, use YYYY-MM like 2024-09")
+
+// ParsePeriod reads a YYYY-MM billing period and returns its first day.
+func ParsePeriod(period string) (time.Time, error) {
+	t, err := time.Parse("2006-01", period)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q: %w", period, ErrInvalidPeriod)
+	}
+	return t, nil
+}
+
+// InvoiceNumber formats the n-th invoice number of year, like INV-2024-000042.
+func InvoiceNumber(year, n int) string {
+	return fmt.Sprintf("INV-%d-%06d", year, n)
+}
@@ -0,0 +1,25 @@
+package billing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePeriod(t *testing.T) {
+	period, err := ParsePeriod("2024-09")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, time.September, 1, 0, 0, 0, 0, time.UTC), period)
+
+	for _, s := range []string{"", "2024-9", "2024-13", "2024-09-01", "09-2024"} {
+		_, err := ParsePeriod(s)
+		assert.ErrorIs(t, err, ErrInvalidPeriod, s)
+	}
+}
+
+func TestInvoiceNumber(t *testing.T) {
+	assert.Equal(t, "INV-2024-000001", InvoiceNumber(2024, 1))
+	assert.Equal(t, "INV-2025-004200", InvoiceNumber(2025, 4200))
+	assert.Equal(t, "INV-2025-1234567", InvoiceNumber(2025, 1234567))
+}
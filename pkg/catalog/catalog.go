@@ -0,0 +1,55 @@
+// Package catalog checks the levels and prerequisite relations of catalog courses.
+package catalog
+
+import "errors"
+
+var ErrUnknownLevel = errors.New("level must be beginner, intermediate or advanced")
+
+const (
+	Beginner     = "beginner"
+	Intermediate = "intermediate"
+	Advanced     = "advanced"
+)
+
+// CheckLevel reports whether level is known.
+func CheckLevel(level string) error {
+	switch level {
+	case Beginner, Intermediate, Advanced:
+		return nil
+	}
+	return ErrUnknownLevel
+}
+
+// Cycle returns the courses of a cycle the prerequisites of subject would close, starting
+// and ending with subject, or nil when there is none. graph maps each course to its
+// current prerequisites, the ones of subject are replaced by prerequisites.
+func Cycle(graph map[string][]string, subject string, prerequisites []string) []string {
+	visited := map[string]bool{}
+	var path []string
+
+	var visit func(id string) bool
+	visit = func(id string) bool {
+		if id == subject {
+			return true
+		}
+		if visited[id] {
+			return false
+		}
+		visited[id] = true
+		path = append(path, id)
+		for _, next := range graph[id] {
+			if visit(next) {
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+
+	for _, id := range prerequisites {
+		if visit(id) {
+			return append(append([]string{subject}, path...), subject)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckLevel(t *testing.T) {
+	assert.NoError(t, CheckLevel(Beginner))
+	assert.NoError(t, CheckLevel(Advanced))
+	assert.ErrorIs(t, CheckLevel("expert"), ErrUnknownLevel)
+	assert.ErrorIs(t, CheckLevel(""), ErrUnknownLevel)
+}
+
+func TestCycle(t *testing.T) {
+	graph := map[string][]string{
+		"algebra":  {"arith"},
+		"calculus": {"algebra", "geometry"},
+		"geometry": {"arith"},
+	}
+
+	assert.Nil(t, Cycle(graph, "physics", []string{"calculus"}))
+	assert.Nil(t, Cycle(graph, "calculus", []string{"algebra"}))
+	assert.Equal(t, []string{"arith", "calculus", "algebra", "arith"}, Cycle(graph, "arith", []string{"calculus"}))
+	assert.Equal(t, []string{"arith", "arith"}, Cycle(graph, "arith", []string{"arith"}))
+	// the replaced prerequisites of algebra no longer lead back to it
+	assert.Nil(t, Cycle(map[string][]string{"algebra": {"calculus"}, "calculus": {"algebra"}}, "algebra", nil))
+}
@@ -0,0 +1,226 @@
+// Package export writes list rows as CSV or XLSX while they are read, the columns are the
+// json fields of the rows. Imports read such files back into records by column.
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	JSON = "json"
+	CSV  = "csv"
+	XLSX = "xlsx"
+)
+
+const (
+	csvType  = "text/csv"
+	xlsxType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	sheet    = "Sheet1"
+)
+
+// All is the limit of a list which is exported, every row of its filters is read.
+const All = math.MaxInt64
+
+var ErrFormat = errors.New("format must be json, csv or xlsx")
+
+// Format is the format a list is asked in, format wins over the Accept header and JSON is
+// the default.
+func Format(format, accept string) (string, error) {
+	switch format {
+	case JSON, CSV, XLSX:
+		return format, nil
+	case "":
+	default:
+		return "", ErrFormat
+	}
+
+	switch {
+	case strings.Contains(accept, csvType):
+		return CSV, nil
+	case strings.Contains(accept, xlsxType):
+		return XLSX, nil
+	}
+	return JSON, nil
+}
+
+// ContentType is the media type of a format.
+func ContentType(format string) string {
+	if format == XLSX {
+		return xlsxType
+	}
+	return csvType
+}
+
+// Columns are the names of the columns of rows of the type of row. Fields of embedded
+// structs are flattened, other structs, slices and maps are left out.
+func Columns(row any) []string {
+	var columns []string
+	fields(reflect.ValueOf(row), func(name string, _ reflect.Value) {
+		columns = append(columns, name)
+	})
+	return columns
+}
+
+// Values are the values of the columns of row, nil for a nil pointer.
+func Values(row any) []any {
+	var values []any
+	fields(reflect.ValueOf(row), func(_ string, v reflect.Value) {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				values = append(values, nil)
+				return
+			}
+			v = v.Elem()
+		}
+		values = append(values, v.Interface())
+	})
+	return values
+}
+
+func fields(v reflect.Value, fn func(name string, v reflect.Value)) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v = reflect.New(v.Type().Elem())
+		}
+		v = v.Elem()
+	}
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		kind := field.Type.Kind()
+		if kind == reflect.Pointer {
+			kind = field.Type.Elem().Kind()
+		}
+		switch kind {
+		case reflect.Struct:
+			if field.Anonymous && name == "" {
+				fields(v.Field(i), fn)
+			}
+			continue
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fn(name, v.Field(i))
+	}
+}
+
+// Writer writes rows in a format, CSV rows reach the underlying writer while they are
+// written and XLSX rows when the writer is closed.
+type Writer struct {
+	w      io.Writer
+	csv    *csv.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	rows   int
+}
+
+// NewWriter starts writing rows of the type of row to w in format with a header of their
+// columns.
+func NewWriter(format string, w io.Writer, row any) (*Writer, error) {
+	writer := &Writer{w: w}
+	switch format {
+	case CSV:
+		writer.csv = csv.NewWriter(w)
+	case XLSX:
+		writer.file = excelize.NewFile()
+		stream, err := writer.file.NewStreamWriter(sheet)
+		if err != nil {
+			writer.file.Close()
+			return nil, err
+		}
+		writer.stream = stream
+	default:
+		return nil, fmt.Errorf("format %q can not be exported", format)
+	}
+
+	columns := Columns(row)
+	header := make([]any, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	if err := writer.write(header); err != nil {
+		writer.Discard()
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Write writes a row.
+func (w *Writer) Write(row any) error {
+	return w.write(Values(row))
+}
+
+func (w *Writer) write(values []any) error {
+	w.rows++
+	if w.csv != nil {
+		record := make([]string, len(values))
+		for i, value := range values {
+			record[i] = text(value)
+		}
+		return w.csv.Write(record)
+	}
+
+	cell, err := excelize.CoordinatesToCellName(1, w.rows)
+	if err != nil {
+		return err
+	}
+	return w.stream.SetRow(cell, values)
+}
+
+// Close writes what is left of the rows, it has to be called for the output to be
+// complete.
+func (w *Writer) Close() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+
+	defer w.file.Close()
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	return w.file.Write(w.w)
+}
+
+// Discard drops the rows of a writer which is not closed, which XLSX keeps until then.
+func (w *Writer) Discard() {
+	if w.file != nil {
+		w.file.Close()
+	}
+}
+
+func text(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
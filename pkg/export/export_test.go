@@ -0,0 +1,107 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+type Stats struct {
+	Lessons int64   `json:"lessons"`
+	Rate    float64 `json:"rate"`
+}
+
+type row struct {
+	Id     string  `json:"id"`
+	Name   string  `json:"name,omitempty"`
+	Active bool    `json:"active"`
+	Due    *string `json:"due"`
+	Stats
+	Groups   []string `json:"groups"`
+	Details  Stats    `json:"details"`
+	Password string   `json:"-"`
+	internal string
+}
+
+func TestFormat(t *testing.T) {
+	for _, tc := range []struct {
+		format, accept, want string
+	}{
+		{"", "", JSON},
+		{"", "application/json", JSON},
+		{"", "text/csv", CSV},
+		{"", "text/csv; charset=utf-8", CSV},
+		{"", xlsxType, XLSX},
+		{"csv", xlsxType, CSV},
+		{"xlsx", "", XLSX},
+		{"json", "text/csv", JSON},
+	} {
+		format, err := Format(tc.format, tc.accept)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.want, format, tc)
+	}
+
+	_, err := Format("pdf", "")
+	assert.ErrorIs(t, err, ErrFormat)
+}
+
+func TestColumns(t *testing.T) {
+	assert.Equal(t, []string{"id", "name", "active", "due", "lessons", "rate"}, Columns(row{}))
+	assert.Equal(t, Columns(row{}), Columns(&row{}))
+}
+
+func TestValues(t *testing.T) {
+	due := "2024-10-10"
+	r := row{Id: "1", Name: "Ali", Due: &due, Stats: Stats{Lessons: 3, Rate: 0.5}, Password: "secret"}
+	assert.Equal(t, []any{"1", "Ali", false, "2024-10-10", int64(3), 0.5}, Values(r))
+
+	r.Due = nil
+	assert.Equal(t, []any{"1", "Ali", false, nil, int64(3), 0.5}, Values(&r))
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(CSV, &buf, row{})
+	assert.NoError(t, err)
+	assert.NoError(t, w.Write(row{Id: "1", Name: "Valiyev, Ali", Active: true, Stats: Stats{Lessons: 12, Rate: 87.5}}))
+	assert.NoError(t, w.Write(row{Id: "2"}))
+	assert.NoError(t, w.Close())
+
+	assert.Equal(t, "id,name,active,due,lessons,rate\n"+
+		"1,\"Valiyev, Ali\",true,,12,87.5\n"+
+		"2,,false,,0,0\n", buf.String())
+}
+
+func TestWriteCSVEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(CSV, &buf, row{})
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	assert.Equal(t, "id,name,active,due,lessons,rate\n", buf.String())
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(XLSX, &buf, row{})
+	assert.NoError(t, err)
+	assert.NoError(t, w.Write(row{Id: "1", Name: "Ali", Stats: Stats{Lessons: 12, Rate: 87.5}}))
+	assert.NoError(t, w.Close())
+
+	f, err := excelize.OpenReader(&buf)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	rows, err := f.GetRows(sheet)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"id", "name", "active", "due", "lessons", "rate"},
+		{"1", "Ali", "FALSE", "", "12", "87.5"},
+	}, rows)
+}
+
+func TestNewWriterFormat(t *testing.T) {
+	_, err := NewWriter(JSON, &bytes.Buffer{}, row{})
+	assert.Error(t, err)
+}
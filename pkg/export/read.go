@@ -0,0 +1,109 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var ErrFileFormat = errors.New("file must be csv or xlsx")
+
+// Record is a row of an imported file by column, Line is its row in the file.
+type Record struct {
+	Line   int
+	Values map[string]string
+}
+
+// FileFormat is the format of a file by the extension of its name.
+func FileFormat(name string) (string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return CSV, nil
+	case ".xlsx":
+		return XLSX, nil
+	}
+	return "", ErrFileFormat
+}
+
+// Read reads the rows of a CSV file or of the first sheet of an XLSX file.
+func Read(format string, r io.Reader) ([][]string, error) {
+	switch format {
+	case CSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return reader.ReadAll()
+	case XLSX:
+		file, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return file.GetRows(file.GetSheetName(0))
+	}
+	return nil, ErrFileFormat
+}
+
+// Records reads the rows under the header of a file into the columns. A column is read
+// from the header of its name unless mapping names another header for it, headers are
+// matched ignoring case and the ones of no column are left out. Empty rows are skipped.
+func Records(rows [][]string, columns []string, mapping map[string]string) ([]Record, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("file has no header")
+	}
+
+	headers := make(map[string]int, len(rows[0]))
+	for i, header := range rows[0] {
+		headers[strings.ToLower(strings.TrimSpace(header))] = i
+	}
+
+	known := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		known[column] = true
+	}
+	for column := range mapping {
+		if !known[column] {
+			return nil, fmt.Errorf("column %q can not be mapped, columns are %s", column, strings.Join(columns, ", "))
+		}
+	}
+
+	index := make(map[string]int, len(columns))
+	for _, column := range columns {
+		header, mapped := mapping[column]
+		if !mapped {
+			header = column
+		}
+		i, ok := headers[strings.ToLower(strings.TrimSpace(header))]
+		if !ok {
+			if mapped {
+				return nil, fmt.Errorf("header %q of column %q is not in the file", header, column)
+			}
+			continue
+		}
+		index[column] = i
+	}
+
+	var records []Record
+	for n, row := range rows[1:] {
+		record := Record{Line: n + 2, Values: make(map[string]string, len(index))}
+		empty := true
+		for column, i := range index {
+			if i < len(row) {
+				value := strings.TrimSpace(row[i])
+				record.Values[column] = value
+				if value != "" {
+					empty = false
+				}
+			}
+		}
+		if !empty {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
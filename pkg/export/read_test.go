@@ -0,0 +1,71 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileFormat(t *testing.T) {
+	format, err := FileFormat("students.CSV")
+	assert.NoError(t, err)
+	assert.Equal(t, CSV, format)
+
+	format, err = FileFormat("teachers.xlsx")
+	assert.NoError(t, err)
+	assert.Equal(t, XLSX, format)
+
+	_, err = FileFormat("students.xls")
+	assert.ErrorIs(t, err, ErrFileFormat)
+}
+
+func TestReadCSV(t *testing.T) {
+	rows, err := Read(CSV, strings.NewReader("id,name\n1, \"Valiyev, Ali\"\n2\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"id", "name"}, {"1", "Valiyev, Ali"}, {"2"}}, rows)
+}
+
+func TestReadXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(XLSX, &buf, row{})
+	assert.NoError(t, err)
+	assert.NoError(t, w.Write(row{Id: "1", Name: "Ali"}))
+	assert.NoError(t, w.Close())
+
+	rows, err := Read(XLSX, &buf)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"id", "name", "active", "due", "lessons", "rate"},
+		{"1", "Ali", "FALSE", "", "0", "0"},
+	}, rows)
+
+	_, err = Read(JSON, &buf)
+	assert.ErrorIs(t, err, ErrFileFormat)
+}
+
+func TestRecords(t *testing.T) {
+	rows := [][]string{
+		{"ID", "E-mail", "Extra"},
+		{"1", " ali@gmail.com ", "x"},
+		{"", ""},
+		{"2"},
+	}
+
+	records, err := Records(rows, []string{"id", "mail", "phone"}, map[string]string{"mail": "e-mail"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Record{
+		{Line: 2, Values: map[string]string{"id": "1", "mail": "ali@gmail.com"}},
+		{Line: 4, Values: map[string]string{"id": "2"}},
+	}, records)
+
+	_, err = Records(rows, []string{"id", "mail"}, map[string]string{"name": "Name"})
+	assert.Error(t, err)
+
+	_, err = Records(rows, []string{"id", "mail"}, map[string]string{"mail": "Mail"})
+	assert.Error(t, err)
+
+	_, err = Records(nil, []string{"id"}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,76 @@
+// Package grading computes weighted grades of assessments and renders them on a grading scale.
+package grading
+
+import (
+	"errors"
+	"math"
+	"strconv"
+)
+
+var ErrUnknownScale = errors.New("grading scale must be 5-point, 100-point or letter")
+
+const (
+	FivePoint    = "5-point"
+	HundredPoint = "100-point"
+	Letter       = "letter"
+)
+
+// Score is a student's score of one assessment.
+type Score struct {
+	Score    float64
+	MaxScore float64
+	Weight   float64
+}
+
+// Percent is the weighted average of the scores as a percentage of their max scores, it is
+// false when no score has a weight.
+func Percent(scores []Score) (float64, bool) {
+	var sum, weights float64
+	for _, s := range scores {
+		if s.MaxScore <= 0 || s.Weight <= 0 {
+			continue
+		}
+		sum += s.Weight * s.Score / s.MaxScore
+		weights += s.Weight
+	}
+	if weights == 0 {
+		return 0, false
+	}
+	return math.Round(sum/weights*10000) / 100, true
+}
+
+// threshold is the lowest percentage getting a grade.
+type threshold struct {
+	min   float64
+	grade string
+}
+
+var scales = map[string][]threshold{
+	FivePoint: {{86, "5"}, {71, "4"}, {56, "3"}, {0, "2"}},
+	Letter:    {{90, "A"}, {80, "B"}, {70, "C"}, {60, "D"}, {0, "F"}},
+}
+
+// CheckScale reports whether scale is known.
+func CheckScale(scale string) error {
+	if _, ok := scales[scale]; ok || scale == HundredPoint {
+		return nil
+	}
+	return ErrUnknownScale
+}
+
+// Grade renders percent on the scale.
+func Grade(scale string, percent float64) (string, error) {
+	if scale == HundredPoint {
+		return strconv.Itoa(int(math.Round(percent))), nil
+	}
+	thresholds, ok := scales[scale]
+	if !ok {
+		return "", ErrUnknownScale
+	}
+	for _, t := range thresholds {
+		if percent >= t.min {
+			return t.grade, nil
+		}
+	}
+	return thresholds[len(thresholds)-1].grade, nil
+}
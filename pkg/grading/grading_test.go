@@ -0,0 +1,45 @@
+package grading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercent(t *testing.T) {
+	percent, ok := Percent([]Score{
+		{Score: 8, MaxScore: 10, Weight: 1},
+		{Score: 45, MaxScore: 50, Weight: 3},
+	})
+	if assert.True(t, ok) {
+		// (0.8*1 + 0.9*3) / 4
+		assert.Equal(t, 87.5, percent)
+	}
+
+	_, ok = Percent(nil)
+	assert.False(t, ok)
+}
+
+func TestGrade(t *testing.T) {
+	cases := []struct {
+		scale   string
+		percent float64
+		want    string
+	}{
+		{FivePoint, 87.5, "5"},
+		{FivePoint, 70.99, "3"},
+		{FivePoint, 10, "2"},
+		{HundredPoint, 87.5, "88"},
+		{Letter, 79.9, "C"},
+		{Letter, 0, "F"},
+	}
+	for _, c := range cases {
+		got, err := Grade(c.scale, c.percent)
+		if assert.NoError(t, err) {
+			assert.Equal(t, c.want, got, "%s %v", c.scale, c.percent)
+		}
+	}
+
+	_, err := Grade("10-point", 50)
+	assert.ErrorIs(t, err, ErrUnknownScale)
+}
@@ -1,10 +1,15 @@
 package pkg
 
 import (
+	crand "crypto/rand"
 	"database/sql"
+	"math"
+	"math/big"
 	"math/rand"
 )
 
+const checkInCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
 func NullStringToString(s sql.NullString) string {
 	if s.Valid {
 		return s.String
@@ -14,4 +19,33 @@ func NullStringToString(s sql.NullString) string {
 
 func GenerateOTP() int {
 	return rand.Intn(900000) + 100000
-}
\ No newline at end of file
+}
+
+// GenerateCheckInCode returns a random code of the given length, ambiguous
+// characters like O/0 and I/1 are left out so that it can be typed by hand.
+func GenerateCheckInCode(length int) (string, error) {
+	code := make([]byte, length)
+	max := big.NewInt(int64(len(checkInCodeAlphabet)))
+	for i := range code {
+		n, err := crand.Int(crand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = checkInCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// DistanceInMeters returns the great-circle distance between two points.
+func DistanceInMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadius = 6371000
+
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
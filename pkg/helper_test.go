@@ -0,0 +1,26 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateCheckInCode(t *testing.T) {
+	code, err := GenerateCheckInCode(6)
+	if assert.NoError(t, err) {
+		assert.Len(t, code, 6)
+		for _, r := range code {
+			assert.True(t, strings.ContainsRune(checkInCodeAlphabet, r))
+		}
+	}
+}
+
+func TestDistanceInMeters(t *testing.T) {
+	// Amir Temur square to Chorsu bazaar, Tashkent.
+	distance := DistanceInMeters(41.3111, 69.2797, 41.3264, 69.2361)
+	assert.InDelta(t, 3970, distance, 100)
+
+	assert.Zero(t, DistanceInMeters(41.3111, 69.2797, 41.3111, 69.2797))
+}
@@ -0,0 +1,108 @@
+package payment
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// SignatureHeader carries the signature of the fake provider's callbacks.
+const SignatureHeader = "X-Signature"
+
+// fakeCallback is the body of the fake provider's callbacks, shaped like Click's: action
+// is 0 to prepare and 1 to complete, a negative error means the money was not taken.
+type fakeCallback struct {
+	ClickTransId    string `json:"click_trans_id"`
+	MerchantTransId string `json:"merchant_trans_id"`
+	Amount          int64  `json:"amount"`
+	Action          int    `json:"action"`
+	Error           int    `json:"error"`
+}
+
+type fakeReply struct {
+	ClickTransId      string `json:"click_trans_id"`
+	MerchantTransId   string `json:"merchant_trans_id"`
+	MerchantConfirmId string `json:"merchant_confirm_id,omitempty"`
+	Error             int    `json:"error"`
+	ErrorNote         string `json:"error_note"`
+}
+
+// Fake is a local provider for tests, its callbacks are JSON bodies signed by the
+// HMAC-SHA256 of the shared secret in SignatureHeader.
+type Fake struct {
+	secret []byte
+}
+
+func NewFake(secret string) Fake {
+	return Fake{secret: []byte(secret)}
+}
+
+func (f Fake) Name() string {
+	return "fake"
+}
+
+func (f Fake) CheckoutURL(checkout Checkout) string {
+	query := url.Values{}
+	query.Set("merchant_trans_id", checkout.TransactionId)
+	query.Set("amount", fmt.Sprint(checkout.Amount))
+	query.Set("invoice", checkout.InvoiceNumber)
+	return "https://fake.pay/checkout?" + query.Encode()
+}
+
+func (f Fake) ParseCallback(header http.Header, body []byte) (Callback, error) {
+	var req fakeCallback
+	if err := json.Unmarshal(body, &req); err != nil {
+		return Callback{}, err
+	}
+
+	callback := Callback{
+		ProviderTxId:  req.ClickTransId,
+		TransactionId: req.MerchantTransId,
+		Amount:        req.Amount,
+		Failed:        req.Error < 0,
+	}
+	if !Verify(f.secret, body, header.Get(SignatureHeader)) {
+		return callback, ErrBadSignature
+	}
+
+	switch req.Action {
+	case 0:
+		callback.Action = Prepare
+	case 1:
+		callback.Action = Complete
+	default:
+		return callback, ErrUnknownAction
+	}
+	return callback, nil
+}
+
+func (f Fake) Reply(callback Callback, result Result) any {
+	return fakeReply{
+		ClickTransId:      callback.ProviderTxId,
+		MerchantTransId:   callback.TransactionId,
+		MerchantConfirmId: result.PaymentId,
+		Error:             result.Code,
+		ErrorNote:         result.Message,
+	}
+}
+
+// Callback builds a signed callback of the fake provider, it plays the provider in tests.
+func (f Fake) Callback(callback Callback) (http.Header, []byte) {
+	req := fakeCallback{
+		ClickTransId:    callback.ProviderTxId,
+		MerchantTransId: callback.TransactionId,
+		Amount:          callback.Amount,
+	}
+	if callback.Action == Complete {
+		req.Action = 1
+	}
+	if callback.Failed {
+		req.Error = -1
+	}
+
+	body, _ := json.Marshal(req)
+	header := http.Header{}
+	header.Set(SignatureHeader, Sign(f.secret, body))
+	return header, body
+}
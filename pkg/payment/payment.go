@@ -0,0 +1,118 @@
+// Package payment is the interface of the payment gateways which call back about the
+// checkouts paid through them, in two steps like Click and Payme do: a prepare before
+// the money is taken and a complete after.
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+var (
+	ErrUnknownProvider = errors.New("payment provider is not known")
+	ErrBadSignature    = errors.New("signature is not valid")
+	ErrUnknownAction   = errors.New("action is not known")
+)
+
+// The actions of a callback.
+const (
+	Prepare  = "prepare"
+	Complete = "complete"
+)
+
+// The codes of a callback's result, they follow Click's.
+const (
+	CodeOK                 = 0
+	CodeBadSignature       = -1
+	CodeBadAmount          = -2
+	CodeUnknownAction      = -3
+	CodeAlreadyPaid        = -4
+	CodeInvoiceNotFound    = -5
+	CodeUnknownTransaction = -6
+	CodeBadRequest         = -8
+	CodeCancelled          = -9
+)
+
+// Checkout is what is asked to be paid through a gateway, TransactionId is ours.
+type Checkout struct {
+	TransactionId string
+	InvoiceNumber string
+	Amount        int64
+}
+
+// Callback is a provider's notification about a transaction. Failed is set when the
+// provider could not take the money.
+type Callback struct {
+	Action        string
+	ProviderTxId  string
+	TransactionId string
+	Amount        int64
+	Failed        bool
+}
+
+// Result is the answer to a callback, PaymentId is the recorded payment once it is
+// completed.
+type Result struct {
+	Code      int
+	Message   string
+	PaymentId string
+}
+
+// Gateway is a payment provider.
+type Gateway interface {
+	Name() string
+	// CheckoutURL is the page the payer is sent to.
+	CheckoutURL(checkout Checkout) string
+	// ParseCallback reads a callback, ErrBadSignature is returned when it is not signed by
+	// the provider.
+	ParseCallback(header http.Header, body []byte) (Callback, error)
+	// Reply is the body answering a callback.
+	Reply(callback Callback, result Result) any
+}
+
+var (
+	mu       sync.RWMutex
+	gateways = map[string]Gateway{}
+)
+
+// Register makes the gateway available by its name, a gateway of the same name is
+// replaced.
+func Register(gateway Gateway) {
+	mu.Lock()
+	defer mu.Unlock()
+	gateways[gateway.Name()] = gateway
+}
+
+// Get returns the gateway registered with the name.
+func Get(name string) (Gateway, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	gateway, ok := gateways[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return gateway, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of body with secret.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the hex HMAC-SHA256 of body with secret, in
+// constant time.
+func Verify(secret, body []byte, signature string) bool {
+	sum, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(sum, mac.Sum(nil))
+}
@@ -0,0 +1,60 @@
+package payment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret, body := []byte("secret"), []byte(`{"amount":1000}`)
+	signature := Sign(secret, body)
+
+	assert.True(t, Verify(secret, body, signature))
+	assert.False(t, Verify([]byte("other"), body, signature))
+	assert.False(t, Verify(secret, []byte(`{"amount":1}`), signature))
+	assert.False(t, Verify(secret, body, "not hex"))
+	assert.False(t, Verify(secret, body, ""))
+}
+
+func TestRegistry(t *testing.T) {
+	Register(NewFake("secret"))
+
+	gateway, err := Get("fake")
+	assert.NoError(t, err)
+	assert.Equal(t, "fake", gateway.Name())
+
+	_, err = Get("payme")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
+
+func TestFakeCallback(t *testing.T) {
+	fake := NewFake("secret")
+	sent := Callback{Action: Complete, ProviderTxId: "42", TransactionId: "tx", Amount: 1500}
+
+	header, body := fake.Callback(sent)
+	got, err := fake.ParseCallback(header, body)
+	assert.NoError(t, err)
+	assert.Equal(t, sent, got)
+
+	header, body = fake.Callback(Callback{Action: Prepare, TransactionId: "tx", Failed: true})
+	got, err = fake.ParseCallback(header, body)
+	assert.NoError(t, err)
+	assert.Equal(t, Prepare, got.Action)
+	assert.True(t, got.Failed)
+
+	// a callback signed by someone else keeps what was read of it to be answered
+	_, body = fake.Callback(sent)
+	got, err = NewFake("other").ParseCallback(header, body)
+	assert.ErrorIs(t, err, ErrBadSignature)
+	assert.Equal(t, "tx", got.TransactionId)
+
+	_, err = fake.ParseCallback(header, []byte("{"))
+	assert.Error(t, err)
+}
+
+func TestFakeReply(t *testing.T) {
+	reply := NewFake("secret").Reply(Callback{ProviderTxId: "42", TransactionId: "tx"},
+		Result{Code: CodeAlreadyPaid, Message: "already paid"})
+	assert.Equal(t, fakeReply{ClickTransId: "42", MerchantTransId: "tx", Error: -4, ErrorNote: "already paid"}, reply)
+}
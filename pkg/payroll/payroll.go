@@ -0,0 +1,153 @@
+// Package payroll computes teachers' pay from delivered lesson minutes and renders payroll
+// statements as CSV and PDF.
+package payroll
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Statement is the pay of every teacher for the lessons delivered in a period, money is in
+// minor units.
+type Statement struct {
+	Period   string
+	Lines    []Line
+	Total    int64
+	LockedAt time.Time
+}
+
+// Line is the pay of a teacher for the lessons of a subject, SubstituteLessons are the
+// ones the teacher gave in place of another teacher.
+type Line struct {
+	Teacher           string
+	Subject           string
+	Lessons           int64
+	SubstituteLessons int64
+	Minutes           int64
+	HourlyRate        int64
+	Amount            int64
+}
+
+// Amount is the pay of minutes at the hourly rate, rounded half up to a minor unit.
+func Amount(minutes, hourlyRate int64) int64 {
+	return (minutes*hourlyRate + 30) / 60
+}
+
+// Hours turns minutes into hours.
+func Hours(minutes int64) float64 {
+	return float64(minutes) / 60
+}
+
+// Money renders minor units with two decimals.
+func Money(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+var csvHeader = []string{"teacher", "subject", "lessons", "substitute_lessons", "hours", "hourly_rate", "amount"}
+
+// WriteCSV writes the statement's lines and their total as CSV to w, money is in minor
+// units.
+func WriteCSV(w io.Writer, s Statement) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, l := range s.Lines {
+		record := []string{
+			l.Teacher,
+			l.Subject,
+			fmt.Sprint(l.Lessons),
+			fmt.Sprint(l.SubstituteLessons),
+			fmt.Sprintf("%.2f", Hours(l.Minutes)),
+			fmt.Sprint(l.HourlyRate),
+			fmt.Sprint(l.Amount),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	if err := cw.Write([]string{"total", "", "", "", "", "", fmt.Sprint(s.Total)}); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+const lineHeight = 7
+
+// RenderPDF writes the statement as a PDF to w. The built-in fonts only cover Latin-1,
+// other characters are replaced.
+func RenderPDF(w io.Writer, s Statement) error {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetTitle("Payroll statement - "+s.Period, true)
+	pdf.SetCreationDate(s.LockedAt)
+	pdf.SetAutoPageBreak(true, 15)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 6, tr(fmt.Sprintf("Locked %s - page %d", s.LockedAt.Format("2006-01-02 15:04"), pdf.PageNo())), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+	width, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	width -= left + right
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, tr("Payroll statement"), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(0, 6, tr(s.Period), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	columns := []struct {
+		title string
+		width float64
+		align string
+	}{
+		{"Teacher", (width - 145) / 2, "L"},
+		{"Subject", (width - 145) / 2, "L"},
+		{"Lessons", 25, "R"},
+		{"Substitutions", 30, "R"},
+		{"Hours", 25, "R"},
+		{"Hourly rate", 30, "R"},
+		{"Amount", 35, "R"},
+	}
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(230, 230, 230)
+	for _, c := range columns {
+		pdf.CellFormat(c.width, lineHeight, tr(c.title), "1", 0, c.align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 10)
+	for _, l := range s.Lines {
+		values := []string{
+			l.Teacher,
+			l.Subject,
+			fmt.Sprint(l.Lessons),
+			fmt.Sprint(l.SubstituteLessons),
+			fmt.Sprintf("%.2f", Hours(l.Minutes)),
+			Money(l.HourlyRate),
+			Money(l.Amount),
+		}
+		for i, c := range columns {
+			pdf.CellFormat(c.width, lineHeight, tr(values[i]), "1", 0, c.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(width-columns[len(columns)-1].width, lineHeight, tr("Total"), "1", 0, "R", true, 0, "")
+	pdf.CellFormat(columns[len(columns)-1].width, lineHeight, tr(Money(s.Total)), "1", 1, "R", true, 0, "")
+
+	return pdf.Output(w)
+}
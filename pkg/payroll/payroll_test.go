@@ -0,0 +1,56 @@
+package payroll
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAmount(t *testing.T) {
+	assert.Equal(t, int64(5000000), Amount(60, 5000000))
+	assert.Equal(t, int64(7500000), Amount(90, 5000000))
+	// 45 minutes at 1 per hour is 0.75, rounded up
+	assert.Equal(t, int64(1), Amount(45, 1))
+	assert.Equal(t, int64(0), Amount(29, 1))
+	assert.Equal(t, int64(0), Amount(0, 5000000))
+}
+
+func TestMoney(t *testing.T) {
+	assert.Equal(t, "0.00", Money(0))
+	assert.Equal(t, "0.05", Money(5))
+	assert.Equal(t, "1234.56", Money(123456))
+	assert.Equal(t, "-1.50", Money(-150))
+}
+
+func statement() Statement {
+	return Statement{
+		Period: "2024-09-01 - 2024-09-30",
+		Lines: []Line{
+			{Teacher: "Ali Valiyev", Subject: "Math", Lessons: 12, SubstituteLessons: 2, Minutes: 1080, HourlyRate: 5000000, Amount: 90000000},
+			{Teacher: "Ali Valiyev", Subject: "Physics, advanced", Lessons: 1, Minutes: 45, HourlyRate: 6000000, Amount: 4500000},
+		},
+		Total:    94500000,
+		LockedAt: time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteCSV(&buf, statement()))
+	assert.Equal(t, "teacher,subject,lessons,substitute_lessons,hours,hourly_rate,amount\n"+
+		"Ali Valiyev,Math,12,2,18.00,5000000,90000000\n"+
+		"Ali Valiyev,\"Physics, advanced\",1,0,0.75,6000000,4500000\n"+
+		"total,,,,,,94500000\n", buf.String())
+}
+
+func TestRenderPDF(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, RenderPDF(&buf, statement()))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+
+	buf.Reset()
+	assert.NoError(t, RenderPDF(&buf, Statement{Period: "empty"}))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+}
@@ -0,0 +1,112 @@
+// Package quiz checks quiz questions and grades the answers given to them.
+package quiz
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+)
+
+const (
+	Single   = "single"
+	Multiple = "multiple"
+	Numeric  = "numeric"
+	Text     = "text"
+)
+
+var ErrUnknownKind = errors.New("question kind must be single, multiple, numeric or text")
+
+// Question is a question with its right answer. Correct holds the indexes of the right
+// options of choice questions, Number and Tolerance the right answer of numeric ones and
+// Accepted the accepted answers of text ones.
+type Question struct {
+	Kind      string
+	Options   []string
+	Correct   []int
+	Number    float64
+	Tolerance float64
+	Accepted  []string
+	Points    float64
+}
+
+// Answer is a student's answer, only the field of the question's kind is read.
+type Answer struct {
+	Choices []int
+	Number  *float64
+	Text    string
+}
+
+// Check reports whether the question can be graded.
+func Check(q Question) error {
+	if q.Points <= 0 {
+		return errors.New("points must be positive")
+	}
+
+	switch q.Kind {
+	case Single, Multiple:
+		if len(q.Options) < 2 {
+			return errors.New("a choice question needs at least two options")
+		}
+		if len(q.Correct) == 0 {
+			return errors.New("a choice question needs a correct option")
+		}
+		if q.Kind == Single && len(q.Correct) != 1 {
+			return errors.New("a single choice question has exactly one correct option")
+		}
+		for _, i := range q.Correct {
+			if i < 0 || i >= len(q.Options) {
+				return fmt.Errorf("correct option %d is out of range", i)
+			}
+		}
+	case Numeric:
+		if q.Tolerance < 0 {
+			return errors.New("tolerance must not be negative")
+		}
+	case Text:
+		if len(q.Accepted) == 0 {
+			return errors.New("a text question needs an accepted answer")
+		}
+	default:
+		return ErrUnknownKind
+	}
+	return nil
+}
+
+// Grade tells whether the answer is right and the points it earns, a choice answer is right
+// only when it picks exactly the correct options and a text answer ignores case and spacing.
+func Grade(q Question, a Answer) (bool, float64) {
+	var right bool
+	switch q.Kind {
+	case Single, Multiple:
+		right = sameSet(q.Correct, a.Choices)
+	case Numeric:
+		right = a.Number != nil && math.Abs(*a.Number-q.Number) <= q.Tolerance
+	case Text:
+		text := normalize(a.Text)
+		right = text != "" && slices.ContainsFunc(q.Accepted, func(s string) bool { return normalize(s) == text })
+	}
+
+	if right {
+		return true, q.Points
+	}
+	return false, 0
+}
+
+// Shuffle puts the items in a random order.
+func Shuffle[T any](items []T) {
+	rand.Shuffle(len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+}
+
+func sameSet(a, b []int) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
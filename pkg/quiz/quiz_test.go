@@ -0,0 +1,50 @@
+package quiz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	assert.NoError(t, Check(Question{Kind: Single, Options: []string{"a", "b"}, Correct: []int{1}, Points: 1}))
+	assert.Error(t, Check(Question{Kind: Single, Options: []string{"a", "b"}, Correct: []int{0, 1}, Points: 1}))
+	assert.Error(t, Check(Question{Kind: Multiple, Options: []string{"a", "b"}, Correct: []int{2}, Points: 1}))
+	assert.Error(t, Check(Question{Kind: Text, Points: 1}))
+	assert.Error(t, Check(Question{Kind: Numeric, Points: 0}))
+	assert.ErrorIs(t, Check(Question{Kind: "essay", Points: 1}), ErrUnknownKind)
+}
+
+func TestGrade(t *testing.T) {
+	number := func(f float64) *float64 { return &f }
+	multiple := Question{Kind: Multiple, Options: []string{"a", "b", "c"}, Correct: []int{0, 2}, Points: 2}
+	numeric := Question{Kind: Numeric, Number: 3.14, Tolerance: 0.01, Points: 1}
+	text := Question{Kind: Text, Accepted: []string{"Tashkent"}, Points: 1}
+
+	cases := []struct {
+		question Question
+		answer   Answer
+		right    bool
+		points   float64
+	}{
+		{multiple, Answer{Choices: []int{2, 0}}, true, 2},
+		{multiple, Answer{Choices: []int{0}}, false, 0},
+		{multiple, Answer{Choices: []int{0, 1, 2}}, false, 0},
+		{numeric, Answer{Number: number(3.145)}, true, 1},
+		{numeric, Answer{Number: number(3.2)}, false, 0},
+		{numeric, Answer{}, false, 0},
+		{text, Answer{Text: "  tashkent "}, true, 1},
+		{text, Answer{Text: ""}, false, 0},
+	}
+	for _, c := range cases {
+		right, points := Grade(c.question, c.answer)
+		assert.Equal(t, c.right, right, "%+v", c.answer)
+		assert.Equal(t, c.points, points, "%+v", c.answer)
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	Shuffle(items)
+	assert.ElementsMatch(t, []int{1, 2, 3, 4, 5}, items)
+}
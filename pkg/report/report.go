@@ -0,0 +1,186 @@
+// Package report renders students' progress reports as PDF.
+package report
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Progress is everything a progress report shows about a student over a period.
+type Progress struct {
+	Student     Student
+	Period      string
+	Scale       string
+	Attendance  Attendance
+	Subjects    []Subject
+	Comments    []Comment
+	GeneratedAt time.Time
+}
+
+type Student struct {
+	Name       string
+	ExternalId string
+	Email      string
+	Phone      string
+	Age        int
+	IsActive   bool
+}
+
+// Attendance counts the student's lessons of the period by attendance status.
+type Attendance struct {
+	Lessons  int
+	Present  int
+	Late     int
+	Absent   int
+	Excused  int
+	Unmarked int
+}
+
+// Rate is the share of marked lessons the student attended, late ones included.
+func (a Attendance) Rate() (float64, bool) {
+	marked := a.Lessons - a.Unmarked
+	if marked <= 0 {
+		return 0, false
+	}
+	return float64(a.Present+a.Late) / float64(marked) * 100, true
+}
+
+// Subject is the student's grade of a subject, Grade is empty when nothing is scored.
+type Subject struct {
+	Name        string
+	Assessments int
+	Scored      int
+	Percent     float64
+	Grade       string
+}
+
+type Comment struct {
+	Date    string
+	Subject string
+	Teacher string
+	Title   string
+	Text    string
+}
+
+const (
+	lineHeight = 6
+	labelWidth = 45
+)
+
+// Render writes the report as a PDF to w. The built-in fonts only cover Latin-1, other
+// characters are replaced.
+func Render(w io.Writer, p Progress) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Progress report - "+p.Student.Name, true)
+	pdf.SetCreationDate(p.GeneratedAt)
+	pdf.SetAutoPageBreak(true, 15)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, lineHeight, tr(fmt.Sprintf("Generated %s - page %d", p.GeneratedAt.Format("2006-01-02 15:04"), pdf.PageNo())), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+	width, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	width -= left + right
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, tr("Progress report"), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(0, lineHeight, tr(p.Period), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	heading := func(title string) {
+		pdf.Ln(2)
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.CellFormat(0, 8, tr(title), "B", 1, "L", false, 0, "")
+		pdf.Ln(1)
+	}
+	field := func(label, value string) {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(labelWidth, lineHeight, tr(label), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, lineHeight, tr(value), "", 1, "L", false, 0, "")
+	}
+
+	heading("Student")
+	status := "active"
+	if !p.Student.IsActive {
+		status = "inactive"
+	}
+	field("Name", p.Student.Name)
+	field("External id", p.Student.ExternalId)
+	field("Email", p.Student.Email)
+	field("Phone", p.Student.Phone)
+	field("Age", fmt.Sprint(p.Student.Age))
+	field("Status", status)
+
+	heading("Attendance")
+	a := p.Attendance
+	field("Lessons", fmt.Sprint(a.Lessons))
+	field("Present", fmt.Sprint(a.Present))
+	field("Late", fmt.Sprint(a.Late))
+	field("Absent", fmt.Sprint(a.Absent))
+	field("Excused", fmt.Sprint(a.Excused))
+	field("Not marked", fmt.Sprint(a.Unmarked))
+	if rate, ok := a.Rate(); ok {
+		field("Attendance rate", fmt.Sprintf("%.1f%%", rate))
+	}
+
+	heading("Grades (" + p.Scale + ")")
+	if len(p.Subjects) == 0 {
+		pdf.SetFont("Helvetica", "I", 10)
+		pdf.CellFormat(0, lineHeight, tr("No assessments in this period."), "", 1, "L", false, 0, "")
+	} else {
+		columns := []struct {
+			title string
+			width float64
+			align string
+		}{
+			{"Subject", width - 100, "L"},
+			{"Assessments", 30, "R"},
+			{"Scored", 20, "R"},
+			{"Percent", 25, "R"},
+			{"Grade", 25, "C"},
+		}
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.SetFillColor(230, 230, 230)
+		for _, c := range columns {
+			pdf.CellFormat(c.width, 7, tr(c.title), "1", 0, c.align, true, 0, "")
+		}
+		pdf.Ln(-1)
+
+		pdf.SetFont("Helvetica", "", 10)
+		for _, s := range p.Subjects {
+			percent, grade := "-", "-"
+			if s.Scored > 0 {
+				percent, grade = fmt.Sprintf("%.2f%%", s.Percent), s.Grade
+			}
+			values := []string{s.Name, fmt.Sprint(s.Assessments), fmt.Sprint(s.Scored), percent, grade}
+			for i, c := range columns {
+				pdf.CellFormat(c.width, 7, tr(values[i]), "1", 0, c.align, false, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+	}
+
+	heading("Teacher comments")
+	if len(p.Comments) == 0 {
+		pdf.SetFont("Helvetica", "I", 10)
+		pdf.CellFormat(0, lineHeight, tr("No comments in this period."), "", 1, "L", false, 0, "")
+	}
+	for _, c := range p.Comments {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.MultiCell(0, lineHeight, tr(fmt.Sprintf("%s - %s, %s (%s)", c.Date, c.Subject, c.Title, c.Teacher)), "", "L", false)
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(0, lineHeight, tr(c.Text), "", "L", false)
+		pdf.Ln(2)
+	}
+
+	return pdf.Output(w)
+}
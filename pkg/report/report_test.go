@@ -0,0 +1,38 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttendanceRate(t *testing.T) {
+	rate, ok := Attendance{Lessons: 10, Present: 6, Late: 1, Absent: 1, Unmarked: 2}.Rate()
+	if assert.True(t, ok) {
+		assert.InDelta(t, 87.5, rate, 0.001)
+	}
+
+	_, ok = Attendance{Lessons: 3, Unmarked: 3}.Rate()
+	assert.False(t, ok)
+}
+
+func TestRender(t *testing.T) {
+	var buf bytes.Buffer
+	err := Render(&buf, Progress{
+		Student:    Student{Name: "Aziz Karimov", ExternalId: "ST-0042", Age: 15, IsActive: true},
+		Period:     "Term 1: 2024-09-02 - 2024-12-27",
+		Scale:      "5-point",
+		Attendance: Attendance{Lessons: 4, Present: 3, Absent: 1},
+		Subjects: []Subject{
+			{Name: "Math", Assessments: 3, Scored: 2, Percent: 87.5, Grade: "5"},
+			{Name: "History", Assessments: 1},
+		},
+		Comments:    []Comment{{Date: "2024-10-01", Subject: "Math", Teacher: "Olga Ivanova", Title: "Fractions", Text: "Good work, check step 3 again."}},
+		GeneratedAt: time.Date(2024, 12, 28, 10, 0, 0, 0, time.UTC),
+	})
+	if assert.NoError(t, err) {
+		assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+	}
+}
@@ -0,0 +1,817 @@
+// Package scheduler builds a weekly timetable from teaching requirements.
+//
+// Hard constraints (a teacher, a group or a room is never in two places at once,
+// teachers only teach their subjects inside their availability, rooms fit the group)
+// are never violated. Soft constraints (no gaps in a teacher's or group's day,
+// balanced days, a subject at most once a day per group) are minimised by a local search
+// that starts from a constructive solution.
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Period is one lesson slot of a school day, e.g. 09:00-10:20.
+type Period struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Window is a time range of a weekday.
+type Window struct {
+	Day   time.Weekday `json:"weekday"`
+	Start string       `json:"start"`
+	End   string       `json:"end"`
+}
+
+type Teacher struct {
+	Id         string
+	SubjectIds []string
+	// MaxLessons limits lessons per week, zero means no limit.
+	MaxLessons int
+	// Availability lists the windows the teacher can work in, empty means always.
+	Availability []Window
+}
+
+type Group struct {
+	Id   string
+	Size int
+}
+
+type Room struct {
+	Name string
+	// Capacity is the number of seats, zero means any group fits.
+	Capacity int
+}
+
+// Requirement asks for Lessons lessons a week of a subject for a group.
+// TeacherId pins the teacher, otherwise a qualified one is picked.
+type Requirement struct {
+	GroupId   string
+	SubjectId string
+	TeacherId string
+	Lessons   int
+}
+
+// Busy blocks a teacher, a room or a group for a window, e.g. by lessons which
+// are already in the timetable. Only one of the ids is expected to be set.
+type Busy struct {
+	TeacherId string
+	GroupId   string
+	Room      string
+	Window    Window
+}
+
+type Input struct {
+	Days         []time.Weekday
+	Periods      []Period
+	Teachers     []Teacher
+	Groups       []Group
+	Rooms        []Room
+	Requirements []Requirement
+	Busy         []Busy
+}
+
+type Options struct {
+	Seed int64
+	// Iterations of the local search, zero means a default depending on the input size.
+	Iterations int
+}
+
+type Lesson struct {
+	GroupId   string       `json:"group_id"`
+	SubjectId string       `json:"subject_id"`
+	TeacherId string       `json:"teacher_id"`
+	Room      string       `json:"room_name"`
+	Day       time.Weekday `json:"weekday"`
+	Period    int          `json:"period"`
+	Start     string       `json:"start"`
+	End       string       `json:"end"`
+}
+
+// Cost is the weighted sum of the soft constraint violations of a timetable.
+type Cost struct {
+	TeacherGaps    int `json:"teacher_gaps"`
+	GroupGaps      int `json:"group_gaps"`
+	Imbalance      int `json:"imbalance"`
+	SubjectRepeats int `json:"subject_repeats"`
+	Total          int `json:"total"`
+}
+
+const (
+	teacherGapWeight    = 3
+	groupGapWeight      = 2
+	imbalanceWeight     = 1
+	subjectRepeatWeight = 2
+)
+
+type Result struct {
+	Lessons []Lesson `json:"lessons"`
+	Cost    Cost     `json:"cost"`
+}
+
+var ErrUnsolvable = errors.New("no timetable satisfies the constraints")
+
+// unit is a single lesson to place, index fields point into the solver's slices.
+type unit struct {
+	group, subject, teacher int
+}
+
+type placement struct {
+	slot, room int
+}
+
+type solver struct {
+	in      Input
+	days    []time.Weekday
+	periods [][2]int
+	nSlots  int
+
+	teacherIdx map[string]int
+	groupIdx   map[string]int
+	subjects   []string
+	subjectIdx map[string]int
+
+	teacherOK  [][]bool // teacher -> slot -> can work
+	groupOK    [][]bool
+	roomOK     [][]bool
+	roomFits   [][]bool // group -> room
+	units      []unit
+	place      []placement
+	teacherAt  [][]int // teacher -> slot -> unit or -1
+	groupAt    [][]int
+	roomAt     [][]int
+	teacherCap []int
+
+	rnd *rand.Rand
+}
+
+// Solve builds a timetable satisfying every hard constraint of in, or returns an error
+// wrapping ErrUnsolvable which names the lesson that could not be placed.
+func Solve(in Input, opts Options) (Result, error) {
+	s, err := newSolver(in, opts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if err := s.assignTeachers(); err != nil {
+		return Result{}, err
+	}
+
+	if err := s.construct(); err != nil {
+		return Result{}, err
+	}
+
+	iterations := opts.Iterations
+	if iterations == 0 {
+		iterations = 200 * len(s.units)
+	}
+	s.improve(iterations)
+
+	return s.result(), nil
+}
+
+func newSolver(in Input, opts Options) (*solver, error) {
+	if len(in.Days) == 0 {
+		return nil, errors.New("no school days given")
+	}
+	if len(in.Periods) == 0 {
+		return nil, errors.New("no periods given")
+	}
+	if len(in.Rooms) == 0 {
+		return nil, errors.New("no rooms given")
+	}
+
+	s := &solver{
+		in:         in,
+		teacherIdx: map[string]int{},
+		groupIdx:   map[string]int{},
+		subjectIdx: map[string]int{},
+		rnd:        rand.New(rand.NewSource(opts.Seed)),
+	}
+
+	s.days = append(s.days, in.Days...)
+	sort.Slice(s.days, func(i, j int) bool { return s.days[i] < s.days[j] })
+	for i := 1; i < len(s.days); i++ {
+		if s.days[i] == s.days[i-1] {
+			return nil, fmt.Errorf("day %s is given twice", s.days[i])
+		}
+	}
+
+	for _, p := range in.Periods {
+		start, err := ParseClock(p.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := ParseClock(p.End)
+		if err != nil {
+			return nil, err
+		}
+		if end <= start {
+			return nil, fmt.Errorf("period %s-%s ends before it starts", p.Start, p.End)
+		}
+		s.periods = append(s.periods, [2]int{start, end})
+	}
+	sort.Slice(s.periods, func(i, j int) bool { return s.periods[i][0] < s.periods[j][0] })
+	for i := 1; i < len(s.periods); i++ {
+		if s.periods[i][0] < s.periods[i-1][1] {
+			return nil, errors.New("periods overlap")
+		}
+	}
+	s.nSlots = len(s.days) * len(s.periods)
+
+	for i, t := range in.Teachers {
+		s.teacherIdx[t.Id] = i
+		ok := make([]bool, s.nSlots)
+		for slot := range ok {
+			ok[slot] = len(t.Availability) == 0
+			for _, w := range t.Availability {
+				if in, err := s.slotInside(slot, w); err != nil {
+					return nil, err
+				} else if in {
+					ok[slot] = true
+					break
+				}
+			}
+		}
+		s.teacherOK = append(s.teacherOK, ok)
+		s.teacherCap = append(s.teacherCap, t.MaxLessons)
+	}
+
+	for i, g := range in.Groups {
+		s.groupIdx[g.Id] = i
+		s.groupOK = append(s.groupOK, allTrue(s.nSlots))
+		fits := make([]bool, len(in.Rooms))
+		for r, room := range in.Rooms {
+			fits[r] = room.Capacity == 0 || room.Capacity >= g.Size
+		}
+		s.roomFits = append(s.roomFits, fits)
+	}
+
+	roomIdx := map[string]int{}
+	for i, r := range in.Rooms {
+		roomIdx[r.Name] = i
+		s.roomOK = append(s.roomOK, allTrue(s.nSlots))
+	}
+
+	for _, b := range in.Busy {
+		var ok []bool
+		switch {
+		case b.TeacherId != "":
+			if i, found := s.teacherIdx[b.TeacherId]; found {
+				ok = s.teacherOK[i]
+			}
+		case b.GroupId != "":
+			if i, found := s.groupIdx[b.GroupId]; found {
+				ok = s.groupOK[i]
+			}
+		case b.Room != "":
+			if i, found := roomIdx[b.Room]; found {
+				ok = s.roomOK[i]
+			}
+		}
+		if ok == nil {
+			continue
+		}
+		for slot := range ok {
+			overlaps, err := s.slotOverlaps(slot, b.Window)
+			if err != nil {
+				return nil, err
+			}
+			if overlaps {
+				ok[slot] = false
+			}
+		}
+	}
+
+	for _, r := range in.Requirements {
+		if _, found := s.groupIdx[r.GroupId]; !found {
+			return nil, fmt.Errorf("group %s is not given", r.GroupId)
+		}
+		if r.Lessons <= 0 {
+			return nil, fmt.Errorf("group %s needs a positive number of lessons of subject %s", r.GroupId, r.SubjectId)
+		}
+		if _, found := s.subjectIdx[r.SubjectId]; !found {
+			s.subjectIdx[r.SubjectId] = len(s.subjects)
+			s.subjects = append(s.subjects, r.SubjectId)
+		}
+	}
+
+	return s, nil
+}
+
+func allTrue(n int) []bool {
+	ok := make([]bool, n)
+	for i := range ok {
+		ok[i] = true
+	}
+	return ok
+}
+
+func (s *solver) slotDay(slot int) time.Weekday {
+	return s.days[slot/len(s.periods)]
+}
+
+func (s *solver) slotPeriod(slot int) int {
+	return slot % len(s.periods)
+}
+
+func (s *solver) slotInside(slot int, w Window) (bool, error) {
+	start, end, err := parseWindow(w)
+	if err != nil {
+		return false, err
+	}
+	p := s.periods[s.slotPeriod(slot)]
+	return s.slotDay(slot) == w.Day && start <= p[0] && p[1] <= end, nil
+}
+
+func (s *solver) slotOverlaps(slot int, w Window) (bool, error) {
+	start, end, err := parseWindow(w)
+	if err != nil {
+		return false, err
+	}
+	p := s.periods[s.slotPeriod(slot)]
+	return s.slotDay(slot) == w.Day && start < p[1] && p[0] < end, nil
+}
+
+// assignTeachers picks one teacher per requirement, so that a group meets the same
+// teacher of a subject all week. The requirements with fewest candidates go first and
+// the least loaded candidate wins.
+func (s *solver) assignTeachers() error {
+	type candidates struct {
+		req      Requirement
+		teachers []int
+	}
+
+	var all []candidates
+	for _, r := range s.in.Requirements {
+		c := candidates{req: r}
+		for i, t := range s.in.Teachers {
+			if r.TeacherId != "" && t.Id != r.TeacherId {
+				continue
+			}
+			if teaches(t, r.SubjectId) {
+				c.teachers = append(c.teachers, i)
+			}
+		}
+		if len(c.teachers) == 0 {
+			if r.TeacherId != "" {
+				return fmt.Errorf("%w: teacher %s does not teach subject %s", ErrUnsolvable, r.TeacherId, r.SubjectId)
+			}
+			return fmt.Errorf("%w: nobody teaches subject %s", ErrUnsolvable, r.SubjectId)
+		}
+		all = append(all, c)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return len(all[i].teachers) < len(all[j].teachers) })
+
+	load := make([]int, len(s.in.Teachers))
+	for _, c := range all {
+		best := -1
+		for _, t := range c.teachers {
+			if s.teacherCap[t] > 0 && load[t]+c.req.Lessons > s.teacherCap[t] {
+				continue
+			}
+			if best == -1 || load[t] < load[best] {
+				best = t
+			}
+		}
+		if best == -1 {
+			return fmt.Errorf("%w: every teacher of subject %s is at the workload cap", ErrUnsolvable, c.req.SubjectId)
+		}
+		load[best] += c.req.Lessons
+
+		for i := 0; i < c.req.Lessons; i++ {
+			s.units = append(s.units, unit{
+				group:   s.groupIdx[c.req.GroupId],
+				subject: s.subjectIdx[c.req.SubjectId],
+				teacher: best,
+			})
+		}
+	}
+
+	return nil
+}
+
+func teaches(t Teacher, subjectId string) bool {
+	for _, id := range t.SubjectIds {
+		if id == subjectId {
+			return true
+		}
+	}
+	return false
+}
+
+func newTable(rows, cols int) [][]int {
+	table := make([][]int, rows)
+	for i := range table {
+		table[i] = make([]int, cols)
+		for j := range table[i] {
+			table[i][j] = -1
+		}
+	}
+	return table
+}
+
+// construct places every unit by backtracking, always continuing with the unit that
+// has the fewest feasible slots left.
+func (s *solver) construct() error {
+	s.teacherAt = newTable(len(s.in.Teachers), s.nSlots)
+	s.groupAt = newTable(len(s.in.Groups), s.nSlots)
+	s.roomAt = newTable(len(s.in.Rooms), s.nSlots)
+	s.place = make([]placement, len(s.units))
+	for i := range s.place {
+		s.place[i] = placement{slot: -1, room: -1}
+	}
+
+	budget := 100000
+	if !s.backtrack(len(s.units), &budget) {
+		for u := range s.units {
+			if s.place[u].slot == -1 && len(s.feasible(u)) == 0 {
+				return s.unplacedError(u)
+			}
+		}
+		return fmt.Errorf("%w: search gave up", ErrUnsolvable)
+	}
+
+	return nil
+}
+
+func (s *solver) unplacedError(u int) error {
+	un := s.units[u]
+	return fmt.Errorf("%w: no free slot for subject %s of group %s with teacher %s",
+		ErrUnsolvable, s.subjects[un.subject], s.in.Groups[un.group].Id, s.in.Teachers[un.teacher].Id)
+}
+
+func (s *solver) backtrack(left int, budget *int) bool {
+	if left == 0 {
+		return true
+	}
+	*budget--
+	if *budget < 0 {
+		return false
+	}
+
+	next, options := -1, []placement(nil)
+	for u := range s.units {
+		if s.place[u].slot != -1 {
+			continue
+		}
+		feasible := s.feasible(u)
+		if next == -1 || len(feasible) < len(options) {
+			next, options = u, feasible
+		}
+		if len(options) == 0 {
+			return false
+		}
+	}
+
+	sort.SliceStable(options, func(i, j int) bool {
+		return s.placementCost(next, options[i]) < s.placementCost(next, options[j])
+	})
+
+	for _, p := range options {
+		s.assign(next, p)
+		if s.backtrack(left-1, budget) {
+			return true
+		}
+		s.unassign(next)
+		if *budget < 0 {
+			return false
+		}
+	}
+
+	return false
+}
+
+// feasible lists the slots of unit u which keep every hard constraint, each with the
+// smallest free room which fits the group.
+func (s *solver) feasible(u int) []placement {
+	un := s.units[u]
+	var options []placement
+	for slot := 0; slot < s.nSlots; slot++ {
+		if !s.teacherOK[un.teacher][slot] || s.teacherAt[un.teacher][slot] != -1 {
+			continue
+		}
+		if !s.groupOK[un.group][slot] || s.groupAt[un.group][slot] != -1 {
+			continue
+		}
+		if room := s.freeRoom(un.group, slot); room != -1 {
+			options = append(options, placement{slot: slot, room: room})
+		}
+	}
+	return options
+}
+
+func (s *solver) freeRoom(group, slot int) int {
+	best := -1
+	for r, room := range s.in.Rooms {
+		if !s.roomFits[group][r] || !s.roomOK[r][slot] || s.roomAt[r][slot] != -1 {
+			continue
+		}
+		if best == -1 || room.Capacity < s.in.Rooms[best].Capacity {
+			best = r
+		}
+	}
+	return best
+}
+
+// placementCost estimates how much placing u at p hurts the soft constraints, it
+// orders the choices of the constructive search.
+func (s *solver) placementCost(u int, p placement) int {
+	un := s.units[u]
+	day := p.slot / len(s.periods)
+	period := s.slotPeriod(p.slot)
+	first := day * len(s.periods)
+
+	cost := 0
+	groupLessons, teacherLessons, teacherNear := 0, 0, false
+	for i := 0; i < len(s.periods); i++ {
+		if g := s.groupAt[un.group][first+i]; g != -1 {
+			groupLessons++
+			if s.units[g].subject == un.subject {
+				cost += 4 * subjectRepeatWeight
+			}
+		}
+		if s.teacherAt[un.teacher][first+i] != -1 {
+			teacherLessons++
+			if i == period-1 || i == period+1 {
+				teacherNear = true
+			}
+		}
+	}
+	cost += groupLessons
+	if teacherLessons > 0 && !teacherNear {
+		cost += teacherGapWeight
+	}
+	return cost
+}
+
+func (s *solver) assign(u int, p placement) {
+	un := s.units[u]
+	s.place[u] = p
+	s.teacherAt[un.teacher][p.slot] = u
+	s.groupAt[un.group][p.slot] = u
+	s.roomAt[p.room][p.slot] = u
+}
+
+func (s *solver) unassign(u int) {
+	un := s.units[u]
+	p := s.place[u]
+	s.teacherAt[un.teacher][p.slot] = -1
+	s.groupAt[un.group][p.slot] = -1
+	s.roomAt[p.room][p.slot] = -1
+	s.place[u] = placement{slot: -1, room: -1}
+}
+
+// improve runs a local search which moves single lessons to other feasible slots or
+// swaps two lessons of a group, keeping every change that does not raise the cost.
+func (s *solver) improve(iterations int) {
+	if len(s.units) == 0 {
+		return
+	}
+
+	cost := s.cost().Total
+	for i := 0; i < iterations && cost > 0; i++ {
+		u := s.rnd.Intn(len(s.units))
+		old := s.place[u]
+
+		if s.rnd.Intn(2) == 0 {
+			s.unassign(u)
+			options := s.feasible(u)
+			if len(options) == 0 {
+				s.assign(u, old)
+				continue
+			}
+			s.assign(u, options[s.rnd.Intn(len(options))])
+			if next := s.cost().Total; next <= cost {
+				cost = next
+				continue
+			}
+			s.unassign(u)
+			s.assign(u, old)
+			continue
+		}
+
+		v := s.groupAt[s.units[u].group][s.rnd.Intn(s.nSlots)]
+		if v == -1 || v == u {
+			continue
+		}
+		if s.trySwap(u, v) {
+			if next := s.cost().Total; next <= cost {
+				cost = next
+				continue
+			}
+			s.trySwap(u, v)
+		}
+	}
+}
+
+// trySwap exchanges the slots of two lessons of the same group when both stay feasible.
+func (s *solver) trySwap(u, v int) bool {
+	pu, pv := s.place[u], s.place[v]
+	s.unassign(u)
+	s.unassign(v)
+
+	uOK := s.teacherOK[s.units[u].teacher][pv.slot] && s.teacherAt[s.units[u].teacher][pv.slot] == -1
+	vOK := s.teacherOK[s.units[v].teacher][pu.slot] && s.teacherAt[s.units[v].teacher][pu.slot] == -1
+	if uOK && vOK {
+		s.assign(u, pv)
+		s.assign(v, pu)
+		return true
+	}
+
+	s.assign(u, pu)
+	s.assign(v, pv)
+	return false
+}
+
+func (s *solver) cost() Cost {
+	c := Cost{}
+
+	for t := range s.teacherAt {
+		gaps, imbalance := s.dayStats(s.teacherAt[t])
+		c.TeacherGaps += gaps
+		c.Imbalance += imbalance
+	}
+	for g := range s.groupAt {
+		gaps, imbalance := s.dayStats(s.groupAt[g])
+		c.GroupGaps += gaps
+		c.Imbalance += imbalance
+
+		for d := range s.days {
+			seen := map[int]int{}
+			for p := 0; p < len(s.periods); p++ {
+				if u := s.groupAt[g][d*len(s.periods)+p]; u != -1 {
+					seen[s.units[u].subject]++
+				}
+			}
+			for _, n := range seen {
+				c.SubjectRepeats += n - 1
+			}
+		}
+	}
+
+	c.Total = teacherGapWeight*c.TeacherGaps + groupGapWeight*c.GroupGaps +
+		imbalanceWeight*c.Imbalance + subjectRepeatWeight*c.SubjectRepeats
+	return c
+}
+
+// dayStats returns the number of idle periods between the first and the last lesson
+// of every day and how far the daily lesson counts are from an even spread.
+func (s *solver) dayStats(at []int) (gaps, imbalance int) {
+	counts := make([]int, len(s.days))
+	total := 0
+	for d := range s.days {
+		first, last := -1, -1
+		for p := 0; p < len(s.periods); p++ {
+			if at[d*len(s.periods)+p] != -1 {
+				if first == -1 {
+					first = p
+				}
+				last = p
+				counts[d]++
+			}
+		}
+		if first != -1 {
+			gaps += last - first + 1 - counts[d]
+		}
+		total += counts[d]
+	}
+
+	if total == 0 {
+		return gaps, 0
+	}
+	low := total / len(s.days)
+	high := low
+	if total%len(s.days) != 0 {
+		high++
+	}
+	for _, n := range counts {
+		if n > high {
+			imbalance += n - high
+		} else if n < low {
+			imbalance += low - n
+		}
+	}
+	return gaps, imbalance
+}
+
+func (s *solver) result() Result {
+	res := Result{Cost: s.cost()}
+	for u, un := range s.units {
+		p := s.place[u]
+		period := s.periods[s.slotPeriod(p.slot)]
+		res.Lessons = append(res.Lessons, Lesson{
+			GroupId:   s.in.Groups[un.group].Id,
+			SubjectId: s.subjects[un.subject],
+			TeacherId: s.in.Teachers[un.teacher].Id,
+			Room:      s.in.Rooms[p.room].Name,
+			Day:       s.slotDay(p.slot),
+			Period:    s.slotPeriod(p.slot) + 1,
+			Start:     FormatClock(period[0]),
+			End:       FormatClock(period[1]),
+		})
+	}
+
+	sort.SliceStable(res.Lessons, func(i, j int) bool {
+		a, b := res.Lessons[i], res.Lessons[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		return a.GroupId < b.GroupId
+	})
+	return res
+}
+
+func parseWindow(w Window) (int, int, error) {
+	start, err := ParseClock(w.Start)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := ParseClock(w.End)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// ParseClock parses "15:04" into minutes since midnight.
+func ParseClock(clock string) (int, error) {
+	parts := strings.Split(clock, ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("time %q is not in HH:MM format", clock)
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil || hours < 0 || hours > 24 {
+		return 0, fmt.Errorf("time %q is not in HH:MM format", clock)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes < 0 || minutes > 59 || hours*60+minutes > 24*60 {
+		return 0, fmt.Errorf("time %q is not in HH:MM format", clock)
+	}
+	return hours*60 + minutes, nil
+}
+
+// AtClock returns the clock time of the day in the local time zone, 24:00 is the
+// midnight which ends the day.
+func AtClock(day time.Time, clock string) (time.Time, error) {
+	minutes, err := ParseClock(clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, minutes, 0, 0, time.Local), nil
+}
+
+// LeaveWindows blocks every day of a leave from start to end that falls into [from, to),
+// in the time zone of from. The timetable repeats weekly so the weekday is blocked in
+// every week. Days the leave only touches for less than a minute are not blocked.
+func LeaveWindows(start, end, from, to time.Time) []Window {
+	start, end = start.In(from.Location()), end.In(from.Location())
+
+	var windows []Window
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		dayStart, dayEnd := day, day.AddDate(0, 0, 1)
+		if !start.Before(dayEnd) || !end.After(dayStart) {
+			continue
+		}
+
+		window := Window{Day: day.Weekday(), Start: "00:00", End: "24:00"}
+		if start.After(dayStart) {
+			window.Start = start.Format("15:04")
+		}
+		if end.Before(dayEnd) {
+			window.End = end.Format("15:04")
+		}
+		if window.Start != window.End {
+			windows = append(windows, window)
+		}
+	}
+	return windows
+}
+
+// BusyWindow turns a lesson from start to end into a weekly window in the local time
+// zone, a lesson running over midnight blocks the rest of its first day. It reports
+// false for a lesson shorter than a minute, which blocks nothing.
+func BusyWindow(start, end time.Time) (Window, bool) {
+	start, end = start.In(time.Local), end.In(time.Local)
+
+	window := Window{Day: start.Weekday(), Start: start.Format("15:04"), End: end.Format("15:04")}
+	if end.YearDay() != start.YearDay() || end.Year() != start.Year() {
+		window.End = "24:00"
+	}
+	return window, window.Start != window.End
+}
+
+// FormatClock formats minutes since midnight as "15:04".
+func FormatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
@@ -0,0 +1,263 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	periods  = []Period{{"08:30", "09:50"}, {"10:00", "11:20"}, {"11:30", "12:50"}, {"14:00", "15:20"}}
+)
+
+func schoolInput() Input {
+	in := Input{
+		Days:    weekdays,
+		Periods: periods,
+		Teachers: []Teacher{
+			{Id: "math-1", SubjectIds: []string{"math"}},
+			{Id: "math-2", SubjectIds: []string{"math"}},
+			{Id: "physics", SubjectIds: []string{"physics"}},
+			{Id: "english", SubjectIds: []string{"english"}, Availability: []Window{
+				{Day: time.Monday, Start: "08:00", End: "13:00"},
+				{Day: time.Wednesday, Start: "08:00", End: "13:00"},
+				{Day: time.Friday, Start: "08:00", End: "13:00"},
+			}},
+		},
+		Rooms: []Room{{Name: "101", Capacity: 30}, {Name: "102", Capacity: 20}},
+	}
+
+	for i := 0; i < 3; i++ {
+		group := fmt.Sprintf("group-%d", i)
+		in.Groups = append(in.Groups, Group{Id: group, Size: 12 + 6*i})
+		in.Requirements = append(in.Requirements,
+			Requirement{GroupId: group, SubjectId: "math", Lessons: 4},
+			Requirement{GroupId: group, SubjectId: "physics", Lessons: 3},
+			Requirement{GroupId: group, SubjectId: "english", Lessons: 2},
+		)
+	}
+	return in
+}
+
+func assertHardConstraints(t *testing.T, in Input, res Result) {
+	t.Helper()
+
+	type key struct {
+		who  string
+		day  time.Weekday
+		slot int
+	}
+	busy := map[key]bool{}
+	sizes := map[string]int{}
+	for _, g := range in.Groups {
+		sizes[g.Id] = g.Size
+	}
+	capacity := map[string]int{}
+	for _, r := range in.Rooms {
+		capacity[r.Name] = r.Capacity
+	}
+
+	for _, l := range res.Lessons {
+		for _, who := range []string{"t:" + l.TeacherId, "g:" + l.GroupId, "r:" + l.Room} {
+			k := key{who, l.Day, l.Period}
+			assert.False(t, busy[k], "%s is double booked on %s period %d", who, l.Day, l.Period)
+			busy[k] = true
+		}
+		if capacity[l.Room] > 0 {
+			assert.LessOrEqual(t, sizes[l.GroupId], capacity[l.Room])
+		}
+		if l.TeacherId == "english" {
+			assert.Contains(t, []time.Weekday{time.Monday, time.Wednesday, time.Friday}, l.Day)
+			assert.NotEqual(t, "14:00", l.Start)
+		}
+	}
+}
+
+func TestSolve(t *testing.T) {
+	in := schoolInput()
+
+	res, err := Solve(in, Options{Seed: 1})
+	if assert.NoError(t, err) {
+		assert.Len(t, res.Lessons, 27)
+		assertHardConstraints(t, in, res)
+
+		mathTeachers := map[string]string{}
+		for _, l := range res.Lessons {
+			if l.SubjectId == "math" {
+				if teacher, ok := mathTeachers[l.GroupId]; ok {
+					assert.Equal(t, teacher, l.TeacherId, "a group keeps one teacher per subject")
+				}
+				mathTeachers[l.GroupId] = l.TeacherId
+			}
+		}
+	}
+}
+
+func TestSolveImprovesSoftConstraints(t *testing.T) {
+	in := schoolInput()
+
+	constructed, err := Solve(in, Options{Seed: 7, Iterations: -1})
+	if !assert.NoError(t, err) {
+		return
+	}
+	improved, err := Solve(in, Options{Seed: 7})
+	if assert.NoError(t, err) {
+		assert.LessOrEqual(t, improved.Cost.Total, constructed.Cost.Total)
+		assertHardConstraints(t, in, improved)
+	}
+}
+
+func TestSolveRespectsBusy(t *testing.T) {
+	in := schoolInput()
+	for _, day := range weekdays {
+		in.Busy = append(in.Busy, Busy{Room: "101", Window: Window{Day: day, Start: "08:00", End: "18:00"}})
+	}
+
+	_, err := Solve(in, Options{Seed: 1})
+	assert.True(t, errors.Is(err, ErrUnsolvable), "group-2 does not fit room 102")
+
+	in.Busy = []Busy{
+		{Room: "101", Window: Window{Day: time.Monday, Start: "08:00", End: "18:00"}},
+		{Room: "101", Window: Window{Day: time.Tuesday, Start: "08:00", End: "10:00"}},
+		{TeacherId: "physics", Window: Window{Day: time.Thursday, Start: "10:30", End: "18:00"}},
+	}
+	res, err := Solve(in, Options{Seed: 1})
+	if assert.NoError(t, err) {
+		assertHardConstraints(t, in, res)
+		for _, l := range res.Lessons {
+			if l.Room == "101" {
+				assert.NotEqual(t, time.Monday, l.Day)
+				assert.False(t, l.Day == time.Tuesday && l.Period == 1)
+			}
+			if l.TeacherId == "physics" && l.Day == time.Thursday {
+				assert.Equal(t, 1, l.Period)
+			}
+		}
+	}
+}
+
+func TestSolveUnsolvable(t *testing.T) {
+	in := schoolInput()
+	in.Requirements = append(in.Requirements, Requirement{GroupId: "group-0", SubjectId: "chemistry", Lessons: 1})
+
+	_, err := Solve(in, Options{})
+	assert.True(t, errors.Is(err, ErrUnsolvable))
+
+	in = schoolInput()
+	in.Requirements[0].Lessons = 21
+
+	_, err = Solve(in, Options{})
+	assert.True(t, errors.Is(err, ErrUnsolvable))
+}
+
+func TestParseClock(t *testing.T) {
+	minutes, err := ParseClock("09:05")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 545, minutes)
+		assert.Equal(t, "09:05", FormatClock(minutes))
+	}
+
+	for _, clock := range []string{"9", "25:00", "10:60", "ab:cd"} {
+		_, err := ParseClock(clock)
+		assert.Error(t, err, clock)
+	}
+}
+
+func TestSolveWindowEndingAtMidnight(t *testing.T) {
+	in := Input{
+		Days:    []time.Weekday{time.Monday},
+		Periods: []Period{{"22:40", "24:00"}},
+		Teachers: []Teacher{{Id: "math", SubjectIds: []string{"math"}, Availability: []Window{
+			{Day: time.Monday, Start: "18:00", End: "24:00"},
+		}}},
+		Rooms:        []Room{{Name: "101"}},
+		Groups:       []Group{{Id: "evening"}},
+		Requirements: []Requirement{{GroupId: "evening", SubjectId: "math", Lessons: 1}},
+	}
+
+	res, err := Solve(in, Options{Seed: 1})
+	if assert.NoError(t, err) && assert.Len(t, res.Lessons, 1) {
+		assert.Equal(t, "22:40", res.Lessons[0].Start)
+		assert.Equal(t, "24:00", res.Lessons[0].End)
+	}
+
+	minutes, err := ParseClock("24:00")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 24*60, minutes)
+	}
+	_, err = ParseClock("24:01")
+	assert.Error(t, err)
+}
+
+func TestAtClock(t *testing.T) {
+	day := time.Date(2024, 9, 2, 0, 0, 0, 0, time.Local)
+
+	from, err := AtClock(day, "08:30")
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2024, 9, 2, 8, 30, 0, 0, time.Local), from)
+	}
+
+	to, err := AtClock(day, "24:00")
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2024, 9, 3, 0, 0, 0, 0, time.Local), to)
+	}
+
+	_, err = AtClock(day, "24:30")
+	assert.Error(t, err)
+}
+
+// at is a clock time of September 2024 in the local time zone, the 2nd is a Monday.
+func at(day, hour, min int) time.Time {
+	return time.Date(2024, 9, day, hour, min, 0, 0, time.Local)
+}
+
+func TestLeaveWindows(t *testing.T) {
+	from, to := at(2, 0, 0), at(9, 0, 0)
+	cases := []struct {
+		name       string
+		start, end time.Time
+		want       []Window
+	}{
+		{"covers the range", at(1, 12, 0), at(10, 12, 0), []Window{
+			{time.Monday, "00:00", "24:00"}, {time.Tuesday, "00:00", "24:00"}, {time.Wednesday, "00:00", "24:00"},
+			{time.Thursday, "00:00", "24:00"}, {time.Friday, "00:00", "24:00"}, {time.Saturday, "00:00", "24:00"},
+			{time.Sunday, "00:00", "24:00"},
+		}},
+		{"over midnight", at(4, 10, 0), at(5, 12, 30), []Window{
+			{time.Wednesday, "10:00", "24:00"}, {time.Thursday, "00:00", "12:30"},
+		}},
+		{"ends at midnight", at(2, 0, 0), at(3, 0, 0), []Window{{time.Monday, "00:00", "24:00"}}},
+		{"starts before the range", at(1, 8, 0), at(2, 9, 0), []Window{{time.Monday, "00:00", "09:00"}}},
+		{"ends after the range", at(8, 22, 0), at(9, 10, 0), []Window{{time.Sunday, "22:00", "24:00"}}},
+		{"before the range", at(1, 8, 0), at(2, 0, 0), nil},
+		{"after the range", at(9, 0, 0), at(9, 10, 0), nil},
+		{"no length", at(4, 10, 0), at(4, 10, 0), nil},
+		{"shorter than a minute", at(4, 10, 0), at(4, 10, 0).Add(30 * time.Second), nil},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, LeaveWindows(c.start, c.end, from, to), c.name)
+	}
+}
+
+func TestBusyWindow(t *testing.T) {
+	cases := []struct {
+		name       string
+		start, end time.Time
+		want       Window
+		ok         bool
+	}{
+		{"lesson", at(2, 8, 30), at(2, 9, 50), Window{time.Monday, "08:30", "09:50"}, true},
+		{"over midnight", at(2, 23, 0), at(3, 0, 30), Window{time.Monday, "23:00", "24:00"}, true},
+		{"ends at midnight", at(8, 22, 40), at(9, 0, 0), Window{time.Sunday, "22:40", "24:00"}, true},
+		{"no length", at(2, 10, 0), at(2, 10, 0), Window{time.Monday, "10:00", "10:00"}, false},
+	}
+	for _, c := range cases {
+		window, ok := BusyWindow(c.start, c.end)
+		assert.Equal(t, c.ok, ok, c.name)
+		assert.Equal(t, c.want, window, c.name)
+	}
+}
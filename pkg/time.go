@@ -0,0 +1,105 @@
+package pkg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidTime     = errors.New("time is not valid, use RFC 3339 like 2006-01-02T15:04:05+05:00")
+	ErrInvalidTimezone = errors.New("timezone is not valid, use an IANA name like Asia/Tashkent")
+)
+
+type locationKey struct{}
+
+// inputLayouts are the accepted formats of times sent without an offset, they are read in
+// the user's time zone.
+var inputLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// WithLocation returns a context whose times are rendered and read in loc.
+func WithLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, locationKey{}, loc)
+}
+
+// Location returns the user's time zone set by WithLocation, or else the school's one
+// which is the process' local time zone.
+func Location(ctx context.Context) *time.Location {
+	if loc, ok := ctx.Value(locationKey{}).(*time.Location); ok && loc != nil {
+		return loc
+	}
+	return time.Local
+}
+
+// FormatTime renders t as RFC 3339 in the context's time zone.
+func FormatTime(ctx context.Context, t time.Time) string {
+	return t.In(Location(ctx)).Format(time.RFC3339)
+}
+
+// ParseTime reads an RFC 3339 time, or a time without an offset in the context's time zone.
+func ParseTime(ctx context.Context, s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range inputLayouts {
+		if t, err := time.ParseInLocation(layout, s, Location(ctx)); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q: %w", s, ErrInvalidTime)
+}
+
+// NormalizeTime turns an input time into RFC 3339 so that the database does not read it
+// in its own time zone. Empty strings are left as they are.
+func NormalizeTime(ctx context.Context, s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	t, err := ParseTime(ctx, s)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(time.RFC3339), nil
+}
+
+// CheckTimezone reports whether name is a known IANA time zone, an empty name stands for
+// the school's one.
+func CheckTimezone(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("%q: %w", name, ErrInvalidTimezone)
+	}
+	return nil
+}
+
+type timeText struct {
+	ctx context.Context
+	dst *string
+}
+
+func (t timeText) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*t.dst = ""
+	case time.Time:
+		*t.dst = FormatTime(t.ctx, v)
+	default:
+		return fmt.Errorf("cannot scan %T into a time", src)
+	}
+	return nil
+}
+
+// TimeText scans a timestamptz column into dst as RFC 3339 in the context's time zone,
+// NULL becomes an empty string.
+func TimeText(ctx context.Context, dst *string) sql.Scanner {
+	return timeText{ctx: ctx, dst: dst}
+}
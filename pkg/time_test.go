@@ -0,0 +1,46 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTime(t *testing.T) {
+	tashkent, err := time.LoadLocation("Asia/Tashkent")
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctx := WithLocation(context.Background(), tashkent)
+
+	got, err := ParseTime(ctx, "2024-09-02 09:00:00")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "2024-09-02T04:00:00Z", got.UTC().Format(time.RFC3339))
+	}
+
+	got, err = ParseTime(ctx, "2024-09-02T09:00:00+03:00")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "2024-09-02T06:00:00Z", got.UTC().Format(time.RFC3339))
+	}
+
+	_, err = ParseTime(ctx, "02.09.2024")
+	assert.Error(t, err)
+}
+
+func TestTimeText(t *testing.T) {
+	tashkent, err := time.LoadLocation("Asia/Tashkent")
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctx := WithLocation(context.Background(), tashkent)
+
+	var s string
+	if assert.NoError(t, TimeText(ctx, &s).Scan(time.Date(2024, 9, 2, 4, 0, 0, 0, time.UTC))) {
+		assert.Equal(t, "2024-09-02T09:00:00+05:00", s)
+	}
+	if assert.NoError(t, TimeText(ctx, &s).Scan(nil)) {
+		assert.Empty(t, s)
+	}
+}
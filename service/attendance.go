@@ -0,0 +1,105 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/storage"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var ErrNotLessonTeacher = errors.New("only the lesson's teacher can do this")
+
+type attendanceService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewAttendanceService(storage storage.IStorage, logger logger.ILogger) attendanceService {
+	return attendanceService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (s attendanceService) MarkBulk(ctx context.Context, req models.MarkAttendanceRequest, teacherId string) error {
+	if len(req.Records) == 0 {
+		return errors.New("no attendance records given")
+	}
+
+	for i, record := range req.Records {
+		switch record.Status {
+		case config.ATTENDANCE_PRESENT, config.ATTENDANCE_LATE, config.ATTENDANCE_ABSENT, config.ATTENDANCE_EXCUSED:
+		default:
+			return fmt.Errorf("status %q is not valid", record.Status)
+		}
+
+		checkInTime, err := pkg.NormalizeTime(ctx, record.CheckInTime)
+		if err != nil {
+			return err
+		}
+		req.Records[i].CheckInTime = checkInTime
+	}
+
+	lessonId, err := uuid.Parse(req.TimeTableId)
+	if err != nil {
+		return err
+	}
+
+	lesson, err := s.storage.TimeStorage().GetTime(ctx, lessonId)
+	if err != nil {
+		s.logger.Error("failed to get a lesson: ", logger.Error(err))
+		return err
+	}
+
+	if lesson.TeacherId.String() != teacherId {
+		return ErrNotLessonTeacher
+	}
+
+	if err := s.storage.AttendanceStorage().MarkBulk(ctx, req, teacherId); err != nil {
+		s.logger.Error("failed to mark attendance: ", logger.Error(err))
+		return err
+	}
+
+	invalidateDashboard(ctx, s.storage, s.logger)
+	return nil
+}
+
+func (s attendanceService) GetByLesson(ctx context.Context, timeTableId string) (models.GetLessonAttendanceResponse, error) {
+	resp, err := s.storage.AttendanceStorage().GetByLesson(ctx, timeTableId)
+	if err != nil {
+		s.logger.Error("failed to get lesson's attendance: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+// GetStudentAttendance returns the student's attendance of the lessons held from fromDate
+// to toDate.
+func (s attendanceService) GetStudentAttendance(ctx context.Context, studentId, fromDate, toDate string) (models.StudentAttendanceResponse, error) {
+	if err := checkDates(fromDate, toDate); err != nil {
+		return models.StudentAttendanceResponse{}, err
+	}
+	from, to, err := dayRange(fromDate, toDate)
+	if err != nil {
+		return models.StudentAttendanceResponse{}, err
+	}
+
+	summary, err := s.storage.AttendanceStorage().GetSummary(ctx, studentId, from, to)
+	if err != nil {
+		s.logger.Error("failed to get attendance summary: ", logger.Error(err))
+		return models.StudentAttendanceResponse{}, err
+	}
+	lessons, err := s.storage.TimeStorage().GetStudentLessons(ctx, studentId, from, to, true)
+	if err != nil {
+		s.logger.Error("failed to get student's lessons: ", logger.Error(err))
+		return models.StudentAttendanceResponse{}, err
+	}
+
+	return models.StudentAttendanceResponse{Summary: summary, Lessons: lessons}, nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/domain"
+	"backend_course/lms/pkg/logger"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkBulk(t *testing.T) {
+	lesson := domain.TimeTable{Id: uuid.New(), TeacherId: uuid.New()}
+	attendance := &fakeAttendance{}
+	s := NewAttendanceService(fakeStorage{attendance: attendance, lessons: &fakeLessons{lesson: lesson}}, logger.New("test"))
+	ctx := context.Background()
+	teacherId := lesson.TeacherId.String()
+
+	record := func(status, checkIn string) models.MarkAttendanceRequest {
+		return models.MarkAttendanceRequest{
+			TimeTableId: lesson.Id.String(),
+			Records:     []models.AttendanceRecord{{StudentId: uuid.NewString(), Status: status, CheckInTime: checkIn}},
+		}
+	}
+
+	assert.Error(t, s.MarkBulk(ctx, models.MarkAttendanceRequest{TimeTableId: lesson.Id.String()}, teacherId))
+	assert.Error(t, s.MarkBulk(ctx, record("sleeping", ""), teacherId))
+	assert.Error(t, s.MarkBulk(ctx, record(config.ATTENDANCE_LATE, "at nine"), teacherId))
+	assert.ErrorIs(t, s.MarkBulk(ctx, record(config.ATTENDANCE_PRESENT, ""), uuid.NewString()), ErrNotLessonTeacher)
+	assert.Empty(t, attendance.marked)
+
+	// check-in times are stored with their offset
+	if assert.NoError(t, s.MarkBulk(ctx, record(config.ATTENDANCE_LATE, "2024-09-02T08:40:00+05:00"), teacherId)) &&
+		assert.Len(t, attendance.marked, 1) {
+		assert.Equal(t, "2024-09-02T08:40:00+05:00", attendance.marked[0].Records[0].CheckInTime)
+	}
+	for _, status := range []string{config.ATTENDANCE_PRESENT, config.ATTENDANCE_ABSENT, config.ATTENDANCE_EXCUSED} {
+		assert.NoError(t, s.MarkBulk(ctx, record(status, ""), teacherId), status)
+	}
+}
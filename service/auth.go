@@ -3,6 +3,7 @@ package service
 import (
 	"backend_course/lms/api/models"
 	"backend_course/lms/config"
+	"backend_course/lms/domain"
 	"backend_course/lms/pkg"
 	"backend_course/lms/pkg/check"
 	"backend_course/lms/pkg/jwt"
@@ -43,8 +44,73 @@ func (s authService) Login(ctx context.Context, req models.LoginRequest) (models
 	}
 
 	m := make(map[interface{}]interface{})
-	m["user_id"] = teacher.Id
+	m["user_id"] = teacher.Id.String()
 	m["user_role"] = config.TEACHER_TYPE
+	if timezone, ok := teacher.Timezone.Get(); ok {
+		m["timezone"] = timezone
+	}
+	accessToken, refreshToken, err := jwt.GenJWT(m)
+	if err != nil {
+		s.logger.Error("failed to get access and refresh token: ", logger.Error(err))
+		return resp, err
+	}
+	resp.AccessToken = accessToken
+	resp.RefreshToken = refreshToken
+
+	return resp, nil
+}
+
+func (s authService) StudentLogin(ctx context.Context, req models.LoginRequest) (models.LoginResponse, error) {
+	resp := models.LoginResponse{}
+
+	student, err := s.storage.StudentStorage().GetStudentByLogin(ctx, req.Login)
+	if err != nil {
+		s.logger.Error("failed to get student by login: ", logger.Error(err))
+		return resp, err
+	}
+
+	if err = pkg.CompareHashAndPassword(student.Password, req.Password); err != nil {
+		s.logger.Error("password is not match: ", logger.Error(err))
+		return resp, errors.New("password doesn't match")
+	}
+
+	m := make(map[interface{}]interface{})
+	m["user_id"] = student.Id.String()
+	m["user_role"] = config.STUDENT_TYPE
+	if timezone, ok := student.Timezone.Get(); ok {
+		m["timezone"] = timezone
+	}
+	accessToken, refreshToken, err := jwt.GenJWT(m)
+	if err != nil {
+		s.logger.Error("failed to get access and refresh token: ", logger.Error(err))
+		return resp, err
+	}
+	resp.AccessToken = accessToken
+	resp.RefreshToken = refreshToken
+
+	return resp, nil
+}
+
+func (s authService) GuardianLogin(ctx context.Context, req models.LoginRequest) (models.LoginResponse, error) {
+	resp := models.LoginResponse{}
+
+	guardian, err := s.storage.GuardianStorage().GetGuardianByLogin(ctx, req.Login)
+	if err != nil {
+		s.logger.Error("failed to get guardian by login: ", logger.Error(err))
+		return resp, err
+	}
+
+	if err = pkg.CompareHashAndPassword(guardian.Password, req.Password); err != nil {
+		s.logger.Error("password is not match: ", logger.Error(err))
+		return resp, errors.New("password doesn't match")
+	}
+
+	m := make(map[interface{}]interface{})
+	m["user_id"] = guardian.Id
+	m["user_role"] = config.GUARDIAN_TYPE
+	if guardian.Timezone != "" {
+		m["timezone"] = guardian.Timezone
+	}
 	accessToken, refreshToken, err := jwt.GenJWT(m)
 	if err != nil {
 		s.logger.Error("failed to get access and refresh token: ", logger.Error(err))
@@ -77,11 +143,10 @@ func (s authService) TeacherRegister(ctx context.Context, req models.RegisterReq
 	return nil
 }
 
-func (s authService) TeacherRegisterConfirm(ctx context.Context, req models.RegisterConfirmRequest) error {
-	code := s.storage.Redis().Get(ctx, req.AddTeacher.Email)
-	resultCode := cast.ToInt(code)
-	if resultCode == req.Code {
-		_, err := s.storage.TeacherStorage().Create(ctx, req.AddTeacher)
+func (s authService) TeacherRegisterConfirm(ctx context.Context, teacher domain.Teacher, code int) error {
+	resultCode := cast.ToInt(s.storage.Redis().Get(ctx, teacher.Email))
+	if resultCode == code {
+		_, err := s.storage.TeacherStorage().Create(ctx, teacher)
 
 		if err != nil {
 			s.logger.Error("failed to create a new teacher: ", logger.Error(err))
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/pkg/scheduler"
+	"backend_course/lms/storage"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type availabilityService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewAvailabilityService(storage storage.IStorage, logger logger.ILogger) availabilityService {
+	return availabilityService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (s availabilityService) SetWindows(ctx context.Context, teacherId string, req models.TeacherAvailability) error {
+	for i, w := range req.Windows {
+		if w.Weekday < 0 || w.Weekday > 6 {
+			return fmt.Errorf("weekday %d is not valid, use 0 (Sunday) to 6 (Saturday)", w.Weekday)
+		}
+		start, err := scheduler.ParseClock(w.Start)
+		if err != nil {
+			return err
+		}
+		end, err := scheduler.ParseClock(w.End)
+		if err != nil {
+			return err
+		}
+		if end <= start {
+			return fmt.Errorf("window %s-%s ends before it starts", w.Start, w.End)
+		}
+		req.Windows[i].TeacherId = teacherId
+	}
+
+	if err := s.storage.AvailabilityStorage().SetWindows(ctx, teacherId, req.Windows); err != nil {
+		s.logger.Error("failed to set teacher's availability: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s availabilityService) GetWindows(ctx context.Context, teacherId string) (models.TeacherAvailability, error) {
+	windows, err := s.storage.AvailabilityStorage().GetWindows(ctx, teacherId)
+	if err != nil {
+		s.logger.Error("failed to get teacher's availability: ", logger.Error(err))
+		return models.TeacherAvailability{}, err
+	}
+	return models.TeacherAvailability{Windows: windows}, nil
+}
+
+func (s availabilityService) RequestLeave(ctx context.Context, leave models.AddLeave) (string, error) {
+	from, err := pkg.ParseTime(ctx, leave.FromDate)
+	if err != nil {
+		return "", fmt.Errorf("from_date: %w", err)
+	}
+	to, err := pkg.ParseTime(ctx, leave.ToDate)
+	if err != nil {
+		return "", fmt.Errorf("to_date: %w", err)
+	}
+	if !from.Before(to) {
+		return "", errors.New("leave ends before it starts")
+	}
+	leave.FromDate, leave.ToDate = from.Format(time.RFC3339), to.Format(time.RFC3339)
+
+	id, err := s.storage.AvailabilityStorage().CreateLeave(ctx, leave)
+	if err != nil {
+		s.logger.Error("failed to create a leave: ", logger.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+func (s availabilityService) GetLeaves(ctx context.Context, req models.GetAllLeavesRequest) (models.GetAllLeavesResponse, error) {
+	resp, err := s.storage.AvailabilityStorage().GetLeaves(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get leaves: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+// EachLeave hands the leaves of the request to fn as they are read.
+func (s availabilityService) EachLeave(ctx context.Context, req models.GetAllLeavesRequest, fn func(models.Leave) error) error {
+	if err := s.storage.AvailabilityStorage().EachLeave(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export leaves: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ReviewLeave approves or rejects a pending leave, an approval reports the lessons it hits.
+func (s availabilityService) ReviewLeave(ctx context.Context, id string, req models.ReviewLeave) (models.LeaveImpactResponse, error) {
+	if req.Status != config.LEAVE_APPROVED && req.Status != config.LEAVE_REJECTED {
+		return models.LeaveImpactResponse{}, fmt.Errorf("status must be %s or %s", config.LEAVE_APPROVED, config.LEAVE_REJECTED)
+	}
+
+	if err := s.storage.AvailabilityStorage().ReviewLeave(ctx, id, req.Status); err != nil {
+		s.logger.Error("failed to review a leave: ", logger.Error(err))
+		return models.LeaveImpactResponse{}, err
+	}
+
+	return s.GetLeaveImpact(ctx, id)
+}
+
+// GetLeaveImpact lists the lessons falling into the leave.
+func (s availabilityService) GetLeaveImpact(ctx context.Context, id string) (models.LeaveImpactResponse, error) {
+	resp := models.LeaveImpactResponse{}
+
+	leave, err := s.storage.AvailabilityStorage().GetLeave(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get a leave: ", logger.Error(err))
+		return resp, err
+	}
+	resp.Leave = leave
+
+	lessons, err := s.storage.AvailabilityStorage().GetImpactedLessons(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get lessons impacted by a leave: ", logger.Error(err))
+		return resp, err
+	}
+	resp.Lessons = lessons
+	resp.Count = int64(len(lessons))
+
+	return resp, nil
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg"
+	"backend_course/lms/pkg/billing"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/storage"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrInvoiceCancelled = errors.New("invoice is cancelled")
+	ErrInvoicePaid      = errors.New("only unpaid invoices can be cancelled")
+	ErrPaymentTooLarge  = errors.New("payment is more than the outstanding amount of the invoice")
+)
+
+// defaultDueDay is the day of the month invoices are due when a plan does not set it.
+const defaultDueDay = 10
+
+type billingService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewBillingService(storage storage.IStorage, logger logger.ILogger) billingService {
+	return billingService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// today is the date invoices become overdue after, in the school's time zone.
+func today() string {
+	return time.Now().Format("2006-01-02")
+}
+
+func checkPlan(name string, monthlyFee int64, dueDay int) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("name is required")
+	}
+	if monthlyFee < 0 {
+		return errors.New("monthly_fee can not be negative")
+	}
+	if dueDay < 1 || dueDay > 28 {
+		return errors.New("due_day must be between 1 and 28")
+	}
+	return nil
+}
+
+func (s billingService) CreatePlan(ctx context.Context, plan models.AddPricePlan) (string, error) {
+	if plan.DueDay == 0 {
+		plan.DueDay = defaultDueDay
+	}
+	if err := checkPlan(plan.Name, plan.MonthlyFee, plan.DueDay); err != nil {
+		return "", err
+	}
+	if (plan.SubjectId == "") == (plan.GroupId == "") {
+		return "", errors.New("give either subject_id or group_id")
+	}
+
+	id, err := s.storage.BillingStorage().CreatePlan(ctx, plan)
+	if err != nil {
+		s.logger.Error("failed to create a price plan: ", logger.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+func (s billingService) UpdatePlan(ctx context.Context, id string, plan models.UpdatePricePlan) error {
+	if err := checkPlan(plan.Name, plan.MonthlyFee, plan.DueDay); err != nil {
+		return err
+	}
+
+	if err := s.storage.BillingStorage().UpdatePlan(ctx, id, plan); err != nil {
+		s.logger.Error("failed to update a price plan: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s billingService) GetPlan(ctx context.Context, id string) (models.PricePlan, error) {
+	plan, err := s.storage.BillingStorage().GetPlan(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get a price plan: ", logger.Error(err))
+		return plan, err
+	}
+	return plan, nil
+}
+
+func (s billingService) GetPlans(ctx context.Context, req models.GetAllPricePlansRequest) (models.GetAllPricePlansResponse, error) {
+	resp, err := s.storage.BillingStorage().GetPlans(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get price plans: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+// EachPlan hands the price plans of the request to fn as they are read.
+func (s billingService) EachPlan(ctx context.Context, req models.GetAllPricePlansRequest, fn func(models.PricePlan) error) error {
+	if err := s.storage.BillingStorage().EachPlan(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export price plans: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s billingService) SetDiscount(ctx context.Context, discount models.AddDiscount) (string, error) {
+	if discount.Percent < 1 || discount.Percent > 100 {
+		return "", errors.New("percent must be between 1 and 100")
+	}
+
+	id, err := s.storage.BillingStorage().SetDiscount(ctx, discount)
+	if err != nil {
+		s.logger.Error("failed to set a discount: ", logger.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+func (s billingService) DeleteDiscount(ctx context.Context, id string) error {
+	if err := s.storage.BillingStorage().DeleteDiscount(ctx, id); err != nil {
+		s.logger.Error("failed to delete a discount: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s billingService) GetDiscounts(ctx context.Context, req models.GetAllDiscountsRequest) (models.GetAllDiscountsResponse, error) {
+	resp, err := s.storage.BillingStorage().GetDiscounts(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get discounts: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+// EachDiscount hands the discounts of the request to fn as they are read.
+func (s billingService) EachDiscount(ctx context.Context, req models.GetAllDiscountsRequest, fn func(models.Discount) error) error {
+	if err := s.storage.BillingStorage().EachDiscount(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export discounts: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GenerateInvoices invoices the enrolled students of every active plan for the period,
+// running it again only invoices the students who were missed.
+func (s billingService) GenerateInvoices(ctx context.Context, req models.GenerateInvoices) (models.GenerateInvoicesResponse, error) {
+	resp := models.GenerateInvoicesResponse{Period: req.Period}
+
+	period, err := billing.ParsePeriod(req.Period)
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Created, err = s.storage.BillingStorage().GenerateInvoices(ctx, period.Format("2006-01-02"), period.Year())
+	if err != nil {
+		s.logger.Error("failed to generate invoices: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+func (s billingService) GetInvoice(ctx context.Context, id string) (models.Invoice, error) {
+	invoice, err := s.storage.BillingStorage().GetInvoice(ctx, id, today())
+	if err != nil {
+		s.logger.Error("failed to get an invoice: ", logger.Error(err))
+		return invoice, err
+	}
+	return invoice, nil
+}
+
+func checkInvoicesRequest(req models.GetAllInvoicesRequest) error {
+	switch req.Status {
+	case "", config.INVOICE_OPEN, config.INVOICE_PAID, config.INVOICE_OVERDUE, config.INVOICE_CANCELLED:
+	default:
+		return fmt.Errorf("status %q is not valid, use open, paid, overdue or cancelled", req.Status)
+	}
+	if req.Period != "" {
+		if _, err := billing.ParsePeriod(req.Period); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s billingService) GetInvoices(ctx context.Context, req models.GetAllInvoicesRequest) (models.GetAllInvoicesResponse, error) {
+	if err := checkInvoicesRequest(req); err != nil {
+		return models.GetAllInvoicesResponse{}, err
+	}
+	req.Today = today()
+
+	resp, err := s.storage.BillingStorage().GetInvoices(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get invoices: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+// EachInvoice hands the invoices of the request to fn as they are read.
+func (s billingService) EachInvoice(ctx context.Context, req models.GetAllInvoicesRequest, fn func(models.Invoice) error) error {
+	if err := checkInvoicesRequest(req); err != nil {
+		return err
+	}
+	req.Today = today()
+
+	if err := s.storage.BillingStorage().EachInvoice(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export invoices: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UpdateInvoice cancels an invoice which has no payments.
+func (s billingService) UpdateInvoice(ctx context.Context, id string, req models.UpdateInvoice) error {
+	if req.Status != config.INVOICE_CANCELLED {
+		return fmt.Errorf("status %q is not valid, invoices can only be cancelled", req.Status)
+	}
+
+	invoice, err := s.storage.BillingStorage().GetInvoice(ctx, id, today())
+	if err != nil {
+		s.logger.Error("failed to get an invoice: ", logger.Error(err))
+		return err
+	}
+	if invoice.Status == config.INVOICE_CANCELLED {
+		return ErrInvoiceCancelled
+	}
+
+	err = s.storage.BillingStorage().CancelInvoice(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrInvoicePaid
+	}
+	if err != nil {
+		s.logger.Error("failed to cancel an invoice: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// AddPayment records a payment of an invoice, an invoice can not be paid more than its
+// total.
+func (s billingService) AddPayment(ctx context.Context, payment models.AddPayment) (string, error) {
+	if payment.Amount <= 0 {
+		return "", errors.New("amount must be positive")
+	}
+	switch payment.Method {
+	case config.PAYMENT_CASH, config.PAYMENT_CARD, config.PAYMENT_TRANSFER:
+	default:
+		return "", fmt.Errorf("method %q is not valid, use cash, card or transfer", payment.Method)
+	}
+
+	var err error
+	if payment.PaidAt, err = pkg.NormalizeTime(ctx, payment.PaidAt); err != nil {
+		return "", err
+	}
+
+	invoice, err := s.storage.BillingStorage().GetInvoice(ctx, payment.InvoiceId, today())
+	if err != nil {
+		s.logger.Error("failed to get an invoice: ", logger.Error(err))
+		return "", err
+	}
+	if invoice.Status == config.INVOICE_CANCELLED {
+		return "", ErrInvoiceCancelled
+	}
+	if payment.Amount > invoice.Outstanding {
+		return "", fmt.Errorf("%w, %d is left to pay", ErrPaymentTooLarge, invoice.Outstanding)
+	}
+
+	id, err := s.storage.BillingStorage().AddPayment(ctx, payment)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// another payment or a cancellation came first
+		return "", ErrPaymentTooLarge
+	}
+	if err != nil {
+		s.logger.Error("failed to add a payment: ", logger.Error(err))
+		return "", err
+	}
+	invalidateDashboard(ctx, s.storage, s.logger)
+	return id, nil
+}
+
+func (s billingService) GetPayments(ctx context.Context, req models.GetAllPaymentsRequest) (models.GetAllPaymentsResponse, error) {
+	resp, err := s.storage.BillingStorage().GetPayments(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get payments: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+// EachPayment hands the payments of the request to fn as they are read.
+func (s billingService) EachPayment(ctx context.Context, req models.GetAllPaymentsRequest, fn func(models.Payment) error) error {
+	if err := s.storage.BillingStorage().EachPayment(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export payments: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s billingService) GetBalance(ctx context.Context, studentId string) (models.StudentBalance, error) {
+	balance, err := s.storage.BillingStorage().GetBalance(ctx, studentId, today())
+	if err != nil {
+		s.logger.Error("failed to get a student's balance: ", logger.Error(err))
+		return balance, err
+	}
+	return balance, nil
+}
+
+func (s billingService) GetDebtors(ctx context.Context, req models.DebtorsRequest) (models.DebtorsResponse, error) {
+	req.Today = today()
+
+	resp, err := s.storage.BillingStorage().GetDebtors(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get debtors: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+// EachDebtor hands the debtors of the request to fn as they are read.
+func (s billingService) EachDebtor(ctx context.Context, req models.DebtorsRequest, fn func(models.Debtor) error) error {
+	req.Today = today()
+
+	if err := s.storage.BillingStorage().EachDebtor(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export debtors: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
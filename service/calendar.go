@@ -0,0 +1,172 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/storage"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type calendarService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewCalendarService(storage storage.IStorage, logger logger.ILogger) calendarService {
+	return calendarService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// termRange turns a term into the timestamps bounding its days, reports take it in place of
+// start and end dates. The days are the school's ones whatever the user's time zone.
+func termRange(ctx context.Context, storage storage.IStorage, termId string) (string, string, error) {
+	term, err := storage.CalendarStorage().GetTerm(ctx, termId)
+	if err != nil {
+		return "", "", err
+	}
+	return dayRange(term.StartDate, term.EndDate)
+}
+
+// dayRange turns the dates from and to into the timestamps bounding their days in the
+// school's time zone.
+func dayRange(from, to string) (string, string, error) {
+	start, err := time.ParseInLocation("2006-01-02", from, time.Local)
+	if err != nil {
+		return "", "", err
+	}
+	end, err := time.ParseInLocation("2006-01-02", to, time.Local)
+	if err != nil {
+		return "", "", err
+	}
+	end = end.AddDate(0, 0, 1).Add(-time.Second)
+	return start.Format(time.RFC3339), end.Format(time.RFC3339), nil
+}
+
+func checkDates(from, to string) error {
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return fmt.Errorf("start date is not valid: %w", err)
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return fmt.Errorf("end date is not valid: %w", err)
+	}
+	if end.Before(start) {
+		return errors.New("end date is before start date")
+	}
+	return nil
+}
+
+func (s calendarService) CreateAcademicYear(ctx context.Context, year models.AddAcademicYear) (string, error) {
+	if err := checkDates(year.StartDate, year.EndDate); err != nil {
+		return "", err
+	}
+
+	id, err := s.storage.CalendarStorage().CreateAcademicYear(ctx, year)
+	if err != nil {
+		s.logger.Error("failed to create an academic year: ", logger.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+func (s calendarService) DeleteAcademicYear(ctx context.Context, id string) error {
+	if err := s.storage.CalendarStorage().DeleteAcademicYear(ctx, id); err != nil {
+		s.logger.Error("failed to delete an academic year: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s calendarService) GetAcademicYears(ctx context.Context) (models.GetAllAcademicYearsResponse, error) {
+	resp, err := s.storage.CalendarStorage().GetAcademicYears(ctx)
+	if err != nil {
+		s.logger.Error("failed to get academic years: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+func (s calendarService) CreateTerm(ctx context.Context, term models.AddTerm) (string, error) {
+	if err := checkDates(term.StartDate, term.EndDate); err != nil {
+		return "", err
+	}
+
+	id, err := s.storage.CalendarStorage().CreateTerm(ctx, term)
+	if err != nil {
+		s.logger.Error("failed to create a term: ", logger.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+func (s calendarService) GetTerm(ctx context.Context, id string) (models.Term, error) {
+	term, err := s.storage.CalendarStorage().GetTerm(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get a term: ", logger.Error(err))
+		return term, err
+	}
+	return term, nil
+}
+
+func (s calendarService) DeleteTerm(ctx context.Context, id string) error {
+	if err := s.storage.CalendarStorage().DeleteTerm(ctx, id); err != nil {
+		s.logger.Error("failed to delete a term: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s calendarService) CreateClosure(ctx context.Context, closure models.AddClosure) (string, error) {
+	if closure.Kind == "" {
+		closure.Kind = config.CLOSURE_HOLIDAY
+	}
+	if closure.Kind != config.CLOSURE_HOLIDAY && closure.Kind != config.CLOSURE_CLOSURE {
+		return "", fmt.Errorf("kind must be %s or %s", config.CLOSURE_HOLIDAY, config.CLOSURE_CLOSURE)
+	}
+	if err := checkDates(closure.FromDate, closure.ToDate); err != nil {
+		return "", err
+	}
+
+	id, err := s.storage.CalendarStorage().CreateClosure(ctx, closure)
+	if err != nil {
+		s.logger.Error("failed to create a closure: ", logger.Error(err))
+		return "", err
+	}
+	invalidateDashboard(ctx, s.storage, s.logger)
+	return id, nil
+}
+
+func (s calendarService) DeleteClosure(ctx context.Context, id string) error {
+	if err := s.storage.CalendarStorage().DeleteClosure(ctx, id); err != nil {
+		s.logger.Error("failed to delete a closure: ", logger.Error(err))
+		return err
+	}
+	invalidateDashboard(ctx, s.storage, s.logger)
+	return nil
+}
+
+// GetClosures lists the closures between the dates, or within the term when one is given.
+func (s calendarService) GetClosures(ctx context.Context, req models.GetAllClosuresRequest) (models.GetAllClosuresResponse, error) {
+	if req.TermId != "" {
+		term, err := s.storage.CalendarStorage().GetTerm(ctx, req.TermId)
+		if err != nil {
+			s.logger.Error("failed to get a term: ", logger.Error(err))
+			return models.GetAllClosuresResponse{}, err
+		}
+		req.FromDate, req.ToDate = term.StartDate, term.EndDate
+	}
+
+	resp, err := s.storage.CalendarStorage().GetClosures(ctx, req.FromDate, req.ToDate)
+	if err != nil {
+		s.logger.Error("failed to get closures: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg/logger"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDates(t *testing.T) {
+	cases := []struct {
+		from, to string
+		valid    bool
+	}{
+		{"2024-09-02", "2024-12-27", true},
+		{"2024-09-02", "2024-09-02", true},
+		{"2024-09-02", "2024-09-01", false},
+		{"2024-9-2", "2024-12-27", false},
+		{"2024-09-02", "", false},
+	}
+	for _, c := range cases {
+		err := checkDates(c.from, c.to)
+		assert.Equal(t, c.valid, err == nil, "%s %s", c.from, c.to)
+	}
+}
+
+func TestTermRange(t *testing.T) {
+	calendar := &fakeCalendar{terms: map[string]models.Term{
+		"autumn": {Id: "autumn", StartDate: "2024-09-02", EndDate: "2024-12-27"},
+	}}
+	storage := fakeStorage{calendar: calendar}
+
+	// the range holds every second of the term's days in the school's time zone
+	from, to, err := termRange(context.Background(), storage, "autumn")
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2024, 9, 2, 0, 0, 0, 0, time.Local).Format(time.RFC3339), from)
+		assert.Equal(t, time.Date(2024, 12, 27, 23, 59, 59, 0, time.Local).Format(time.RFC3339), to)
+	}
+
+	_, _, err = termRange(context.Background(), storage, "spring")
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestFitTerm(t *testing.T) {
+	calendar := &fakeCalendar{terms: map[string]models.Term{
+		// a term starting on a Wednesday
+		"autumn": {Id: "autumn", StartDate: "2024-09-04", EndDate: "2024-12-27"},
+	}}
+	s := NewScheduleService(fakeStorage{calendar: calendar}, logger.New("test"))
+	ctx := context.Background()
+
+	cases := []struct {
+		req       models.GenerateScheduleRequest
+		weekStart string
+		weeks     int
+	}{
+		{models.GenerateScheduleRequest{TermId: "autumn"}, "2024-09-02", 17},
+		{models.GenerateScheduleRequest{TermId: "autumn", Weeks: 4}, "2024-09-02", 4},
+		{models.GenerateScheduleRequest{TermId: "autumn", WeekStart: "2024-12-23"}, "2024-12-23", 1},
+	}
+	for _, c := range cases {
+		req := c.req
+		if assert.NoError(t, s.fitTerm(ctx, &req)) {
+			assert.Equal(t, c.weekStart, req.WeekStart)
+			assert.Equal(t, c.weeks, req.Weeks)
+		}
+	}
+
+	req := models.GenerateScheduleRequest{TermId: "autumn", WeekStart: "next monday"}
+	assert.Error(t, s.fitTerm(ctx, &req))
+	req = models.GenerateScheduleRequest{TermId: "spring"}
+	assert.ErrorIs(t, s.fitTerm(ctx, &req), pgx.ErrNoRows)
+}
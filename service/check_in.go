@@ -0,0 +1,157 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+var (
+	ErrInvalidCheckInCode = errors.New("check-in code is not valid or expired")
+	ErrAlreadyCheckedIn   = errors.New("already checked in to this lesson")
+)
+
+type checkInService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewCheckInService(storage storage.IStorage, logger logger.ILogger) checkInService {
+	return checkInService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func checkInLessonKey(timeTableId string) string {
+	return "check-in:lesson:" + timeTableId
+}
+
+func checkInCodeKey(code string) string {
+	return "check-in:code:" + code
+}
+
+func checkInUsedKey(timeTableId, studentId string) string {
+	return "check-in:used:" + timeTableId + ":" + studentId
+}
+
+// GenerateCode issues a new check-in code for the teacher's lesson in progress.
+// Codes rotate: issuing a new one invalidates the previous code of the lesson.
+func (s checkInService) GenerateCode(ctx context.Context, teacherId string, req models.CheckInCodeRequest) (models.CheckInCode, error) {
+	resp := models.CheckInCode{}
+
+	if req.Geofence != nil && req.Geofence.RadiusMeters <= 0 {
+		return resp, errors.New("geofence radius must be positive")
+	}
+
+	lesson, err := s.storage.TeacherStorage().CheckTeacherLesson(ctx, teacherId)
+	if err != nil {
+		s.logger.Error("failed to get a teacher's lesson: ", logger.Error(err))
+		return resp, err
+	}
+	if lesson.Status != config.LESSON_IN_PROGRESS {
+		return resp, errors.New("teacher has no lesson in progress")
+	}
+
+	code, err := pkg.GenerateCheckInCode(config.CheckInCodeLength)
+	if err != nil {
+		s.logger.Error("failed to generate check-in code: ", logger.Error(err))
+		return resp, err
+	}
+
+	resp = models.CheckInCode{
+		Code:        code,
+		QRPayload:   "lms://check-in?code=" + code,
+		TimeTableId: lesson.Lesson.TimeTableId,
+		TeacherId:   teacherId,
+		Geofence:    req.Geofence,
+		ExpiresIn:   int(config.CheckInCodeTTL.Seconds()),
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return models.CheckInCode{}, err
+	}
+
+	if previous := cast.ToString(s.storage.Redis().Get(ctx, checkInLessonKey(lesson.Lesson.TimeTableId))); previous != "" {
+		if err := s.storage.Redis().Del(ctx, checkInCodeKey(previous)); err != nil {
+			s.logger.Error("failed to delete previous check-in code: ", logger.Error(err))
+			return models.CheckInCode{}, err
+		}
+	}
+
+	if err := s.storage.Redis().SetX(ctx, checkInCodeKey(code), string(data), config.CheckInCodeTTL); err != nil {
+		s.logger.Error("failed to save check-in code: ", logger.Error(err))
+		return models.CheckInCode{}, err
+	}
+	if err := s.storage.Redis().SetX(ctx, checkInLessonKey(lesson.Lesson.TimeTableId), code, config.CheckInCodeTTL); err != nil {
+		s.logger.Error("failed to save check-in code: ", logger.Error(err))
+		return models.CheckInCode{}, err
+	}
+
+	return resp, nil
+}
+
+// Redeem marks the student present in the lesson the code was issued for.
+// A student can check in to a lesson only once.
+func (s checkInService) Redeem(ctx context.Context, studentId string, req models.CheckInRequest) (string, error) {
+	raw := cast.ToString(s.storage.Redis().Get(ctx, checkInCodeKey(strings.ToUpper(strings.TrimSpace(req.Code)))))
+	if raw == "" {
+		return "", ErrInvalidCheckInCode
+	}
+
+	code := models.CheckInCode{}
+	if err := json.Unmarshal([]byte(raw), &code); err != nil {
+		s.logger.Error("failed to read check-in code: ", logger.Error(err))
+		return "", err
+	}
+
+	if code.Geofence != nil {
+		if req.Latitude == nil || req.Longitude == nil {
+			return "", errors.New("location is required to check in to this lesson")
+		}
+		distance := pkg.DistanceInMeters(code.Geofence.Latitude, code.Geofence.Longitude, *req.Latitude, *req.Longitude)
+		if distance > code.Geofence.RadiusMeters {
+			return "", errors.New("you are too far from the room")
+		}
+	}
+
+	usedKey := checkInUsedKey(code.TimeTableId, studentId)
+	ok, err := s.storage.Redis().SetNX(ctx, usedKey, time.Now().Unix(), 24*time.Hour)
+	if err != nil {
+		s.logger.Error("failed to save check-in: ", logger.Error(err))
+		return "", err
+	}
+	if !ok {
+		return "", ErrAlreadyCheckedIn
+	}
+
+	err = s.storage.AttendanceStorage().MarkBulk(ctx, models.MarkAttendanceRequest{
+		TimeTableId: code.TimeTableId,
+		Records: []models.AttendanceRecord{{
+			StudentId:   studentId,
+			Status:      config.ATTENDANCE_PRESENT,
+			CheckInTime: time.Now().Format(time.RFC3339),
+			Note:        "self check-in",
+		}},
+	}, code.TeacherId)
+	if err != nil {
+		s.logger.Error("failed to mark attendance: ", logger.Error(err))
+		if delErr := s.storage.Redis().Del(ctx, usedKey); delErr != nil {
+			s.logger.Error("failed to delete check-in: ", logger.Error(delErr))
+		}
+		return "", err
+	}
+
+	invalidateDashboard(ctx, s.storage, s.logger)
+	return code.TimeTableId, nil
+}
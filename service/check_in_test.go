@@ -0,0 +1,87 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg/logger"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCheckInService(status string) (checkInService, *fakeTeachers, *fakeAttendance) {
+	teachers := &fakeTeachers{lesson: models.CheckLessonTeacher{
+		Status: status,
+		Lesson: &models.LessonDetails{TimeTableId: uuid.NewString()},
+	}}
+	attendance := &fakeAttendance{}
+	storage := fakeStorage{teachers: teachers, attendance: attendance, redis: newFakeRedis()}
+	return NewCheckInService(storage, logger.New("test")), teachers, attendance
+}
+
+func TestCheckInNeedsLessonInProgress(t *testing.T) {
+	for _, status := range []string{config.LESSON_UPCOMING, config.LESSON_NONE} {
+		s, _, _ := newTestCheckInService(status)
+		_, err := s.GenerateCode(context.Background(), uuid.NewString(), models.CheckInCodeRequest{})
+		assert.Error(t, err, status)
+	}
+}
+
+func TestCheckIn(t *testing.T) {
+	s, teachers, attendance := newTestCheckInService(config.LESSON_IN_PROGRESS)
+	ctx := context.Background()
+	teacherId, studentId := uuid.NewString(), uuid.NewString()
+
+	first, err := s.GenerateCode(ctx, teacherId, models.CheckInCodeRequest{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, teachers.lesson.Lesson.TimeTableId, first.TimeTableId)
+
+	// a new code of the lesson replaces the previous one
+	code, err := s.GenerateCode(ctx, teacherId, models.CheckInCodeRequest{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = s.Redeem(ctx, studentId, models.CheckInRequest{Code: first.Code})
+	assert.ErrorIs(t, err, ErrInvalidCheckInCode)
+
+	lessonId, err := s.Redeem(ctx, studentId, models.CheckInRequest{Code: " " + strings.ToLower(code.Code) + " "})
+	if assert.NoError(t, err) && assert.Len(t, attendance.marked, 1) {
+		assert.Equal(t, code.TimeTableId, lessonId)
+		assert.Equal(t, studentId, attendance.marked[0].Records[0].StudentId)
+		assert.Equal(t, config.ATTENDANCE_PRESENT, attendance.marked[0].Records[0].Status)
+	}
+
+	_, err = s.Redeem(ctx, studentId, models.CheckInRequest{Code: code.Code})
+	assert.ErrorIs(t, err, ErrAlreadyCheckedIn)
+	assert.Len(t, attendance.marked, 1)
+}
+
+func TestCheckInGeofence(t *testing.T) {
+	s, _, attendance := newTestCheckInService(config.LESSON_IN_PROGRESS)
+	ctx := context.Background()
+
+	_, err := s.GenerateCode(ctx, uuid.NewString(), models.CheckInCodeRequest{Geofence: &models.Geofence{Latitude: 41.3, Longitude: 69.2}})
+	assert.Error(t, err)
+
+	code, err := s.GenerateCode(ctx, uuid.NewString(), models.CheckInCodeRequest{
+		Geofence: &models.Geofence{Latitude: 41.3, Longitude: 69.2, RadiusMeters: 100},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	near, far := 41.3005, 41.31
+	longitude := 69.2
+	_, err = s.Redeem(ctx, uuid.NewString(), models.CheckInRequest{Code: code.Code})
+	assert.Error(t, err)
+	_, err = s.Redeem(ctx, uuid.NewString(), models.CheckInRequest{Code: code.Code, Latitude: &far, Longitude: &longitude})
+	assert.Error(t, err)
+	_, err = s.Redeem(ctx, uuid.NewString(), models.CheckInRequest{Code: code.Code, Latitude: &near, Longitude: &longitude})
+	assert.NoError(t, err)
+	assert.Len(t, attendance.marked, 1)
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// dashboardVersionKey holds the version of the cached dashboards, moving it to a new one
+// drops them all. It outlives the dashboards, so the ones cached before it expires are gone
+// by then.
+const dashboardVersionKey = "dashboard:version"
+
+func dashboardKey(version, today string, req models.DashboardRequest) string {
+	return "dashboard:" + version + ":" + today + ":" + req.FromDate + ":" + req.ToDate
+}
+
+// invalidateDashboard drops the cached dashboards after a write changing their figures. A
+// failure is only logged, the dashboards expire after config.DashboardCacheTTL anyway.
+func invalidateDashboard(ctx context.Context, storage storage.IStorage, log logger.ILogger) {
+	if err := storage.Redis().SetX(ctx, dashboardVersionKey, time.Now().UnixNano(), 24*time.Hour); err != nil {
+		log.Warning("failed to invalidate the dashboard: ", logger.Error(err))
+	}
+}
+
+type dashboardService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewDashboardService(storage storage.IStorage, logger logger.ILogger) dashboardService {
+	return dashboardService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Get returns the dashboard from the cache, computing it when it is not there.
+func (s dashboardService) Get(ctx context.Context, req models.DashboardRequest) (models.Dashboard, error) {
+	if (req.FromDate == "") != (req.ToDate == "") {
+		return models.Dashboard{}, errors.New("give both from_date and to_date or neither")
+	}
+	if req.FromDate != "" {
+		if err := checkDates(req.FromDate, req.ToDate); err != nil {
+			return models.Dashboard{}, err
+		}
+	}
+
+	version := cast.ToString(s.storage.Redis().Get(ctx, dashboardVersionKey))
+	key := dashboardKey(version, today(), req)
+	if cached := cast.ToString(s.storage.Redis().Get(ctx, key)); cached != "" {
+		var dashboard models.Dashboard
+		if err := json.Unmarshal([]byte(cached), &dashboard); err == nil {
+			return dashboard, nil
+		}
+	}
+
+	dashboard, err := s.compute(ctx, req)
+	if err != nil {
+		return dashboard, err
+	}
+
+	data, err := json.Marshal(dashboard)
+	if err != nil {
+		return dashboard, err
+	}
+	if err := s.storage.Redis().SetX(ctx, key, string(data), config.DashboardCacheTTL); err != nil {
+		s.logger.Warning("failed to cache the dashboard: ", logger.Error(err))
+	}
+	return dashboard, nil
+}
+
+// compute gathers the figures of the dashboard. Without a period the new students are the
+// ones of the last 12 months, the attendance is the one of this week and the revenue and
+// top subjects are the ones of this month.
+func (s dashboardService) compute(ctx context.Context, req models.DashboardRequest) (models.Dashboard, error) {
+	dashboard := models.Dashboard{FromDate: req.FromDate, ToDate: req.ToDate}
+
+	now := time.Now()
+	date := func(t time.Time) string { return t.Format("2006-01-02") }
+	year, month, _ := now.Date()
+	studentsFrom := date(time.Date(year, month-11, 1, 0, 0, 0, 0, time.Local))
+	weekFrom := date(now.AddDate(0, 0, -(int(now.Weekday())+6)%7))
+	monthFrom := date(time.Date(year, month, 1, 0, 0, 0, 0, time.Local))
+	to := date(now)
+	if req.FromDate != "" {
+		studentsFrom, weekFrom, monthFrom, to = req.FromDate, req.FromDate, req.FromDate, req.ToDate
+	}
+
+	var err error
+	dashboard.Students, err = s.storage.DashboardStorage().GetStudentCounts(ctx)
+	if err != nil {
+		s.logger.Error("failed to count students: ", logger.Error(err))
+		return dashboard, err
+	}
+
+	from, end, err := dayRange(studentsFrom, to)
+	if err != nil {
+		return dashboard, err
+	}
+	dashboard.NewStudents, err = s.storage.DashboardStorage().GetNewStudents(ctx, from, end)
+	if err != nil {
+		s.logger.Error("failed to count new students: ", logger.Error(err))
+		return dashboard, err
+	}
+
+	from, end, err = dayRange(date(now), date(now))
+	if err != nil {
+		return dashboard, err
+	}
+	dashboard.LessonsToday, err = s.storage.DashboardStorage().CountLessons(ctx, from, end)
+	if err != nil {
+		s.logger.Error("failed to count today's lessons: ", logger.Error(err))
+		return dashboard, err
+	}
+
+	from, end, err = dayRange(weekFrom, to)
+	if err != nil {
+		return dashboard, err
+	}
+	dashboard.Attendance, err = s.storage.DashboardStorage().GetAttendance(ctx, from, end)
+	if err != nil {
+		s.logger.Error("failed to sum attendance: ", logger.Error(err))
+		return dashboard, err
+	}
+	dashboard.Attendance.FromDate, dashboard.Attendance.ToDate = weekFrom, to
+	a := dashboard.Attendance
+	if marked := a.Present + a.Late + a.Absent + a.Excused; marked > 0 {
+		dashboard.Attendance.Rate = float64((a.Present+a.Late)*10000/marked) / 100
+	}
+
+	from, end, err = dayRange(monthFrom, to)
+	if err != nil {
+		return dashboard, err
+	}
+	dashboard.Revenue, err = s.storage.DashboardStorage().GetRevenue(ctx, from, end)
+	if err != nil {
+		s.logger.Error("failed to sum revenue: ", logger.Error(err))
+		return dashboard, err
+	}
+	dashboard.Revenue.FromDate, dashboard.Revenue.ToDate = monthFrom, to
+
+	dashboard.TopSubjects, err = s.storage.DashboardStorage().GetTopSubjects(ctx, from, end, config.DashboardSubjects)
+	if err != nil {
+		s.logger.Error("failed to get top subjects: ", logger.Error(err))
+		return dashboard, err
+	}
+
+	dashboard.GeneratedAt = pkg.FormatTime(ctx, now)
+	return dashboard, nil
+}
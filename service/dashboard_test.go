@@ -0,0 +1,61 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg/logger"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/cast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDashboardKey(t *testing.T) {
+	req := models.DashboardRequest{FromDate: "2024-09-01", ToDate: "2024-09-30"}
+	key := dashboardKey("1", "2024-09-15", req)
+
+	assert.NotEqual(t, key, dashboardKey("2", "2024-09-15", req))
+	assert.NotEqual(t, key, dashboardKey("1", "2024-09-16", req))
+	assert.NotEqual(t, key, dashboardKey("1", "2024-09-15", models.DashboardRequest{}))
+	assert.Equal(t, key, dashboardKey("1", "2024-09-15", req))
+}
+
+func TestDashboardCache(t *testing.T) {
+	redis := newFakeRedis()
+	storage := fakeStorage{redis: redis}
+	s := NewDashboardService(storage, logger.New("test"))
+	ctx := context.Background()
+	req := models.DashboardRequest{FromDate: "2024-09-01", ToDate: "2024-09-30"}
+
+	// a cached dashboard is returned without computing it, the fake has no dashboard storage
+	cached := models.Dashboard{FromDate: req.FromDate, ToDate: req.ToDate, LessonsToday: 7}
+	data, _ := json.Marshal(cached)
+	key := dashboardKey("", today(), req)
+	assert.NoError(t, redis.SetX(ctx, key, string(data), 0))
+
+	dashboard, err := s.Get(ctx, req)
+	if assert.NoError(t, err) {
+		assert.Equal(t, cached, dashboard)
+	}
+
+	// a write moves the version, the cached dashboards are not read any more
+	invalidateDashboard(ctx, storage, logger.New("test"))
+	version := cast.ToString(redis.Get(ctx, dashboardVersionKey))
+	assert.NotEmpty(t, version)
+	assert.NotEqual(t, key, dashboardKey(version, today(), req))
+}
+
+func TestDashboardDates(t *testing.T) {
+	s := NewDashboardService(fakeStorage{}, logger.New("test"))
+
+	for _, req := range []models.DashboardRequest{
+		{FromDate: "2024-09-01"},
+		{ToDate: "2024-09-30"},
+		{FromDate: "2024-09-30", ToDate: "2024-09-01"},
+		{FromDate: "September", ToDate: "2024-09-30"},
+	} {
+		_, err := s.Get(context.Background(), req)
+		assert.Error(t, err, "%+v", req)
+	}
+}
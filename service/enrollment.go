@@ -0,0 +1,110 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/storage"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrPrerequisitesNotMet = errors.New("prerequisites are not completed")
+	ErrAlreadyEnrolled     = errors.New("student is already enrolled in the subject")
+	ErrNotYourEnrollment   = errors.New("students can only drop their own enrollments")
+)
+
+type enrollmentService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewEnrollmentService(storage storage.IStorage, logger logger.ILogger) enrollmentService {
+	return enrollmentService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Enroll enrolls the student in the subject once every prerequisite of it is completed.
+func (s enrollmentService) Enroll(ctx context.Context, studentId, subjectId string) (string, error) {
+	missing, err := s.storage.EnrollmentStorage().MissingPrerequisites(ctx, studentId, subjectId)
+	if err != nil {
+		s.logger.Error("failed to get missing prerequisites: ", logger.Error(err))
+		return "", err
+	}
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for _, subject := range missing {
+			name := subject.Name
+			if subject.Code != "" {
+				name = subject.Code + " " + name
+			}
+			names = append(names, name)
+		}
+		return "", fmt.Errorf("%w: %s", ErrPrerequisitesNotMet, strings.Join(names, ", "))
+	}
+
+	id, err := s.storage.EnrollmentStorage().Enroll(ctx, studentId, subjectId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrAlreadyEnrolled
+	}
+	if err != nil {
+		s.logger.Error("failed to enroll a student: ", logger.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+func (s enrollmentService) GetEnrollments(ctx context.Context, req models.GetAllEnrollmentsRequest) (models.GetAllEnrollmentsResponse, error) {
+	resp, err := s.storage.EnrollmentStorage().GetEnrollments(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get enrollments: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+// EachEnrollment hands the enrollments of the request to fn as they are read.
+func (s enrollmentService) EachEnrollment(ctx context.Context, req models.GetAllEnrollmentsRequest, fn func(models.Enrollment) error) error {
+	if err := s.storage.EnrollmentStorage().EachEnrollment(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export enrollments: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UpdateEnrollment sets the status of an enrollment. Students can only drop their own
+// enrollments, teachers of the subject can set any status.
+func (s enrollmentService) UpdateEnrollment(ctx context.Context, authInfo models.AuthInfo, id string, req models.UpdateEnrollment) error {
+	switch req.Status {
+	case config.ENROLLMENT_ACTIVE, config.ENROLLMENT_COMPLETED, config.ENROLLMENT_DROPPED:
+	default:
+		return fmt.Errorf("status %q is not valid, use active, completed or dropped", req.Status)
+	}
+
+	enrollment, err := s.storage.EnrollmentStorage().GetEnrollment(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get an enrollment: ", logger.Error(err))
+		return err
+	}
+
+	if authInfo.UserRole == config.STUDENT_TYPE {
+		if enrollment.StudentId != authInfo.UserID || req.Status != config.ENROLLMENT_DROPPED {
+			return ErrNotYourEnrollment
+		}
+	} else if err := checkSubjectTeacher(ctx, s.storage, s.logger, authInfo.UserID, enrollment.SubjectId); err != nil {
+		return err
+	}
+
+	if err := s.storage.EnrollmentStorage().UpdateStatus(ctx, id, req.Status); err != nil {
+		s.logger.Error("failed to update an enrollment: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg/grading"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/storage"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	ErrNotSubjectTeacher = errors.New("only the subject's teacher can do this")
+	ErrScoreOutOfRange   = errors.New("score must be between 0 and the assessment's max score")
+)
+
+type gradebookService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewGradebookService(storage storage.IStorage, logger logger.ILogger) gradebookService {
+	return gradebookService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// checkSubjectTeacher rejects teachers who do not teach the subject.
+func checkSubjectTeacher(ctx context.Context, s storage.IStorage, log logger.ILogger, teacherId, subjectId string) error {
+	teaches, err := s.TeacherStorage().TeachesSubject(ctx, teacherId, subjectId)
+	if err != nil {
+		log.Error("failed to check teacher's subject: ", logger.Error(err))
+		return err
+	}
+	if !teaches {
+		return ErrNotSubjectTeacher
+	}
+	return nil
+}
+
+func (s gradebookService) CreateAssessment(ctx context.Context, teacherId string, assessment models.AddAssessment) (string, error) {
+	switch assessment.Kind {
+	case config.ASSESSMENT_QUIZ, config.ASSESSMENT_EXAM, config.ASSESSMENT_HOMEWORK:
+	default:
+		return "", fmt.Errorf("kind %q is not valid, use quiz, exam or homework", assessment.Kind)
+	}
+	if assessment.MaxScore <= 0 {
+		return "", errors.New("max score must be positive")
+	}
+	if assessment.Weight == 0 {
+		assessment.Weight = 1
+	}
+	if assessment.Weight < 0 {
+		return "", errors.New("weight must be positive")
+	}
+	if _, err := time.Parse("2006-01-02", assessment.HeldOn); err != nil {
+		return "", fmt.Errorf("held on date is not valid: %w", err)
+	}
+
+	if err := checkSubjectTeacher(ctx, s.storage, s.logger, teacherId, assessment.SubjectId); err != nil {
+		return "", err
+	}
+
+	id, err := s.storage.GradebookStorage().CreateAssessment(ctx, teacherId, assessment)
+	if err != nil {
+		s.logger.Error("failed to create an assessment: ", logger.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+func (s gradebookService) DeleteAssessment(ctx context.Context, teacherId, id string) error {
+	assessment, err := s.storage.GradebookStorage().GetAssessment(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get an assessment: ", logger.Error(err))
+		return err
+	}
+
+	if err := checkSubjectTeacher(ctx, s.storage, s.logger, teacherId, assessment.SubjectId); err != nil {
+		return err
+	}
+
+	if err := s.storage.GradebookStorage().DeleteAssessment(ctx, id); err != nil {
+		s.logger.Error("failed to delete an assessment: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s gradebookService) GetAssessments(ctx context.Context, req models.GetAllAssessmentsRequest) (models.GetAllAssessmentsResponse, error) {
+	resp, err := s.storage.GradebookStorage().GetAssessments(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get assessments: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+// EachAssessment hands the assessments of the request to fn as they are read.
+func (s gradebookService) EachAssessment(ctx context.Context, req models.GetAllAssessmentsRequest, fn func(models.Assessment) error) error {
+	if err := s.storage.GradebookStorage().EachAssessment(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export assessments: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// SetScores records the students' scores of the assessment, only the subject's teachers
+// can enter them.
+func (s gradebookService) SetScores(ctx context.Context, teacherId, assessmentId string, req models.SetScoresRequest) error {
+	if len(req.Scores) == 0 {
+		return errors.New("no scores given")
+	}
+
+	assessment, err := s.storage.GradebookStorage().GetAssessment(ctx, assessmentId)
+	if err != nil {
+		s.logger.Error("failed to get an assessment: ", logger.Error(err))
+		return err
+	}
+
+	for _, score := range req.Scores {
+		if score.Score < 0 || score.Score > assessment.MaxScore {
+			return fmt.Errorf("student %s: %w", score.StudentId, ErrScoreOutOfRange)
+		}
+	}
+
+	if err := checkSubjectTeacher(ctx, s.storage, s.logger, teacherId, assessment.SubjectId); err != nil {
+		return err
+	}
+
+	if err := s.storage.GradebookStorage().SetScores(ctx, assessmentId, teacherId, req.Scores); err != nil {
+		s.logger.Error("failed to set scores: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// gradeScale defaults the grading scale to the school's one.
+func gradeScale(scale string) (string, error) {
+	if scale == "" {
+		scale = config.GradingScale
+	}
+	return scale, grading.CheckScale(scale)
+}
+
+// grade computes the weighted grade of the scored rows, unscored assessments do not count.
+func grade(scale string, rows []models.GradeRow) (float64, string, int) {
+	var scores []grading.Score
+	for _, row := range rows {
+		if row.Score != nil {
+			scores = append(scores, grading.Score{Score: *row.Score, MaxScore: row.Assessment.MaxScore, Weight: row.Assessment.Weight})
+		}
+	}
+	percent, ok := grading.Percent(scores)
+	if !ok {
+		return 0, "", 0
+	}
+	letter, _ := grading.Grade(scale, percent)
+	return percent, letter, len(scores)
+}
+
+// GetStudentGrades returns the student's weighted grade of every subject with the
+// assessments it is made of.
+func (s gradebookService) GetStudentGrades(ctx context.Context, req models.GradesRequest) (models.StudentGradesResponse, error) {
+	var err error
+	if req.Scale, err = gradeScale(req.Scale); err != nil {
+		return models.StudentGradesResponse{}, err
+	}
+
+	rows, err := s.storage.GradebookStorage().GetGradeRows(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get grades: ", logger.Error(err))
+		return models.StudentGradesResponse{}, err
+	}
+
+	return models.StudentGradesResponse{StudentId: req.StudentId, Scale: req.Scale, Subjects: subjectGrades(req.Scale, rows)}, nil
+}
+
+// subjectGrades groups a student's rows, which come ordered by subject, into the grade of
+// every subject.
+func subjectGrades(scale string, rows []models.GradeRow) []models.SubjectGrade {
+	subjects := []models.SubjectGrade{}
+	for start := 0; start < len(rows); {
+		end := start
+		for end < len(rows) && rows[end].SubjectId == rows[start].SubjectId {
+			end++
+		}
+
+		subject := models.SubjectGrade{SubjectId: rows[start].SubjectId, SubjectName: rows[start].SubjectName}
+		subject.Percent, subject.Grade, _ = grade(scale, rows[start:end])
+		for _, row := range rows[start:end] {
+			subject.Assessments = append(subject.Assessments, models.AssessmentScore{
+				AssessmentId: row.Assessment.Id,
+				Name:         row.Assessment.Name,
+				Kind:         row.Assessment.Kind,
+				MaxScore:     row.Assessment.MaxScore,
+				Weight:       row.Assessment.Weight,
+				HeldOn:       row.Assessment.HeldOn,
+				Score:        row.Score,
+			})
+		}
+		subjects = append(subjects, subject)
+		start = end
+	}
+	return subjects
+}
+
+// GetClassGrades returns the weighted grade of every student of the group in the subject and
+// the class average over the graded students.
+func (s gradebookService) GetClassGrades(ctx context.Context, req models.GradesRequest) (models.ClassGradesResponse, error) {
+	if req.SubjectId == "" {
+		return models.ClassGradesResponse{}, errors.New("subject_id is required")
+	}
+	var err error
+	if req.Scale, err = gradeScale(req.Scale); err != nil {
+		return models.ClassGradesResponse{}, err
+	}
+
+	rows, err := s.storage.GradebookStorage().GetGradeRows(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get grades: ", logger.Error(err))
+		return models.ClassGradesResponse{}, err
+	}
+
+	resp := models.ClassGradesResponse{GroupId: req.GroupId, SubjectId: req.SubjectId, Scale: req.Scale, Students: []models.StudentGrade{}}
+	assessments := map[string]bool{}
+	var sum float64
+	graded := 0
+	// rows come ordered by student
+	for start := 0; start < len(rows); {
+		end := start
+		for end < len(rows) && rows[end].StudentId == rows[start].StudentId {
+			assessments[rows[end].Assessment.Id] = true
+			end++
+		}
+
+		student := models.StudentGrade{StudentId: rows[start].StudentId, StudentName: rows[start].StudentName}
+		student.Percent, student.Grade, student.Scored = grade(req.Scale, rows[start:end])
+		if student.Scored > 0 {
+			sum += student.Percent
+			graded++
+		}
+		resp.Students = append(resp.Students, student)
+		start = end
+	}
+
+	resp.Assessments = len(assessments)
+	if graded > 0 {
+		resp.Average = math.Round(sum/float64(graded)*100) / 100
+	}
+
+	return resp, nil
+}
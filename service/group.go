@@ -0,0 +1,83 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/storage"
+	"context"
+)
+
+type groupService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewGroupService(storage storage.IStorage, logger logger.ILogger) groupService {
+	return groupService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (s groupService) Create(ctx context.Context, group models.AddGroup) (string, error) {
+	id, err := s.storage.GroupStorage().Create(ctx, group)
+	if err != nil {
+		s.logger.Error("failed to create a group: ", logger.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+func (s groupService) Update(ctx context.Context, group models.Group) (string, error) {
+	id, err := s.storage.GroupStorage().Update(ctx, group)
+	if err != nil {
+		s.logger.Error("failed to update a group: ", logger.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+func (s groupService) Delete(ctx context.Context, id string) error {
+	err := s.storage.GroupStorage().Delete(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to delete a group: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s groupService) GetAll(ctx context.Context, req models.GetAllGroupsRequest) (models.GetAllGroupsResponse, error) {
+	res, err := s.storage.GroupStorage().GetAll(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get all groups: ", logger.Error(err))
+		return res, err
+	}
+	return res, nil
+}
+
+// Each hands the groups of the request to fn as they are read.
+func (s groupService) Each(ctx context.Context, req models.GetAllGroupsRequest, fn func(models.Group) error) error {
+	if err := s.storage.GroupStorage().Each(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export groups: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s groupService) GetGroup(ctx context.Context, id string) (models.Group, error) {
+	group, err := s.storage.GroupStorage().GetGroup(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get a group: ", logger.Error(err))
+		return group, err
+	}
+	return group, nil
+}
+
+func (s groupService) SetStudents(ctx context.Context, id string, req models.GroupStudents) error {
+	err := s.storage.GroupStorage().SetStudents(ctx, id, req.StudentIds)
+	if err != nil {
+		s.logger.Error("failed to set group's students: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
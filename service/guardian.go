@@ -0,0 +1,143 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/storage"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrNotYourChild = errors.New("guardians can only see their own children")
+
+type guardianService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewGuardianService(storage storage.IStorage, logger logger.ILogger) guardianService {
+	return guardianService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func checkGuardian(guardian models.AddGuardian) error {
+	if strings.TrimSpace(guardian.FirstName) == "" || strings.TrimSpace(guardian.LastName) == "" {
+		return errors.New("first_name and last_name are required")
+	}
+	if guardian.Timezone != "" {
+		if _, err := time.LoadLocation(guardian.Timezone); err != nil {
+			return fmt.Errorf("timezone is not valid: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s guardianService) Create(ctx context.Context, guardian models.AddGuardian) (string, error) {
+	if err := checkGuardian(guardian); err != nil {
+		return "", err
+	}
+
+	id, err := s.storage.GuardianStorage().Create(ctx, guardian)
+	if err != nil {
+		s.logger.Error("failed to create a guardian: ", logger.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+func (s guardianService) Update(ctx context.Context, id string, guardian models.AddGuardian) error {
+	if err := checkGuardian(guardian); err != nil {
+		return err
+	}
+
+	if err := s.storage.GuardianStorage().Update(ctx, id, guardian); err != nil {
+		s.logger.Error("failed to update a guardian: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s guardianService) Delete(ctx context.Context, id string) error {
+	if err := s.storage.GuardianStorage().Delete(ctx, id); err != nil {
+		s.logger.Error("failed to delete a guardian: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetGuardian returns the guardian with the linked students.
+func (s guardianService) GetGuardian(ctx context.Context, id string) (models.Guardian, error) {
+	guardian, err := s.storage.GuardianStorage().GetGuardian(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get a guardian: ", logger.Error(err))
+		return guardian, err
+	}
+
+	if guardian.Students, err = s.GetChildren(ctx, id); err != nil {
+		return guardian, err
+	}
+	return guardian, nil
+}
+
+func (s guardianService) GetAll(ctx context.Context, req models.GetAllGuardiansRequest) (models.GetAllGuardiansResponse, error) {
+	resp, err := s.storage.GuardianStorage().GetAll(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get all guardians: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+// Each hands the guardians of the request to fn as they are read.
+func (s guardianService) Each(ctx context.Context, req models.GetAllGuardiansRequest, fn func(models.Guardian) error) error {
+	if err := s.storage.GuardianStorage().Each(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export guardians: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// SetStudents replaces the students linked to the guardian.
+func (s guardianService) SetStudents(ctx context.Context, id string, req models.SetGuardianStudents) error {
+	for _, student := range req.Students {
+		switch student.Relation {
+		case config.RELATION_MOTHER, config.RELATION_FATHER, config.RELATION_GUARDIAN, config.RELATION_OTHER:
+		default:
+			return fmt.Errorf("relation %q is not valid, use mother, father, guardian or other", student.Relation)
+		}
+	}
+
+	if err := s.storage.GuardianStorage().SetStudents(ctx, id, req.Students); err != nil {
+		s.logger.Error("failed to set guardian's students: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s guardianService) GetChildren(ctx context.Context, id string) ([]models.GuardianStudent, error) {
+	students, err := s.storage.GuardianStorage().GetStudents(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get guardian's students: ", logger.Error(err))
+		return nil, err
+	}
+	return students, nil
+}
+
+// CheckChild returns ErrNotYourChild unless the student is linked to the guardian.
+func (s guardianService) CheckChild(ctx context.Context, guardianId, studentId string) error {
+	linked, err := s.storage.GuardianStorage().IsLinked(ctx, guardianId, studentId)
+	if err != nil {
+		s.logger.Error("failed to check guardian's students: ", logger.Error(err))
+		return err
+	}
+	if !linked {
+		return ErrNotYourChild
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/storage"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrSubmissionGraded = errors.New("the submission is already graded")
+	ErrNotInGroup       = errors.New("student is not in the assignment's group")
+)
+
+type homeworkService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewHomeworkService(storage storage.IStorage, logger logger.ILogger) homeworkService {
+	return homeworkService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// submissionStatus is late when the work comes in after the due date.
+func submissionStatus(dueAt, submittedAt time.Time) string {
+	if submittedAt.After(dueAt) {
+		return config.SUBMISSION_LATE
+	}
+	return config.SUBMISSION_SUBMITTED
+}
+
+func (s homeworkService) CreateAssignment(ctx context.Context, teacherId string, assignment models.AddAssignment) (string, error) {
+	if strings.TrimSpace(assignment.Title) == "" {
+		return "", errors.New("title is required")
+	}
+	if assignment.DueAt == "" {
+		return "", errors.New("due_at is required")
+	}
+	dueAt, err := pkg.NormalizeTime(ctx, assignment.DueAt)
+	if err != nil {
+		return "", err
+	}
+	assignment.DueAt = dueAt
+	if assignment.Attachments == nil {
+		assignment.Attachments = []string{}
+	}
+
+	if err := checkSubjectTeacher(ctx, s.storage, s.logger, teacherId, assignment.SubjectId); err != nil {
+		return "", err
+	}
+
+	id, err := s.storage.HomeworkStorage().CreateAssignment(ctx, teacherId, assignment)
+	if err != nil {
+		s.logger.Error("failed to create an assignment: ", logger.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+func (s homeworkService) GetAssignment(ctx context.Context, id string) (models.Assignment, error) {
+	assignment, err := s.storage.HomeworkStorage().GetAssignment(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get an assignment: ", logger.Error(err))
+		return assignment, err
+	}
+	return assignment, nil
+}
+
+func (s homeworkService) DeleteAssignment(ctx context.Context, teacherId, id string) error {
+	assignment, err := s.GetAssignment(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := checkSubjectTeacher(ctx, s.storage, s.logger, teacherId, assignment.SubjectId); err != nil {
+		return err
+	}
+
+	if err := s.storage.HomeworkStorage().DeleteAssignment(ctx, id); err != nil {
+		s.logger.Error("failed to delete an assignment: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s homeworkService) GetAssignments(ctx context.Context, req models.GetAllAssignmentsRequest) (models.GetAllAssignmentsResponse, error) {
+	resp, err := s.storage.HomeworkStorage().GetAssignments(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get assignments: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+// EachAssignment hands the assignments of the request to fn as they are read.
+func (s homeworkService) EachAssignment(ctx context.Context, req models.GetAllAssignmentsRequest, fn func(models.Assignment) error) error {
+	if err := s.storage.HomeworkStorage().EachAssignment(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export assignments: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Submit hands in the student's work, it is late when it comes after the due date. A student
+// can resubmit until the submission is graded, a returned submission is open again. It
+// returns the files of the replaced submission, which are not needed any more.
+func (s homeworkService) Submit(ctx context.Context, submission models.AddSubmission) (string, []string, error) {
+	if strings.TrimSpace(submission.Text) == "" && len(submission.Files) == 0 {
+		return "", nil, errors.New("a submission needs a text or files")
+	}
+	if submission.Files == nil {
+		submission.Files = []string{}
+	}
+
+	assignment, err := s.GetAssignment(ctx, submission.AssignmentId)
+	if err != nil {
+		return "", nil, err
+	}
+	dueAt, err := pkg.ParseTime(ctx, assignment.DueAt)
+	if err != nil {
+		return "", nil, err
+	}
+
+	existing, err := s.storage.HomeworkStorage().GetStudentSubmission(ctx, submission.AssignmentId, submission.StudentId)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		s.logger.Error("failed to get the student's submission: ", logger.Error(err))
+		return "", nil, err
+	}
+	if err == nil && existing.Status == config.SUBMISSION_GRADED {
+		return "", nil, ErrSubmissionGraded
+	}
+
+	submission.Status = submissionStatus(dueAt, time.Now())
+
+	id, replaced, err := s.storage.HomeworkStorage().Submit(ctx, submission)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if existing.Id != "" {
+			// graded after it was read above
+			return "", nil, ErrSubmissionGraded
+		}
+		return "", nil, ErrNotInGroup
+	}
+	if err != nil {
+		s.logger.Error("failed to submit: ", logger.Error(err))
+		return "", nil, err
+	}
+	return id, replaced, nil
+}
+
+func (s homeworkService) GetMySubmission(ctx context.Context, assignmentId, studentId string) (models.Submission, error) {
+	submission, err := s.storage.HomeworkStorage().GetStudentSubmission(ctx, assignmentId, studentId)
+	if err != nil {
+		s.logger.Error("failed to get the student's submission: ", logger.Error(err))
+		return submission, err
+	}
+	return submission, nil
+}
+
+// GetSubmissions returns every submission of the assignment, only the subject's teachers
+// can see them.
+func (s homeworkService) GetSubmissions(ctx context.Context, teacherId, assignmentId string) (models.GetAssignmentSubmissionsResponse, error) {
+	assignment, err := s.GetAssignment(ctx, assignmentId)
+	if err != nil {
+		return models.GetAssignmentSubmissionsResponse{}, err
+	}
+
+	if err := checkSubjectTeacher(ctx, s.storage, s.logger, teacherId, assignment.SubjectId); err != nil {
+		return models.GetAssignmentSubmissionsResponse{}, err
+	}
+
+	resp, err := s.storage.HomeworkStorage().GetSubmissions(ctx, assignmentId)
+	if err != nil {
+		s.logger.Error("failed to get submissions: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+// ReviewSubmission grades the submission or returns it with a comment so that the student
+// can submit it again.
+func (s homeworkService) ReviewSubmission(ctx context.Context, teacherId, id string, review models.ReviewSubmission) error {
+	switch review.Status {
+	case config.SUBMISSION_GRADED:
+	case config.SUBMISSION_RETURNED:
+		if strings.TrimSpace(review.Comment) == "" {
+			return errors.New("a returned submission needs a comment")
+		}
+	default:
+		return fmt.Errorf("status %q is not valid, use graded or returned", review.Status)
+	}
+	if review.Score != nil && *review.Score < 0 {
+		return errors.New("score must not be negative")
+	}
+
+	submission, err := s.storage.HomeworkStorage().GetSubmission(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get a submission: ", logger.Error(err))
+		return err
+	}
+	assignment, err := s.GetAssignment(ctx, submission.AssignmentId)
+	if err != nil {
+		return err
+	}
+
+	if err := checkSubjectTeacher(ctx, s.storage, s.logger, teacherId, assignment.SubjectId); err != nil {
+		return err
+	}
+
+	if err := s.storage.HomeworkStorage().ReviewSubmission(ctx, id, teacherId, review); err != nil {
+		s.logger.Error("failed to review a submission: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"backend_course/lms/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmissionStatus(t *testing.T) {
+	dueAt := time.Date(2024, 9, 2, 18, 0, 0, 0, time.UTC)
+	tashkent := time.FixedZone("UZT", 5*60*60)
+
+	cases := []struct {
+		name        string
+		submittedAt time.Time
+		want        string
+	}{
+		{"early", dueAt.Add(-time.Hour), config.SUBMISSION_SUBMITTED},
+		{"on the due time", dueAt, config.SUBMISSION_SUBMITTED},
+		{"same time in another zone", dueAt.In(tashkent), config.SUBMISSION_SUBMITTED},
+		{"a second late", dueAt.Add(time.Second), config.SUBMISSION_LATE},
+		{"next day", dueAt.AddDate(0, 0, 1), config.SUBMISSION_LATE},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, submissionStatus(dueAt, c.submittedAt), c.name)
+	}
+}
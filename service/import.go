@@ -0,0 +1,135 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg"
+	"errors"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+var ErrImportConflict = errors.New("a mail or phone of the file was taken while it was imported, import it again")
+
+// importRow collects the errors of the fields of a row of an import.
+type importRow struct {
+	line   int
+	mail   string
+	phone  string
+	errors models.FieldErrors
+}
+
+// check records err for field, a field keeps only its first error.
+func (r *importRow) check(field string, err error) {
+	if err == nil {
+		return
+	}
+	for _, e := range r.errors {
+		if e.Field == field {
+			return
+		}
+	}
+	r.errors = append(r.errors, models.FieldError{Field: field, Message: err.Error()})
+}
+
+func (r *importRow) required(field, value string) {
+	if value == "" {
+		r.check(field, errors.New("is required"))
+	}
+}
+
+// fields records the field errors of a request parser.
+func (r *importRow) fields(err error) {
+	var fields models.FieldErrors
+	if errors.As(err, &fields) {
+		for _, f := range fields {
+			r.check(f.Field, errors.New(f.Message))
+		}
+		return
+	}
+	r.check("row", err)
+}
+
+func importContacts(rows []importRow) models.ImportContacts {
+	contacts := models.ImportContacts{}
+	for _, row := range rows {
+		contacts.Mails = append(contacts.Mails, row.mail)
+		contacts.Phones = append(contacts.Phones, row.phone)
+	}
+	return contacts
+}
+
+// checkUnique fails the mails and phones which are taken or repeat an earlier row.
+func checkUnique(rows []importRow, taken models.ImportContacts) {
+	takenMails := make(map[string]bool, len(taken.Mails))
+	for _, mail := range taken.Mails {
+		takenMails[mail] = true
+	}
+	takenPhones := make(map[string]bool, len(taken.Phones))
+	for _, phone := range taken.Phones {
+		takenPhones[phone] = true
+	}
+
+	mails := make(map[string]int, len(rows))
+	phones := make(map[string]int, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		unique := func(field, value string, seen map[string]int, taken map[string]bool) {
+			if value == "" {
+				return
+			}
+			if taken[value] {
+				row.check(field, errors.New("is already taken"))
+			} else if line, ok := seen[value]; ok {
+				row.check(field, errors.New("repeats row "+strconv.Itoa(line)))
+			} else {
+				seen[value] = row.line
+			}
+		}
+		unique("mail", row.mail, mails, takenMails)
+		unique("phone", row.phone, phones, takenPhones)
+	}
+}
+
+func newImportResponse(rows []importRow, dryRun bool) models.ImportResponse {
+	resp := models.ImportResponse{Rows: len(rows), DryRun: dryRun, Errors: []models.ImportRowError{}}
+	for _, row := range rows {
+		if len(row.errors) == 0 {
+			resp.Valid++
+			continue
+		}
+		for _, e := range row.errors {
+			resp.Errors = append(resp.Errors, models.ImportRowError{Row: row.line, Field: e.Field, Message: e.Message})
+		}
+	}
+	return resp
+}
+
+// hashPasswords replaces the passwords by their hashes, which are computed in parallel
+// because bcrypt is slow on purpose.
+func hashPasswords(passwords []*string) error {
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		err   error
+		slots = make(chan struct{}, runtime.NumCPU())
+	)
+	for _, password := range passwords {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(password *string) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			hash, e := pkg.HashPassword(*password)
+			if e != nil {
+				once.Do(func() { err = e })
+				return
+			}
+			*password = hash
+		}(password)
+	}
+	wg.Wait()
+	return err
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/pkg/payment"
+	"backend_course/lms/storage"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrNotYourInvoice = errors.New("students can only pay their own invoices")
+	ErrNothingToPay   = errors.New("invoice has nothing left to pay")
+)
+
+type paymentService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewPaymentService(storage storage.IStorage, logger logger.ILogger) paymentService {
+	return paymentService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Checkout starts paying what is left of an invoice through the provider.
+func (s paymentService) Checkout(ctx context.Context, authInfo models.AuthInfo, invoiceId string, req models.CreateCheckout) (models.Checkout, error) {
+	gateway, err := payment.Get(req.Provider)
+	if err != nil {
+		return models.Checkout{}, err
+	}
+
+	invoice, err := s.storage.BillingStorage().GetInvoice(ctx, invoiceId, today())
+	if err != nil {
+		s.logger.Error("failed to get an invoice: ", logger.Error(err))
+		return models.Checkout{}, err
+	}
+	if authInfo.UserRole == config.STUDENT_TYPE && invoice.StudentId != authInfo.UserID {
+		return models.Checkout{}, ErrNotYourInvoice
+	}
+	if invoice.Status == config.INVOICE_CANCELLED {
+		return models.Checkout{}, ErrInvoiceCancelled
+	}
+	if invoice.Outstanding == 0 {
+		return models.Checkout{}, ErrNothingToPay
+	}
+
+	id, err := s.storage.BillingStorage().CreateTransaction(ctx, invoiceId, gateway.Name(), invoice.Outstanding)
+	if err != nil {
+		s.logger.Error("failed to create a payment transaction: ", logger.Error(err))
+		return models.Checkout{}, err
+	}
+
+	return models.Checkout{
+		TransactionId: id,
+		Provider:      gateway.Name(),
+		Amount:        invoice.Outstanding,
+		CheckoutURL: gateway.CheckoutURL(payment.Checkout{
+			TransactionId: id,
+			InvoiceNumber: invoice.Number,
+			Amount:        invoice.Outstanding,
+		}),
+	}, nil
+}
+
+// Callback handles a callback of the provider and returns the body answering it. The
+// error is only returned when the callback could not be handled, the provider then sends
+// it again.
+func (s paymentService) Callback(ctx context.Context, provider string, header http.Header, body []byte) (any, error) {
+	gateway, err := payment.Get(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	callback, err := gateway.ParseCallback(header, body)
+	switch {
+	case errors.Is(err, payment.ErrBadSignature):
+		s.logger.Warning("payment callback with a bad signature: ", logger.String("provider", provider))
+		return gateway.Reply(callback, payment.Result{Code: payment.CodeBadSignature, Message: err.Error()}), nil
+	case errors.Is(err, payment.ErrUnknownAction):
+		return gateway.Reply(callback, payment.Result{Code: payment.CodeUnknownAction, Message: err.Error()}), nil
+	case err != nil:
+		return gateway.Reply(callback, payment.Result{Code: payment.CodeBadRequest, Message: err.Error()}), nil
+	}
+
+	result, err := s.reconcile(ctx, gateway.Name(), callback)
+	if err != nil {
+		s.logger.Error("failed to handle a payment callback: ", logger.Error(err))
+		return nil, err
+	}
+	return gateway.Reply(callback, result), nil
+}
+
+// reconcile moves the transaction of the callback along, a completed one records the
+// payment of its invoice. Callbacks sent again get the answer they got the first time.
+func (s paymentService) reconcile(ctx context.Context, provider string, callback payment.Callback) (payment.Result, error) {
+	if err := uuid.Validate(callback.TransactionId); err != nil {
+		return payment.Result{Code: payment.CodeUnknownTransaction, Message: "transaction is not found"}, nil
+	}
+	transaction, err := s.storage.BillingStorage().GetTransaction(ctx, callback.TransactionId)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && transaction.Provider != provider) {
+		return payment.Result{Code: payment.CodeUnknownTransaction, Message: "transaction is not found"}, nil
+	}
+	if err != nil {
+		return payment.Result{}, err
+	}
+	if callback.Amount != transaction.Amount {
+		return payment.Result{Code: payment.CodeBadAmount, Message: "amount does not match the transaction"}, nil
+	}
+
+	switch transaction.State {
+	case config.TRANSACTION_COMPLETED:
+		if callback.Action == payment.Complete && callback.ProviderTxId == transaction.ProviderTxId {
+			return payment.Result{Code: payment.CodeOK, Message: "success", PaymentId: transaction.PaymentId}, nil
+		}
+		return payment.Result{Code: payment.CodeAlreadyPaid, Message: "transaction is already paid"}, nil
+	case config.TRANSACTION_CANCELLED:
+		return payment.Result{Code: payment.CodeCancelled, Message: "transaction is cancelled"}, nil
+	}
+
+	if callback.Failed {
+		if err := s.storage.BillingStorage().CancelTransaction(ctx, transaction.Id); err != nil {
+			return payment.Result{}, err
+		}
+		return payment.Result{Code: payment.CodeCancelled, Message: "transaction is cancelled"}, nil
+	}
+
+	if callback.Action == payment.Prepare {
+		invoice, err := s.storage.BillingStorage().GetInvoice(ctx, transaction.InvoiceId, today())
+		if errors.Is(err, pgx.ErrNoRows) {
+			return payment.Result{Code: payment.CodeInvoiceNotFound, Message: "invoice is not found"}, nil
+		}
+		if err != nil {
+			return payment.Result{}, err
+		}
+		if invoice.Status == config.INVOICE_CANCELLED {
+			return payment.Result{Code: payment.CodeCancelled, Message: "invoice is cancelled"}, nil
+		}
+		if invoice.Outstanding < transaction.Amount {
+			return payment.Result{Code: payment.CodeAlreadyPaid, Message: "invoice is already paid"}, nil
+		}
+
+		err = s.storage.BillingStorage().PrepareTransaction(ctx, transaction.Id, callback.ProviderTxId)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return payment.Result{Code: payment.CodeBadRequest, Message: "transaction is prepared by another provider transaction"}, nil
+		}
+		if err != nil {
+			return payment.Result{}, err
+		}
+		return payment.Result{Code: payment.CodeOK, Message: "success"}, nil
+	}
+
+	if transaction.State != config.TRANSACTION_PREPARED || transaction.ProviderTxId != callback.ProviderTxId {
+		return payment.Result{Code: payment.CodeUnknownTransaction, Message: "transaction is not prepared"}, nil
+	}
+
+	paymentId, err := s.storage.BillingStorage().CompleteTransaction(ctx, transaction.Id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// the invoice was paid or cancelled since the transaction was prepared
+		if err := s.storage.BillingStorage().CancelTransaction(ctx, transaction.Id); err != nil {
+			return payment.Result{}, err
+		}
+		return payment.Result{Code: payment.CodeAlreadyPaid, Message: "invoice can no longer be paid"}, nil
+	}
+	if err != nil {
+		return payment.Result{}, err
+	}
+
+	invalidateDashboard(ctx, s.storage, s.logger)
+	s.logger.Info("payment is received: ", logger.String("provider", provider), logger.String("payment_id", paymentId))
+	return payment.Result{Code: payment.CodeOK, Message: "success", PaymentId: paymentId}, nil
+}
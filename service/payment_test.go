@@ -0,0 +1,214 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/pkg/payment"
+	"backend_course/lms/storage"
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPaySecret = "test-pay-secret"
+
+// fakeBilling keeps an invoice and its transactions in memory the way billingRepo keeps
+// them in the tables.
+type fakeBilling struct {
+	storage.BillingStorage
+	mu           sync.Mutex
+	invoice      models.Invoice
+	transactions map[string]*models.PaymentTransaction
+	payments     int
+}
+
+func newFakeBilling(amount int64) (*fakeBilling, string) {
+	id := uuid.NewString()
+	invoice := models.Invoice{Id: uuid.NewString(), Amount: amount, Total: amount, Outstanding: amount, Status: config.INVOICE_ISSUED}
+	return &fakeBilling{
+		invoice: invoice,
+		transactions: map[string]*models.PaymentTransaction{
+			id: {Id: id, InvoiceId: invoice.Id, Provider: "fake", Amount: amount, State: config.TRANSACTION_CREATED},
+		},
+	}, id
+}
+
+func (b *fakeBilling) GetTransaction(ctx context.Context, id string) (models.PaymentTransaction, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	transaction, ok := b.transactions[id]
+	if !ok {
+		return models.PaymentTransaction{}, pgx.ErrNoRows
+	}
+	return *transaction, nil
+}
+
+func (b *fakeBilling) GetInvoice(ctx context.Context, id, today string) (models.Invoice, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if id != b.invoice.Id {
+		return models.Invoice{}, pgx.ErrNoRows
+	}
+	return b.invoice, nil
+}
+
+func (b *fakeBilling) PrepareTransaction(ctx context.Context, id, providerTxId string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	transaction := b.transactions[id]
+	if transaction.State == config.TRANSACTION_CREATED ||
+		(transaction.State == config.TRANSACTION_PREPARED && transaction.ProviderTxId == providerTxId) {
+		transaction.State, transaction.ProviderTxId = config.TRANSACTION_PREPARED, providerTxId
+		return nil
+	}
+	return pgx.ErrNoRows
+}
+
+func (b *fakeBilling) CancelTransaction(ctx context.Context, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	transaction := b.transactions[id]
+	if transaction.State == config.TRANSACTION_CREATED || transaction.State == config.TRANSACTION_PREPARED {
+		transaction.State = config.TRANSACTION_CANCELLED
+	}
+	return nil
+}
+
+func (b *fakeBilling) CompleteTransaction(ctx context.Context, id string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	transaction := b.transactions[id]
+	if transaction.State == config.TRANSACTION_COMPLETED {
+		return transaction.PaymentId, nil
+	}
+	if transaction.State != config.TRANSACTION_PREPARED || b.invoice.Outstanding < transaction.Amount {
+		return "", pgx.ErrNoRows
+	}
+	b.payments++
+	b.invoice.Paid += transaction.Amount
+	b.invoice.Outstanding -= transaction.Amount
+	transaction.State, transaction.PaymentId = config.TRANSACTION_COMPLETED, uuid.NewString()
+	return transaction.PaymentId, nil
+}
+
+type callbackReply struct {
+	Error             int    `json:"error"`
+	MerchantConfirmId string `json:"merchant_confirm_id"`
+}
+
+// sendCallback plays the fake provider sending a callback signed with secret.
+func sendCallback(t *testing.T, s paymentService, secret string, callback payment.Callback) callbackReply {
+	t.Helper()
+	header, body := payment.NewFake(secret).Callback(callback)
+	resp, err := s.Callback(context.Background(), "fake", header, body)
+	if !assert.NoError(t, err) {
+		return callbackReply{}
+	}
+
+	var reply callbackReply
+	data, _ := json.Marshal(resp)
+	assert.NoError(t, json.Unmarshal(data, &reply))
+	return reply
+}
+
+func newTestPaymentService(amount int64) (paymentService, *fakeBilling, string) {
+	payment.Register(payment.NewFake(testPaySecret))
+	billing, id := newFakeBilling(amount)
+	return NewPaymentService(fakeStorage{billing: billing}, logger.New("test")), billing, id
+}
+
+func TestPaymentCallback(t *testing.T) {
+	s, billing, id := newTestPaymentService(500000)
+	callback := payment.Callback{ProviderTxId: "click-1", TransactionId: id, Amount: 500000, Action: payment.Prepare}
+
+	reply := sendCallback(t, s, testPaySecret, callback)
+	assert.Equal(t, payment.CodeOK, reply.Error)
+	assert.Equal(t, config.TRANSACTION_PREPARED, billing.transactions[id].State)
+
+	callback.Action = payment.Complete
+	reply = sendCallback(t, s, testPaySecret, callback)
+	assert.Equal(t, payment.CodeOK, reply.Error)
+	assert.NotEmpty(t, reply.MerchantConfirmId)
+	assert.Equal(t, 1, billing.payments)
+	assert.Equal(t, int64(0), billing.invoice.Outstanding)
+
+	// the provider repeats a callback it got no answer to, the payment is not recorded again
+	again := sendCallback(t, s, testPaySecret, callback)
+	assert.Equal(t, payment.CodeOK, again.Error)
+	assert.Equal(t, reply.MerchantConfirmId, again.MerchantConfirmId)
+	assert.Equal(t, 1, billing.payments)
+
+	// another provider transaction can not pay it again
+	callback.ProviderTxId = "click-2"
+	reply = sendCallback(t, s, testPaySecret, callback)
+	assert.Equal(t, payment.CodeAlreadyPaid, reply.Error)
+	assert.Equal(t, 1, billing.payments)
+}
+
+func TestPaymentCallbackConcurrentComplete(t *testing.T) {
+	s, billing, id := newTestPaymentService(500000)
+	callback := payment.Callback{ProviderTxId: "click-1", TransactionId: id, Amount: 500000, Action: payment.Prepare}
+	assert.Equal(t, payment.CodeOK, sendCallback(t, s, testPaySecret, callback).Error)
+
+	callback.Action = payment.Complete
+	var wg sync.WaitGroup
+	replies := make([]callbackReply, 5)
+	for i := range replies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			replies[i] = sendCallback(t, s, testPaySecret, callback)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, reply := range replies {
+		assert.Equal(t, payment.CodeOK, reply.Error)
+		assert.Equal(t, replies[0].MerchantConfirmId, reply.MerchantConfirmId)
+	}
+	assert.Equal(t, 1, billing.payments)
+}
+
+func TestPaymentCallbackBadSignature(t *testing.T) {
+	s, billing, id := newTestPaymentService(500000)
+
+	for _, action := range []string{payment.Prepare, payment.Complete} {
+		callback := payment.Callback{ProviderTxId: "click-1", TransactionId: id, Amount: 500000, Action: action}
+		reply := sendCallback(t, s, "guessed-secret", callback)
+		assert.Equal(t, payment.CodeBadSignature, reply.Error)
+	}
+	assert.Equal(t, config.TRANSACTION_CREATED, billing.transactions[id].State)
+	assert.Equal(t, 0, billing.payments)
+}
+
+func TestPaymentCallbackBadAmount(t *testing.T) {
+	s, billing, id := newTestPaymentService(500000)
+	callback := payment.Callback{ProviderTxId: "click-1", TransactionId: id, Amount: 1000, Action: payment.Prepare}
+
+	reply := sendCallback(t, s, testPaySecret, callback)
+	assert.Equal(t, payment.CodeBadAmount, reply.Error)
+	assert.Equal(t, config.TRANSACTION_CREATED, billing.transactions[id].State)
+
+	callback.Amount = 500000
+	assert.Equal(t, payment.CodeOK, sendCallback(t, s, testPaySecret, callback).Error)
+
+	callback.Action, callback.Amount = payment.Complete, 1000
+	reply = sendCallback(t, s, testPaySecret, callback)
+	assert.Equal(t, payment.CodeBadAmount, reply.Error)
+	assert.Equal(t, config.TRANSACTION_PREPARED, billing.transactions[id].State)
+	assert.Equal(t, 0, billing.payments)
+}
+
+func TestPaymentCallbackUnknownProvider(t *testing.T) {
+	s, _, id := newTestPaymentService(500000)
+	header, body := payment.NewFake(testPaySecret).Callback(payment.Callback{TransactionId: id, Amount: 500000})
+
+	_, err := s.Callback(context.Background(), "click", header, body)
+	assert.ErrorIs(t, err, payment.ErrUnknownProvider)
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/pkg/payroll"
+	"backend_course/lms/storage"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrPayrollOverlap = errors.New("period overlaps another payroll run")
+	ErrPeriodNotOver  = errors.New("payroll can only be run for a period which is over")
+)
+
+type payrollService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewPayrollService(storage storage.IStorage, logger logger.ILogger) payrollService {
+	return payrollService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (s payrollService) SetRate(ctx context.Context, teacherId string, rate models.SetRate) error {
+	if rate.HourlyRate < 0 {
+		return errors.New("hourly_rate can not be negative")
+	}
+
+	if err := s.storage.PayrollStorage().SetRate(ctx, teacherId, rate); err != nil {
+		s.logger.Error("failed to set a teacher's rate: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s payrollService) DeleteRate(ctx context.Context, teacherId, subjectId string) error {
+	if err := s.storage.PayrollStorage().DeleteRate(ctx, teacherId, subjectId); err != nil {
+		s.logger.Error("failed to delete a teacher's rate: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s payrollService) GetRates(ctx context.Context, teacherId string) (models.TeacherRates, error) {
+	rates, err := s.storage.PayrollStorage().GetRates(ctx, teacherId)
+	if err != nil {
+		s.logger.Error("failed to get a teacher's rates: ", logger.Error(err))
+		return rates, err
+	}
+	return rates, nil
+}
+
+// compute pays the lessons delivered from from to to.
+func (s payrollService) compute(ctx context.Context, from, to string) (models.PayrollRun, error) {
+	run := models.PayrollRun{FromDate: from, ToDate: to}
+
+	start, end, err := dayRange(from, to)
+	if err != nil {
+		return run, err
+	}
+
+	run.Lines, err = s.storage.PayrollStorage().GetDeliveredLessons(ctx, start, end)
+	if err != nil {
+		s.logger.Error("failed to get delivered lessons: ", logger.Error(err))
+		return run, err
+	}
+	for i := range run.Lines {
+		line := &run.Lines[i]
+		line.Hours = payroll.Hours(line.Minutes)
+		line.Amount = payroll.Amount(line.Minutes, line.HourlyRate)
+		run.Total += line.Amount
+	}
+	return run, nil
+}
+
+// Preview pays the lessons delivered from from to to without saving it, lessons which are
+// not over yet are left out.
+func (s payrollService) Preview(ctx context.Context, from, to string) (models.PayrollRun, error) {
+	if err := checkDates(from, to); err != nil {
+		return models.PayrollRun{}, err
+	}
+	return s.compute(ctx, from, to)
+}
+
+// CreateRun saves the pay of the lessons of a period which is over as a locked run, the
+// periods of runs can not overlap.
+func (s payrollService) CreateRun(ctx context.Context, req models.CreatePayrollRun) (string, error) {
+	if err := checkDates(req.FromDate, req.ToDate); err != nil {
+		return "", err
+	}
+	if req.ToDate >= today() {
+		return "", ErrPeriodNotOver
+	}
+
+	run, err := s.compute(ctx, req.FromDate, req.ToDate)
+	if err != nil {
+		return "", err
+	}
+	run.CreatedBy = req.CreatedBy
+
+	id, err := s.storage.PayrollStorage().CreateRun(ctx, run)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrPayrollOverlap
+	}
+	if err != nil {
+		s.logger.Error("failed to create a payroll run: ", logger.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+func (s payrollService) GetRun(ctx context.Context, id string) (models.PayrollRun, error) {
+	run, err := s.storage.PayrollStorage().GetRun(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get a payroll run: ", logger.Error(err))
+		return run, err
+	}
+	for i := range run.Lines {
+		run.Lines[i].Hours = payroll.Hours(run.Lines[i].Minutes)
+	}
+	return run, nil
+}
+
+func (s payrollService) GetRuns(ctx context.Context, req models.GetAllPayrollRunsRequest) (models.GetAllPayrollRunsResponse, error) {
+	resp, err := s.storage.PayrollStorage().GetRuns(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get payroll runs: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+// EachRun hands the payroll runs of the request to fn as they are read.
+func (s payrollService) EachRun(ctx context.Context, req models.GetAllPayrollRunsRequest, fn func(models.PayrollRun) error) error {
+	if err := s.storage.PayrollStorage().EachRun(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export payroll runs: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s payrollService) statement(ctx context.Context, id string) (payroll.Statement, error) {
+	run, err := s.GetRun(ctx, id)
+	if err != nil {
+		return payroll.Statement{}, err
+	}
+	lockedAt, err := pkg.ParseTime(ctx, run.LockedAt)
+	if err != nil {
+		return payroll.Statement{}, err
+	}
+
+	statement := payroll.Statement{
+		Period:   run.FromDate + " - " + run.ToDate,
+		Total:    run.Total,
+		LockedAt: lockedAt,
+	}
+	for _, line := range run.Lines {
+		statement.Lines = append(statement.Lines, payroll.Line{
+			Teacher:           line.TeacherName,
+			Subject:           line.SubjectName,
+			Lessons:           line.Lessons,
+			SubstituteLessons: line.SubstituteLessons,
+			Minutes:           line.Minutes,
+			HourlyRate:        line.HourlyRate,
+			Amount:            line.Amount,
+		})
+	}
+	return statement, nil
+}
+
+// StatementCSV writes the statement of a run as CSV to w.
+func (s payrollService) StatementCSV(ctx context.Context, id string, w io.Writer) error {
+	statement, err := s.statement(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := payroll.WriteCSV(w, statement); err != nil {
+		s.logger.Error("failed to write a payroll statement: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// StatementPDF writes the statement of a run as PDF to w.
+func (s payrollService) StatementPDF(ctx context.Context, id string, w io.Writer) error {
+	statement, err := s.statement(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := payroll.RenderPDF(w, statement); err != nil {
+		s.logger.Error("failed to render a payroll statement: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,417 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/pkg/quiz"
+	"backend_course/lms/storage"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrQuizClosed       = errors.New("the quiz is not open")
+	ErrNoAttemptsLeft   = errors.New("no attempts of the quiz are left")
+	ErrAttemptSubmitted = errors.New("the attempt is already submitted")
+	ErrAttemptExpired   = errors.New("the attempt's time limit is over, it is scored as empty")
+	ErrNotYourAttempt   = errors.New("the attempt belongs to another student")
+)
+
+type quizService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewQuizService(storage storage.IStorage, logger logger.ILogger) quizService {
+	return quizService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func toQuizQuestion(q models.QuizQuestion) quiz.Question {
+	question := quiz.Question{
+		Kind:      q.Kind,
+		Options:   q.Options,
+		Correct:   q.Correct,
+		Tolerance: q.Tolerance,
+		Accepted:  q.Accepted,
+		Points:    q.Points,
+	}
+	if q.Number != nil {
+		question.Number = *q.Number
+	}
+	return question
+}
+
+// CreateQuiz creates the quiz with an assessment of the subject's gradebook its scores are
+// written to, the assessment is held on the day the quiz opens.
+func (s quizService) CreateQuiz(ctx context.Context, teacherId string, req models.AddQuiz) (string, error) {
+	if strings.TrimSpace(req.Title) == "" {
+		return "", errors.New("title is required")
+	}
+	opensAt, err := pkg.ParseTime(ctx, req.OpensAt)
+	if err != nil {
+		return "", err
+	}
+	closesAt, err := pkg.ParseTime(ctx, req.ClosesAt)
+	if err != nil {
+		return "", err
+	}
+	if !closesAt.After(opensAt) {
+		return "", errors.New("closes_at must be after opens_at")
+	}
+	req.OpensAt, req.ClosesAt = opensAt.Format(time.RFC3339), closesAt.Format(time.RFC3339)
+	if req.TimeLimitMinutes <= 0 {
+		return "", errors.New("time limit must be positive")
+	}
+	if req.MaxAttempts == 0 {
+		req.MaxAttempts = 1
+	}
+	if req.MaxAttempts < 0 {
+		return "", errors.New("max attempts must be positive")
+	}
+	if req.Weight == 0 {
+		req.Weight = 1
+	}
+	if req.Weight < 0 {
+		return "", errors.New("weight must be positive")
+	}
+	if len(req.Questions) == 0 {
+		return "", errors.New("a quiz needs questions")
+	}
+
+	var maxScore float64
+	for i, q := range req.Questions {
+		if strings.TrimSpace(q.Text) == "" {
+			return "", fmt.Errorf("question %d: text is required", i+1)
+		}
+		if q.Kind == quiz.Numeric && q.Number == nil {
+			return "", fmt.Errorf("question %d: a numeric question needs a number", i+1)
+		}
+		question := toQuizQuestion(models.QuizQuestion{Kind: q.Kind, Options: q.Options, Correct: q.Correct,
+			Number: q.Number, Tolerance: q.Tolerance, Accepted: q.Accepted, Points: q.Points})
+		if err := quiz.Check(question); err != nil {
+			return "", fmt.Errorf("question %d: %w", i+1, err)
+		}
+		if req.Questions[i].Options == nil {
+			req.Questions[i].Options = []string{}
+		}
+		if req.Questions[i].Correct == nil {
+			req.Questions[i].Correct = []int{}
+		}
+		if req.Questions[i].Accepted == nil {
+			req.Questions[i].Accepted = []string{}
+		}
+		maxScore += q.Points
+	}
+
+	if err := checkSubjectTeacher(ctx, s.storage, s.logger, teacherId, req.SubjectId); err != nil {
+		return "", err
+	}
+
+	assessment := models.AddAssessment{
+		SubjectId: req.SubjectId,
+		GroupId:   req.GroupId,
+		Name:      req.Title,
+		Kind:      config.ASSESSMENT_QUIZ,
+		MaxScore:  maxScore,
+		Weight:    req.Weight,
+		HeldOn:    opensAt.In(time.Local).Format("2006-01-02"),
+	}
+
+	id, err := s.storage.QuizStorage().CreateQuiz(ctx, teacherId, req, assessment)
+	if err != nil {
+		s.logger.Error("failed to create a quiz: ", logger.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+func (s quizService) GetQuiz(ctx context.Context, id string) (models.Quiz, error) {
+	resp, err := s.storage.QuizStorage().GetQuiz(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get a quiz: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+func (s quizService) DeleteQuiz(ctx context.Context, teacherId, id string) error {
+	q, err := s.GetQuiz(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := checkSubjectTeacher(ctx, s.storage, s.logger, teacherId, q.SubjectId); err != nil {
+		return err
+	}
+
+	if err := s.storage.QuizStorage().DeleteQuiz(ctx, id); err != nil {
+		s.logger.Error("failed to delete a quiz: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s quizService) GetQuizzes(ctx context.Context, req models.GetAllQuizzesRequest) (models.GetAllQuizzesResponse, error) {
+	resp, err := s.storage.QuizStorage().GetQuizzes(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get quizzes: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+// EachQuiz hands the quizzes of the request to fn as they are read.
+func (s quizService) EachQuiz(ctx context.Context, req models.GetAllQuizzesRequest, fn func(models.Quiz) error) error {
+	if err := s.storage.QuizStorage().EachQuiz(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export quizzes: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// attemptQuestions lists the quiz's questions in the attempt's order without their answers.
+func attemptQuestions(q models.Quiz, order []string) []models.AttemptQuestion {
+	byId := make(map[string]models.QuizQuestion, len(q.Questions))
+	for _, question := range q.Questions {
+		byId[question.Id] = question
+	}
+
+	questions := make([]models.AttemptQuestion, 0, len(order))
+	for _, id := range order {
+		if question, ok := byId[id]; ok {
+			questions = append(questions, models.AttemptQuestion{
+				Id:      question.Id,
+				Kind:    question.Kind,
+				Text:    question.Text,
+				Options: question.Options,
+				Points:  question.Points,
+			})
+		}
+	}
+	return questions
+}
+
+// StartAttempt starts an attempt of the quiz with its questions in a random order, an attempt
+// still running is given back instead. The attempt ends after the time limit or when the
+// quiz closes, whichever comes first.
+func (s quizService) StartAttempt(ctx context.Context, quizId, studentId string) (models.QuizAttempt, error) {
+	q, err := s.GetQuiz(ctx, quizId)
+	if err != nil {
+		return models.QuizAttempt{}, err
+	}
+	opensAt, err := pkg.ParseTime(ctx, q.OpensAt)
+	if err != nil {
+		return models.QuizAttempt{}, err
+	}
+	closesAt, err := pkg.ParseTime(ctx, q.ClosesAt)
+	if err != nil {
+		return models.QuizAttempt{}, err
+	}
+
+	now := time.Now()
+	if now.Before(opensAt) || !now.Before(closesAt) {
+		return models.QuizAttempt{}, ErrQuizClosed
+	}
+
+	attempts, err := s.storage.QuizStorage().GetAttempts(ctx, quizId, studentId)
+	if err != nil {
+		s.logger.Error("failed to get the student's attempts: ", logger.Error(err))
+		return models.QuizAttempt{}, err
+	}
+	for _, attempt := range attempts.Attempts {
+		deadline, err := pkg.ParseTime(ctx, attempt.Deadline)
+		if err != nil {
+			return models.QuizAttempt{}, err
+		}
+		if attempt.SubmittedAt == "" && now.Before(deadline) {
+			attempt.Questions = attemptQuestions(q, attempt.QuestionOrder)
+			return attempt, nil
+		}
+	}
+	if len(attempts.Attempts) >= q.MaxAttempts {
+		return models.QuizAttempt{}, ErrNoAttemptsLeft
+	}
+
+	order := make([]string, 0, len(q.Questions))
+	for _, question := range q.Questions {
+		order = append(order, question.Id)
+	}
+	quiz.Shuffle(order)
+
+	deadline := now.Add(time.Duration(q.TimeLimitMinutes) * time.Minute)
+	if deadline.After(closesAt) {
+		deadline = closesAt
+	}
+
+	attempt := models.QuizAttempt{
+		QuizId:        quizId,
+		StudentId:     studentId,
+		QuestionOrder: order,
+		Deadline:      deadline.Format(time.RFC3339),
+	}
+	attempt.Id, err = s.storage.QuizStorage().CreateAttempt(ctx, attempt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if len(attempts.Attempts) > 0 {
+			return models.QuizAttempt{}, ErrNoAttemptsLeft
+		}
+		// another attempt may have been started since the attempts were read above
+		attempts, err = s.storage.QuizStorage().GetAttempts(ctx, quizId, studentId)
+		if err != nil {
+			s.logger.Error("failed to get the student's attempts: ", logger.Error(err))
+			return models.QuizAttempt{}, err
+		}
+		if len(attempts.Attempts) > 0 {
+			return models.QuizAttempt{}, ErrNoAttemptsLeft
+		}
+		return models.QuizAttempt{}, ErrNotInGroup
+	}
+	if err != nil {
+		s.logger.Error("failed to start an attempt: ", logger.Error(err))
+		return models.QuizAttempt{}, err
+	}
+
+	attempt, err = s.storage.QuizStorage().GetAttempt(ctx, attempt.Id)
+	if err != nil {
+		s.logger.Error("failed to get an attempt: ", logger.Error(err))
+		return models.QuizAttempt{}, err
+	}
+	attempt.Questions = attemptQuestions(q, attempt.QuestionOrder)
+	return attempt, nil
+}
+
+// SubmitAttempt grades the answers of the attempt and writes the student's best score into
+// the gradebook. Answers coming after the time limit are not graded, the attempt is scored
+// as empty.
+func (s quizService) SubmitAttempt(ctx context.Context, studentId, attemptId string, req models.SubmitQuizRequest) (models.QuizAttempt, error) {
+	attempt, err := s.storage.QuizStorage().GetAttempt(ctx, attemptId)
+	if err != nil {
+		s.logger.Error("failed to get an attempt: ", logger.Error(err))
+		return attempt, err
+	}
+	if attempt.StudentId != studentId {
+		return models.QuizAttempt{}, ErrNotYourAttempt
+	}
+	if attempt.SubmittedAt != "" {
+		return models.QuizAttempt{}, ErrAttemptSubmitted
+	}
+	deadline, err := pkg.ParseTime(ctx, attempt.Deadline)
+	if err != nil {
+		return models.QuizAttempt{}, err
+	}
+
+	q, err := s.GetQuiz(ctx, attempt.QuizId)
+	if err != nil {
+		return models.QuizAttempt{}, err
+	}
+
+	expired := time.Now().After(deadline.Add(config.QuizSubmitGrace))
+
+	var (
+		logs  []models.QuizAnswerLog
+		score float64
+	)
+	if !expired {
+		answers := make(map[string]models.QuizAnswer, len(req.Answers))
+		for _, answer := range req.Answers {
+			answers[answer.QuestionId] = answer
+		}
+
+		for _, question := range q.Questions {
+			answer, ok := answers[question.Id]
+			if !ok {
+				continue
+			}
+			if answer.Choices == nil {
+				answer.Choices = []int{}
+			}
+			correct, points := quiz.Grade(toQuizQuestion(question), quiz.Answer{
+				Choices: answer.Choices,
+				Number:  answer.Number,
+				Text:    answer.Text,
+			})
+			logs = append(logs, models.QuizAnswerLog{
+				QuestionId: question.Id,
+				Choices:    answer.Choices,
+				Number:     answer.Number,
+				Text:       answer.Text,
+				Correct:    correct,
+				Points:     points,
+			})
+			score += points
+		}
+	}
+
+	err = s.storage.QuizStorage().SubmitAttempt(ctx, attemptId, logs, score, expired)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.QuizAttempt{}, ErrAttemptSubmitted
+	}
+	if err != nil {
+		s.logger.Error("failed to submit an attempt: ", logger.Error(err))
+		return models.QuizAttempt{}, err
+	}
+	if expired {
+		return models.QuizAttempt{}, ErrAttemptExpired
+	}
+
+	attempt, err = s.storage.QuizStorage().GetAttempt(ctx, attemptId)
+	if err != nil {
+		s.logger.Error("failed to get an attempt: ", logger.Error(err))
+		return attempt, err
+	}
+	return attempt, nil
+}
+
+// GetAttempts returns the attempts of the quiz, only the subject's teachers can see them.
+func (s quizService) GetAttempts(ctx context.Context, teacherId, quizId string) (models.GetQuizAttemptsResponse, error) {
+	q, err := s.GetQuiz(ctx, quizId)
+	if err != nil {
+		return models.GetQuizAttemptsResponse{}, err
+	}
+
+	if err := checkSubjectTeacher(ctx, s.storage, s.logger, teacherId, q.SubjectId); err != nil {
+		return models.GetQuizAttemptsResponse{}, err
+	}
+
+	resp, err := s.storage.QuizStorage().GetAttempts(ctx, quizId, "")
+	if err != nil {
+		s.logger.Error("failed to get attempts: ", logger.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+// GetAttempt returns the log of an attempt with its graded answers to the subject's teachers
+// and to the student who made it.
+func (s quizService) GetAttempt(ctx context.Context, authInfo models.AuthInfo, id string) (models.QuizAttempt, error) {
+	attempt, err := s.storage.QuizStorage().GetAttempt(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get an attempt: ", logger.Error(err))
+		return attempt, err
+	}
+
+	if authInfo.UserRole == config.STUDENT_TYPE {
+		if attempt.StudentId != authInfo.UserID {
+			return models.QuizAttempt{}, ErrNotYourAttempt
+		}
+		return attempt, nil
+	}
+
+	q, err := s.GetQuiz(ctx, attempt.QuizId)
+	if err != nil {
+		return models.QuizAttempt{}, err
+	}
+	if err := checkSubjectTeacher(ctx, s.storage, s.logger, authInfo.UserID, q.SubjectId); err != nil {
+		return models.QuizAttempt{}, err
+	}
+	return attempt, nil
+}
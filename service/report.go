@@ -0,0 +1,344 @@
+package service
+
+import (
+	"archive/zip"
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/pkg/report"
+	"backend_course/lms/storage"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidReportPeriod = errors.New("report period is not valid")
+
+type reportService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewReportService(storage storage.IStorage, logger logger.ILogger) reportService {
+	return reportService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// reportPeriod is the period of a report as the dates its assessments are held between,
+// the timestamps bounding its days and the label printed on the report.
+type reportPeriod struct {
+	fromDate, toDate string
+	from, to         string
+	label            string
+}
+
+func (s reportService) period(ctx context.Context, req models.ReportRequest) (reportPeriod, error) {
+	var p reportPeriod
+	if req.TermId != "" {
+		term, err := s.storage.CalendarStorage().GetTerm(ctx, req.TermId)
+		if err != nil {
+			s.logger.Error("failed to get a term: ", logger.Error(err))
+			return p, err
+		}
+		p.fromDate, p.toDate = term.StartDate, term.EndDate
+		p.label = fmt.Sprintf("%s: %s - %s", term.Name, term.StartDate, term.EndDate)
+	} else {
+		if req.FromDate == "" || req.ToDate == "" {
+			return p, fmt.Errorf("%w: term_id or from_date and to_date are required", ErrInvalidReportPeriod)
+		}
+		if err := checkDates(req.FromDate, req.ToDate); err != nil {
+			return p, fmt.Errorf("%w: %v", ErrInvalidReportPeriod, err)
+		}
+		p.fromDate, p.toDate = req.FromDate, req.ToDate
+		p.label = fmt.Sprintf("%s - %s", req.FromDate, req.ToDate)
+	}
+
+	var err error
+	p.from, p.to, err = dayRange(p.fromDate, p.toDate)
+	return p, err
+}
+
+// progress gathers the student's profile, attendance, grades and teacher comments of the
+// period.
+func (s reportService) progress(ctx context.Context, studentId uuid.UUID, p reportPeriod, scale string) (report.Progress, error) {
+	student, err := s.storage.StudentStorage().GetStudent(ctx, studentId)
+	if err != nil {
+		s.logger.Error("failed to get a student: ", logger.Error(err))
+		return report.Progress{}, err
+	}
+
+	attendance, err := s.storage.AttendanceStorage().GetSummary(ctx, studentId.String(), p.from, p.to)
+	if err != nil {
+		s.logger.Error("failed to get attendance summary: ", logger.Error(err))
+		return report.Progress{}, err
+	}
+
+	rows, err := s.storage.GradebookStorage().GetGradeRows(ctx, models.GradesRequest{
+		StudentId: studentId.String(),
+		FromDate:  p.fromDate,
+		ToDate:    p.toDate,
+	})
+	if err != nil {
+		s.logger.Error("failed to get grades: ", logger.Error(err))
+		return report.Progress{}, err
+	}
+
+	comments, err := s.storage.HomeworkStorage().GetComments(ctx, studentId.String(), p.from, p.to)
+	if err != nil {
+		s.logger.Error("failed to get teacher comments: ", logger.Error(err))
+		return report.Progress{}, err
+	}
+
+	progress := report.Progress{
+		Student: report.Student{
+			Name:       student.FirstName + " " + student.LastName,
+			ExternalId: student.ExternalId,
+			Email:      student.Email,
+			Phone:      student.Phone,
+			Age:        student.Age,
+			IsActive:   student.IsActive,
+		},
+		Period: p.label,
+		Scale:  scale,
+		Attendance: report.Attendance{
+			Lessons:  attendance.Lessons,
+			Present:  attendance.Present,
+			Late:     attendance.Late,
+			Absent:   attendance.Absent,
+			Excused:  attendance.Excused,
+			Unmarked: attendance.Unmarked,
+		},
+		GeneratedAt: time.Now(),
+	}
+
+	for _, subject := range subjectGrades(scale, rows) {
+		scored := 0
+		for _, a := range subject.Assessments {
+			if a.Score != nil {
+				scored++
+			}
+		}
+		progress.Subjects = append(progress.Subjects, report.Subject{
+			Name:        subject.SubjectName,
+			Assessments: len(subject.Assessments),
+			Scored:      scored,
+			Percent:     subject.Percent,
+			Grade:       subject.Grade,
+		})
+	}
+
+	for _, c := range comments {
+		date := c.ReviewedAt
+		if t, err := time.Parse(time.RFC3339, c.ReviewedAt); err == nil {
+			date = t.Format("2006-01-02")
+		}
+		progress.Comments = append(progress.Comments, report.Comment{
+			Date:    date,
+			Subject: c.SubjectName,
+			Teacher: c.TeacherName,
+			Title:   c.Assignment,
+			Text:    c.Comment,
+		})
+	}
+
+	return progress, nil
+}
+
+// StudentPDF writes the student's progress report of the period to w as PDF.
+func (s reportService) StudentPDF(ctx context.Context, studentId uuid.UUID, req models.ReportRequest, w io.Writer) error {
+	scale, err := gradeScale(req.Scale)
+	if err != nil {
+		return err
+	}
+	p, err := s.period(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	progress, err := s.progress(ctx, studentId, p, scale)
+	if err != nil {
+		return err
+	}
+
+	if err := report.Render(w, progress); err != nil {
+		s.logger.Error("failed to render a report: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+var unsafeFileName = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// GroupZIP writes a ZIP of the progress reports of every student of the group to w.
+func (s reportService) GroupZIP(ctx context.Context, groupId string, req models.ReportRequest, w io.Writer) error {
+	scale, err := gradeScale(req.Scale)
+	if err != nil {
+		return err
+	}
+	p, err := s.period(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	group, err := s.storage.GroupStorage().GetGroup(ctx, groupId)
+	if err != nil {
+		s.logger.Error("failed to get a group: ", logger.Error(err))
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	for _, id := range group.StudentIds {
+		studentId, err := uuid.Parse(id)
+		if err != nil {
+			return err
+		}
+		progress, err := s.progress(ctx, studentId, p, scale)
+		if err != nil {
+			return err
+		}
+
+		name := unsafeFileName.ReplaceAllString(progress.Student.Name, "_")
+		file, err := archive.Create(fmt.Sprintf("%s_%s.pdf", name, id[:8]))
+		if err != nil {
+			return err
+		}
+		if err := report.Render(file, progress); err != nil {
+			s.logger.Error("failed to render a report: ", logger.Error(err))
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// TeacherWorkload sums the scheduled lessons of every teacher in a term or from from_date
+// to to_date per week or month, subject, room and group.
+func (s reportService) TeacherWorkload(ctx context.Context, req models.TeacherWorkloadRequest) (models.TeacherWorkloadResponse, error) {
+	req, p, err := s.workloadRequest(ctx, req)
+	if err != nil {
+		return models.TeacherWorkloadResponse{}, err
+	}
+
+	resp, err := s.storage.TimeStorage().GetTeacherWorkload(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get teachers' workload: ", logger.Error(err))
+		return resp, err
+	}
+	resp.FromDate, resp.ToDate, resp.Interval = p.fromDate, p.toDate, req.Interval
+	return resp, nil
+}
+
+// EachTeacherWorkload hands the sums of the teachers of the request to fn as they are read.
+func (s reportService) EachTeacherWorkload(ctx context.Context, req models.TeacherWorkloadRequest, fn func(models.TeacherWorkload) error) error {
+	req, _, err := s.workloadRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if err := s.storage.TimeStorage().EachTeacherWorkload(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export teachers' workload: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// workloadRequest checks the interval of a workload request and bounds it by its period.
+func (s reportService) workloadRequest(ctx context.Context, req models.TeacherWorkloadRequest) (models.TeacherWorkloadRequest, reportPeriod, error) {
+	switch req.Interval {
+	case "":
+		req.Interval = "week"
+	case "week", "month":
+	default:
+		return req, reportPeriod{}, fmt.Errorf("interval %q is not valid, use week or month", req.Interval)
+	}
+
+	p, err := s.period(ctx, models.ReportRequest{TermId: req.TermId, FromDate: req.FromDate, ToDate: req.ToDate})
+	if err != nil {
+		return req, p, err
+	}
+	req.From, req.To = p.from, p.to
+	return req, p, nil
+}
+
+// RoomUtilization computes how much of their opening hours the rooms are booked for
+// lessons in a term or from from_date to to_date, per day or week and per hour of the
+// week. Rooms below req.UnderUsed percent, config.UnderUsedOccupancy by default, are
+// under-used.
+func (s reportService) RoomUtilization(ctx context.Context, req models.RoomUtilizationRequest) (models.RoomUtilizationResponse, error) {
+	req, p, underUsed, err := s.utilizationRequest(ctx, req)
+	if err != nil {
+		return models.RoomUtilizationResponse{}, err
+	}
+
+	resp, err := s.storage.RoomStorage().GetUtilization(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get rooms' utilization: ", logger.Error(err))
+		return resp, err
+	}
+	resp.FromDate, resp.ToDate, resp.Interval = p.fromDate, p.toDate, req.Interval
+	resp.UnderUsed = []string{}
+	for i, room := range resp.Rooms {
+		if room.Occupancy < float64(underUsed) {
+			resp.Rooms[i].UnderUsed = true
+			resp.UnderUsed = append(resp.UnderUsed, room.RoomName)
+		}
+	}
+	return resp, nil
+}
+
+// EachRoomUtilization hands the occupancy of the rooms per day or week to fn as it is
+// read, each room's periods are followed by its total which tells whether it is
+// under-used.
+func (s reportService) EachRoomUtilization(ctx context.Context, req models.RoomUtilizationRequest, fn func(models.RoomUtilizationRow) error) error {
+	req, _, underUsed, err := s.utilizationRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	err = s.storage.RoomStorage().EachUtilization(ctx, req, func(row models.RoomUtilizationRow) error {
+		if row.Period == "total" {
+			under := row.Occupancy < float64(underUsed)
+			row.UnderUsed = &under
+		}
+		return fn(row)
+	})
+	if err != nil {
+		s.logger.Error("failed to export rooms' utilization: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// utilizationRequest checks the interval and the under-used percent of a utilization
+// request and bounds it by its period and the rooms' default opening hours.
+func (s reportService) utilizationRequest(ctx context.Context, req models.RoomUtilizationRequest) (models.RoomUtilizationRequest, reportPeriod, int, error) {
+	switch req.Interval {
+	case "":
+		req.Interval = "day"
+	case "day", "week":
+	default:
+		return req, reportPeriod{}, 0, fmt.Errorf("interval %q is not valid, use day or week", req.Interval)
+	}
+	underUsed := config.UnderUsedOccupancy
+	if req.UnderUsed != nil {
+		underUsed = *req.UnderUsed
+	}
+	if underUsed < 0 || underUsed > 100 {
+		return req, reportPeriod{}, 0, errors.New("under_used must be between 0 and 100")
+	}
+
+	p, err := s.period(ctx, models.ReportRequest{TermId: req.TermId, FromDate: req.FromDate, ToDate: req.ToDate})
+	if err != nil {
+		return req, p, 0, err
+	}
+	req.From, req.To = p.from, p.to
+	req.OpensAt, req.ClosesAt = config.RoomOpensAt, config.RoomClosesAt
+	return req, p, underUsed, nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg/logger"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestReportService() reportService {
+	calendar := &fakeCalendar{terms: map[string]models.Term{
+		"autumn": {Id: "autumn", Name: "Autumn", StartDate: "2024-09-02", EndDate: "2024-12-27"},
+	}}
+	return NewReportService(fakeStorage{calendar: calendar}, logger.New("test"))
+}
+
+func TestWorkloadRequest(t *testing.T) {
+	s := newTestReportService()
+	ctx := context.Background()
+
+	cases := []struct {
+		interval, want string
+	}{
+		{"", "week"},
+		{"week", "week"},
+		{"month", "month"},
+	}
+	for _, c := range cases {
+		req, p, err := s.workloadRequest(ctx, models.TeacherWorkloadRequest{TermId: "autumn", Interval: c.interval})
+		if assert.NoError(t, err, c.interval) {
+			assert.Equal(t, c.want, req.Interval)
+			assert.Equal(t, "2024-09-02", p.fromDate)
+			assert.Equal(t, time.Date(2024, 12, 27, 23, 59, 59, 0, time.Local).Format(time.RFC3339), req.To)
+		}
+	}
+
+	_, _, err := s.workloadRequest(ctx, models.TeacherWorkloadRequest{TermId: "autumn", Interval: "day"})
+	assert.Error(t, err)
+	_, _, err = s.workloadRequest(ctx, models.TeacherWorkloadRequest{FromDate: "2024-09-02"})
+	assert.ErrorIs(t, err, ErrInvalidReportPeriod)
+	_, _, err = s.workloadRequest(ctx, models.TeacherWorkloadRequest{FromDate: "2024-09-02", ToDate: "2024-09-01"})
+	assert.ErrorIs(t, err, ErrInvalidReportPeriod)
+
+	req, _, err := s.workloadRequest(ctx, models.TeacherWorkloadRequest{FromDate: "2024-09-02", ToDate: "2024-09-08"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2024, 9, 2, 0, 0, 0, 0, time.Local).Format(time.RFC3339), req.From)
+	}
+}
+
+func TestUtilizationRequest(t *testing.T) {
+	s := newTestReportService()
+	ctx := context.Background()
+	percent := func(n int) *int { return &n }
+
+	cases := []struct {
+		interval  string
+		underUsed *int
+		want      string
+		wantUsed  int
+	}{
+		{"", nil, "day", config.UnderUsedOccupancy},
+		{"week", nil, "week", config.UnderUsedOccupancy},
+		{"day", percent(0), "day", 0},
+		{"day", percent(100), "day", 100},
+	}
+	for _, c := range cases {
+		req, _, underUsed, err := s.utilizationRequest(ctx, models.RoomUtilizationRequest{TermId: "autumn", Interval: c.interval, UnderUsed: c.underUsed})
+		if assert.NoError(t, err, c.interval) {
+			assert.Equal(t, c.want, req.Interval)
+			assert.Equal(t, c.wantUsed, underUsed)
+			assert.Equal(t, config.RoomOpensAt, req.OpensAt)
+			assert.Equal(t, config.RoomClosesAt, req.ClosesAt)
+		}
+	}
+
+	for _, req := range []models.RoomUtilizationRequest{
+		{TermId: "autumn", Interval: "month"},
+		{TermId: "autumn", UnderUsed: percent(-1)},
+		{TermId: "autumn", UnderUsed: percent(101)},
+		{ToDate: "2024-09-08"},
+	} {
+		_, _, _, err := s.utilizationRequest(ctx, req)
+		assert.Error(t, err, "%+v", req)
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/pkg/scheduler"
+	"backend_course/lms/storage"
+	"context"
+	"fmt"
+)
+
+type roomService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewRoomService(storage storage.IStorage, logger logger.ILogger) roomService {
+	return roomService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+func (s roomService) Create(ctx context.Context, room models.AddRoom) (string, error) {
+	id, err := s.storage.RoomStorage().Create(ctx, room)
+	if err != nil {
+		s.logger.Error("failed to create a room: ", logger.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+func (s roomService) Update(ctx context.Context, room models.Room) (string, error) {
+	id, err := s.storage.RoomStorage().Update(ctx, room)
+	if err != nil {
+		s.logger.Error("failed to update a room: ", logger.Error(err))
+		return "", err
+	}
+	return id, nil
+}
+
+func (s roomService) Delete(ctx context.Context, id string) error {
+	err := s.storage.RoomStorage().Delete(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to delete a room: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s roomService) GetAll(ctx context.Context, req models.GetAllRoomsRequest) (models.GetAllRoomsResponse, error) {
+	res, err := s.storage.RoomStorage().GetAll(ctx, req)
+	if err != nil {
+		s.logger.Error("failed to get all rooms: ", logger.Error(err))
+		return res, err
+	}
+	return res, nil
+}
+
+// Each hands the rooms of the request to fn as they are read.
+func (s roomService) Each(ctx context.Context, req models.GetAllRoomsRequest, fn func(models.Room) error) error {
+	if err := s.storage.RoomStorage().Each(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export rooms: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s roomService) GetRoom(ctx context.Context, id string) (models.Room, error) {
+	room, err := s.storage.RoomStorage().GetRoom(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get a room: ", logger.Error(err))
+		return room, err
+	}
+	return room, nil
+}
+
+func (s roomService) SetHours(ctx context.Context, roomId string, req models.RoomHours) error {
+	for _, w := range req.Windows {
+		if w.Weekday < 0 || w.Weekday > 6 {
+			return fmt.Errorf("weekday %d is not valid, use 0 (Sunday) to 6 (Saturday)", w.Weekday)
+		}
+		start, err := scheduler.ParseClock(w.Start)
+		if err != nil {
+			return err
+		}
+		end, err := scheduler.ParseClock(w.End)
+		if err != nil {
+			return err
+		}
+		if end <= start {
+			return fmt.Errorf("window %s-%s ends before it starts", w.Start, w.End)
+		}
+	}
+
+	if err := s.storage.RoomStorage().SetHours(ctx, roomId, req.Windows); err != nil {
+		s.logger.Error("failed to set a room's opening hours: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s roomService) GetHours(ctx context.Context, roomId string) (models.RoomHours, error) {
+	windows, err := s.storage.RoomStorage().GetHours(ctx, roomId)
+	if err != nil {
+		s.logger.Error("failed to get a room's opening hours: ", logger.Error(err))
+		return models.RoomHours{}, err
+	}
+	return models.RoomHours{Windows: windows}, nil
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg/logger"
+	"backend_course/lms/pkg/scheduler"
+	"backend_course/lms/storage"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const maxScheduleRooms = 1000
+
+type scheduleService struct {
+	storage storage.IStorage
+	logger  logger.ILogger
+}
+
+func NewScheduleService(storage storage.IStorage, logger logger.ILogger) scheduleService {
+	return scheduleService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Generate solves a timetable for the request and saves it as a draft for review.
+func (s scheduleService) Generate(ctx context.Context, req models.GenerateScheduleRequest) (models.ScheduleDraft, error) {
+	draft := models.ScheduleDraft{}
+
+	if req.TermId != "" {
+		if err := s.fitTerm(ctx, &req); err != nil {
+			return draft, err
+		}
+	}
+
+	weekStart, err := time.ParseInLocation("2006-01-02", req.WeekStart, time.Local)
+	if err != nil {
+		return draft, fmt.Errorf("week_start is not a date: %w", err)
+	}
+	if weekStart.Weekday() != time.Monday {
+		return draft, errors.New("week_start must be a Monday")
+	}
+	if req.Weeks <= 0 {
+		req.Weeks = 1
+	}
+	if len(req.Weekdays) == 0 {
+		req.Weekdays = []int{1, 2, 3, 4, 5}
+	}
+	if len(req.Requirements) == 0 {
+		return draft, errors.New("no requirements given")
+	}
+
+	in, err := s.input(ctx, req, weekStart)
+	if err != nil {
+		return draft, err
+	}
+
+	res, err := scheduler.Solve(in, scheduler.Options{Seed: req.Seed})
+	if err != nil {
+		s.logger.Error("failed to generate a schedule: ", logger.Error(err))
+		return draft, err
+	}
+
+	draft = models.ScheduleDraft{
+		Status:    config.SCHEDULE_DRAFT,
+		WeekStart: req.WeekStart,
+		Weeks:     req.Weeks,
+		Request:   req,
+		Cost: models.ScheduleCost{
+			TeacherGaps:    res.Cost.TeacherGaps,
+			GroupGaps:      res.Cost.GroupGaps,
+			Imbalance:      res.Cost.Imbalance,
+			SubjectRepeats: res.Cost.SubjectRepeats,
+			Total:          res.Cost.Total,
+		},
+	}
+	for _, l := range res.Lessons {
+		draft.Lessons = append(draft.Lessons, models.ScheduledLesson{
+			GroupId:   l.GroupId,
+			SubjectId: l.SubjectId,
+			TeacherId: l.TeacherId,
+			RoomName:  l.Room,
+			Weekday:   int(l.Day),
+			Period:    l.Period,
+			StartTime: l.Start,
+			EndTime:   l.End,
+		})
+	}
+
+	draft.Id, err = s.storage.ScheduleStorage().CreateDraft(ctx, draft)
+	if err != nil {
+		s.logger.Error("failed to save a schedule draft: ", logger.Error(err))
+		return draft, err
+	}
+
+	return draft, nil
+}
+
+// fitTerm makes the draft cover the term when week_start or weeks are not given, the lessons
+// are committed only on the term's days.
+func (s scheduleService) fitTerm(ctx context.Context, req *models.GenerateScheduleRequest) error {
+	term, err := s.storage.CalendarStorage().GetTerm(ctx, req.TermId)
+	if err != nil {
+		s.logger.Error("failed to get a term: ", logger.Error(err))
+		return fmt.Errorf("term %s: %w", req.TermId, err)
+	}
+
+	start, err := time.Parse("2006-01-02", term.StartDate)
+	if err != nil {
+		return err
+	}
+	end, err := time.Parse("2006-01-02", term.EndDate)
+	if err != nil {
+		return err
+	}
+
+	if req.WeekStart == "" {
+		monday := start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+		req.WeekStart = monday.Format("2006-01-02")
+	}
+	if req.Weeks <= 0 {
+		weekStart, err := time.Parse("2006-01-02", req.WeekStart)
+		if err != nil {
+			return fmt.Errorf("week_start is not a date: %w", err)
+		}
+		req.Weeks = int(end.Sub(weekStart).Hours()/24)/7 + 1
+	}
+
+	return nil
+}
+
+func (s scheduleService) input(ctx context.Context, req models.GenerateScheduleRequest, weekStart time.Time) (scheduler.Input, error) {
+	in := scheduler.Input{}
+
+	for _, day := range req.Weekdays {
+		if day < 0 || day > 6 {
+			return in, fmt.Errorf("weekday %d is not valid, use 0 (Sunday) to 6 (Saturday)", day)
+		}
+		in.Days = append(in.Days, time.Weekday(day))
+	}
+
+	for _, p := range req.Periods {
+		in.Periods = append(in.Periods, scheduler.Period{Start: p.Start, End: p.End})
+	}
+
+	teachers, err := s.storage.ScheduleStorage().GetTeacherSubjects(ctx)
+	if err != nil {
+		s.logger.Error("failed to get teachers' subjects: ", logger.Error(err))
+		return in, err
+	}
+	windows, err := s.storage.AvailabilityStorage().GetWindows(ctx, "")
+	if err != nil {
+		s.logger.Error("failed to get teachers' availability: ", logger.Error(err))
+		return in, err
+	}
+	stored := map[string][]scheduler.Window{}
+	for _, w := range windows {
+		stored[w.TeacherId] = append(stored[w.TeacherId], scheduler.Window{
+			Day:   time.Weekday(w.Weekday),
+			Start: w.Start,
+			End:   w.End,
+		})
+	}
+	availability := map[string][]scheduler.Window{}
+	for _, w := range req.Availability {
+		availability[w.TeacherId] = append(availability[w.TeacherId], scheduler.Window{
+			Day:   time.Weekday(w.Weekday),
+			Start: w.Start,
+			End:   w.End,
+		})
+	}
+	for _, t := range teachers {
+		// windows given in the request override the stored ones
+		if _, ok := availability[t.TeacherId]; !ok {
+			availability[t.TeacherId] = stored[t.TeacherId]
+		}
+		in.Teachers = append(in.Teachers, scheduler.Teacher{
+			Id:           t.TeacherId,
+			SubjectIds:   t.SubjectIds,
+			Availability: availability[t.TeacherId],
+		})
+	}
+
+	groups := map[string]bool{}
+	for _, r := range req.Requirements {
+		in.Requirements = append(in.Requirements, scheduler.Requirement{
+			GroupId:   r.GroupId,
+			SubjectId: r.SubjectId,
+			TeacherId: r.TeacherId,
+			Lessons:   r.HoursPerWeek,
+		})
+		if groups[r.GroupId] {
+			continue
+		}
+		groups[r.GroupId] = true
+
+		group, err := s.storage.GroupStorage().GetGroup(ctx, r.GroupId)
+		if err != nil {
+			s.logger.Error("failed to get a group: ", logger.Error(err))
+			return in, fmt.Errorf("group %s: %w", r.GroupId, err)
+		}
+		if group.StudentsCount == 0 {
+			return in, fmt.Errorf("group %s has no students", group.Name)
+		}
+		in.Groups = append(in.Groups, scheduler.Group{Id: group.Id, Size: int(group.StudentsCount)})
+	}
+
+	rooms, err := s.storage.RoomStorage().GetAll(ctx, models.GetAllRoomsRequest{Page: 1, Limit: maxScheduleRooms})
+	if err != nil {
+		s.logger.Error("failed to get rooms: ", logger.Error(err))
+		return in, err
+	}
+	if len(req.Rooms) == 0 {
+		for _, r := range rooms.Rooms {
+			in.Rooms = append(in.Rooms, scheduler.Room{Name: r.Name, Capacity: r.Capacity})
+		}
+	} else {
+		capacity := map[string]int{}
+		for _, r := range rooms.Rooms {
+			capacity[r.Name] = r.Capacity
+		}
+		for _, name := range req.Rooms {
+			in.Rooms = append(in.Rooms, scheduler.Room{Name: name, Capacity: capacity[name]})
+		}
+	}
+
+	weekEnd := weekStart.AddDate(0, 0, 7*req.Weeks)
+	busy, err := s.storage.ScheduleStorage().GetBusy(ctx, weekStart.Format("2006-01-02"), weekEnd.Format("2006-01-02"))
+	if err != nil {
+		s.logger.Error("failed to get busy lessons: ", logger.Error(err))
+		return in, err
+	}
+	for _, b := range busy {
+		window, ok, err := busyWindow(b)
+		if err != nil {
+			return in, err
+		}
+		if !ok {
+			continue
+		}
+		in.Busy = append(in.Busy,
+			scheduler.Busy{TeacherId: b.TeacherId, Window: window},
+			scheduler.Busy{Room: b.RoomName, Window: window},
+		)
+		if b.GroupId != "" {
+			in.Busy = append(in.Busy, scheduler.Busy{GroupId: b.GroupId, Window: window})
+		}
+	}
+
+	leaves, err := s.storage.AvailabilityStorage().GetApprovedLeaves(ctx, weekStart.Format("2006-01-02"), weekEnd.Format("2006-01-02"))
+	if err != nil {
+		s.logger.Error("failed to get approved leaves: ", logger.Error(err))
+		return in, err
+	}
+	for _, l := range leaves {
+		windows, err := leaveWindows(l, weekStart, weekEnd)
+		if err != nil {
+			return in, err
+		}
+		for _, window := range windows {
+			in.Busy = append(in.Busy, scheduler.Busy{TeacherId: l.TeacherId, Window: window})
+		}
+	}
+
+	return in, nil
+}
+
+// leaveWindows blocks the days of the leave that fall into [from, to).
+func leaveWindows(l models.Leave, from, to time.Time) ([]scheduler.Window, error) {
+	start, err := time.Parse(time.RFC3339, l.FromDate)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.Parse(time.RFC3339, l.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	return scheduler.LeaveWindows(start, end, from, to), nil
+}
+
+// busyWindow turns an existing lesson into a weekly window in the school's time zone.
+func busyWindow(b models.BusyInterval) (scheduler.Window, bool, error) {
+	from, err := time.Parse(time.RFC3339, b.FromDate)
+	if err != nil {
+		return scheduler.Window{}, false, err
+	}
+	to, err := time.Parse(time.RFC3339, b.ToDate)
+	if err != nil {
+		return scheduler.Window{}, false, err
+	}
+	window, ok := scheduler.BusyWindow(from, to)
+	return window, ok, nil
+}
+
+func (s scheduleService) GetDraft(ctx context.Context, id string) (models.ScheduleDraft, error) {
+	draft, err := s.storage.ScheduleStorage().GetDraft(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get a schedule draft: ", logger.Error(err))
+		return draft, err
+	}
+	return draft, nil
+}
+
+// Commit writes the reviewed draft into time_table in one transaction.
+func (s scheduleService) Commit(ctx context.Context, id string) (models.CommitScheduleResponse, error) {
+	inserted, err := s.storage.ScheduleStorage().CommitDraft(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to commit a schedule draft: ", logger.Error(err))
+		return models.CommitScheduleResponse{}, err
+	}
+
+	invalidateDashboard(ctx, s.storage, s.logger)
+	return models.CommitScheduleResponse{DraftId: id, Lessons: inserted}, nil
+}
@@ -11,15 +11,49 @@ type IServiceManager interface {
 	Subjects() subjectsService
 	Time() timeService
 	Auth() authService
+	Attendance() attendanceService
+	CheckIn() checkInService
+	Group() groupService
+	Room() roomService
+	Schedule() scheduleService
+	Availability() availabilityService
+	Calendar() calendarService
+	Gradebook() gradebookService
+	Homework() homeworkService
+	Quiz() quizService
+	Report() reportService
+	Enrollment() enrollmentService
+	Guardian() guardianService
+	Billing() billingService
+	Payment() paymentService
+	Payroll() payrollService
+	Dashboard() dashboardService
 }
 
 type Service struct {
-	studentService  studentService
-	teacherService  teacherService
-	subjectsService subjectsService
-	timeService     timeService
-	authService     authService
-	logger          logger.ILogger
+	studentService      studentService
+	teacherService      teacherService
+	subjectsService     subjectsService
+	timeService         timeService
+	authService         authService
+	attendanceService   attendanceService
+	checkInService      checkInService
+	groupService        groupService
+	roomService         roomService
+	scheduleService     scheduleService
+	availabilityService availabilityService
+	calendarService     calendarService
+	gradebookService    gradebookService
+	homeworkService     homeworkService
+	quizService         quizService
+	reportService       reportService
+	enrollmentService   enrollmentService
+	guardianService     guardianService
+	billingService      billingService
+	paymentService      paymentService
+	payrollService      payrollService
+	dashboardService    dashboardService
+	logger              logger.ILogger
 }
 
 func New(storage storage.IStorage, logger logger.ILogger) Service {
@@ -29,6 +63,23 @@ func New(storage storage.IStorage, logger logger.ILogger) Service {
 	services.subjectsService = NewSubjectService(storage, logger)
 	services.timeService = NewTimeService(storage, logger)
 	services.authService = NewAuthService(storage, logger)
+	services.attendanceService = NewAttendanceService(storage, logger)
+	services.checkInService = NewCheckInService(storage, logger)
+	services.groupService = NewGroupService(storage, logger)
+	services.roomService = NewRoomService(storage, logger)
+	services.scheduleService = NewScheduleService(storage, logger)
+	services.availabilityService = NewAvailabilityService(storage, logger)
+	services.calendarService = NewCalendarService(storage, logger)
+	services.gradebookService = NewGradebookService(storage, logger)
+	services.homeworkService = NewHomeworkService(storage, logger)
+	services.quizService = NewQuizService(storage, logger)
+	services.reportService = NewReportService(storage, logger)
+	services.enrollmentService = NewEnrollmentService(storage, logger)
+	services.guardianService = NewGuardianService(storage, logger)
+	services.billingService = NewBillingService(storage, logger)
+	services.paymentService = NewPaymentService(storage, logger)
+	services.payrollService = NewPayrollService(storage, logger)
+	services.dashboardService = NewDashboardService(storage, logger)
 	services.logger = logger
 
 	return services
@@ -53,3 +104,71 @@ func (s Service) Time() timeService {
 func (s Service) Auth() authService {
 	return s.authService
 }
+
+func (s Service) Attendance() attendanceService {
+	return s.attendanceService
+}
+
+func (s Service) CheckIn() checkInService {
+	return s.checkInService
+}
+
+func (s Service) Group() groupService {
+	return s.groupService
+}
+
+func (s Service) Room() roomService {
+	return s.roomService
+}
+
+func (s Service) Schedule() scheduleService {
+	return s.scheduleService
+}
+
+func (s Service) Availability() availabilityService {
+	return s.availabilityService
+}
+
+func (s Service) Calendar() calendarService {
+	return s.calendarService
+}
+
+func (s Service) Gradebook() gradebookService {
+	return s.gradebookService
+}
+
+func (s Service) Homework() homeworkService {
+	return s.homeworkService
+}
+
+func (s Service) Quiz() quizService {
+	return s.quizService
+}
+
+func (s Service) Report() reportService {
+	return s.reportService
+}
+
+func (s Service) Enrollment() enrollmentService {
+	return s.enrollmentService
+}
+
+func (s Service) Guardian() guardianService {
+	return s.guardianService
+}
+
+func (s Service) Billing() billingService {
+	return s.billingService
+}
+
+func (s Service) Payment() paymentService {
+	return s.paymentService
+}
+
+func (s Service) Payroll() payrollService {
+	return s.payrollService
+}
+
+func (s Service) Dashboard() dashboardService {
+	return s.dashboardService
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/domain"
+	"backend_course/lms/storage"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// fakeStorage hands out the fake storages a test sets, the other storages are nil.
+type fakeStorage struct {
+	storage.IStorage
+	billing    *fakeBilling
+	attendance *fakeAttendance
+	lessons    *fakeLessons
+	teachers   *fakeTeachers
+	calendar   *fakeCalendar
+	redis      *fakeRedis
+}
+
+func (s fakeStorage) BillingStorage() storage.BillingStorage {
+	return s.billing
+}
+
+func (s fakeStorage) AttendanceStorage() storage.AttendanceStorage {
+	return s.attendance
+}
+
+func (s fakeStorage) TimeStorage() storage.TimeStorage {
+	return s.lessons
+}
+
+func (s fakeStorage) TeacherStorage() storage.TeacherStorage {
+	return s.teachers
+}
+
+func (s fakeStorage) CalendarStorage() storage.CalendarStorage {
+	return s.calendar
+}
+
+func (s fakeStorage) Redis() storage.IRedisStorage {
+	if s.redis == nil {
+		return newFakeRedis()
+	}
+	return s.redis
+}
+
+// fakeRedis keeps the keys in memory, they do not expire.
+type fakeRedis struct {
+	mu   sync.Mutex
+	keys map[string]interface{}
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{keys: map[string]interface{}{}}
+}
+
+func (r *fakeRedis) SetX(ctx context.Context, key string, value interface{}, duration time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[key] = value
+	return nil
+}
+
+func (r *fakeRedis) SetNX(ctx context.Context, key string, value interface{}, duration time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.keys[key]; ok {
+		return false, nil
+	}
+	r.keys[key] = value
+	return true, nil
+}
+
+func (r *fakeRedis) Get(ctx context.Context, key string) interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.keys[key]
+}
+
+func (r *fakeRedis) Del(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.keys, key)
+	return nil
+}
+
+// fakeAttendance records the marked attendance.
+type fakeAttendance struct {
+	storage.AttendanceStorage
+	marked []models.MarkAttendanceRequest
+}
+
+func (a *fakeAttendance) MarkBulk(ctx context.Context, req models.MarkAttendanceRequest, teacherId string) error {
+	a.marked = append(a.marked, req)
+	return nil
+}
+
+// fakeLessons holds a lesson.
+type fakeLessons struct {
+	storage.TimeStorage
+	lesson domain.TimeTable
+}
+
+func (l *fakeLessons) GetTime(ctx context.Context, id uuid.UUID) (domain.TimeTable, error) {
+	if id != l.lesson.Id {
+		return domain.TimeTable{}, pgx.ErrNoRows
+	}
+	return l.lesson, nil
+}
+
+// fakeTeachers holds the lesson a teacher has now.
+type fakeTeachers struct {
+	storage.TeacherStorage
+	lesson models.CheckLessonTeacher
+}
+
+func (t *fakeTeachers) CheckTeacherLesson(ctx context.Context, id string) (models.CheckLessonTeacher, error) {
+	return t.lesson, nil
+}
+
+// fakeCalendar holds the terms.
+type fakeCalendar struct {
+	storage.CalendarStorage
+	terms map[string]models.Term
+}
+
+func (c *fakeCalendar) GetTerm(ctx context.Context, id string) (models.Term, error) {
+	term, ok := c.terms[id]
+	if !ok {
+		return models.Term{}, pgx.ErrNoRows
+	}
+	return term, nil
+}
@@ -2,11 +2,21 @@ package service
 
 import (
 	"backend_course/lms/api/models"
+	"backend_course/lms/domain"
+	"backend_course/lms/pkg"
+	"backend_course/lms/pkg/check"
+	"backend_course/lms/pkg/export"
 	"backend_course/lms/pkg/logger"
 	"backend_course/lms/storage"
 	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
+var ErrInvalidGroupBy = errors.New("group_by is not valid, use teacher, subject or week")
+
 type studentService struct {
 	storage storage.IStorage
 	logger logger.ILogger
@@ -19,59 +29,69 @@ func NewStudentService(storage storage.IStorage, logger logger.ILogger) studentS
 	}
 }
 
-func (s studentService) Create(ctx context.Context, student models.AddStudent) (string, error) {
-	
+func (s studentService) Create(ctx context.Context, student domain.Student) (uuid.UUID, error) {
 	id, err := s.storage.StudentStorage().Create(ctx, student)
 	if err != nil {
 		s.logger.Error("failed to create a new student: ", logger.Error(err))
-		return "", err
+		return uuid.Nil, err
 	}
 
+	invalidateDashboard(ctx, s.storage, s.logger)
 	return id, nil
 }
 
-func (s studentService) Update(ctx context.Context, student models.Student) (string, error) {
-	
+func (s studentService) Update(ctx context.Context, student domain.Student) (uuid.UUID, error) {
 	id, err := s.storage.StudentStorage().Update(ctx, student)
 	if err != nil {
 		s.logger.Error("failed to update a student: ", logger.Error(err))
-		return "", err
+		return uuid.Nil, err
 	}
-	
+
+	invalidateDashboard(ctx, s.storage, s.logger)
 	return id, nil
 }
 
-func (s studentService) UpdateStatus(ctx context.Context, student models.Student) (string, error) {
-	
+func (s studentService) UpdateStatus(ctx context.Context, student domain.Student) (uuid.UUID, error) {
 	id, err := s.storage.StudentStorage().UpdateStatus(ctx, student)
 	if err != nil {
 		s.logger.Error("failed to update a student's status: ", logger.Error(err))
-		return "", err
+		return uuid.Nil, err
 	}
-	
+
+	invalidateDashboard(ctx, s.storage, s.logger)
 	return id, nil
 }
 
-func (s studentService) Delete(ctx context.Context, id string) error {
+func (s studentService) Delete(ctx context.Context, id uuid.UUID) error {
 	err := s.storage.StudentStorage().Delete(ctx, id)
 	if err != nil {
 		s.logger.Error("failed to delete a student: ", logger.Error(err))
 		return err
 	}
 
+	invalidateDashboard(ctx, s.storage, s.logger)
 	return nil
 }
 
-func (s studentService) GetAll(ctx context.Context, req models.GetAllStudentsRequest) (models.GetAllStudentsResponse, error) {
-	res, err := s.storage.StudentStorage().GetAll(ctx, req)
+func (s studentService) GetAll(ctx context.Context, req models.GetAllStudentsRequest) ([]domain.Student, int64, error) {
+	students, count, err := s.storage.StudentStorage().GetAll(ctx, req)
 	if err != nil {
 		s.logger.Error("failed to get all students: ", logger.Error(err))
-		return res, err
+		return nil, 0, err
+	}
+	return students, count, nil
+}
+
+// Each hands the students of the request to fn as they are read.
+func (s studentService) Each(ctx context.Context, req models.GetAllStudentsRequest, fn func(domain.Student) error) error {
+	if err := s.storage.StudentStorage().Each(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export students: ", logger.Error(err))
+		return err
 	}
-	return res, nil
+	return nil
 }
 
-func (s studentService) GetStudent(ctx context.Context, id string) (models.GetStudent, error) {
+func (s studentService) GetStudent(ctx context.Context, id uuid.UUID) (domain.Student, error) {
 	student, err := s.storage.StudentStorage().GetStudent(ctx, id)
 	if err != nil {
 		s.logger.Error("failed to create a student: ", logger.Error(err))
@@ -90,21 +110,130 @@ func (s studentService) CheckStudentLesson(ctx context.Context, id string) (mode
 	return checkStudent, nil
 }
 
+// GetAllStudentsAttandenceReport sums the started lessons of every student, per teacher,
+// subject or week when GroupBy is set.
 func (s studentService) GetAllStudentsAttandenceReport(ctx context.Context, req models.GetAllStudentsAttandenceReportRequest) (models.GetAllStudentsAttandenceReportResponse, error) {
-	resp, err := s.storage.StudentStorage().GetAllStudentsAttandenceReport(ctx, req)
+	req, err := s.attendanceReportRequest(ctx, req)
+	if err != nil {
+		return models.GetAllStudentsAttandenceReportResponse{}, err
+	}
 
+	resp, err := s.storage.StudentStorage().GetAllStudentsAttandenceReport(ctx, req)
 	if err != nil {
+		s.logger.Error("failed to get students' attendance: ", logger.Error(err))
 		return resp, err
 	}
 	return resp, nil
 }
 
+// EachAttendanceReport hands the attendance of the students of the request to fn as it
+// is read.
+func (s studentService) EachAttendanceReport(ctx context.Context, req models.GetAllStudentsAttandenceReportRequest, fn func(models.StudentAttandenceReport) error) error {
+	req, err := s.attendanceReportRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if err := s.storage.StudentStorage().EachAttendanceReport(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export students' attendance: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// attendanceReportRequest checks the grouping of an attendance report and reads its
+// period from its term or dates.
+func (s studentService) attendanceReportRequest(ctx context.Context, req models.GetAllStudentsAttandenceReportRequest) (models.GetAllStudentsAttandenceReportRequest, error) {
+	switch req.GroupBy {
+	case "", "teacher", "subject", "week":
+	default:
+		return req, ErrInvalidGroupBy
+	}
+	if req.TermId != "" {
+		from, to, err := termRange(ctx, s.storage, req.TermId)
+		if err != nil {
+			s.logger.Error("failed to get a term: ", logger.Error(err))
+			return req, err
+		}
+		req.StartDate, req.EndDate = from, to
+	}
+
+	var err error
+	if req.StartDate, err = pkg.NormalizeTime(ctx, req.StartDate); err != nil {
+		return req, err
+	}
+	if req.EndDate, err = pkg.NormalizeTime(ctx, req.EndDate); err != nil {
+		return req, err
+	}
+	return req, nil
+}
+
 func (s studentService) UploadImage(ctx context.Context, path models.UploadStudentImage) error {
-	
 	err := s.storage.StudentStorage().UploadImage(ctx, path)
 	if err != nil {
 		s.logger.Error("failed to upload student's image: ", logger.Error(err))
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// Import validates the records of a file of students and stores the valid ones in one
+// transaction unless it is a dry run. The response lists the errors of every row.
+func (s studentService) Import(ctx context.Context, records []export.Record, dryRun bool) (models.ImportResponse, error) {
+	rows := make([]importRow, len(records))
+	students := make([]domain.Student, len(records))
+	for i, record := range records {
+		row := &rows[i]
+		add, err := models.NewImportStudent(record.Values)
+		row.line, row.mail, row.phone = record.Line, add.Email, add.Phone
+		row.fields(err)
+		row.required("first_name", add.FirstName)
+		row.required("last_name", add.LastName)
+		row.check("age", check.ValidateYear(add.Age))
+		row.check("phone", check.ValidatePhone(add.Phone))
+		row.check("mail", check.ValidateEmail(add.Email))
+		row.check("password", check.ValidatePassword(add.Password))
+
+		students[i], err = add.Domain(ctx)
+		row.fields(err)
+	}
+
+	taken, err := s.storage.StudentStorage().Taken(ctx, importContacts(rows))
+	if err != nil {
+		s.logger.Error("failed to check contacts of imported students: ", logger.Error(err))
+		return models.ImportResponse{}, err
+	}
+	checkUnique(rows, taken)
+
+	resp := newImportResponse(rows, dryRun)
+	if dryRun || resp.Valid == 0 {
+		return resp, nil
+	}
+
+	valid := make([]domain.Student, 0, resp.Valid)
+	for i, student := range students {
+		if len(rows[i].errors) == 0 {
+			valid = append(valid, student)
+		}
+	}
+	passwords := make([]*string, len(valid))
+	for i := range valid {
+		passwords[i] = &valid[i].Password
+	}
+	if err := hashPasswords(passwords); err != nil {
+		s.logger.Error("failed to hash passwords of imported students: ", logger.Error(err))
+		return models.ImportResponse{}, err
+	}
+
+	resp.Imported, err = s.storage.StudentStorage().Import(ctx, valid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ImportResponse{}, ErrImportConflict
+	}
+	if err != nil {
+		s.logger.Error("failed to import students: ", logger.Error(err))
+		return models.ImportResponse{}, err
+	}
+
+	invalidateDashboard(ctx, s.storage, s.logger)
+	return resp, nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg"
+	"backend_course/lms/pkg/logger"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttendanceReportRequest(t *testing.T) {
+	calendar := &fakeCalendar{terms: map[string]models.Term{
+		"autumn": {Id: "autumn", StartDate: "2024-09-02", EndDate: "2024-12-27"},
+	}}
+	s := NewStudentService(fakeStorage{calendar: calendar}, logger.New("test"))
+	ctx := context.Background()
+
+	for _, groupBy := range []string{"", "teacher", "subject", "week"} {
+		_, err := s.attendanceReportRequest(ctx, models.GetAllStudentsAttandenceReportRequest{GroupBy: groupBy})
+		assert.NoError(t, err, groupBy)
+	}
+	for _, groupBy := range []string{"month", "Teacher", "student"} {
+		_, err := s.attendanceReportRequest(ctx, models.GetAllStudentsAttandenceReportRequest{GroupBy: groupBy})
+		assert.ErrorIs(t, err, ErrInvalidGroupBy, groupBy)
+	}
+
+	// a term replaces the start and end dates
+	req, err := s.attendanceReportRequest(ctx, models.GetAllStudentsAttandenceReportRequest{TermId: "autumn", StartDate: "2020-01-01"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2024, 9, 2, 0, 0, 0, 0, time.Local).Format(time.RFC3339), req.StartDate)
+		assert.Equal(t, time.Date(2024, 12, 27, 23, 59, 59, 0, time.Local).Format(time.RFC3339), req.EndDate)
+	}
+
+	_, err = s.attendanceReportRequest(ctx, models.GetAllStudentsAttandenceReportRequest{StartDate: "yesterday"})
+	assert.ErrorIs(t, err, pkg.ErrInvalidTime)
+}
@@ -2,11 +2,17 @@ package service
 
 import (
 	"backend_course/lms/api/models"
+	"backend_course/lms/pkg/catalog"
 	"backend_course/lms/pkg/logger"
 	"backend_course/lms/storage"
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 )
 
+var ErrPrerequisiteCycle = errors.New("prerequisites must not depend on the subject")
+
 type subjectsService struct {
 	storage storage.IStorage
 	logger  logger.ILogger
@@ -19,7 +25,28 @@ func NewSubjectService(storage storage.IStorage, logger logger.ILogger) subjects
 	}
 }
 
+// checkCourse defaults an empty level to beginner and checks the catalog fields.
+func checkCourse(code string, level *string, creditHours int) error {
+	if len(code) > 20 {
+		return errors.New("code must be at most 20 characters")
+	}
+	if *level == "" {
+		*level = catalog.Beginner
+	}
+	if err := catalog.CheckLevel(*level); err != nil {
+		return err
+	}
+	if creditHours < 0 {
+		return errors.New("credit hours must not be negative")
+	}
+	return nil
+}
+
 func (s subjectsService) Create(ctx context.Context, subject models.AddSubject) (string, error) {
+	if err := checkCourse(subject.Code, &subject.Level, subject.CreditHours); err != nil {
+		return "", err
+	}
+
 	id, err := s.storage.SubjectsStorage().Create(ctx, subject)
 	if err != nil {
 		s.logger.Error("failed to create a subject: ", logger.Error(err))
@@ -29,11 +56,16 @@ func (s subjectsService) Create(ctx context.Context, subject models.AddSubject)
 }
 
 func (s subjectsService) Update(ctx context.Context, subject models.Subjects) (string, error) {
+	if err := checkCourse(subject.Code, &subject.Level, subject.CreditHours); err != nil {
+		return "", err
+	}
+
 	id, err := s.storage.SubjectsStorage().Update(ctx, subject)
 	if err != nil {
 		s.logger.Error("failed to update a subject: ", logger.Error(err))
 		return "", err
 	}
+	invalidateDashboard(ctx, s.storage, s.logger)
 	return id, nil
 }
 
@@ -44,6 +76,7 @@ func (s subjectsService) Delete(ctx context.Context, id string) error {
 		return err
 	}
 
+	invalidateDashboard(ctx, s.storage, s.logger)
 	return nil
 }
 
@@ -57,6 +90,15 @@ func (s subjectsService) GetAll(ctx context.Context, req models.GetAllSubjectsRe
 	return res, nil
 }
 
+// Each hands the subjects of the request to fn as they are read.
+func (s subjectsService) Each(ctx context.Context, req models.GetAllSubjectsRequest, fn func(models.Subjects) error) error {
+	if err := s.storage.SubjectsStorage().Each(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export subjects: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
 func (s subjectsService) GetSubject(ctx context.Context, id string) (models.Subjects, error) {
 	subject, err := s.storage.SubjectsStorage().GetSubject(ctx, id)
 	if err != nil {
@@ -64,5 +106,61 @@ func (s subjectsService) GetSubject(ctx context.Context, id string) (models.Subj
 		return subject, err
 	}
 
+	if subject.Prerequisites, err = s.storage.SubjectsStorage().GetPrerequisites(ctx, id); err != nil {
+		s.logger.Error("failed to get prerequisites: ", logger.Error(err))
+		return subject, err
+	}
+	if subject.Syllabus, err = s.storage.SubjectsStorage().GetSyllabus(ctx, id); err != nil {
+		s.logger.Error("failed to get a syllabus: ", logger.Error(err))
+		return subject, err
+	}
+
 	return subject, nil
 }
+
+// SetSyllabus replaces the subject's syllabus, units and lessons keep the given order.
+func (s subjectsService) SetSyllabus(ctx context.Context, id string, req models.SetSyllabusRequest) error {
+	for i, unit := range req.Units {
+		if strings.TrimSpace(unit.Title) == "" {
+			return fmt.Errorf("unit %d needs a title", i+1)
+		}
+		for j, lesson := range unit.Lessons {
+			if strings.TrimSpace(lesson.Title) == "" {
+				return fmt.Errorf("lesson %d of unit %d needs a title", j+1, i+1)
+			}
+			if lesson.Hours < 0 {
+				return fmt.Errorf("hours of lesson %d of unit %d must not be negative", j+1, i+1)
+			}
+		}
+	}
+
+	if _, err := s.storage.SubjectsStorage().GetSubject(ctx, id); err != nil {
+		s.logger.Error("failed to get a subject: ", logger.Error(err))
+		return err
+	}
+
+	if err := s.storage.SubjectsStorage().SetSyllabus(ctx, id, req.Units); err != nil {
+		s.logger.Error("failed to set a syllabus: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// SetPrerequisites replaces the subjects a student must complete before enrolling in the
+// subject, they must not depend on the subject themselves.
+func (s subjectsService) SetPrerequisites(ctx context.Context, id string, req models.SetPrerequisitesRequest) error {
+	graph, err := s.storage.SubjectsStorage().GetPrerequisiteGraph(ctx)
+	if err != nil {
+		s.logger.Error("failed to get prerequisites: ", logger.Error(err))
+		return err
+	}
+	if cycle := catalog.Cycle(graph, id, req.SubjectIds); cycle != nil {
+		return fmt.Errorf("%w: %s", ErrPrerequisiteCycle, strings.Join(cycle, " -> "))
+	}
+
+	if err := s.storage.SubjectsStorage().SetPrerequisites(ctx, id, req.SubjectIds); err != nil {
+		s.logger.Error("failed to set prerequisites: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
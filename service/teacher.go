@@ -2,11 +2,23 @@ package service
 
 import (
 	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/domain"
+	"backend_course/lms/pkg/check"
+	"backend_course/lms/pkg/export"
 	"backend_course/lms/pkg/logger"
 	"backend_course/lms/storage"
 	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
+var ErrNoQualification = errors.New("teacher is not qualified for the subject")
+
 type teacherService struct {
 	storage storage.IStorage
 	logger  logger.ILogger
@@ -19,25 +31,25 @@ func NewTeacherService(storage storage.IStorage, logger logger.ILogger) teacherS
 	}
 }
 
-func (s teacherService) Create(ctx context.Context, teacher models.AddTeacher) (string, error) {
+func (s teacherService) Create(ctx context.Context, teacher domain.Teacher) (uuid.UUID, error) {
 	id, err := s.storage.TeacherStorage().Create(ctx, teacher)
 	if err != nil {
 		s.logger.Error("failed to create a teacher: ", logger.Error(err))
-		return "", err
+		return uuid.Nil, err
 	}
 	return id, nil
 }
 
-func (s teacherService) Update(ctx context.Context, teacher models.Teacher) (string, error) {
+func (s teacherService) Update(ctx context.Context, teacher domain.Teacher) (uuid.UUID, error) {
 	id, err := s.storage.TeacherStorage().Update(ctx, teacher)
 	if err != nil {
 		s.logger.Error("failed to create a teacher: ", logger.Error(err))
-		return "", err
+		return uuid.Nil, err
 	}
 	return id, nil
 }
 
-func (s teacherService) Delete(ctx context.Context, id string) error {
+func (s teacherService) Delete(ctx context.Context, id uuid.UUID) error {
 	err := s.storage.TeacherStorage().Delete(ctx, id)
 	if err != nil {
 		s.logger.Error("failed to delete a teacher: ", logger.Error(err))
@@ -47,16 +59,25 @@ func (s teacherService) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (s teacherService) GetAll(ctx context.Context, req models.GetAllTeachersRequest) (models.GetAllTeachersResponse, error) {
-	res, err := s.storage.TeacherStorage().GetAll(ctx, req)
+func (s teacherService) GetAll(ctx context.Context, req models.GetAllTeachersRequest) ([]domain.Teacher, int64, error) {
+	teachers, count, err := s.storage.TeacherStorage().GetAll(ctx, req)
 	if err != nil {
 		s.logger.Error("failed to delete all teachers: ", logger.Error(err))
-		return res, err
+		return nil, 0, err
 	}
-	return res, nil
+	return teachers, count, nil
 }
 
-func (s teacherService) GetTeacher(ctx context.Context, id string) (models.Teacher, error) {
+// Each hands the teachers of the request to fn as they are read.
+func (s teacherService) Each(ctx context.Context, req models.GetAllTeachersRequest, fn func(domain.Teacher) error) error {
+	if err := s.storage.TeacherStorage().Each(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export teachers: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s teacherService) GetTeacher(ctx context.Context, id uuid.UUID) (domain.Teacher, error) {
 	teacher, err := s.storage.TeacherStorage().GetTeacher(ctx, id)
 	if err != nil {
 		s.logger.Error("failed to get a teacher: ", logger.Error(err))
@@ -73,4 +94,106 @@ func (s teacherService) CheckTeacherLesson(ctx context.Context, id string) (mode
 		return checkTeacher, err
 	}
 	return checkTeacher, nil
-}
\ No newline at end of file
+}
+
+func (s teacherService) GetSubjects(ctx context.Context, teacherId string) (models.GetTeacherSubjectsResponse, error) {
+	subjects, err := s.storage.TeacherStorage().GetSubjects(ctx, teacherId)
+	if err != nil {
+		s.logger.Error("failed to get teacher's subjects: ", logger.Error(err))
+		return models.GetTeacherSubjectsResponse{}, err
+	}
+	return models.GetTeacherSubjectsResponse{Subjects: subjects, Count: int64(len(subjects))}, nil
+}
+
+// SetSubject qualifies the teacher for the subject, proficiency defaults to proficient and
+// since to today.
+func (s teacherService) SetSubject(ctx context.Context, teacherId, subjectId string, req models.SetTeacherSubject) error {
+	switch req.Proficiency {
+	case "":
+		req.Proficiency = config.PROFICIENCY_PROFICIENT
+	case config.PROFICIENCY_BASIC, config.PROFICIENCY_PROFICIENT, config.PROFICIENCY_EXPERT:
+	default:
+		return fmt.Errorf("proficiency %q is not valid, use basic, proficient or expert", req.Proficiency)
+	}
+	if req.Since == "" {
+		req.Since = time.Now().Format("2006-01-02")
+	} else if _, err := time.Parse("2006-01-02", req.Since); err != nil {
+		return fmt.Errorf("since is not valid: %w", err)
+	}
+
+	if err := s.storage.TeacherStorage().SetSubject(ctx, teacherId, subjectId, req); err != nil {
+		s.logger.Error("failed to set teacher's subject: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s teacherService) RemoveSubject(ctx context.Context, teacherId, subjectId string) error {
+	removed, err := s.storage.TeacherStorage().RemoveSubject(ctx, teacherId, subjectId)
+	if err != nil {
+		s.logger.Error("failed to remove teacher's subject: ", logger.Error(err))
+		return err
+	}
+	if !removed {
+		return ErrNoQualification
+	}
+	return nil
+}
+
+// Import validates the records of a file of teachers and stores the valid ones in one
+// transaction unless it is a dry run. The response lists the errors of every row.
+func (s teacherService) Import(ctx context.Context, records []export.Record, dryRun bool) (models.ImportResponse, error) {
+	rows := make([]importRow, len(records))
+	teachers := make([]domain.Teacher, len(records))
+	for i, record := range records {
+		row := &rows[i]
+		add, err := models.NewImportTeacher(record.Values)
+		row.line, row.mail, row.phone = record.Line, add.Email, add.Phone
+		row.fields(err)
+		row.required("first_name", add.FirstName)
+		row.required("last_name", add.LastName)
+		row.check("phone", check.ValidatePhone(add.Phone))
+		row.check("mail", check.ValidateEmail(add.Email))
+		row.check("password", check.ValidatePassword(add.Password))
+
+		teachers[i], err = add.Domain(ctx)
+		row.fields(err)
+	}
+
+	taken, err := s.storage.TeacherStorage().Taken(ctx, importContacts(rows))
+	if err != nil {
+		s.logger.Error("failed to check contacts of imported teachers: ", logger.Error(err))
+		return models.ImportResponse{}, err
+	}
+	checkUnique(rows, taken)
+
+	resp := newImportResponse(rows, dryRun)
+	if dryRun || resp.Valid == 0 {
+		return resp, nil
+	}
+
+	valid := make([]domain.Teacher, 0, resp.Valid)
+	for i, teacher := range teachers {
+		if len(rows[i].errors) == 0 {
+			valid = append(valid, teacher)
+		}
+	}
+	passwords := make([]*string, len(valid))
+	for i := range valid {
+		passwords[i] = &valid[i].Password
+	}
+	if err := hashPasswords(passwords); err != nil {
+		s.logger.Error("failed to hash passwords of imported teachers: ", logger.Error(err))
+		return models.ImportResponse{}, err
+	}
+
+	resp.Imported, err = s.storage.TeacherStorage().Import(ctx, valid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ImportResponse{}, ErrImportConflict
+	}
+	if err != nil {
+		s.logger.Error("failed to import teachers: ", logger.Error(err))
+		return models.ImportResponse{}, err
+	}
+	return resp, nil
+}
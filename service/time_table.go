@@ -2,9 +2,21 @@ package service
 
 import (
 	"backend_course/lms/api/models"
+	"backend_course/lms/domain"
 	"backend_course/lms/pkg/logger"
 	"backend_course/lms/storage"
 	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSchoolClosed        = errors.New("school is closed on this day")
+	ErrTeacherOnLeave      = errors.New("teacher is on leave at this time")
+	ErrTeacherNotAvailable = errors.New("lesson is outside of the teacher's availability")
+	ErrNotQualified        = errors.New("teacher is not a qualified substitute for this lesson")
 )
 
 type timeService struct {
@@ -19,48 +31,187 @@ func NewTimeService(storage storage.IStorage, logger logger.ILogger) timeService
 	}
 }
 
-func (s timeService) Create(ctx context.Context, time models.Time) (string, error) {
-	id, err := s.storage.TimeStorage().Create(ctx, time)
+func (s timeService) Create(ctx context.Context, lesson domain.TimeTable) (uuid.UUID, error) {
+	if err := s.checkQualification(ctx, lesson); err != nil {
+		return uuid.Nil, err
+	}
+	if err := s.checkAvailability(ctx, lesson); err != nil {
+		return uuid.Nil, err
+	}
+
+	id, err := s.storage.TimeStorage().Create(ctx, lesson)
 	if err != nil {
 		s.logger.Error("failed to create a time table: ", logger.Error(err))
-		return "", err
+		return uuid.Nil, err
 	}
+	invalidateDashboard(ctx, s.storage, s.logger)
 	return id, nil
 }
 
-func (s timeService) Update(ctx context.Context, time models.Time) (string, error) {
-	id, err := s.storage.TimeStorage().Update(ctx, time)
+func (s timeService) Update(ctx context.Context, lesson domain.TimeTable) (uuid.UUID, error) {
+	if err := s.checkQualification(ctx, lesson); err != nil {
+		return uuid.Nil, err
+	}
+	if err := s.checkAvailability(ctx, lesson); err != nil {
+		return uuid.Nil, err
+	}
+
+	id, err := s.storage.TimeStorage().Update(ctx, lesson)
 	if err != nil {
 		s.logger.Error("failed to update a time table: ", logger.Error(err))
-		return "", err
+		return uuid.Nil, err
 	}
+	invalidateDashboard(ctx, s.storage, s.logger)
 	return id, nil
 }
 
-func (s timeService) Delete(ctx context.Context, id string) error {
+func (s timeService) Delete(ctx context.Context, id uuid.UUID) error {
 	err := s.storage.TimeStorage().Delete(ctx, id)
 	if err != nil {
 		s.logger.Error("failed to delete a time table: ", logger.Error(err))
 		return err
 	}
 
+	invalidateDashboard(ctx, s.storage, s.logger)
 	return nil
 }
 
-func (s timeService) GetAll(ctx context.Context, req models.GetAllTimeRequest) (models.GetAllTimeResponse, error) {
-	res, err := s.storage.TimeStorage().GetAll(ctx, req)
+func (s timeService) GetAll(ctx context.Context, req models.GetAllTimeRequest) ([]domain.TimeTable, int64, error) {
+	times, count, err := s.storage.TimeStorage().GetAll(ctx, req)
 	if err != nil {
 		s.logger.Error("failed to get all time tables: ", logger.Error(err))
-		return res, err
+		return nil, 0, err
+	}
+	return times, count, nil
+}
+
+// Each hands the lessons of the request to fn as they are read.
+func (s timeService) Each(ctx context.Context, req models.GetAllTimeRequest, fn func(domain.TimeTable) error) error {
+	if err := s.storage.TimeStorage().Each(ctx, req, fn); err != nil {
+		s.logger.Error("failed to export time tables: ", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s timeService) GetTimeTable(ctx context.Context, id uuid.UUID) (domain.TimeTable, error) {
+	lesson, err := s.storage.TimeStorage().GetTime(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get a time table: ", logger.Error(err))
+		return lesson, err
+	}
+	return lesson, nil
+}
+
+// checkQualification rejects lessons of a subject the teacher is not qualified for.
+func (s timeService) checkQualification(ctx context.Context, lesson domain.TimeTable) error {
+	qualified, err := s.storage.TeacherStorage().TeachesSubject(ctx, lesson.TeacherId.String(), lesson.SubjectId.String())
+	if err != nil {
+		s.logger.Error("failed to check teacher's subjects: ", logger.Error(err))
+		return err
+	}
+	if !qualified {
+		return ErrNoQualification
+	}
+	return nil
+}
+
+// checkAvailability rejects lessons on closed days, outside of the teacher's weekly
+// availability or during the teacher's approved leave.
+func (s timeService) checkAvailability(ctx context.Context, lesson domain.TimeTable) error {
+	from, to := lesson.FromDate.Format(time.RFC3339), lesson.ToDate.Format(time.RFC3339)
+
+	closed, err := s.storage.CalendarStorage().IsClosed(ctx, from, to)
+	if err != nil {
+		s.logger.Error("failed to check the school calendar: ", logger.Error(err))
+		return err
+	}
+	if closed {
+		return ErrSchoolClosed
+	}
+
+	availability, err := s.storage.AvailabilityStorage().CheckLesson(ctx, lesson.TeacherId.String(), from, to)
+	if err != nil {
+		s.logger.Error("failed to check teacher's availability: ", logger.Error(err))
+		return err
+	}
+
+	if availability.OnLeave {
+		return ErrTeacherOnLeave
 	}
-	return res, nil
+	if !availability.WithinAvailability {
+		return ErrTeacherNotAvailable
+	}
+
+	return nil
+}
+
+func (s timeService) GetSubstitutes(ctx context.Context, id uuid.UUID) (models.GetSubstitutesResponse, error) {
+	substitutes, err := s.storage.TimeStorage().GetSubstitutes(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to get substitutes: ", logger.Error(err))
+		return models.GetSubstitutesResponse{}, err
+	}
+	return models.GetSubstitutesResponse{Substitutes: substitutes, Count: int64(len(substitutes))}, nil
 }
 
-func (s timeService) GetTimeTable(ctx context.Context, id string) (models.Time, error) {
-	time, err := s.storage.TimeStorage().GetTime(ctx, id)
+// AssignSubstitute hands the lesson to one of the suggested substitutes, or back to its
+// original teacher.
+func (s timeService) AssignSubstitute(ctx context.Context, id, teacherId uuid.UUID) (models.AssignSubstituteResponse, error) {
+	lesson, err := s.storage.TimeStorage().GetTime(ctx, id)
 	if err != nil {
 		s.logger.Error("failed to get a time table: ", logger.Error(err))
-		return time, err
+		return models.AssignSubstituteResponse{}, err
 	}
-	return time, nil
-}
\ No newline at end of file
+
+	if original, ok := lesson.OriginalTeacherId.Get(); !ok || original != teacherId {
+		substitutes, err := s.storage.TimeStorage().GetSubstitutes(ctx, id)
+		if err != nil {
+			s.logger.Error("failed to get substitutes: ", logger.Error(err))
+			return models.AssignSubstituteResponse{}, err
+		}
+
+		qualified := false
+		for _, substitute := range substitutes {
+			if substitute.TeacherId == teacherId.String() {
+				qualified = true
+				break
+			}
+		}
+		if !qualified {
+			return models.AssignSubstituteResponse{}, ErrNotQualified
+		}
+	}
+
+	originalTeacherId, rows, err := s.storage.TimeStorage().Substitute(ctx, id, teacherId)
+	if err != nil {
+		s.logger.Error("failed to assign a substitute: ", logger.Error(err))
+		return models.AssignSubstituteResponse{}, err
+	}
+
+	return models.AssignSubstituteResponse{
+		TimeTableId:       id.String(),
+		TeacherId:         teacherId.String(),
+		OriginalTeacherId: originalTeacherId,
+		Rows:              rows,
+	}, nil
+}
+
+// GetStudentTimetable returns the student's lessons from fromDate to toDate with their
+// attendance.
+func (s timeService) GetStudentTimetable(ctx context.Context, studentId, fromDate, toDate string) (models.StudentTimetableResponse, error) {
+	if err := checkDates(fromDate, toDate); err != nil {
+		return models.StudentTimetableResponse{}, err
+	}
+	from, to, err := dayRange(fromDate, toDate)
+	if err != nil {
+		return models.StudentTimetableResponse{}, err
+	}
+
+	lessons, err := s.storage.TimeStorage().GetStudentLessons(ctx, studentId, from, to, false)
+	if err != nil {
+		s.logger.Error("failed to get student's lessons: ", logger.Error(err))
+		return models.StudentTimetableResponse{}, err
+	}
+	return models.StudentTimetableResponse{Lessons: lessons, Count: int64(len(lessons))}, nil
+}
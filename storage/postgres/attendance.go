@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type attendanceRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewAttendance(db *pgxpool.Pool) attendanceRepo {
+	return attendanceRepo{
+		db: db,
+	}
+}
+
+// MarkBulk stores attendance of every record for the lesson given by req.TimeTableId.
+// A lesson is spread over one time_table row per student, so each record is attached
+// to the student's own row of the same lesson (same teacher, subject and time).
+func (s *attendanceRepo) MarkBulk(ctx context.Context, req models.MarkAttendanceRequest, teacherId string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO
+		attendance (id, time_table_id, student_id, status, check_in_time, note, marked_by)
+	SELECT
+		$1, tt.id, tt.student_id, $4, NULLIF($5, '')::timestamptz, NULLIF($6, ''), $7
+	FROM
+		time_table tt
+	INNER JOIN
+		time_table l
+	ON
+		l.teacher_id = tt.teacher_id
+		AND l.subject_id = tt.subject_id
+		AND l.from_date = tt.from_date
+		AND l.to_date = tt.to_date
+	WHERE
+		l.id = $2 AND tt.student_id = $3
+	LIMIT 1
+	ON CONFLICT (time_table_id, student_id) DO UPDATE
+	SET
+		status = EXCLUDED.status,
+		check_in_time = EXCLUDED.check_in_time,
+		note = EXCLUDED.note,
+		marked_by = EXCLUDED.marked_by,
+		updated_at = NOW();`
+
+	for _, record := range req.Records {
+		tag, err := tx.Exec(ctx, query, uuid.New(), req.TimeTableId, record.StudentId, record.Status, record.CheckInTime, record.Note, teacherId)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("student %s is not in this lesson", record.StudentId)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *attendanceRepo) GetByLesson(ctx context.Context, timeTableId string) (models.GetLessonAttendanceResponse, error) {
+	resp := models.GetLessonAttendanceResponse{}
+
+	query := `
+	SELECT
+		a.id,
+		a.time_table_id,
+		a.student_id,
+		st.first_name || ' ' || st.last_name AS student_name,
+		a.status,
+		a.check_in_time,
+		a.note,
+		a.marked_by,
+		a.created_at,
+		a.updated_at
+	FROM
+		attendance a
+	INNER JOIN
+		time_table tt
+	ON
+		tt.id = a.time_table_id
+	INNER JOIN
+		time_table l
+	ON
+		l.teacher_id = tt.teacher_id
+		AND l.subject_id = tt.subject_id
+		AND l.from_date = tt.from_date
+		AND l.to_date = tt.to_date
+	INNER JOIN
+		students st
+	ON
+		st.id = a.student_id
+	WHERE
+		l.id = $1
+	ORDER BY
+		student_name;`
+
+	rows, err := s.db.Query(ctx, query, timeTableId)
+	if err != nil {
+		return resp, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			attendance                  models.Attendance
+			studentName, note, markedBy sql.NullString
+		)
+		if err := rows.Scan(
+			&attendance.Id,
+			&attendance.TimeTableId,
+			&attendance.StudentId,
+			&studentName,
+			&attendance.Status,
+			pkg.TimeText(ctx, &attendance.CheckInTime),
+			&note,
+			&markedBy,
+			pkg.TimeText(ctx, &attendance.CreatedAt),
+			pkg.TimeText(ctx, &attendance.UpdatedAt)); err != nil {
+			return resp, err
+		}
+		attendance.StudentName = pkg.NullStringToString(studentName)
+		attendance.Note = pkg.NullStringToString(note)
+		attendance.MarkedBy = pkg.NullStringToString(markedBy)
+
+		resp.Attendance = append(resp.Attendance, attendance)
+	}
+	if err := rows.Err(); err != nil {
+		return resp, err
+	}
+
+	resp.Count = int64(len(resp.Attendance))
+
+	return resp, nil
+}
+
+// GetSummary counts the student's lessons between from and to which have already started
+// by their attendance, holidays and closures are left out.
+func (s *attendanceRepo) GetSummary(ctx context.Context, studentId, from, to string) (models.AttendanceSummary, error) {
+	query := `
+	SELECT
+		COUNT(*),
+		COUNT(*) FILTER (WHERE a.status = 'present'),
+		COUNT(*) FILTER (WHERE a.status = 'late'),
+		COUNT(*) FILTER (WHERE a.status = 'absent'),
+		COUNT(*) FILTER (WHERE a.status = 'excused'),
+		COUNT(*) FILTER (WHERE a.status IS NULL)
+	FROM
+		time_table tt
+	LEFT JOIN
+		attendance a
+	ON
+		a.time_table_id = tt.id AND a.student_id = tt.student_id
+	WHERE
+		tt.student_id = $1
+		AND tt.from_date BETWEEN $2 AND $3
+		AND tt.from_date <= NOW()
+		AND ` + openDay("tt.from_date") + `;`
+
+	var summary models.AttendanceSummary
+	err := s.db.QueryRow(ctx, query, studentId, from, to).Scan(
+		&summary.Lessons,
+		&summary.Present,
+		&summary.Late,
+		&summary.Absent,
+		&summary.Excused,
+		&summary.Unmarked)
+	return summary, err
+}
@@ -0,0 +1,340 @@
+package postgres
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type availabilityRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewAvailability(db *pgxpool.Pool) availabilityRepo {
+	return availabilityRepo{
+		db: db,
+	}
+}
+
+// SetWindows replaces the teacher's weekly availability with windows.
+func (s *availabilityRepo) SetWindows(ctx context.Context, teacherId string, windows []models.AvailabilityWindow) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM teacher_availability WHERE teacher_id = $1`, teacherId); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO
+		teacher_availability (id, teacher_id, weekday, start_time, end_time) VALUES ($1, $2, $3, $4::time, $5::time);`
+
+	for _, w := range windows {
+		if _, err := tx.Exec(ctx, query, uuid.New(), teacherId, w.Weekday, w.Start, w.End); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetWindows returns the availability of a teacher, or of every teacher when teacherId is empty.
+func (s *availabilityRepo) GetWindows(ctx context.Context, teacherId string) ([]models.AvailabilityWindow, error) {
+	query := `
+	SELECT
+		teacher_id,
+		weekday,
+		TO_CHAR(start_time,'HH24:MI'),
+		TO_CHAR(end_time,'HH24:MI')
+	FROM
+		teacher_availability
+	WHERE
+		$1 = '' OR teacher_id::text = $1
+	ORDER BY
+		teacher_id, weekday, start_time;`
+
+	rows, err := s.db.Query(ctx, query, teacherId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var windows []models.AvailabilityWindow
+	for rows.Next() {
+		var w models.AvailabilityWindow
+		if err := rows.Scan(&w.TeacherId, &w.Weekday, &w.Start, &w.End); err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+
+	return windows, rows.Err()
+}
+
+func (s *availabilityRepo) CreateLeave(ctx context.Context, leave models.AddLeave) (string, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO
+		teacher_leaves (id, teacher_id, from_date, to_date, reason) VALUES ($1, $2, $3, $4, NULLIF($5, ''));`
+
+	_, err := s.db.Exec(ctx, query, id, leave.TeacherId, leave.FromDate, leave.ToDate, leave.Reason)
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+const leaveColumns = `
+		id,
+		teacher_id,
+		from_date,
+		to_date,
+		reason,
+		status,
+		reviewed_at,
+		created_at`
+
+func scanLeave(ctx context.Context, row interface{ Scan(dest ...any) error }) (models.Leave, error) {
+	var (
+		leave  models.Leave
+		reason sql.NullString
+	)
+
+	err := row.Scan(
+		&leave.Id,
+		&leave.TeacherId,
+		pkg.TimeText(ctx, &leave.FromDate),
+		pkg.TimeText(ctx, &leave.ToDate),
+		&reason,
+		&leave.Status,
+		pkg.TimeText(ctx, &leave.ReviewedAt),
+		pkg.TimeText(ctx, &leave.CreatedAt))
+	if err != nil {
+		return leave, err
+	}
+
+	leave.Reason = pkg.NullStringToString(reason)
+
+	return leave, nil
+}
+
+func (s *availabilityRepo) GetLeave(ctx context.Context, id string) (models.Leave, error) {
+	query := `
+	SELECT` + leaveColumns + `
+	FROM
+		teacher_leaves
+	WHERE
+		id = $1;`
+
+	return scanLeave(ctx, s.db.QueryRow(ctx, query, id))
+}
+
+func (s *availabilityRepo) GetLeaves(ctx context.Context, req models.GetAllLeavesRequest) (models.GetAllLeavesResponse, error) {
+	resp := models.GetAllLeavesResponse{}
+	err := s.EachLeave(ctx, req, func(leave models.Leave) error {
+		resp.Leaves = append(resp.Leaves, leave)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.db.QueryRow(ctx, `SELECT COUNT(*) FROM teacher_leaves WHERE ($1 = '' OR teacher_id::text = $1) AND ($2 = '' OR status = $2)`,
+		req.TeacherId, req.Status).Scan(&resp.Count)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// EachLeave hands the leaves of the page to fn one at a time as they are read.
+func (s *availabilityRepo) EachLeave(ctx context.Context, req models.GetAllLeavesRequest, fn func(models.Leave) error) error {
+	offest := (req.Page - 1) * req.Limit
+	filter := ` WHERE ($3 = '' OR teacher_id::text = $3) AND ($4 = '' OR status = $4) `
+
+	query := `
+	SELECT` + leaveColumns + `
+	FROM
+		teacher_leaves` + filter + `
+	ORDER BY
+		from_date DESC
+	OFFSET
+		$1
+	LIMIT
+		$2;`
+
+	rows, err := s.db.Query(ctx, query, offest, req.Limit, req.TeacherId, req.Status)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		leave, err := scanLeave(ctx, rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(leave); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GetApprovedLeaves returns the approved leaves overlapping [from, to).
+func (s *availabilityRepo) GetApprovedLeaves(ctx context.Context, from, to string) ([]models.Leave, error) {
+	query := `
+	SELECT` + leaveColumns + `
+	FROM
+		teacher_leaves
+	WHERE
+		status = $3 AND from_date < $2 AND to_date > $1;`
+
+	rows, err := s.db.Query(ctx, query, from, to, config.LEAVE_APPROVED)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var leaves []models.Leave
+	for rows.Next() {
+		leave, err := scanLeave(ctx, rows)
+		if err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, leave)
+	}
+
+	return leaves, rows.Err()
+}
+
+// ReviewLeave approves or rejects a pending leave.
+func (s *availabilityRepo) ReviewLeave(ctx context.Context, id, status string) error {
+	query := `
+	UPDATE
+		teacher_leaves
+	SET
+		status = $2, reviewed_at = NOW()
+	WHERE
+		id = $1 AND status = $3;`
+
+	tag, err := s.db.Exec(ctx, query, id, status, config.LEAVE_PENDING)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("leave is not found or already reviewed")
+	}
+
+	return nil
+}
+
+// GetImpactedLessons returns the teacher's lessons which overlap the leave, one row per
+// lesson with the time_table rows of its students.
+func (s *availabilityRepo) GetImpactedLessons(ctx context.Context, leaveId string) ([]models.ImpactedLesson, error) {
+	query := `
+	SELECT
+		tt.subject_id,
+		sb.name,
+		tt.room_name,
+		tt.from_date,
+		tt.to_date,
+		COUNT(tt.student_id),
+		ARRAY_AGG(tt.id::text)
+	FROM
+		teacher_leaves tl
+	INNER JOIN
+		time_table tt
+	ON
+		tt.teacher_id = tl.teacher_id
+		AND tt.from_date < tl.to_date
+		AND tt.to_date > tl.from_date
+	INNER JOIN
+		subjects sb
+	ON
+		sb.id = tt.subject_id
+	WHERE
+		tl.id = $1 AND ` + openDay("tt.from_date") + `
+	GROUP BY
+		tt.subject_id, sb.name, tt.room_name, tt.from_date, tt.to_date
+	ORDER BY
+		tt.from_date;`
+
+	rows, err := s.db.Query(ctx, query, leaveId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lessons []models.ImpactedLesson
+	for rows.Next() {
+		var (
+			lesson                models.ImpactedLesson
+			subjectName, roomName sql.NullString
+		)
+		if err := rows.Scan(
+			&lesson.SubjectId,
+			&subjectName,
+			&roomName,
+			pkg.TimeText(ctx, &lesson.FromDate),
+			pkg.TimeText(ctx, &lesson.ToDate),
+			&lesson.StudentsCount,
+			&lesson.TimeTableIds); err != nil {
+			return nil, err
+		}
+		lesson.SubjectName = pkg.NullStringToString(subjectName)
+		lesson.RoomName = pkg.NullStringToString(roomName)
+
+		lessons = append(lessons, lesson)
+	}
+
+	return lessons, rows.Err()
+}
+
+// CheckLesson tells whether [from, to) lies inside one of the teacher's availability windows
+// (a teacher without windows is always available) and whether it overlaps an approved leave.
+func (s *availabilityRepo) CheckLesson(ctx context.Context, teacherId, from, to string) (models.LessonAvailability, error) {
+	query := `
+	SELECT
+		NOT EXISTS (SELECT 1 FROM teacher_availability WHERE teacher_id = $1)
+		OR EXISTS (
+			SELECT
+				1
+			FROM
+				teacher_availability
+			WHERE
+				teacher_id = $1
+				AND $2::timestamptz::date = $3::timestamptz::date
+				AND weekday = EXTRACT(DOW FROM $2::timestamptz)
+				AND start_time <= $2::timestamptz::time
+				AND end_time >= $3::timestamptz::time
+		),
+		EXISTS (
+			SELECT
+				1
+			FROM
+				teacher_leaves
+			WHERE
+				teacher_id = $1
+				AND status = $4
+				AND from_date < $3::timestamptz
+				AND to_date > $2::timestamptz
+		);`
+
+	resp := models.LessonAvailability{}
+	err := s.db.QueryRow(ctx, query, teacherId, from, to, config.LEAVE_APPROVED).Scan(&resp.WithinAvailability, &resp.OnLeave)
+
+	return resp, err
+}
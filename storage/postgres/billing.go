@@ -0,0 +1,1039 @@
+package postgres
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg"
+	"backend_course/lms/pkg/billing"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// planColumns are scanned by scanPlan.
+const planColumns = `
+		pp.id,
+		pp.name,
+		COALESCE(pp.subject_id::text, ''),
+		COALESCE(sb.name, ''),
+		COALESCE(pp.group_id::text, ''),
+		COALESCE(g.name, ''),
+		pp.monthly_fee,
+		pp.due_day,
+		pp.active,
+		pp.created_at,
+		pp.updated_at`
+
+const planJoins = `
+	LEFT JOIN
+		subjects sb
+	ON
+		sb.id = pp.subject_id
+	LEFT JOIN
+		groups g
+	ON
+		g.id = pp.group_id`
+
+// planFilter keeps the plans of the subject and group given in $1 and $2, empty ones
+// match everything.
+const planFilter = `
+		($1 = '' OR pp.subject_id::text = $1)
+		AND ($2 = '' OR pp.group_id::text = $2)`
+
+// discountFilter keeps the discounts of the student and plan given in $1 and $2, empty
+// ones match everything.
+const discountFilter = `
+		($1 = '' OR d.student_id::text = $1)
+		AND ($2 = '' OR d.plan_id::text = $2)`
+
+// invoiceStatus is the status of invoice i of which p.paid is paid, invoices past the due
+// date given in $1 are overdue.
+const invoiceStatus = `
+		CASE
+			WHEN i.status = 'cancelled' THEN 'cancelled'
+			WHEN i.amount - i.discount <= p.paid THEN 'paid'
+			WHEN i.due_date < $1::date THEN 'overdue'
+			ELSE 'open'
+		END`
+
+// invoiceColumns are scanned by scanInvoice, they need invoiceJoins.
+const invoiceColumns = `
+		i.id,
+		i.number,
+		i.student_id,
+		st.first_name || ' ' || st.last_name,
+		i.plan_id,
+		pp.name,
+		TO_CHAR(i.period, 'YYYY-MM'),
+		i.amount,
+		i.discount,
+		i.amount - i.discount,
+		p.paid,
+		CASE WHEN i.status = 'cancelled' THEN 0 ELSE GREATEST(i.amount - i.discount - p.paid, 0) END,
+		TO_CHAR(i.due_date, 'YYYY-MM-DD'),` + invoiceStatus + `,
+		i.created_at`
+
+// invoicePaid sums the payments of invoice i as p.paid.
+const invoicePaid = `
+	CROSS JOIN LATERAL (
+		SELECT COALESCE(SUM(amount), 0)::bigint AS paid FROM payments WHERE invoice_id = i.id
+	) p`
+
+const invoiceJoins = `
+	INNER JOIN
+		students st
+	ON
+		st.id = i.student_id
+	INNER JOIN
+		price_plans pp
+	ON
+		pp.id = i.plan_id` + invoicePaid
+
+// invoiceFilter keeps the invoices of the student, plan, YYYY-MM period and status given
+// in $2 to $5, empty ones match everything.
+const invoiceFilter = `
+		($2 = '' OR i.student_id::text = $2)
+		AND ($3 = '' OR i.plan_id::text = $3)
+		AND ($4 = '' OR TO_CHAR(i.period, 'YYYY-MM') = $4)
+		AND ($5 = '' OR` + invoiceStatus + ` = $5)`
+
+// paymentColumns are scanned by scanPayment.
+const paymentColumns = `
+		p.id,
+		p.student_id,
+		st.first_name || ' ' || st.last_name,
+		p.invoice_id,
+		i.number,
+		p.amount,
+		p.method,
+		p.reference,
+		COALESCE(p.recorded_by::text, ''),
+		p.paid_at,
+		p.created_at`
+
+const paymentJoins = `
+	INNER JOIN
+		students st
+	ON
+		st.id = p.student_id
+	INNER JOIN
+		invoices i
+	ON
+		i.id = p.invoice_id`
+
+// paymentFilter keeps the payments of the student and invoice given in $1 and $2, empty
+// ones match everything.
+const paymentFilter = `
+		($1 = '' OR p.student_id::text = $1)
+		AND ($2 = '' OR p.invoice_id::text = $2)`
+
+// debtorsFrom sums the overdue invoices of each student past the due date given in $1 as
+// d, keeping the students owing at least $2 and belonging to the group given in $3.
+const debtorsFrom = `
+		(
+			SELECT
+				i.student_id,
+				SUM(i.amount - i.discount - p.paid)::bigint AS overdue,
+				COUNT(*) AS invoices,
+				MIN(i.due_date) AS oldest_due_date
+			FROM
+				invoices i` + invoicePaid + `
+			WHERE
+				i.status = 'issued'
+				AND i.due_date < $1::date
+				AND i.amount - i.discount > p.paid
+			GROUP BY
+				i.student_id
+		) d
+	WHERE
+		d.overdue >= $2
+		AND ($3 = '' OR EXISTS (
+			SELECT 1 FROM group_students gs WHERE gs.student_id = d.student_id AND gs.group_id::text = $3
+		))`
+
+type billingRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewBilling(db *pgxpool.Pool) billingRepo {
+	return billingRepo{
+		db: db,
+	}
+}
+
+func scanPlan(ctx context.Context, row interface{ Scan(dest ...any) error }) (models.PricePlan, error) {
+	var plan models.PricePlan
+	err := row.Scan(
+		&plan.Id,
+		&plan.Name,
+		&plan.SubjectId,
+		&plan.SubjectName,
+		&plan.GroupId,
+		&plan.GroupName,
+		&plan.MonthlyFee,
+		&plan.DueDay,
+		&plan.Active,
+		pkg.TimeText(ctx, &plan.CreatedAt),
+		pkg.TimeText(ctx, &plan.UpdatedAt))
+	return plan, err
+}
+
+func scanInvoice(ctx context.Context, row interface{ Scan(dest ...any) error }) (models.Invoice, error) {
+	var invoice models.Invoice
+	err := row.Scan(
+		&invoice.Id,
+		&invoice.Number,
+		&invoice.StudentId,
+		&invoice.StudentName,
+		&invoice.PlanId,
+		&invoice.PlanName,
+		&invoice.Period,
+		&invoice.Amount,
+		&invoice.Discount,
+		&invoice.Total,
+		&invoice.Paid,
+		&invoice.Outstanding,
+		&invoice.DueDate,
+		&invoice.Status,
+		pkg.TimeText(ctx, &invoice.CreatedAt))
+	return invoice, err
+}
+
+func scanPayment(ctx context.Context, row interface{ Scan(dest ...any) error }) (models.Payment, error) {
+	var payment models.Payment
+	err := row.Scan(
+		&payment.Id,
+		&payment.StudentId,
+		&payment.StudentName,
+		&payment.InvoiceId,
+		&payment.InvoiceNumber,
+		&payment.Amount,
+		&payment.Method,
+		&payment.Reference,
+		&payment.RecordedBy,
+		pkg.TimeText(ctx, &payment.PaidAt),
+		pkg.TimeText(ctx, &payment.CreatedAt))
+	return payment, err
+}
+
+func (s *billingRepo) CreatePlan(ctx context.Context, plan models.AddPricePlan) (string, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO
+		price_plans (id, name, subject_id, group_id, monthly_fee, due_day)
+		VALUES ($1, $2, NULLIF($3, '')::uuid, NULLIF($4, '')::uuid, $5, $6);`
+
+	_, err := s.db.Exec(ctx, query, id, plan.Name, plan.SubjectId, plan.GroupId, plan.MonthlyFee, plan.DueDay)
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func (s *billingRepo) UpdatePlan(ctx context.Context, id string, plan models.UpdatePricePlan) error {
+	query := `
+	UPDATE
+		price_plans
+	SET
+		name = $2,
+		monthly_fee = $3,
+		due_day = $4,
+		active = $5,
+		updated_at = NOW()
+	WHERE
+		id = $1;`
+
+	_, err := s.db.Exec(ctx, query, id, plan.Name, plan.MonthlyFee, plan.DueDay, plan.Active)
+	return err
+}
+
+func (s *billingRepo) GetPlan(ctx context.Context, id string) (models.PricePlan, error) {
+	query := `
+	SELECT` + planColumns + `
+	FROM
+		price_plans pp` + planJoins + `
+	WHERE
+		pp.id = $1;`
+
+	return scanPlan(ctx, s.db.QueryRow(ctx, query, id))
+}
+
+func (s *billingRepo) GetPlans(ctx context.Context, req models.GetAllPricePlansRequest) (models.GetAllPricePlansResponse, error) {
+	resp := models.GetAllPricePlansResponse{}
+	err := s.EachPlan(ctx, req, func(plan models.PricePlan) error {
+		resp.PricePlans = append(resp.PricePlans, plan)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.db.QueryRow(ctx, `SELECT COUNT(*) FROM price_plans pp WHERE`+planFilter,
+		req.SubjectId, req.GroupId).Scan(&resp.Count)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// EachPlan hands the price plans of the page to fn one at a time as they are read.
+func (s *billingRepo) EachPlan(ctx context.Context, req models.GetAllPricePlansRequest, fn func(models.PricePlan) error) error {
+	offest := (req.Page - 1) * req.Limit
+
+	query := `
+	SELECT` + planColumns + `
+	FROM
+		price_plans pp` + planJoins + `
+	WHERE` + planFilter + `
+	ORDER BY
+		pp.active DESC, pp.name
+	OFFSET
+		$3
+	LIMIT
+		$4;`
+
+	rows, err := s.db.Query(ctx, query, req.SubjectId, req.GroupId, offest, req.Limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		plan, err := scanPlan(ctx, rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(plan); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// SetDiscount sets the student's discount of the plan, replacing the one it had.
+func (s *billingRepo) SetDiscount(ctx context.Context, discount models.AddDiscount) (string, error) {
+	query := `
+	INSERT INTO
+		discounts (id, student_id, plan_id, percent, reason)
+		VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (student_id, plan_id) DO UPDATE
+	SET
+		percent = EXCLUDED.percent,
+		reason = EXCLUDED.reason
+	RETURNING
+		id;`
+
+	var id string
+	err := s.db.QueryRow(ctx, query, uuid.New(), discount.StudentId, discount.PlanId, discount.Percent,
+		discount.Reason).Scan(&id)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (s *billingRepo) DeleteDiscount(ctx context.Context, id string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM discounts WHERE id = $1;`, id)
+	return err
+}
+
+func (s *billingRepo) GetDiscounts(ctx context.Context, req models.GetAllDiscountsRequest) (models.GetAllDiscountsResponse, error) {
+	resp := models.GetAllDiscountsResponse{}
+	err := s.EachDiscount(ctx, req, func(discount models.Discount) error {
+		resp.Discounts = append(resp.Discounts, discount)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.db.QueryRow(ctx, `SELECT COUNT(*) FROM discounts d WHERE`+discountFilter,
+		req.StudentId, req.PlanId).Scan(&resp.Count)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// EachDiscount hands the discounts of the page to fn one at a time as they are read.
+func (s *billingRepo) EachDiscount(ctx context.Context, req models.GetAllDiscountsRequest, fn func(models.Discount) error) error {
+	offest := (req.Page - 1) * req.Limit
+
+	query := `
+	SELECT
+		d.id,
+		d.student_id,
+		st.first_name || ' ' || st.last_name,
+		d.plan_id,
+		pp.name,
+		d.percent,
+		d.reason,
+		d.created_at
+	FROM
+		discounts d
+	INNER JOIN
+		students st
+	ON
+		st.id = d.student_id
+	INNER JOIN
+		price_plans pp
+	ON
+		pp.id = d.plan_id
+	WHERE` + discountFilter + `
+	ORDER BY
+		st.last_name, st.first_name, pp.name
+	OFFSET
+		$3
+	LIMIT
+		$4;`
+
+	rows, err := s.db.Query(ctx, query, req.StudentId, req.PlanId, offest, req.Limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var discount models.Discount
+		if err := rows.Scan(
+			&discount.Id,
+			&discount.StudentId,
+			&discount.StudentName,
+			&discount.PlanId,
+			&discount.PlanName,
+			&discount.Percent,
+			&discount.Reason,
+			pkg.TimeText(ctx, &discount.CreatedAt)); err != nil {
+			return err
+		}
+		if err := fn(discount); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GenerateInvoices invoices every student enrolled in the subject or belonging to the
+// group of an active plan for the period starting on the given first day of a month, the
+// students already invoiced are skipped. The numbers are taken from the counter of the
+// period's year, whose row stays locked until the invoices are stored so that concurrent
+// generations neither share nor skip numbers.
+func (s *billingRepo) GenerateInvoices(ctx context.Context, period string, year int) (int64, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO
+		invoice_counters (year)
+		VALUES ($1)
+	ON CONFLICT (year) DO UPDATE
+	SET
+		last_number = invoice_counters.last_number
+	RETURNING
+		last_number;`
+
+	var last int
+	if err := tx.QueryRow(ctx, query, year).Scan(&last); err != nil {
+		return 0, err
+	}
+
+	query = `
+	SELECT
+		m.student_id,
+		pp.id,
+		pp.monthly_fee,
+		pp.monthly_fee * COALESCE(d.percent, 0) / 100,
+		TO_CHAR($1::date + pp.due_day - 1, 'YYYY-MM-DD')
+	FROM
+		price_plans pp
+	INNER JOIN (
+		SELECT student_id, subject_id, NULL::uuid AS group_id FROM enrollments WHERE status = $2
+		UNION
+		SELECT student_id, NULL::uuid, group_id FROM group_students
+	) m
+	ON
+		m.subject_id = pp.subject_id OR m.group_id = pp.group_id
+	INNER JOIN
+		students st
+	ON
+		st.id = m.student_id
+	LEFT JOIN
+		discounts d
+	ON
+		d.student_id = m.student_id AND d.plan_id = pp.id
+	WHERE
+		pp.active
+		AND NOT EXISTS (
+			SELECT 1 FROM invoices i
+			WHERE i.student_id = m.student_id AND i.plan_id = pp.id AND i.period = $1::date
+		)
+	ORDER BY
+		st.last_name, st.first_name, st.id, pp.name, pp.id;`
+
+	rows, err := tx.Query(ctx, query, period, config.ENROLLMENT_ACTIVE)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var ids, numbers, studentIds, planIds, dueDates []string
+	var amounts, discounts []int64
+	for rows.Next() {
+		var studentId, planId, dueDate string
+		var amount, discount int64
+		if err := rows.Scan(&studentId, &planId, &amount, &discount, &dueDate); err != nil {
+			return 0, err
+		}
+		ids = append(ids, uuid.NewString())
+		numbers = append(numbers, billing.InvoiceNumber(year, last+len(ids)))
+		studentIds = append(studentIds, studentId)
+		planIds = append(planIds, planId)
+		amounts = append(amounts, amount)
+		discounts = append(discounts, discount)
+		dueDates = append(dueDates, dueDate)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	query = `
+	INSERT INTO
+		invoices (id, number, student_id, plan_id, period, amount, discount, due_date)
+	SELECT
+		id, number, student_id, plan_id, $1::date, amount, discount, due_date
+	FROM
+		UNNEST($2::uuid[], $3::text[], $4::uuid[], $5::uuid[], $6::bigint[], $7::bigint[], $8::date[])
+		AS n (id, number, student_id, plan_id, amount, discount, due_date);`
+
+	if _, err := tx.Exec(ctx, query, period, ids, numbers, studentIds, planIds, amounts, discounts, dueDates); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE invoice_counters SET last_number = $2 WHERE year = $1;`, year, last+len(ids)); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
+
+// GetInvoice returns the invoice, it is overdue when its due date is before today.
+func (s *billingRepo) GetInvoice(ctx context.Context, id, today string) (models.Invoice, error) {
+	query := `
+	SELECT` + invoiceColumns + `
+	FROM
+		invoices i` + invoiceJoins + `
+	WHERE
+		i.id = $2;`
+
+	return scanInvoice(ctx, s.db.QueryRow(ctx, query, today, id))
+}
+
+func (s *billingRepo) GetInvoices(ctx context.Context, req models.GetAllInvoicesRequest) (models.GetAllInvoicesResponse, error) {
+	resp := models.GetAllInvoicesResponse{}
+	err := s.EachInvoice(ctx, req, func(invoice models.Invoice) error {
+		resp.Invoices = append(resp.Invoices, invoice)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.db.QueryRow(ctx, `SELECT COUNT(*) FROM invoices i`+invoicePaid+` WHERE`+invoiceFilter,
+		req.Today, req.StudentId, req.PlanId, req.Period, req.Status).Scan(&resp.Count)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// EachInvoice hands the invoices of the page to fn one at a time as they are read.
+func (s *billingRepo) EachInvoice(ctx context.Context, req models.GetAllInvoicesRequest, fn func(models.Invoice) error) error {
+	offest := (req.Page - 1) * req.Limit
+
+	query := `
+	SELECT` + invoiceColumns + `
+	FROM
+		invoices i` + invoiceJoins + `
+	WHERE` + invoiceFilter + `
+	ORDER BY
+		i.period DESC, i.number DESC
+	OFFSET
+		$6
+	LIMIT
+		$7;`
+
+	rows, err := s.db.Query(ctx, query, req.Today, req.StudentId, req.PlanId, req.Period, req.Status, offest, req.Limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		invoice, err := scanInvoice(ctx, rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(invoice); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// CancelInvoice cancels the invoice. Nothing is changed and pgx.ErrNoRows is returned when
+// it is already cancelled or has payments.
+func (s *billingRepo) CancelInvoice(ctx context.Context, id string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// the lock waits for the payments being recorded, the update below sees them
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM invoices WHERE id = $1 FOR UPDATE;`, id); err != nil {
+		return err
+	}
+
+	query := `
+	UPDATE
+		invoices
+	SET
+		status = $2,
+		cancelled_at = NOW()
+	WHERE
+		id = $1
+		AND status = $3
+		AND NOT EXISTS (SELECT 1 FROM payments WHERE invoice_id = $1)
+	RETURNING
+		id;`
+
+	if err := tx.QueryRow(ctx, query, id, config.INVOICE_CANCELLED, config.INVOICE_ISSUED).Scan(&id); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// AddPayment records a payment of an invoice. Nothing is stored and pgx.ErrNoRows is
+// returned when the invoice is cancelled or the payment is more than its outstanding
+// amount.
+func (s *billingRepo) AddPayment(ctx context.Context, payment models.AddPayment) (string, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	// concurrent payments of the invoice wait here, so they never pay it twice
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM invoices WHERE id = $1 FOR UPDATE;`, payment.InvoiceId); err != nil {
+		return "", err
+	}
+
+	query := `
+	INSERT INTO
+		payments (id, student_id, invoice_id, amount, method, reference, recorded_by, paid_at)
+	SELECT
+		$1, i.student_id, i.id, $3, $4, $5, NULLIF($6, '')::uuid, COALESCE(NULLIF($7, '')::timestamptz, NOW())
+	FROM
+		invoices i
+	WHERE
+		i.id = $2
+		AND i.status = $8
+		AND i.amount - i.discount - (SELECT COALESCE(SUM(amount), 0) FROM payments WHERE invoice_id = $2) >= $3
+	RETURNING
+		id;`
+
+	var id string
+	err = tx.QueryRow(ctx, query, uuid.New(), payment.InvoiceId, payment.Amount, payment.Method, payment.Reference,
+		payment.RecordedBy, payment.PaidAt, config.INVOICE_ISSUED).Scan(&id)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (s *billingRepo) GetPayments(ctx context.Context, req models.GetAllPaymentsRequest) (models.GetAllPaymentsResponse, error) {
+	resp := models.GetAllPaymentsResponse{}
+	err := s.EachPayment(ctx, req, func(payment models.Payment) error {
+		resp.Payments = append(resp.Payments, payment)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.db.QueryRow(ctx, `SELECT COUNT(*) FROM payments p WHERE`+paymentFilter,
+		req.StudentId, req.InvoiceId).Scan(&resp.Count)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// EachPayment hands the payments of the page to fn one at a time as they are read.
+func (s *billingRepo) EachPayment(ctx context.Context, req models.GetAllPaymentsRequest, fn func(models.Payment) error) error {
+	offest := (req.Page - 1) * req.Limit
+
+	query := `
+	SELECT` + paymentColumns + `
+	FROM
+		payments p` + paymentJoins + `
+	WHERE` + paymentFilter + `
+	ORDER BY
+		p.paid_at DESC
+	OFFSET
+		$3
+	LIMIT
+		$4;`
+
+	rows, err := s.db.Query(ctx, query, req.StudentId, req.InvoiceId, offest, req.Limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		payment, err := scanPayment(ctx, rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(payment); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GetBalance returns the student's statement, the issued invoices and their payments in
+// the order they happened with the balance after each of them. Invoices are overdue when
+// their due date is before today.
+func (s *billingRepo) GetBalance(ctx context.Context, studentId, today string) (models.StudentBalance, error) {
+	balance := models.StudentBalance{StudentId: studentId, Entries: []models.BalanceEntry{}}
+
+	query := `
+	SELECT
+		e.date,
+		e.kind,
+		e.id,
+		e.reference,
+		e.amount,
+		SUM(e.amount) OVER (ORDER BY e.date, e.kind, e.id ROWS UNBOUNDED PRECEDING)::bigint
+	FROM (
+		SELECT
+			i.created_at AS date, 'invoice' AS kind, i.id, i.number AS reference, i.amount - i.discount AS amount
+		FROM
+			invoices i
+		WHERE
+			i.student_id = $1 AND i.status = $2
+		UNION ALL
+		SELECT
+			p.paid_at, 'payment', p.id, i.number, -p.amount
+		FROM
+			payments p
+		INNER JOIN
+			invoices i
+		ON
+			i.id = p.invoice_id
+		WHERE
+			p.student_id = $1
+	) e
+	ORDER BY
+		e.date, e.kind, e.id;`
+
+	rows, err := s.db.Query(ctx, query, studentId, config.INVOICE_ISSUED)
+	if err != nil {
+		return balance, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.BalanceEntry
+		if err := rows.Scan(
+			pkg.TimeText(ctx, &entry.Date),
+			&entry.Kind,
+			&entry.Id,
+			&entry.Reference,
+			&entry.Amount,
+			&entry.Balance); err != nil {
+			return balance, err
+		}
+		if entry.Amount > 0 {
+			balance.Invoiced += entry.Amount
+		} else {
+			balance.Paid -= entry.Amount
+		}
+		balance.Balance = entry.Balance
+		balance.Entries = append(balance.Entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return balance, err
+	}
+
+	query = `
+	SELECT
+		COALESCE(SUM(i.amount - i.discount - p.paid), 0)::bigint
+	FROM
+		invoices i` + invoicePaid + `
+	WHERE
+		i.student_id = $1
+		AND i.status = $2
+		AND i.due_date < $3::date
+		AND i.amount - i.discount > p.paid;`
+
+	if err := s.db.QueryRow(ctx, query, studentId, config.INVOICE_ISSUED, today).Scan(&balance.Overdue); err != nil {
+		return balance, err
+	}
+
+	return balance, nil
+}
+
+// GetDebtors returns the students with overdue invoices, the ones owing the most first.
+func (s *billingRepo) GetDebtors(ctx context.Context, req models.DebtorsRequest) (models.DebtorsResponse, error) {
+	resp := models.DebtorsResponse{}
+	err := s.EachDebtor(ctx, req, func(debtor models.Debtor) error {
+		resp.Debtors = append(resp.Debtors, debtor)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.db.QueryRow(ctx, `SELECT COUNT(*), COALESCE(SUM(d.overdue), 0)::bigint FROM`+debtorsFrom,
+		req.Today, req.MinOverdue, req.GroupId).Scan(&resp.Count, &resp.TotalOverdue)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// EachDebtor hands the debtors of the page to fn one at a time as they are read.
+func (s *billingRepo) EachDebtor(ctx context.Context, req models.DebtorsRequest, fn func(models.Debtor) error) error {
+	offest := (req.Page - 1) * req.Limit
+
+	query := `
+	SELECT
+		st.id,
+		st.first_name || ' ' || st.last_name,
+		COALESCE(st.phone, ''),
+		(SELECT COALESCE(SUM(amount - discount), 0) FROM invoices WHERE student_id = st.id AND status = 'issued')::bigint
+			- (SELECT COALESCE(SUM(amount), 0) FROM payments WHERE student_id = st.id)::bigint,
+		d.overdue,
+		d.invoices,
+		TO_CHAR(d.oldest_due_date, 'YYYY-MM-DD')
+	FROM (
+		SELECT d.* FROM` + debtorsFrom + `
+	) d
+	INNER JOIN
+		students st
+	ON
+		st.id = d.student_id
+	ORDER BY
+		d.overdue DESC, st.last_name, st.first_name
+	OFFSET
+		$4
+	LIMIT
+		$5;`
+
+	rows, err := s.db.Query(ctx, query, req.Today, req.MinOverdue, req.GroupId, offest, req.Limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var debtor models.Debtor
+		if err := rows.Scan(
+			&debtor.StudentId,
+			&debtor.StudentName,
+			&debtor.Phone,
+			&debtor.Balance,
+			&debtor.Overdue,
+			&debtor.OverdueInvoices,
+			&debtor.OldestDueDate); err != nil {
+			return err
+		}
+		if err := fn(debtor); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *billingRepo) CreateTransaction(ctx context.Context, invoiceId, provider string, amount int64) (string, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO
+		payment_transactions (id, invoice_id, provider, amount)
+		VALUES ($1, $2, $3, $4);`
+
+	if _, err := s.db.Exec(ctx, query, id, invoiceId, provider, amount); err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func (s *billingRepo) GetTransaction(ctx context.Context, id string) (models.PaymentTransaction, error) {
+	query := `
+	SELECT
+		id,
+		invoice_id,
+		provider,
+		COALESCE(provider_tx_id, ''),
+		amount,
+		state,
+		COALESCE(payment_id::text, ''),
+		created_at,
+		updated_at
+	FROM
+		payment_transactions
+	WHERE
+		id = $1;`
+
+	var transaction models.PaymentTransaction
+	err := s.db.QueryRow(ctx, query, id).Scan(
+		&transaction.Id,
+		&transaction.InvoiceId,
+		&transaction.Provider,
+		&transaction.ProviderTxId,
+		&transaction.Amount,
+		&transaction.State,
+		&transaction.PaymentId,
+		pkg.TimeText(ctx, &transaction.CreatedAt),
+		pkg.TimeText(ctx, &transaction.UpdatedAt))
+	return transaction, err
+}
+
+// PrepareTransaction marks the transaction prepared by the provider's transaction, which
+// is repeated harmlessly. Nothing is changed and pgx.ErrNoRows is returned when it is
+// completed, cancelled or prepared by another provider's transaction.
+func (s *billingRepo) PrepareTransaction(ctx context.Context, id, providerTxId string) error {
+	query := `
+	UPDATE
+		payment_transactions
+	SET
+		state = $3,
+		provider_tx_id = $2,
+		updated_at = NOW()
+	WHERE
+		id = $1
+		AND (state = $4 OR (state = $3 AND provider_tx_id = $2))
+	RETURNING
+		id;`
+
+	return s.db.QueryRow(ctx, query, id, providerTxId, config.TRANSACTION_PREPARED,
+		config.TRANSACTION_CREATED).Scan(&id)
+}
+
+// CancelTransaction cancels the transaction unless it is completed.
+func (s *billingRepo) CancelTransaction(ctx context.Context, id string) error {
+	query := `
+	UPDATE
+		payment_transactions
+	SET
+		state = $2,
+		updated_at = NOW()
+	WHERE
+		id = $1
+		AND state IN ($3, $4);`
+
+	_, err := s.db.Exec(ctx, query, id, config.TRANSACTION_CANCELLED, config.TRANSACTION_CREATED,
+		config.TRANSACTION_PREPARED)
+	return err
+}
+
+// CompleteTransaction records the payment of a prepared transaction and returns its id,
+// a completed transaction returns the payment it recorded so that repeated callbacks pay
+// once. pgx.ErrNoRows is returned when the transaction is not prepared or the invoice can
+// no longer take the payment.
+func (s *billingRepo) CompleteTransaction(ctx context.Context, id string) (string, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	// repeated callbacks wait here and then find the transaction completed
+	var state, invoiceId, paymentId string
+	err = tx.QueryRow(ctx, `SELECT state, invoice_id, COALESCE(payment_id::text, '') FROM payment_transactions WHERE id = $1 FOR UPDATE;`,
+		id).Scan(&state, &invoiceId, &paymentId)
+	if err != nil {
+		return "", err
+	}
+	if state == config.TRANSACTION_COMPLETED {
+		return paymentId, nil
+	}
+	if state != config.TRANSACTION_PREPARED {
+		return "", pgx.ErrNoRows
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM invoices WHERE id = $1 FOR UPDATE;`, invoiceId); err != nil {
+		return "", err
+	}
+
+	query := `
+	INSERT INTO
+		payments (id, student_id, invoice_id, amount, method, reference)
+	SELECT
+		$1, i.student_id, i.id, t.amount, $3, t.provider || ':' || t.provider_tx_id
+	FROM
+		payment_transactions t
+	INNER JOIN
+		invoices i
+	ON
+		i.id = t.invoice_id
+	WHERE
+		t.id = $2
+		AND i.status = $4
+		AND i.amount - i.discount - (SELECT COALESCE(SUM(amount), 0) FROM payments WHERE invoice_id = i.id) >= t.amount
+	RETURNING
+		id;`
+
+	err = tx.QueryRow(ctx, query, uuid.New(), id, config.PAYMENT_ONLINE, config.INVOICE_ISSUED).Scan(&paymentId)
+	if err != nil {
+		return "", err
+	}
+
+	query = `
+	UPDATE
+		payment_transactions
+	SET
+		state = $2,
+		payment_id = $3,
+		updated_at = NOW()
+	WHERE
+		id = $1;`
+
+	if _, err := tx.Exec(ctx, query, id, config.TRANSACTION_COMPLETED, paymentId); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return paymentId, nil
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/domain"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// createTestInvoice stores a student with an invoice of amount to pay.
+func createTestInvoice(t *testing.T, amount int64) string {
+	t.Helper()
+	ctx := context.Background()
+	studentRepo := NewStudent(db)
+	subjectRepo := NewSubject(db)
+	billingRepo := NewBilling(db)
+
+	studentId, err := studentRepo.Create(ctx, domain.Student{
+		FirstName: faker.Name(),
+		LastName:  faker.Word(),
+		Age:       10,
+		Phone:     faker.Phonenumber(),
+		Email:     uuid.NewString() + "@gmail.com",
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	subjectId, err := subjectRepo.Create(ctx, models.AddSubject{Name: faker.Word(), Type: faker.Word(), Level: "beginner"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	// a plan is for exactly one subject or group
+	planId, err := billingRepo.CreatePlan(ctx, models.AddPricePlan{Name: faker.Word(), SubjectId: subjectId, MonthlyFee: amount, DueDay: 10})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	invoiceId := uuid.NewString()
+	_, err = db.Exec(ctx, `
+	INSERT INTO
+		invoices (id, number, student_id, plan_id, period, amount, due_date)
+		VALUES ($1, $2, $3, $4, $5, $6, $5);`,
+		invoiceId, invoiceId[:20], studentId, planId, time.Now().Format("2006-01-02"), amount)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return invoiceId
+}
+
+func countPayments(t *testing.T, invoiceId string) int {
+	t.Helper()
+	var count int
+	err := db.QueryRow(context.Background(), `SELECT COUNT(*) FROM payments WHERE invoice_id = $1;`, invoiceId).Scan(&count)
+	assert.NoError(t, err)
+	return count
+}
+
+func TestCompleteTransaction(t *testing.T) {
+	ctx := context.Background()
+	billingRepo := NewBilling(db)
+	invoiceId := createTestInvoice(t, 500000)
+
+	id, err := billingRepo.CreateTransaction(ctx, invoiceId, "fake", 500000)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// only a prepared transaction records a payment
+	_, err = billingRepo.CompleteTransaction(ctx, id)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+
+	assert.NoError(t, billingRepo.PrepareTransaction(ctx, id, uuid.NewString()))
+
+	// repeated callbacks complete it at the same time, the payment is recorded once
+	var wg sync.WaitGroup
+	paymentIds := make([]string, 5)
+	errs := make([]error, 5)
+	for i := range paymentIds {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			paymentIds[i], errs[i] = billingRepo.CompleteTransaction(ctx, id)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range paymentIds {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, paymentIds[0], paymentIds[i])
+	}
+	assert.Equal(t, 1, countPayments(t, invoiceId))
+
+	transaction, err := billingRepo.GetTransaction(ctx, id)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "completed", transaction.State)
+		assert.Equal(t, paymentIds[0], transaction.PaymentId)
+	}
+}
+
+func TestCompleteTransactionPaidInvoice(t *testing.T) {
+	ctx := context.Background()
+	billingRepo := NewBilling(db)
+	invoiceId := createTestInvoice(t, 500000)
+
+	first, err := billingRepo.CreateTransaction(ctx, invoiceId, "fake", 500000)
+	assert.NoError(t, err)
+	second, err := billingRepo.CreateTransaction(ctx, invoiceId, "fake", 500000)
+	assert.NoError(t, err)
+	assert.NoError(t, billingRepo.PrepareTransaction(ctx, first, uuid.NewString()))
+	assert.NoError(t, billingRepo.PrepareTransaction(ctx, second, uuid.NewString()))
+
+	_, err = billingRepo.CompleteTransaction(ctx, first)
+	assert.NoError(t, err)
+
+	// the invoice has nothing left to pay for the second checkout
+	_, err = billingRepo.CompleteTransaction(ctx, second)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	assert.Equal(t, 1, countPayments(t, invoiceId))
+}
@@ -0,0 +1,236 @@
+package postgres
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg"
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// openDay is a condition which drops the rows whose timestamp column falls on a holiday or
+// closure.
+func openDay(column string) string {
+	return `NOT EXISTS (SELECT 1 FROM closures cl WHERE (` + column + `)::date BETWEEN cl.from_date AND cl.to_date)`
+}
+
+type calendarRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewCalendar(db *pgxpool.Pool) calendarRepo {
+	return calendarRepo{
+		db: db,
+	}
+}
+
+func (s *calendarRepo) CreateAcademicYear(ctx context.Context, year models.AddAcademicYear) (string, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO
+		academic_years (id, name, start_date, end_date) VALUES ($1, $2, $3, $4);`
+
+	_, err := s.db.Exec(ctx, query, id, year.Name, year.StartDate, year.EndDate)
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func (s *calendarRepo) DeleteAcademicYear(ctx context.Context, id string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM academic_years WHERE id = $1;`, id)
+	return err
+}
+
+// GetAcademicYears returns every academic year with its terms, the latest first.
+func (s *calendarRepo) GetAcademicYears(ctx context.Context) (models.GetAllAcademicYearsResponse, error) {
+	resp := models.GetAllAcademicYearsResponse{}
+
+	query := `
+	SELECT
+		id,
+		name,
+		TO_CHAR(start_date,'YYYY-MM-DD'),
+		TO_CHAR(end_date,'YYYY-MM-DD'),
+		created_at
+	FROM
+		academic_years
+	ORDER BY
+		start_date DESC;`
+
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return resp, err
+	}
+	defer rows.Close()
+
+	index := map[string]int{}
+	for rows.Next() {
+		var year models.AcademicYear
+		if err := rows.Scan(&year.Id, &year.Name, &year.StartDate, &year.EndDate, pkg.TimeText(ctx, &year.CreatedAt)); err != nil {
+			return resp, err
+		}
+		index[year.Id] = len(resp.AcademicYears)
+		resp.AcademicYears = append(resp.AcademicYears, year)
+	}
+	if err := rows.Err(); err != nil {
+		return resp, err
+	}
+	resp.Count = int64(len(resp.AcademicYears))
+
+	query = `
+	SELECT` + termColumns + `
+	FROM
+		terms
+	ORDER BY
+		start_date;`
+
+	rows, err = s.db.Query(ctx, query)
+	if err != nil {
+		return resp, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var term models.Term
+		if err := rows.Scan(&term.Id, &term.AcademicYearId, &term.Name, &term.StartDate, &term.EndDate, pkg.TimeText(ctx, &term.CreatedAt)); err != nil {
+			return resp, err
+		}
+		i := index[term.AcademicYearId]
+		resp.AcademicYears[i].Terms = append(resp.AcademicYears[i].Terms, term)
+	}
+
+	return resp, rows.Err()
+}
+
+const termColumns = `
+		id,
+		academic_year_id,
+		name,
+		TO_CHAR(start_date,'YYYY-MM-DD'),
+		TO_CHAR(end_date,'YYYY-MM-DD'),
+		created_at`
+
+// CreateTerm adds a term which has to lie within its academic year.
+func (s *calendarRepo) CreateTerm(ctx context.Context, term models.AddTerm) (string, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO
+		terms (id, academic_year_id, name, start_date, end_date)
+	SELECT
+		$1, ay.id, $3, $4, $5
+	FROM
+		academic_years ay
+	WHERE
+		ay.id = $2 AND ay.start_date <= $4::date AND ay.end_date >= $5::date;`
+
+	tag, err := s.db.Exec(ctx, query, id, term.AcademicYearId, term.Name, term.StartDate, term.EndDate)
+	if err != nil {
+		return "", err
+	}
+	if tag.RowsAffected() == 0 {
+		return "", errors.New("academic year is not found or the term does not lie within it")
+	}
+
+	return id.String(), nil
+}
+
+func (s *calendarRepo) GetTerm(ctx context.Context, id string) (models.Term, error) {
+	query := `
+	SELECT` + termColumns + `
+	FROM
+		terms
+	WHERE
+		id = $1;`
+
+	var term models.Term
+	err := s.db.QueryRow(ctx, query, id).Scan(&term.Id, &term.AcademicYearId, &term.Name, &term.StartDate, &term.EndDate, pkg.TimeText(ctx, &term.CreatedAt))
+
+	return term, err
+}
+
+func (s *calendarRepo) DeleteTerm(ctx context.Context, id string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM terms WHERE id = $1;`, id)
+	return err
+}
+
+func (s *calendarRepo) CreateClosure(ctx context.Context, closure models.AddClosure) (string, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO
+		closures (id, name, kind, from_date, to_date) VALUES ($1, $2, $3, $4, $5);`
+
+	_, err := s.db.Exec(ctx, query, id, closure.Name, closure.Kind, closure.FromDate, closure.ToDate)
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func (s *calendarRepo) DeleteClosure(ctx context.Context, id string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM closures WHERE id = $1;`, id)
+	return err
+}
+
+// GetClosures returns the closures overlapping [from, to], empty dates leave that side open.
+func (s *calendarRepo) GetClosures(ctx context.Context, from, to string) (models.GetAllClosuresResponse, error) {
+	resp := models.GetAllClosuresResponse{}
+
+	query := `
+	SELECT
+		id,
+		name,
+		kind,
+		TO_CHAR(from_date,'YYYY-MM-DD'),
+		TO_CHAR(to_date,'YYYY-MM-DD'),
+		created_at
+	FROM
+		closures
+	WHERE
+		($1 = '' OR to_date >= $1::date)
+		AND ($2 = '' OR from_date <= $2::date)
+	ORDER BY
+		from_date;`
+
+	rows, err := s.db.Query(ctx, query, from, to)
+	if err != nil {
+		return resp, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var closure models.Closure
+		if err := rows.Scan(&closure.Id, &closure.Name, &closure.Kind, &closure.FromDate, &closure.ToDate, pkg.TimeText(ctx, &closure.CreatedAt)); err != nil {
+			return resp, err
+		}
+		resp.Closures = append(resp.Closures, closure)
+	}
+	resp.Count = int64(len(resp.Closures))
+
+	return resp, rows.Err()
+}
+
+// IsClosed tells whether any day from from to to is a holiday or closure.
+func (s *calendarRepo) IsClosed(ctx context.Context, from, to string) (bool, error) {
+	query := `
+	SELECT EXISTS (
+		SELECT
+			1
+		FROM
+			closures
+		WHERE
+			from_date <= $2::timestamptz::date AND to_date >= $1::timestamptz::date
+	);`
+
+	var closed bool
+	err := s.db.QueryRow(ctx, query, from, to).Scan(&closed)
+
+	return closed, err
+}
@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"backend_course/lms/api/models"
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type dashboardRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewDashboard(db *pgxpool.Pool) dashboardRepo {
+	return dashboardRepo{
+		db: db,
+	}
+}
+
+func (s *dashboardRepo) GetStudentCounts(ctx context.Context) (models.StudentCounts, error) {
+	query := `
+	SELECT
+		COUNT(*) FILTER (WHERE is_active),
+		COUNT(*) FILTER (WHERE NOT COALESCE(is_active, FALSE)),
+		COUNT(*)
+	FROM
+		students;`
+
+	var counts models.StudentCounts
+	err := s.db.QueryRow(ctx, query).Scan(&counts.Active, &counts.Inactive, &counts.Total)
+	return counts, err
+}
+
+// GetNewStudents counts the students created between from and to per month, months
+// without any are counted as 0.
+func (s *dashboardRepo) GetNewStudents(ctx context.Context, from, to string) ([]models.MonthCount, error) {
+	query := `
+	SELECT
+		TO_CHAR(m, 'YYYY-MM'),
+		COUNT(st.id)
+	FROM
+		generate_series(DATE_TRUNC('month', $1::timestamptz), $2::timestamptz, INTERVAL '1 month') AS m
+	LEFT JOIN
+		students st
+	ON
+		st.created_at >= m
+		AND st.created_at < m + INTERVAL '1 month'
+		AND st.created_at BETWEEN $1 AND $2
+	GROUP BY
+		m
+	ORDER BY
+		m;`
+
+	rows, err := s.db.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	months := []models.MonthCount{}
+	for rows.Next() {
+		var month models.MonthCount
+		if err := rows.Scan(&month.Month, &month.Count); err != nil {
+			return nil, err
+		}
+		months = append(months, month)
+	}
+
+	return months, rows.Err()
+}
+
+// CountLessons counts the lessons starting between from and to, time_table has a row per
+// student of a lesson. Holidays and closures are left out.
+func (s *dashboardRepo) CountLessons(ctx context.Context, from, to string) (int64, error) {
+	query := `
+	SELECT
+		COUNT(DISTINCT (tt.teacher_id, tt.subject_id, tt.from_date))
+	FROM
+		time_table tt
+	WHERE
+		tt.from_date BETWEEN $1 AND $2
+		AND ` + openDay("tt.from_date") + `;`
+
+	var count int64
+	err := s.db.QueryRow(ctx, query, from, to).Scan(&count)
+	return count, err
+}
+
+// GetAttendance counts the marks of every student of the lessons which started between
+// from and to.
+func (s *dashboardRepo) GetAttendance(ctx context.Context, from, to string) (models.AttendanceRate, error) {
+	query := `
+	SELECT
+		COUNT(*) FILTER (WHERE a.status = 'present'),
+		COUNT(*) FILTER (WHERE a.status = 'late'),
+		COUNT(*) FILTER (WHERE a.status = 'absent'),
+		COUNT(*) FILTER (WHERE a.status = 'excused'),
+		COUNT(*) FILTER (WHERE a.status IS NULL)
+	FROM
+		time_table tt
+	LEFT JOIN
+		attendance a
+	ON
+		a.time_table_id = tt.id AND a.student_id = tt.student_id
+	WHERE
+		tt.from_date BETWEEN $1 AND $2
+		AND tt.from_date <= NOW()
+		AND ` + openDay("tt.from_date") + `;`
+
+	var rate models.AttendanceRate
+	err := s.db.QueryRow(ctx, query, from, to).Scan(
+		&rate.Present,
+		&rate.Late,
+		&rate.Absent,
+		&rate.Excused,
+		&rate.Unmarked)
+	return rate, err
+}
+
+func (s *dashboardRepo) GetRevenue(ctx context.Context, from, to string) (models.Revenue, error) {
+	query := `
+	SELECT
+		COALESCE(SUM(amount), 0)::bigint,
+		COUNT(*)
+	FROM
+		payments
+	WHERE
+		paid_at BETWEEN $1 AND $2;`
+
+	var revenue models.Revenue
+	err := s.db.QueryRow(ctx, query, from, to).Scan(&revenue.Amount, &revenue.Payments)
+	return revenue, err
+}
+
+// GetTopSubjects returns the limit subjects with the most lessons starting between from
+// and to.
+func (s *dashboardRepo) GetTopSubjects(ctx context.Context, from, to string, limit int) ([]models.SubjectStat, error) {
+	query := `
+	SELECT
+		tt.subject_id,
+		COALESCE(sb.name, ''),
+		COUNT(DISTINCT (tt.teacher_id, tt.from_date)),
+		COUNT(DISTINCT tt.student_id)
+	FROM
+		time_table tt
+	INNER JOIN
+		subjects sb
+	ON
+		sb.id = tt.subject_id
+	WHERE
+		tt.from_date BETWEEN $1 AND $2
+		AND ` + openDay("tt.from_date") + `
+	GROUP BY
+		tt.subject_id, sb.name
+	ORDER BY
+		3 DESC, 4 DESC, sb.name
+	LIMIT
+		$3;`
+
+	rows, err := s.db.Query(ctx, query, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subjects := []models.SubjectStat{}
+	for rows.Next() {
+		var subject models.SubjectStat
+		if err := rows.Scan(&subject.SubjectId, &subject.SubjectName, &subject.Lessons, &subject.Students); err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, subject)
+	}
+
+	return subjects, rows.Err()
+}
@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// enrollmentColumns are scanned by scanEnrollment.
+const enrollmentColumns = `
+		e.id,
+		e.student_id,
+		st.first_name || ' ' || st.last_name,
+		e.subject_id,
+		COALESCE(sb.name, ''),
+		e.status,
+		e.enrolled_at,
+		e.completed_at`
+
+// enrollmentFilter keeps the enrollments of the student, subject and status given in $1,
+// $2 and $3, empty ones match everything.
+const enrollmentFilter = `
+		($1 = '' OR e.student_id::text = $1)
+		AND ($2 = '' OR e.subject_id::text = $2)
+		AND ($3 = '' OR e.status = $3)`
+
+const enrollmentJoins = `
+	INNER JOIN
+		students st
+	ON
+		st.id = e.student_id
+	INNER JOIN
+		subjects sb
+	ON
+		sb.id = e.subject_id`
+
+type enrollmentRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewEnrollment(db *pgxpool.Pool) enrollmentRepo {
+	return enrollmentRepo{
+		db: db,
+	}
+}
+
+func scanEnrollment(ctx context.Context, row interface{ Scan(dest ...any) error }) (models.Enrollment, error) {
+	var enrollment models.Enrollment
+	err := row.Scan(
+		&enrollment.Id,
+		&enrollment.StudentId,
+		&enrollment.StudentName,
+		&enrollment.SubjectId,
+		&enrollment.SubjectName,
+		&enrollment.Status,
+		pkg.TimeText(ctx, &enrollment.EnrolledAt),
+		pkg.TimeText(ctx, &enrollment.CompletedAt))
+	return enrollment, err
+}
+
+// MissingPrerequisites returns the prerequisites of the subject the student has not
+// completed.
+func (s *enrollmentRepo) MissingPrerequisites(ctx context.Context, studentId, subjectId string) ([]models.SubjectRef, error) {
+	query := `
+	SELECT
+		sb.id,
+		COALESCE(sb.code, ''),
+		COALESCE(sb.name, '')
+	FROM
+		subject_prerequisites p
+	INNER JOIN
+		subjects sb
+	ON
+		sb.id = p.prerequisite_id
+	WHERE
+		p.subject_id = $1
+		AND NOT EXISTS (
+			SELECT 1 FROM enrollments e
+			WHERE e.subject_id = p.prerequisite_id AND e.student_id = $2 AND e.status = $3
+		)
+	ORDER BY
+		sb.code, sb.name;`
+
+	return scanSubjectRefs(s.db.Query(ctx, query, subjectId, studentId, config.ENROLLMENT_COMPLETED))
+}
+
+// Enroll enrolls the student in the subject, a dropped enrollment becomes active again.
+// Nothing is stored and pgx.ErrNoRows is returned when the student is already enrolled or
+// has completed the subject.
+func (s *enrollmentRepo) Enroll(ctx context.Context, studentId, subjectId string) (string, error) {
+	query := `
+	INSERT INTO
+		enrollments (id, student_id, subject_id, status)
+		VALUES ($1, $2, $3, $4)
+	ON CONFLICT (student_id, subject_id) DO UPDATE
+	SET
+		status = EXCLUDED.status,
+		enrolled_at = NOW(),
+		completed_at = NULL
+	WHERE
+		enrollments.status = $5
+	RETURNING
+		id;`
+
+	var id string
+	err := s.db.QueryRow(ctx, query, uuid.New(), studentId, subjectId, config.ENROLLMENT_ACTIVE,
+		config.ENROLLMENT_DROPPED).Scan(&id)
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (s *enrollmentRepo) GetEnrollment(ctx context.Context, id string) (models.Enrollment, error) {
+	query := `
+	SELECT` + enrollmentColumns + `
+	FROM
+		enrollments e` + enrollmentJoins + `
+	WHERE
+		e.id = $1;`
+
+	return scanEnrollment(ctx, s.db.QueryRow(ctx, query, id))
+}
+
+func (s *enrollmentRepo) GetEnrollments(ctx context.Context, req models.GetAllEnrollmentsRequest) (models.GetAllEnrollmentsResponse, error) {
+	resp := models.GetAllEnrollmentsResponse{}
+	err := s.EachEnrollment(ctx, req, func(enrollment models.Enrollment) error {
+		resp.Enrollments = append(resp.Enrollments, enrollment)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.db.QueryRow(ctx, `SELECT COUNT(*) FROM enrollments e WHERE`+enrollmentFilter,
+		req.StudentId, req.SubjectId, req.Status).Scan(&resp.Count)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// EachEnrollment hands the enrollments of the page to fn one at a time as they are read.
+func (s *enrollmentRepo) EachEnrollment(ctx context.Context, req models.GetAllEnrollmentsRequest, fn func(models.Enrollment) error) error {
+	offest := (req.Page - 1) * req.Limit
+
+	query := `
+	SELECT` + enrollmentColumns + `
+	FROM
+		enrollments e` + enrollmentJoins + `
+	WHERE` + enrollmentFilter + `
+	ORDER BY
+		e.enrolled_at DESC
+	OFFSET
+		$4
+	LIMIT
+		$5;`
+
+	rows, err := s.db.Query(ctx, query, req.StudentId, req.SubjectId, req.Status, offest, req.Limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		enrollment, err := scanEnrollment(ctx, rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(enrollment); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// UpdateStatus sets the status of the enrollment, completed_at is kept only while it is
+// completed.
+func (s *enrollmentRepo) UpdateStatus(ctx context.Context, id, status string) error {
+	query := `
+	UPDATE
+		enrollments
+	SET
+		status = $2,
+		completed_at = CASE WHEN $2 = $3 THEN COALESCE(completed_at, NOW()) END
+	WHERE
+		id = $1;`
+
+	_, err := s.db.Exec(ctx, query, id, status, config.ENROLLMENT_COMPLETED)
+	return err
+}
@@ -0,0 +1,263 @@
+package postgres
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// assessmentColumns are scanned by scanAssessment.
+const assessmentColumns = `
+		a.id,
+		a.subject_id,
+		a.group_id,
+		COALESCE(a.teacher_id::text, ''),
+		a.name,
+		a.kind,
+		a.max_score::float8,
+		a.weight::float8,
+		TO_CHAR(a.held_on,'YYYY-MM-DD'),
+		a.created_at`
+
+// assessmentFilter keeps the assessments of the group, subject and term given in $n, $n+1
+// and $n+2 held between the dates given in $n+3 and $n+4, empty ones match everything.
+func assessmentFilter(n int) string {
+	return fmt.Sprintf(`
+		($%d = '' OR a.group_id::text = $%d)
+		AND ($%d = '' OR a.subject_id::text = $%d)
+		AND ($%d = '' OR EXISTS (
+			SELECT 1 FROM terms t WHERE t.id::text = $%d AND a.held_on BETWEEN t.start_date AND t.end_date
+		))
+		AND ($%d = '' OR a.held_on >= $%d::date)
+		AND ($%d = '' OR a.held_on <= $%d::date)`, n, n, n+1, n+1, n+2, n+2, n+3, n+3, n+4, n+4)
+}
+
+type gradebookRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewGradebook(db *pgxpool.Pool) gradebookRepo {
+	return gradebookRepo{
+		db: db,
+	}
+}
+
+func scanAssessment(ctx context.Context, row interface{ Scan(dest ...any) error }) (models.Assessment, error) {
+	var assessment models.Assessment
+	err := row.Scan(
+		&assessment.Id,
+		&assessment.SubjectId,
+		&assessment.GroupId,
+		&assessment.TeacherId,
+		&assessment.Name,
+		&assessment.Kind,
+		&assessment.MaxScore,
+		&assessment.Weight,
+		&assessment.HeldOn,
+		pkg.TimeText(ctx, &assessment.CreatedAt))
+	return assessment, err
+}
+
+func (s *gradebookRepo) CreateAssessment(ctx context.Context, teacherId string, assessment models.AddAssessment) (string, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO
+		assessments (id, subject_id, group_id, teacher_id, name, kind, max_score, weight, held_on)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);`
+
+	_, err := s.db.Exec(ctx, query, id, assessment.SubjectId, assessment.GroupId, teacherId, assessment.Name, assessment.Kind,
+		assessment.MaxScore, assessment.Weight, assessment.HeldOn)
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func (s *gradebookRepo) GetAssessment(ctx context.Context, id string) (models.Assessment, error) {
+	query := `
+	SELECT` + assessmentColumns + `
+	FROM
+		assessments a
+	WHERE
+		a.id = $1;`
+
+	return scanAssessment(ctx, s.db.QueryRow(ctx, query, id))
+}
+
+func (s *gradebookRepo) DeleteAssessment(ctx context.Context, id string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM assessments WHERE id = $1;`, id)
+	return err
+}
+
+func (s *gradebookRepo) GetAssessments(ctx context.Context, req models.GetAllAssessmentsRequest) (models.GetAllAssessmentsResponse, error) {
+	resp := models.GetAllAssessmentsResponse{}
+	err := s.EachAssessment(ctx, req, func(assessment models.Assessment) error {
+		resp.Assessments = append(resp.Assessments, assessment)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.db.QueryRow(ctx, `SELECT COUNT(*) FROM assessments a WHERE`+assessmentFilter(1),
+		req.GroupId, req.SubjectId, req.TermId, "", "").Scan(&resp.Count)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// EachAssessment hands the assessments of the page to fn one at a time as they are read.
+func (s *gradebookRepo) EachAssessment(ctx context.Context, req models.GetAllAssessmentsRequest, fn func(models.Assessment) error) error {
+	offest := (req.Page - 1) * req.Limit
+
+	query := `
+	SELECT` + assessmentColumns + `
+	FROM
+		assessments a
+	WHERE` + assessmentFilter(3) + `
+	ORDER BY
+		a.held_on DESC, a.created_at DESC
+	OFFSET
+		$1
+	LIMIT
+		$2;`
+
+	rows, err := s.db.Query(ctx, query, offest, req.Limit, req.GroupId, req.SubjectId, req.TermId, "", "")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		assessment, err := scanAssessment(ctx, rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(assessment); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// SetScores stores the scores of the assessment, each student has to be in the
+// assessment's group. A student's earlier score is replaced.
+func (s *gradebookRepo) SetScores(ctx context.Context, assessmentId, teacherId string, scores []models.Score) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO
+		scores (assessment_id, student_id, score, graded_by)
+	SELECT
+		a.id, gs.student_id, $3, $4
+	FROM
+		assessments a
+	INNER JOIN
+		group_students gs
+	ON
+		gs.group_id = a.group_id
+	WHERE
+		a.id = $1 AND gs.student_id = $2
+	ON CONFLICT (assessment_id, student_id) DO UPDATE
+	SET
+		score = EXCLUDED.score,
+		graded_by = EXCLUDED.graded_by,
+		updated_at = NOW();`
+
+	for _, score := range scores {
+		tag, err := tx.Exec(ctx, query, assessmentId, score.StudentId, score.Score, teacherId)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("student %s is not in this assessment's group", score.StudentId)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetGradeRows returns every assessment of the students matching req with their scores,
+// ordered by subject, student and date. A student is graded on the assessments of their groups.
+func (s *gradebookRepo) GetGradeRows(ctx context.Context, req models.GradesRequest) ([]models.GradeRow, error) {
+	query := `
+	SELECT
+		st.id,
+		st.first_name || ' ' || st.last_name AS student_name,
+		sb.id,
+		sb.name,` + assessmentColumns + `,
+		sc.score::float8
+	FROM
+		assessments a
+	INNER JOIN
+		group_students gs
+	ON
+		gs.group_id = a.group_id
+	INNER JOIN
+		students st
+	ON
+		st.id = gs.student_id
+	INNER JOIN
+		subjects sb
+	ON
+		sb.id = a.subject_id
+	LEFT JOIN
+		scores sc
+	ON
+		sc.assessment_id = a.id AND sc.student_id = st.id
+	WHERE
+		($1 = '' OR st.id::text = $1) AND` + assessmentFilter(2) + `
+	ORDER BY
+		sb.name, sb.id, student_name, st.id, a.held_on, a.created_at;`
+
+	rows, err := s.db.Query(ctx, query, req.StudentId, req.GroupId, req.SubjectId, req.TermId, req.FromDate, req.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var gradeRows []models.GradeRow
+	for rows.Next() {
+		var (
+			row                      models.GradeRow
+			studentName, subjectName sql.NullString
+		)
+		a := &row.Assessment
+		if err := rows.Scan(
+			&row.StudentId,
+			&studentName,
+			&row.SubjectId,
+			&subjectName,
+			&a.Id,
+			&a.SubjectId,
+			&a.GroupId,
+			&a.TeacherId,
+			&a.Name,
+			&a.Kind,
+			&a.MaxScore,
+			&a.Weight,
+			&a.HeldOn,
+			pkg.TimeText(ctx, &a.CreatedAt),
+			&row.Score); err != nil {
+			return nil, err
+		}
+		row.StudentName = pkg.NullStringToString(studentName)
+		row.SubjectName = pkg.NullStringToString(subjectName)
+		gradeRows = append(gradeRows, row)
+	}
+
+	return gradeRows, rows.Err()
+}
@@ -0,0 +1,194 @@
+package postgres
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type groupRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewGroup(db *pgxpool.Pool) groupRepo {
+	return groupRepo{
+		db: db,
+	}
+}
+
+func (s *groupRepo) Create(ctx context.Context, group models.AddGroup) (string, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO
+		groups (id, name) VALUES ($1, $2);`
+
+	_, err := s.db.Exec(ctx, query, id, group.Name)
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func (s *groupRepo) Update(ctx context.Context, group models.Group) (string, error) {
+	query := `
+	UPDATE
+		groups
+	SET
+		name = $2, updated_at = NOW()
+	WHERE 
+		id = $1;`
+
+	_, err := s.db.Exec(ctx, query, group.Id, group.Name)
+	if err != nil {
+		return "", err
+	}
+	return group.Id, nil
+}
+
+func (s *groupRepo) Delete(ctx context.Context, id string) error {
+	query := `
+	DELETE
+	FROM
+		groups
+	WHERE 
+		id = $1;`
+
+	_, err := s.db.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *groupRepo) GetAll(ctx context.Context, req models.GetAllGroupsRequest) (models.GetAllGroupsResponse, error) {
+	resp := models.GetAllGroupsResponse{}
+	err := s.Each(ctx, req, func(group models.Group) error {
+		resp.Groups = append(resp.Groups, group)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.db.QueryRow(ctx, `SELECT COUNT(*) FROM groups WHERE name ILIKE '%' || $1 || '%'`, req.Search).Scan(&resp.Count)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Each hands the groups of the page to fn one at a time as they are read.
+func (s *groupRepo) Each(ctx context.Context, req models.GetAllGroupsRequest, fn func(models.Group) error) error {
+	offest := (req.Page - 1) * req.Limit
+
+	query := `
+	SELECT
+		g.id,
+		g.name,
+		COUNT(gs.student_id),
+		g.created_at,
+		g.updated_at
+	FROM
+		groups g
+	LEFT JOIN
+		group_students gs
+	ON
+		gs.group_id = g.id
+	WHERE
+		g.name ILIKE '%' || $3 || '%'
+	GROUP BY
+		g.id
+	ORDER BY
+		g.name
+	OFFSET
+		$1
+	LIMIT
+		$2;`
+
+	rows, err := s.db.Query(ctx, query, offest, req.Limit, req.Search)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var group models.Group
+		if err := rows.Scan(
+			&group.Id,
+			&group.Name,
+			&group.StudentsCount,
+			pkg.TimeText(ctx, &group.CreatedAt),
+			pkg.TimeText(ctx, &group.UpdatedAt)); err != nil {
+			return err
+		}
+
+		if err := fn(group); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *groupRepo) GetGroup(ctx context.Context, id string) (models.Group, error) {
+	query := `
+	SELECT
+		g.id,
+		g.name,
+		g.created_at,
+		g.updated_at,
+		COALESCE(ARRAY_AGG(gs.student_id::text) FILTER (WHERE gs.student_id IS NOT NULL), '{}')
+	FROM
+		groups g
+	LEFT JOIN
+		group_students gs
+	ON
+		gs.group_id = g.id
+	WHERE
+		g.id = $1
+	GROUP BY
+		g.id;`
+
+	var group models.Group
+
+	err := s.db.QueryRow(ctx, query, id).Scan(&group.Id, &group.Name, pkg.TimeText(ctx, &group.CreatedAt), pkg.TimeText(ctx, &group.UpdatedAt), &group.StudentIds)
+	if err != nil {
+		return group, err
+	}
+
+	group.StudentsCount = int64(len(group.StudentIds))
+
+	return group, nil
+}
+
+// SetStudents replaces the group's members with studentIds.
+func (s *groupRepo) SetStudents(ctx context.Context, id string, studentIds []string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM group_students WHERE group_id = $1`, id); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO
+		group_students (group_id, student_id)
+	SELECT
+		$1, UNNEST($2::uuid[])
+	ON CONFLICT DO NOTHING;`
+
+	if _, err := tx.Exec(ctx, query, id, studentIds); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
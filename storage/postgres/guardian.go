@@ -0,0 +1,256 @@
+package postgres
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// guardianColumns are scanned by scanGuardian.
+const guardianColumns = `
+		g.id,
+		g.first_name,
+		g.last_name,
+		g.phone,
+		g.mail,
+		COALESCE(g.timezone, ''),
+		g.created_at,
+		g.updated_at`
+
+// guardianFilter keeps the guardians whose name matches the search given in $1 and the
+// guardians of the student given in $2, empty ones match everything.
+const guardianFilter = `
+		($1 = '' OR g.first_name ILIKE '%' || $1 || '%' OR g.last_name ILIKE '%' || $1 || '%')
+		AND ($2 = '' OR EXISTS (
+			SELECT 1 FROM guardian_students gs WHERE gs.guardian_id = g.id AND gs.student_id::text = $2
+		))`
+
+type guardianRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewGuardian(db *pgxpool.Pool) guardianRepo {
+	return guardianRepo{
+		db: db,
+	}
+}
+
+func scanGuardian(ctx context.Context, row interface{ Scan(dest ...any) error }, extra ...any) (models.Guardian, error) {
+	var guardian models.Guardian
+	err := row.Scan(append([]any{
+		&guardian.Id,
+		&guardian.FirstName,
+		&guardian.LastName,
+		&guardian.Phone,
+		&guardian.Email,
+		&guardian.Timezone,
+		pkg.TimeText(ctx, &guardian.CreatedAt),
+		pkg.TimeText(ctx, &guardian.UpdatedAt),
+	}, extra...)...)
+	return guardian, err
+}
+
+func (s *guardianRepo) Create(ctx context.Context, guardian models.AddGuardian) (string, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO
+		guardians (id, first_name, last_name, phone, mail, password, timezone)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''));`
+
+	_, err := s.db.Exec(ctx, query, id, guardian.FirstName, guardian.LastName, guardian.Phone, guardian.Email,
+		guardian.Password, guardian.Timezone)
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+// Update changes the guardian's profile, the password is kept when it is empty.
+func (s *guardianRepo) Update(ctx context.Context, id string, guardian models.AddGuardian) error {
+	query := `
+	UPDATE
+		guardians
+	SET
+		first_name = $2,
+		last_name = $3,
+		phone = $4,
+		mail = $5,
+		password = COALESCE(NULLIF($6, ''), password),
+		timezone = NULLIF($7, ''),
+		updated_at = NOW()
+	WHERE
+		id = $1;`
+
+	_, err := s.db.Exec(ctx, query, id, guardian.FirstName, guardian.LastName, guardian.Phone, guardian.Email,
+		guardian.Password, guardian.Timezone)
+	return err
+}
+
+func (s *guardianRepo) Delete(ctx context.Context, id string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM guardians WHERE id = $1;`, id)
+	return err
+}
+
+func (s *guardianRepo) GetGuardian(ctx context.Context, id string) (models.Guardian, error) {
+	query := `
+	SELECT` + guardianColumns + `
+	FROM
+		guardians g
+	WHERE
+		g.id = $1;`
+
+	return scanGuardian(ctx, s.db.QueryRow(ctx, query, id))
+}
+
+// GetGuardianByLogin returns the guardian with the email with the password hash.
+func (s *guardianRepo) GetGuardianByLogin(ctx context.Context, login string) (models.Guardian, error) {
+	query := `
+	SELECT` + guardianColumns + `,
+		g.password
+	FROM
+		guardians g
+	WHERE
+		g.mail = $1;`
+
+	var password string
+	guardian, err := scanGuardian(ctx, s.db.QueryRow(ctx, query, login), &password)
+	guardian.Password = password
+	return guardian, err
+}
+
+func (s *guardianRepo) GetAll(ctx context.Context, req models.GetAllGuardiansRequest) (models.GetAllGuardiansResponse, error) {
+	resp := models.GetAllGuardiansResponse{}
+	err := s.Each(ctx, req, func(guardian models.Guardian) error {
+		resp.Guardians = append(resp.Guardians, guardian)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.db.QueryRow(ctx, `SELECT COUNT(*) FROM guardians g WHERE`+guardianFilter,
+		req.Search, req.StudentId).Scan(&resp.Count)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Each hands the guardians of the page to fn one at a time as they are read.
+func (s *guardianRepo) Each(ctx context.Context, req models.GetAllGuardiansRequest, fn func(models.Guardian) error) error {
+	offest := (req.Page - 1) * req.Limit
+
+	query := `
+	SELECT` + guardianColumns + `
+	FROM
+		guardians g
+	WHERE` + guardianFilter + `
+	ORDER BY
+		g.last_name, g.first_name
+	OFFSET
+		$3
+	LIMIT
+		$4;`
+
+	rows, err := s.db.Query(ctx, query, req.Search, req.StudentId, offest, req.Limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		guardian, err := scanGuardian(ctx, rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(guardian); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// SetStudents replaces the students linked to the guardian.
+func (s *guardianRepo) SetStudents(ctx context.Context, id string, students []models.GuardianStudent) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM guardian_students WHERE guardian_id = $1`, id); err != nil {
+		return err
+	}
+
+	studentIds := make([]string, 0, len(students))
+	relations := make([]string, 0, len(students))
+	for _, student := range students {
+		studentIds = append(studentIds, student.StudentId)
+		relations = append(relations, student.Relation)
+	}
+
+	query := `
+	INSERT INTO
+		guardian_students (guardian_id, student_id, relation)
+	SELECT
+		$1, student_id, relation
+	FROM
+		UNNEST($2::uuid[], $3::text[]) AS l (student_id, relation)
+	ON CONFLICT DO NOTHING;`
+
+	if _, err := tx.Exec(ctx, query, id, studentIds, relations); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetStudents returns the students linked to the guardian.
+func (s *guardianRepo) GetStudents(ctx context.Context, id string) ([]models.GuardianStudent, error) {
+	query := `
+	SELECT
+		gs.student_id,
+		COALESCE(st.first_name, '') || ' ' || COALESCE(st.last_name, ''),
+		gs.relation
+	FROM
+		guardian_students gs
+	INNER JOIN
+		students st
+	ON
+		st.id = gs.student_id
+	WHERE
+		gs.guardian_id = $1
+	ORDER BY
+		st.first_name, st.last_name;`
+
+	rows, err := s.db.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	students := []models.GuardianStudent{}
+	for rows.Next() {
+		var student models.GuardianStudent
+		if err := rows.Scan(&student.StudentId, &student.StudentName, &student.Relation); err != nil {
+			return nil, err
+		}
+		students = append(students, student)
+	}
+
+	return students, rows.Err()
+}
+
+// IsLinked reports whether the student is linked to the guardian.
+func (s *guardianRepo) IsLinked(ctx context.Context, guardianId, studentId string) (bool, error) {
+	var linked bool
+	err := s.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM guardian_students WHERE guardian_id::text = $1 AND student_id::text = $2);`,
+		guardianId, studentId).Scan(&linked)
+	return linked, err
+}
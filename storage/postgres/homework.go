@@ -0,0 +1,373 @@
+package postgres
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg"
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// assignmentColumns are scanned by scanAssignment.
+const assignmentColumns = `
+		a.id,
+		a.subject_id,
+		a.group_id,
+		COALESCE(a.teacher_id::text, ''),
+		a.title,
+		a.description,
+		a.attachments,
+		a.due_at,
+		a.created_at`
+
+// assignmentFilter keeps the assignments of the group and subject given in $1 and $2 and
+// of the groups of the student given in $3, empty ones match everything.
+const assignmentFilter = `
+		($1 = '' OR a.group_id::text = $1)
+		AND ($2 = '' OR a.subject_id::text = $2)
+		AND ($3 = '' OR EXISTS (
+			SELECT 1 FROM group_students gs WHERE gs.group_id = a.group_id AND gs.student_id::text = $3
+		))`
+
+// submissionColumns are scanned by scanSubmission.
+const submissionColumns = `
+		s.id,
+		s.assignment_id,
+		s.student_id,
+		st.first_name || ' ' || st.last_name,
+		s.text,
+		s.files,
+		s.status,
+		s.attempt,
+		s.score::float8,
+		s.comment,
+		COALESCE(s.reviewed_by::text, ''),
+		s.submitted_at,
+		s.reviewed_at`
+
+type homeworkRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewHomework(db *pgxpool.Pool) homeworkRepo {
+	return homeworkRepo{
+		db: db,
+	}
+}
+
+func scanAssignment(ctx context.Context, row interface{ Scan(dest ...any) error }) (models.Assignment, error) {
+	var assignment models.Assignment
+	err := row.Scan(
+		&assignment.Id,
+		&assignment.SubjectId,
+		&assignment.GroupId,
+		&assignment.TeacherId,
+		&assignment.Title,
+		&assignment.Description,
+		&assignment.Attachments,
+		pkg.TimeText(ctx, &assignment.DueAt),
+		pkg.TimeText(ctx, &assignment.CreatedAt))
+	return assignment, err
+}
+
+func scanSubmission(ctx context.Context, row interface{ Scan(dest ...any) error }) (models.Submission, error) {
+	var submission models.Submission
+	err := row.Scan(
+		&submission.Id,
+		&submission.AssignmentId,
+		&submission.StudentId,
+		&submission.StudentName,
+		&submission.Text,
+		&submission.Files,
+		&submission.Status,
+		&submission.Attempt,
+		&submission.Score,
+		&submission.Comment,
+		&submission.ReviewedBy,
+		pkg.TimeText(ctx, &submission.SubmittedAt),
+		pkg.TimeText(ctx, &submission.ReviewedAt))
+	return submission, err
+}
+
+func (s *homeworkRepo) CreateAssignment(ctx context.Context, teacherId string, assignment models.AddAssignment) (string, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO
+		assignments (id, subject_id, group_id, teacher_id, title, description, attachments, due_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8);`
+
+	_, err := s.db.Exec(ctx, query, id, assignment.SubjectId, assignment.GroupId, teacherId, assignment.Title,
+		assignment.Description, assignment.Attachments, assignment.DueAt)
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func (s *homeworkRepo) GetAssignment(ctx context.Context, id string) (models.Assignment, error) {
+	query := `
+	SELECT` + assignmentColumns + `
+	FROM
+		assignments a
+	WHERE
+		a.id = $1;`
+
+	return scanAssignment(ctx, s.db.QueryRow(ctx, query, id))
+}
+
+func (s *homeworkRepo) DeleteAssignment(ctx context.Context, id string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM assignments WHERE id = $1;`, id)
+	return err
+}
+
+func (s *homeworkRepo) GetAssignments(ctx context.Context, req models.GetAllAssignmentsRequest) (models.GetAllAssignmentsResponse, error) {
+	resp := models.GetAllAssignmentsResponse{}
+	err := s.EachAssignment(ctx, req, func(assignment models.Assignment) error {
+		resp.Assignments = append(resp.Assignments, assignment)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.db.QueryRow(ctx, `SELECT COUNT(*) FROM assignments a WHERE`+assignmentFilter,
+		req.GroupId, req.SubjectId, req.StudentId).Scan(&resp.Count)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// EachAssignment hands the assignments of the page to fn one at a time as they are read.
+func (s *homeworkRepo) EachAssignment(ctx context.Context, req models.GetAllAssignmentsRequest, fn func(models.Assignment) error) error {
+	offest := (req.Page - 1) * req.Limit
+
+	query := `
+	SELECT` + assignmentColumns + `
+	FROM
+		assignments a
+	WHERE` + assignmentFilter + `
+	ORDER BY
+		a.due_at DESC, a.created_at DESC
+	OFFSET
+		$4
+	LIMIT
+		$5;`
+
+	rows, err := s.db.Query(ctx, query, req.GroupId, req.SubjectId, req.StudentId, offest, req.Limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		assignment, err := scanAssignment(ctx, rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(assignment); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Submit stores the student's submission of the assignment, a resubmission replaces the
+// earlier one and counts as a new attempt. It returns the files of the replaced submission.
+// Nothing is stored and pgx.ErrNoRows is returned when the student is not in the
+// assignment's group or the submission is already graded.
+func (s *homeworkRepo) Submit(ctx context.Context, submission models.AddSubmission) (string, []string, error) {
+	query := `
+	WITH previous AS (
+		SELECT
+			files
+		FROM
+			submissions
+		WHERE
+			assignment_id = $2 AND student_id = $3
+		FOR UPDATE
+	)
+	INSERT INTO
+		submissions (id, assignment_id, student_id, text, files, status)
+	SELECT
+		$1, a.id, gs.student_id, $4, $5, $6
+	FROM
+		assignments a
+	INNER JOIN
+		group_students gs
+	ON
+		gs.group_id = a.group_id
+	WHERE
+		a.id = $2 AND gs.student_id = $3
+	ON CONFLICT (assignment_id, student_id) DO UPDATE
+	SET
+		text = EXCLUDED.text,
+		files = EXCLUDED.files,
+		status = EXCLUDED.status,
+		attempt = submissions.attempt + 1,
+		score = NULL,
+		submitted_at = NOW(),
+		reviewed_at = NULL
+	WHERE
+		submissions.status <> $7
+	RETURNING
+		id, (SELECT files FROM previous);`
+
+	var (
+		id       string
+		replaced []string
+	)
+	err := s.db.QueryRow(ctx, query, uuid.New(), submission.AssignmentId, submission.StudentId, submission.Text,
+		submission.Files, submission.Status, config.SUBMISSION_GRADED).Scan(&id, &replaced)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return id, replaced, nil
+}
+
+func (s *homeworkRepo) GetSubmission(ctx context.Context, id string) (models.Submission, error) {
+	query := `
+	SELECT` + submissionColumns + `
+	FROM
+		submissions s
+	INNER JOIN
+		students st
+	ON
+		st.id = s.student_id
+	WHERE
+		s.id = $1;`
+
+	return scanSubmission(ctx, s.db.QueryRow(ctx, query, id))
+}
+
+// GetStudentSubmission returns the student's submission of the assignment, pgx.ErrNoRows
+// when there is none.
+func (s *homeworkRepo) GetStudentSubmission(ctx context.Context, assignmentId, studentId string) (models.Submission, error) {
+	query := `
+	SELECT` + submissionColumns + `
+	FROM
+		submissions s
+	INNER JOIN
+		students st
+	ON
+		st.id = s.student_id
+	WHERE
+		s.assignment_id = $1 AND s.student_id = $2;`
+
+	return scanSubmission(ctx, s.db.QueryRow(ctx, query, assignmentId, studentId))
+}
+
+func (s *homeworkRepo) GetSubmissions(ctx context.Context, assignmentId string) (models.GetAssignmentSubmissionsResponse, error) {
+	resp := models.GetAssignmentSubmissionsResponse{Submissions: []models.Submission{}}
+
+	query := `
+	SELECT` + submissionColumns + `
+	FROM
+		submissions s
+	INNER JOIN
+		students st
+	ON
+		st.id = s.student_id
+	WHERE
+		s.assignment_id = $1
+	ORDER BY
+		s.submitted_at;`
+
+	rows, err := s.db.Query(ctx, query, assignmentId)
+	if err != nil {
+		return resp, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		submission, err := scanSubmission(ctx, rows)
+		if err != nil {
+			return resp, err
+		}
+		resp.Submissions = append(resp.Submissions, submission)
+	}
+
+	return resp, rows.Err()
+}
+
+func (s *homeworkRepo) ReviewSubmission(ctx context.Context, id, teacherId string, review models.ReviewSubmission) error {
+	query := `
+	UPDATE
+		submissions
+	SET
+		status = $2,
+		score = $3,
+		comment = $4,
+		reviewed_by = $5,
+		reviewed_at = NOW()
+	WHERE
+		id = $1;`
+
+	_, err := s.db.Exec(ctx, query, id, review.Status, review.Score, review.Comment, teacherId)
+	return err
+}
+
+// GetComments returns the teachers' comments on the student's submissions reviewed between
+// from and to, oldest first.
+func (s *homeworkRepo) GetComments(ctx context.Context, studentId, from, to string) ([]models.TeacherComment, error) {
+	query := `
+	SELECT
+		sb.name,
+		COALESCE(t.first_name || ' ' || t.last_name, ''),
+		a.title,
+		s.comment,
+		s.reviewed_at
+	FROM
+		submissions s
+	INNER JOIN
+		assignments a
+	ON
+		a.id = s.assignment_id
+	INNER JOIN
+		subjects sb
+	ON
+		sb.id = a.subject_id
+	LEFT JOIN
+		teachers t
+	ON
+		t.id = s.reviewed_by
+	WHERE
+		s.student_id = $1
+		AND s.comment <> ''
+		AND s.reviewed_at BETWEEN $2 AND $3
+	ORDER BY
+		s.reviewed_at;`
+
+	rows, err := s.db.Query(ctx, query, studentId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []models.TeacherComment
+	for rows.Next() {
+		var (
+			comment     models.TeacherComment
+			subjectName sql.NullString
+		)
+		if err := rows.Scan(
+			&subjectName,
+			&comment.TeacherName,
+			&comment.Assignment,
+			&comment.Comment,
+			pkg.TimeText(ctx, &comment.ReviewedAt)); err != nil {
+			return nil, err
+		}
+		comment.SubjectName = pkg.NullStringToString(subjectName)
+		comments = append(comments, comment)
+	}
+
+	return comments, rows.Err()
+}
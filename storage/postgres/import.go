@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"backend_course/lms/api/models"
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// takenContacts returns the mails and phones of contacts which rows of table already
+// have, table is students or teachers.
+func takenContacts(ctx context.Context, db interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}, table string, contacts models.ImportContacts) (models.ImportContacts, error) {
+	taken := models.ImportContacts{}
+
+	query := fmt.Sprintf(`
+	SELECT 'mail', mail FROM %[1]s WHERE mail = ANY($1)
+	UNION ALL
+	SELECT 'phone', phone FROM %[1]s WHERE phone = ANY($2);`, table)
+
+	rows, err := db.Query(ctx, query, contacts.Mails, contacts.Phones)
+	if err != nil {
+		return taken, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var column, value string
+		if err := rows.Scan(&column, &value); err != nil {
+			return taken, err
+		}
+		if column == "mail" {
+			taken.Mails = append(taken.Mails, value)
+		} else {
+			taken.Phones = append(taken.Phones, value)
+		}
+	}
+	return taken, rows.Err()
+}
+
+// copyImport copies rows into table in one transaction. The table is locked against
+// other writes while the contacts of the rows are checked again, it returns
+// pgx.ErrNoRows when one of them was taken since the rows were validated.
+func copyImport(ctx context.Context, tx pgx.Tx, table string, columns []string, contacts models.ImportContacts, rows [][]any) (int64, error) {
+	if _, err := tx.Exec(ctx, `LOCK TABLE `+table+` IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return 0, err
+	}
+
+	taken, err := takenContacts(ctx, tx, table, contacts)
+	if err != nil {
+		return 0, err
+	}
+	if len(taken.Mails) > 0 || len(taken.Phones) > 0 {
+		return 0, pgx.ErrNoRows
+	}
+
+	n, err := tx.CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromRows(rows))
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit(ctx)
+}
@@ -0,0 +1,391 @@
+package postgres
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// runColumns are scanned by scanRun.
+const runColumns = `
+		pr.id,
+		TO_CHAR(pr.from_date, 'YYYY-MM-DD'),
+		TO_CHAR(pr.to_date, 'YYYY-MM-DD'),
+		pr.total,
+		COALESCE(pr.created_by::text, ''),
+		pr.locked_at`
+
+type payrollRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPayroll(db *pgxpool.Pool) payrollRepo {
+	return payrollRepo{
+		db: db,
+	}
+}
+
+func scanRun(ctx context.Context, row interface{ Scan(dest ...any) error }) (models.PayrollRun, error) {
+	var run models.PayrollRun
+	err := row.Scan(
+		&run.Id,
+		&run.FromDate,
+		&run.ToDate,
+		&run.Total,
+		&run.CreatedBy,
+		pkg.TimeText(ctx, &run.LockedAt))
+	return run, err
+}
+
+// SetRate sets the teacher's hourly rate, or the teacher's rate of the subject when one is
+// given.
+func (s *payrollRepo) SetRate(ctx context.Context, teacherId string, rate models.SetRate) error {
+	if rate.SubjectId == "" {
+		query := `
+		UPDATE
+			teachers
+		SET
+			hourly_rate = $2,
+			updated_at = NOW()
+		WHERE
+			id = $1
+		RETURNING
+			id;`
+
+		return s.db.QueryRow(ctx, query, teacherId, rate.HourlyRate).Scan(&teacherId)
+	}
+
+	query := `
+	INSERT INTO
+		teacher_subject_rates (teacher_id, subject_id, hourly_rate)
+		VALUES ($1, $2, $3)
+	ON CONFLICT (teacher_id, subject_id) DO UPDATE
+	SET
+		hourly_rate = EXCLUDED.hourly_rate,
+		updated_at = NOW();`
+
+	_, err := s.db.Exec(ctx, query, teacherId, rate.SubjectId, rate.HourlyRate)
+	return err
+}
+
+func (s *payrollRepo) DeleteRate(ctx context.Context, teacherId, subjectId string) error {
+	query := `
+	DELETE FROM
+		teacher_subject_rates
+	WHERE
+		teacher_id = $1 AND subject_id = $2;`
+
+	_, err := s.db.Exec(ctx, query, teacherId, subjectId)
+	return err
+}
+
+func (s *payrollRepo) GetRates(ctx context.Context, teacherId string) (models.TeacherRates, error) {
+	rates := models.TeacherRates{TeacherId: teacherId}
+
+	err := s.db.QueryRow(ctx, `SELECT hourly_rate FROM teachers WHERE id = $1`, teacherId).Scan(&rates.HourlyRate)
+	if err != nil {
+		return rates, err
+	}
+
+	query := `
+	SELECT
+		r.subject_id,
+		COALESCE(sb.name, ''),
+		r.hourly_rate
+	FROM
+		teacher_subject_rates r
+	INNER JOIN
+		subjects sb
+	ON
+		sb.id = r.subject_id
+	WHERE
+		r.teacher_id = $1
+	ORDER BY
+		sb.name;`
+
+	rows, err := s.db.Query(ctx, query, teacherId)
+	if err != nil {
+		return rates, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rate models.SubjectRate
+		if err := rows.Scan(&rate.SubjectId, &rate.SubjectName, &rate.HourlyRate); err != nil {
+			return rates, err
+		}
+		rates.Subjects = append(rates.Subjects, rate)
+	}
+
+	return rates, rows.Err()
+}
+
+// GetDeliveredLessons sums the lessons which started in [from, to] and are over, per
+// teacher and subject with the rate they are paid at. time_table has a row per student of
+// a lesson, so a lesson is its teacher, subject and time. Lessons on closures and the ones
+// whose teacher was on an approved leave without a substitute were not given. A
+// substituted lesson is paid to its substitute.
+func (s *payrollRepo) GetDeliveredLessons(ctx context.Context, from, to string) ([]models.PayrollLine, error) {
+	query := `
+	SELECT
+		l.teacher_id,
+		TRIM(COALESCE(t.first_name, '') || ' ' || COALESCE(t.last_name, '')),
+		l.subject_id,
+		COALESCE(sb.name, ''),
+		COUNT(*),
+		COUNT(*) FILTER (WHERE l.substitute),
+		SUM(l.minutes)::bigint,
+		COALESCE(r.hourly_rate, t.hourly_rate)
+	FROM (
+		SELECT
+			tt.teacher_id,
+			tt.subject_id,
+			BOOL_OR(tt.original_teacher_id IS NOT NULL) AS substitute,
+			EXTRACT(EPOCH FROM tt.to_date - tt.from_date)::bigint / 60 AS minutes
+		FROM
+			time_table tt
+		WHERE
+			tt.from_date BETWEEN $1 AND $2
+			AND tt.to_date <= NOW()
+			AND ` + openDay("tt.from_date") + `
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					teacher_leaves tl
+				WHERE
+					tl.teacher_id = tt.teacher_id
+					AND tl.status = $3
+					AND tl.from_date < tt.to_date
+					AND tl.to_date > tt.from_date
+			)
+		GROUP BY
+			tt.teacher_id, tt.subject_id, tt.from_date, tt.to_date
+	) l
+	INNER JOIN
+		teachers t
+	ON
+		t.id = l.teacher_id
+	INNER JOIN
+		subjects sb
+	ON
+		sb.id = l.subject_id
+	LEFT JOIN
+		teacher_subject_rates r
+	ON
+		r.teacher_id = l.teacher_id AND r.subject_id = l.subject_id
+	GROUP BY
+		l.teacher_id, t.first_name, t.last_name, t.hourly_rate, l.subject_id, sb.name, r.hourly_rate
+	ORDER BY
+		t.last_name, t.first_name, l.teacher_id, sb.name, l.subject_id;`
+
+	rows, err := s.db.Query(ctx, query, from, to, config.LEAVE_APPROVED)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []models.PayrollLine
+	for rows.Next() {
+		var line models.PayrollLine
+		err := rows.Scan(
+			&line.TeacherId,
+			&line.TeacherName,
+			&line.SubjectId,
+			&line.SubjectName,
+			&line.Lessons,
+			&line.SubstituteLessons,
+			&line.Minutes,
+			&line.HourlyRate)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+// CreateRun stores the run and its lines, it returns pgx.ErrNoRows when the period
+// overlaps the one of another run. The table is locked until the run is stored so that
+// concurrent runs of a period do not both pass the check.
+func (s *payrollRepo) CreateRun(ctx context.Context, run models.PayrollRun) (string, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `LOCK TABLE payroll_runs IN EXCLUSIVE MODE`); err != nil {
+		return "", err
+	}
+
+	query := `
+	INSERT INTO
+		payroll_runs (id, from_date, to_date, total, created_by)
+	SELECT
+		$1, $2, $3, $4, NULLIF($5, '')::uuid
+	WHERE
+		NOT EXISTS (
+			SELECT 1 FROM payroll_runs
+			WHERE daterange(from_date, to_date, '[]') && daterange($2::date, $3::date, '[]')
+		)
+	RETURNING
+		id;`
+
+	var id string
+	err = tx.QueryRow(ctx, query, uuid.New(), run.FromDate, run.ToDate, run.Total, run.CreatedBy).Scan(&id)
+	if err != nil {
+		return "", err
+	}
+
+	if len(run.Lines) > 0 {
+		var teacherIds, teacherNames, subjectIds, subjectNames []string
+		var lessons, substituteLessons, minutes, rates, amounts []int64
+		for _, line := range run.Lines {
+			teacherIds = append(teacherIds, line.TeacherId)
+			teacherNames = append(teacherNames, line.TeacherName)
+			subjectIds = append(subjectIds, line.SubjectId)
+			subjectNames = append(subjectNames, line.SubjectName)
+			lessons = append(lessons, line.Lessons)
+			substituteLessons = append(substituteLessons, line.SubstituteLessons)
+			minutes = append(minutes, line.Minutes)
+			rates = append(rates, line.HourlyRate)
+			amounts = append(amounts, line.Amount)
+		}
+
+		query = `
+		INSERT INTO
+			payroll_lines (run_id, teacher_id, teacher_name, subject_id, subject_name, lessons,
+				substitute_lessons, minutes, hourly_rate, amount)
+		SELECT
+			$1, teacher_id, teacher_name, subject_id, subject_name, lessons,
+			substitute_lessons, minutes, hourly_rate, amount
+		FROM
+			UNNEST($2::uuid[], $3::text[], $4::uuid[], $5::text[], $6::int[], $7::int[], $8::int[],
+				$9::bigint[], $10::bigint[])
+			AS l (teacher_id, teacher_name, subject_id, subject_name, lessons, substitute_lessons,
+				minutes, hourly_rate, amount);`
+
+		_, err = tx.Exec(ctx, query, id, teacherIds, teacherNames, subjectIds, subjectNames, lessons,
+			substituteLessons, minutes, rates, amounts)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return id, tx.Commit(ctx)
+}
+
+func (s *payrollRepo) GetRun(ctx context.Context, id string) (models.PayrollRun, error) {
+	query := `
+	SELECT` + runColumns + `
+	FROM
+		payroll_runs pr
+	WHERE
+		pr.id = $1;`
+
+	run, err := scanRun(ctx, s.db.QueryRow(ctx, query, id))
+	if err != nil {
+		return run, err
+	}
+
+	query = `
+	SELECT
+		teacher_id,
+		teacher_name,
+		subject_id,
+		subject_name,
+		lessons,
+		substitute_lessons,
+		minutes,
+		hourly_rate,
+		amount
+	FROM
+		payroll_lines
+	WHERE
+		run_id = $1
+	ORDER BY
+		teacher_name, teacher_id, subject_name, subject_id;`
+
+	rows, err := s.db.Query(ctx, query, id)
+	if err != nil {
+		return run, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line models.PayrollLine
+		err := rows.Scan(
+			&line.TeacherId,
+			&line.TeacherName,
+			&line.SubjectId,
+			&line.SubjectName,
+			&line.Lessons,
+			&line.SubstituteLessons,
+			&line.Minutes,
+			&line.HourlyRate,
+			&line.Amount)
+		if err != nil {
+			return run, err
+		}
+		run.Lines = append(run.Lines, line)
+	}
+
+	return run, rows.Err()
+}
+
+// GetRuns lists the runs without their lines, the latest period first.
+func (s *payrollRepo) GetRuns(ctx context.Context, req models.GetAllPayrollRunsRequest) (models.GetAllPayrollRunsResponse, error) {
+	resp := models.GetAllPayrollRunsResponse{}
+	err := s.EachRun(ctx, req, func(run models.PayrollRun) error {
+		resp.PayrollRuns = append(resp.PayrollRuns, run)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	if err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM payroll_runs`).Scan(&resp.Count); err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// EachRun hands the runs of the page to fn one at a time as they are read.
+func (s *payrollRepo) EachRun(ctx context.Context, req models.GetAllPayrollRunsRequest, fn func(models.PayrollRun) error) error {
+	offest := (req.Page - 1) * req.Limit
+
+	query := `
+	SELECT` + runColumns + `
+	FROM
+		payroll_runs pr
+	ORDER BY
+		pr.from_date DESC
+	OFFSET
+		$1
+	LIMIT
+		$2;`
+
+	rows, err := s.db.Query(ctx, query, offest, req.Limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		run, err := scanRun(ctx, rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(run); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
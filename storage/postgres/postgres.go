@@ -29,6 +29,8 @@ func New(ctx context.Context, cfg config.Config, redis storage.IRedisStorage) (s
 	}
 	pgxPoolConfig.MaxConns = 50
 	pgxPoolConfig.MaxConnLifetime = time.Hour
+	// dates, weekdays and clock times of timestamptz columns are taken in the school's zone
+	pgxPoolConfig.ConnConfig.RuntimeParams["timezone"] = cfg.Timezone
 
 	newPool, err := pgxpool.NewWithConfig(ctx, pgxPoolConfig)
 	if err != nil {
@@ -65,6 +67,75 @@ func (s Store) TimeStorage() storage.TimeStorage {
 	return &newTime
 }
 
+func (s Store) AttendanceStorage() storage.AttendanceStorage {
+	newAttendance := NewAttendance(s.Pool)
+	return &newAttendance
+}
+
+func (s Store) GroupStorage() storage.GroupStorage {
+	newGroup := NewGroup(s.Pool)
+	return &newGroup
+}
+
+func (s Store) RoomStorage() storage.RoomStorage {
+	newRoom := NewRoom(s.Pool)
+	return &newRoom
+}
+
+func (s Store) ScheduleStorage() storage.ScheduleStorage {
+	newSchedule := NewSchedule(s.Pool)
+	return &newSchedule
+}
+
+func (s Store) AvailabilityStorage() storage.AvailabilityStorage {
+	newAvailability := NewAvailability(s.Pool)
+	return &newAvailability
+}
+
 func (s Store) Redis() storage.IRedisStorage {
 	return redis.New(s.cfg)
-}
\ No newline at end of file
+}
+func (s Store) CalendarStorage() storage.CalendarStorage {
+	newCalendar := NewCalendar(s.Pool)
+	return &newCalendar
+}
+
+func (s Store) GradebookStorage() storage.GradebookStorage {
+	newGradebook := NewGradebook(s.Pool)
+	return &newGradebook
+}
+
+func (s Store) HomeworkStorage() storage.HomeworkStorage {
+	newHomework := NewHomework(s.Pool)
+	return &newHomework
+}
+
+func (s Store) QuizStorage() storage.QuizStorage {
+	newQuiz := NewQuiz(s.Pool)
+	return &newQuiz
+}
+
+func (s Store) EnrollmentStorage() storage.EnrollmentStorage {
+	newEnrollment := NewEnrollment(s.Pool)
+	return &newEnrollment
+}
+
+func (s Store) GuardianStorage() storage.GuardianStorage {
+	newGuardian := NewGuardian(s.Pool)
+	return &newGuardian
+}
+
+func (s Store) BillingStorage() storage.BillingStorage {
+	newBilling := NewBilling(s.Pool)
+	return &newBilling
+}
+
+func (s Store) PayrollStorage() storage.PayrollStorage {
+	newPayroll := NewPayroll(s.Pool)
+	return &newPayroll
+}
+
+func (s Store) DashboardStorage() storage.DashboardStorage {
+	newDashboard := NewDashboard(s.Pool)
+	return &newDashboard
+}
@@ -0,0 +1,461 @@
+package postgres
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// quizColumns are scanned by scanQuiz.
+const quizColumns = `
+		q.id,
+		q.assessment_id,
+		q.subject_id,
+		q.group_id,
+		COALESCE(q.teacher_id::text, ''),
+		q.title,
+		q.opens_at,
+		q.closes_at,
+		q.time_limit_minutes,
+		q.max_attempts,
+		(SELECT COALESCE(SUM(qq.points), 0)::float8 FROM quiz_questions qq WHERE qq.quiz_id = q.id),
+		q.created_at`
+
+// quizFilter keeps the quizzes of the group and subject given in $1 and $2 and of the groups
+// of the student given in $3, empty ones match everything.
+const quizFilter = `
+		($1 = '' OR q.group_id::text = $1)
+		AND ($2 = '' OR q.subject_id::text = $2)
+		AND ($3 = '' OR EXISTS (
+			SELECT 1 FROM group_students gs WHERE gs.group_id = q.group_id AND gs.student_id::text = $3
+		))`
+
+// attemptColumns are scanned by scanAttempt.
+const attemptColumns = `
+		att.id,
+		att.quiz_id,
+		att.student_id,
+		st.first_name || ' ' || st.last_name,
+		att.started_at,
+		att.deadline,
+		att.submitted_at,
+		att.expired,
+		att.score::float8,
+		att.question_order::text[]`
+
+type quizRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewQuiz(db *pgxpool.Pool) quizRepo {
+	return quizRepo{
+		db: db,
+	}
+}
+
+func scanQuiz(ctx context.Context, row interface{ Scan(dest ...any) error }) (models.Quiz, error) {
+	var quiz models.Quiz
+	err := row.Scan(
+		&quiz.Id,
+		&quiz.AssessmentId,
+		&quiz.SubjectId,
+		&quiz.GroupId,
+		&quiz.TeacherId,
+		&quiz.Title,
+		pkg.TimeText(ctx, &quiz.OpensAt),
+		pkg.TimeText(ctx, &quiz.ClosesAt),
+		&quiz.TimeLimitMinutes,
+		&quiz.MaxAttempts,
+		&quiz.MaxScore,
+		pkg.TimeText(ctx, &quiz.CreatedAt))
+	return quiz, err
+}
+
+func scanAttempt(ctx context.Context, row interface{ Scan(dest ...any) error }) (models.QuizAttempt, error) {
+	var attempt models.QuizAttempt
+	err := row.Scan(
+		&attempt.Id,
+		&attempt.QuizId,
+		&attempt.StudentId,
+		&attempt.StudentName,
+		pkg.TimeText(ctx, &attempt.StartedAt),
+		pkg.TimeText(ctx, &attempt.Deadline),
+		pkg.TimeText(ctx, &attempt.SubmittedAt),
+		&attempt.Expired,
+		&attempt.Score,
+		&attempt.QuestionOrder)
+	return attempt, err
+}
+
+// CreateQuiz stores the quiz with its questions and the assessment its scores go to.
+func (s *quizRepo) CreateQuiz(ctx context.Context, teacherId string, quiz models.AddQuiz, assessment models.AddAssessment) (string, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	assessmentId, id := uuid.New(), uuid.New()
+
+	_, err = tx.Exec(ctx, `
+	INSERT INTO
+		assessments (id, subject_id, group_id, teacher_id, name, kind, max_score, weight, held_on)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);`,
+		assessmentId, assessment.SubjectId, assessment.GroupId, teacherId, assessment.Name, assessment.Kind,
+		assessment.MaxScore, assessment.Weight, assessment.HeldOn)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.Exec(ctx, `
+	INSERT INTO
+		quizzes (id, assessment_id, subject_id, group_id, teacher_id, title, opens_at, closes_at, time_limit_minutes, max_attempts)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);`,
+		id, assessmentId, quiz.SubjectId, quiz.GroupId, teacherId, quiz.Title, quiz.OpensAt, quiz.ClosesAt,
+		quiz.TimeLimitMinutes, quiz.MaxAttempts)
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+	INSERT INTO
+		quiz_questions (id, quiz_id, position, kind, text, options, correct, number, tolerance, accepted, points)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);`
+
+	for i, question := range quiz.Questions {
+		_, err = tx.Exec(ctx, query, uuid.New(), id, i+1, question.Kind, question.Text, question.Options,
+			question.Correct, question.Number, question.Tolerance, question.Accepted, question.Points)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+// GetQuiz returns the quiz with its questions and their answers.
+func (s *quizRepo) GetQuiz(ctx context.Context, id string) (models.Quiz, error) {
+	query := `
+	SELECT` + quizColumns + `
+	FROM
+		quizzes q
+	WHERE
+		q.id = $1;`
+
+	quiz, err := scanQuiz(ctx, s.db.QueryRow(ctx, query, id))
+	if err != nil {
+		return quiz, err
+	}
+
+	rows, err := s.db.Query(ctx, `
+	SELECT
+		id,
+		position,
+		kind,
+		text,
+		options,
+		correct,
+		number::float8,
+		tolerance::float8,
+		accepted,
+		points::float8
+	FROM
+		quiz_questions
+	WHERE
+		quiz_id = $1
+	ORDER BY
+		position;`, id)
+	if err != nil {
+		return quiz, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var question models.QuizQuestion
+		if err := rows.Scan(
+			&question.Id,
+			&question.Position,
+			&question.Kind,
+			&question.Text,
+			&question.Options,
+			&question.Correct,
+			&question.Number,
+			&question.Tolerance,
+			&question.Accepted,
+			&question.Points); err != nil {
+			return quiz, err
+		}
+		quiz.Questions = append(quiz.Questions, question)
+	}
+
+	return quiz, rows.Err()
+}
+
+// DeleteQuiz deletes the quiz through its assessment, so that its scores go too.
+func (s *quizRepo) DeleteQuiz(ctx context.Context, id string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM assessments WHERE id = (SELECT assessment_id FROM quizzes WHERE id = $1);`, id)
+	return err
+}
+
+func (s *quizRepo) GetQuizzes(ctx context.Context, req models.GetAllQuizzesRequest) (models.GetAllQuizzesResponse, error) {
+	resp := models.GetAllQuizzesResponse{}
+	err := s.EachQuiz(ctx, req, func(quiz models.Quiz) error {
+		resp.Quizzes = append(resp.Quizzes, quiz)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.db.QueryRow(ctx, `SELECT COUNT(*) FROM quizzes q WHERE`+quizFilter,
+		req.GroupId, req.SubjectId, req.StudentId).Scan(&resp.Count)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// EachQuiz hands the quizzes of the page to fn one at a time as they are read.
+func (s *quizRepo) EachQuiz(ctx context.Context, req models.GetAllQuizzesRequest, fn func(models.Quiz) error) error {
+	offest := (req.Page - 1) * req.Limit
+
+	query := `
+	SELECT` + quizColumns + `
+	FROM
+		quizzes q
+	WHERE` + quizFilter + `
+	ORDER BY
+		q.opens_at DESC
+	OFFSET
+		$4
+	LIMIT
+		$5;`
+
+	rows, err := s.db.Query(ctx, query, req.GroupId, req.SubjectId, req.StudentId, offest, req.Limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		quiz, err := scanQuiz(ctx, rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(quiz); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// CreateAttempt starts an attempt of the quiz, nothing is stored and pgx.ErrNoRows is
+// returned when the student is not in the quiz's group or has no attempts left. The
+// student's membership of the group is locked while the attempts are counted so that
+// concurrent starts do not both pass the limit.
+func (s *quizRepo) CreateAttempt(ctx context.Context, attempt models.QuizAttempt) (string, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	lock := `
+	SELECT
+		1
+	FROM
+		quizzes q
+	INNER JOIN
+		group_students gs
+	ON
+		gs.group_id = q.group_id
+	WHERE
+		q.id = $1 AND gs.student_id = $2
+	FOR UPDATE OF gs;`
+
+	if _, err := tx.Exec(ctx, lock, attempt.QuizId, attempt.StudentId); err != nil {
+		return "", err
+	}
+
+	query := `
+	INSERT INTO
+		quiz_attempts (id, quiz_id, student_id, question_order, deadline)
+	SELECT
+		$1, q.id, gs.student_id, $4, $5
+	FROM
+		quizzes q
+	INNER JOIN
+		group_students gs
+	ON
+		gs.group_id = q.group_id
+	WHERE
+		q.id = $2 AND gs.student_id = $3
+		AND (SELECT COUNT(*) FROM quiz_attempts a WHERE a.quiz_id = q.id AND a.student_id = $3) < q.max_attempts
+	RETURNING
+		id;`
+
+	var id string
+	err = tx.QueryRow(ctx, query, uuid.New(), attempt.QuizId, attempt.StudentId, attempt.QuestionOrder,
+		attempt.Deadline).Scan(&id)
+	if err != nil {
+		return "", err
+	}
+	return id, tx.Commit(ctx)
+}
+
+// GetAttempt returns the attempt with its graded answers.
+func (s *quizRepo) GetAttempt(ctx context.Context, id string) (models.QuizAttempt, error) {
+	query := `
+	SELECT` + attemptColumns + `
+	FROM
+		quiz_attempts att
+	INNER JOIN
+		students st
+	ON
+		st.id = att.student_id
+	WHERE
+		att.id = $1;`
+
+	attempt, err := scanAttempt(ctx, s.db.QueryRow(ctx, query, id))
+	if err != nil {
+		return attempt, err
+	}
+
+	rows, err := s.db.Query(ctx, `
+	SELECT
+		qa.question_id,
+		qq.text,
+		qa.choices,
+		qa.number::float8,
+		qa.text,
+		qa.correct,
+		qa.points::float8
+	FROM
+		quiz_answers qa
+	INNER JOIN
+		quiz_questions qq
+	ON
+		qq.id = qa.question_id
+	WHERE
+		qa.attempt_id = $1
+	ORDER BY
+		array_position($2::uuid[], qa.question_id);`, id, attempt.QuestionOrder)
+	if err != nil {
+		return attempt, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var answer models.QuizAnswerLog
+		if err := rows.Scan(
+			&answer.QuestionId,
+			&answer.Question,
+			&answer.Choices,
+			&answer.Number,
+			&answer.Text,
+			&answer.Correct,
+			&answer.Points); err != nil {
+			return attempt, err
+		}
+		attempt.Answers = append(attempt.Answers, answer)
+	}
+
+	return attempt, rows.Err()
+}
+
+// GetAttempts returns the attempts of the quiz, of one student when studentId is given.
+func (s *quizRepo) GetAttempts(ctx context.Context, quizId, studentId string) (models.GetQuizAttemptsResponse, error) {
+	resp := models.GetQuizAttemptsResponse{Attempts: []models.QuizAttempt{}}
+
+	query := `
+	SELECT` + attemptColumns + `
+	FROM
+		quiz_attempts att
+	INNER JOIN
+		students st
+	ON
+		st.id = att.student_id
+	WHERE
+		att.quiz_id = $1 AND ($2 = '' OR att.student_id::text = $2)
+	ORDER BY
+		att.started_at;`
+
+	rows, err := s.db.Query(ctx, query, quizId, studentId)
+	if err != nil {
+		return resp, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		attempt, err := scanAttempt(ctx, rows)
+		if err != nil {
+			return resp, err
+		}
+		resp.Attempts = append(resp.Attempts, attempt)
+	}
+
+	return resp, rows.Err()
+}
+
+// SubmitAttempt closes the attempt with its graded answers and keeps the student's best
+// score of the quiz in the gradebook. pgx.ErrNoRows is returned when the attempt is
+// already submitted.
+func (s *quizRepo) SubmitAttempt(ctx context.Context, attemptId string, answers []models.QuizAnswerLog, score float64, expired bool) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var assessmentId, studentId string
+	err = tx.QueryRow(ctx, `
+	UPDATE
+		quiz_attempts att
+	SET
+		submitted_at = NOW(),
+		expired = $2,
+		score = $3
+	FROM
+		quizzes q
+	WHERE
+		att.id = $1 AND att.submitted_at IS NULL AND q.id = att.quiz_id
+	RETURNING
+		q.assessment_id, att.student_id;`, attemptId, expired, score).Scan(&assessmentId, &studentId)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO
+		quiz_answers (attempt_id, question_id, choices, number, text, correct, points)
+		VALUES ($1, $2, $3, $4, $5, $6, $7);`
+
+	for _, answer := range answers {
+		_, err = tx.Exec(ctx, query, attemptId, answer.QuestionId, answer.Choices, answer.Number, answer.Text,
+			answer.Correct, answer.Points)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+	INSERT INTO
+		scores (assessment_id, student_id, score)
+		VALUES ($1, $2, $3)
+	ON CONFLICT (assessment_id, student_id) DO UPDATE
+	SET
+		score = GREATEST(scores.score, EXCLUDED.score),
+		updated_at = NOW();`, assessmentId, studentId, score)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
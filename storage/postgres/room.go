@@ -0,0 +1,457 @@
+package postgres
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/pkg"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type roomRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewRoom(db *pgxpool.Pool) roomRepo {
+	return roomRepo{
+		db: db,
+	}
+}
+
+func (s *roomRepo) Create(ctx context.Context, room models.AddRoom) (string, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO
+		rooms (id, name, capacity) VALUES ($1, $2, $3);`
+
+	_, err := s.db.Exec(ctx, query, id, room.Name, room.Capacity)
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func (s *roomRepo) Update(ctx context.Context, room models.Room) (string, error) {
+	query := `
+	UPDATE
+		rooms
+	SET
+		name = $2, capacity = $3, updated_at = NOW()
+	WHERE 
+		id = $1;`
+
+	_, err := s.db.Exec(ctx, query, room.Id, room.Name, room.Capacity)
+	if err != nil {
+		return "", err
+	}
+	return room.Id, nil
+}
+
+func (s *roomRepo) Delete(ctx context.Context, id string) error {
+	query := `
+	DELETE
+	FROM
+		rooms
+	WHERE 
+		id = $1;`
+
+	_, err := s.db.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *roomRepo) GetAll(ctx context.Context, req models.GetAllRoomsRequest) (models.GetAllRoomsResponse, error) {
+	resp := models.GetAllRoomsResponse{}
+	err := s.Each(ctx, req, func(room models.Room) error {
+		resp.Rooms = append(resp.Rooms, room)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.db.QueryRow(ctx, `SELECT COUNT(*) FROM rooms WHERE name ILIKE '%' || $1 || '%'`, req.Search).Scan(&resp.Count)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// Each hands the rooms of the page to fn one at a time as they are read.
+func (s *roomRepo) Each(ctx context.Context, req models.GetAllRoomsRequest, fn func(models.Room) error) error {
+	offest := (req.Page - 1) * req.Limit
+
+	query := `
+	SELECT
+		id,
+		name,
+		capacity,
+		created_at,
+		updated_at
+	FROM
+		rooms
+	WHERE
+		name ILIKE '%' || $3 || '%'
+	ORDER BY
+		name
+	OFFSET
+		$1
+	LIMIT
+		$2;`
+
+	rows, err := s.db.Query(ctx, query, offest, req.Limit, req.Search)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var room models.Room
+		if err := rows.Scan(
+			&room.Id,
+			&room.Name,
+			&room.Capacity,
+			pkg.TimeText(ctx, &room.CreatedAt),
+			pkg.TimeText(ctx, &room.UpdatedAt)); err != nil {
+			return err
+		}
+
+		if err := fn(room); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *roomRepo) GetRoom(ctx context.Context, id string) (models.Room, error) {
+	query := `
+	SELECT
+		id,
+		name,
+		capacity,
+		created_at,
+		updated_at
+	FROM
+		rooms
+	WHERE
+		id = $1;`
+
+	var room models.Room
+
+	err := s.db.QueryRow(ctx, query, id).Scan(&room.Id, &room.Name, &room.Capacity, pkg.TimeText(ctx, &room.CreatedAt), pkg.TimeText(ctx, &room.UpdatedAt))
+	if err != nil {
+		return room, err
+	}
+
+	return room, nil
+}
+
+// SetHours replaces the room's opening hours.
+func (s *roomRepo) SetHours(ctx context.Context, roomId string, windows []models.OpeningWindow) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM room_hours WHERE room_id = $1`, roomId); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO
+		room_hours (id, room_id, weekday, open_time, close_time) VALUES ($1, $2, $3, $4::time, $5::time);`
+
+	for _, w := range windows {
+		if _, err := tx.Exec(ctx, query, uuid.New(), roomId, w.Weekday, w.Start, w.End); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *roomRepo) GetHours(ctx context.Context, roomId string) ([]models.OpeningWindow, error) {
+	query := `
+	SELECT
+		weekday,
+		TO_CHAR(open_time, 'HH24:MI'),
+		TO_CHAR(close_time, 'HH24:MI')
+	FROM
+		room_hours
+	WHERE
+		room_id = $1
+	ORDER BY
+		weekday, open_time;`
+
+	rows, err := s.db.Query(ctx, query, roomId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := []models.OpeningWindow{}
+	for rows.Next() {
+		var w models.OpeningWindow
+		if err := rows.Scan(&w.Weekday, &w.Start, &w.End); err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+
+	return windows, rows.Err()
+}
+
+// roomUsage has the opening windows of the rooms (all of them or the one given in $3) on
+// the open days from $1 to $2 as hours, with $5 to $6 as the hours of rooms without their
+// own, and the parts of the lessons held in a room inside its windows as used. Lessons
+// name their room, and a lesson with several students is a single booking.
+var roomUsage = `
+	WITH hours AS (
+		SELECT
+			r.id AS room_id,
+			d::date AS day,
+			(d::date + COALESCE(rh.open_time, $5::time))::timestamptz AS opens,
+			(d::date + COALESCE(rh.close_time, $6::time))::timestamptz AS closes
+		FROM
+			rooms r
+		CROSS JOIN
+			generate_series($1::timestamptz::date, $2::timestamptz::date, INTERVAL '1 day') AS d
+		LEFT JOIN
+			room_hours rh
+		ON
+			rh.room_id = r.id AND rh.weekday = EXTRACT(DOW FROM d)
+		WHERE
+			($3 = '' OR r.id::text = $3)
+			AND (rh.id IS NOT NULL OR NOT EXISTS (SELECT 1 FROM room_hours x WHERE x.room_id = r.id))
+			AND ` + openDay("d") + `
+	), lessons AS (
+		SELECT DISTINCT
+			r.id AS room_id,
+			tt.from_date,
+			tt.to_date
+		FROM
+			time_table tt
+		INNER JOIN
+			rooms r
+		ON
+			r.name = tt.room_name
+		WHERE
+			tt.from_date BETWEEN $1 AND $2
+			AND ($3 = '' OR r.id::text = $3)
+	), used AS (
+		SELECT
+			h.room_id,
+			h.day,
+			l.from_date,
+			GREATEST(l.from_date, h.opens) AS starts,
+			LEAST(l.to_date, h.closes) AS ends
+		FROM
+			hours h
+		INNER JOIN
+			lessons l
+		ON
+			l.room_id = h.room_id AND l.from_date < h.closes AND l.to_date > h.opens
+	)`
+
+// occupancy is the percentage of open minutes taken by used ones, at most 100.
+func occupancy(used, open string) string {
+	return `COALESCE(ROUND(LEAST(100, 100.0 * ` + used + ` / NULLIF(` + open + `, 0)), 2), 0)::float8`
+}
+
+// roomPeriods sums the lessons and minutes of every room per period and in total, the
+// period of a total is empty.
+var roomPeriods = `, opened AS (
+		SELECT
+			room_id,
+			DATE_TRUNC($4, day)::date AS period,
+			(EXTRACT(EPOCH FROM SUM(closes - opens)) / 60)::bigint AS minutes
+		FROM
+			hours
+		GROUP BY
+			1, 2
+	), booked AS (
+		SELECT
+			room_id,
+			DATE_TRUNC($4, day)::date AS period,
+			COUNT(DISTINCT from_date) AS lessons,
+			(EXTRACT(EPOCH FROM SUM(ends - starts)) / 60)::bigint AS minutes
+		FROM
+			used
+		GROUP BY
+			1, 2
+	)
+	SELECT
+		r.id,
+		r.name,
+		r.capacity,
+		COALESCE(TO_CHAR(o.period, 'YYYY-MM-DD'), ''),
+		SUM(COALESCE(b.lessons, 0))::bigint,
+		SUM(o.minutes)::bigint,
+		SUM(COALESCE(b.minutes, 0))::bigint,
+		` + occupancy("SUM(COALESCE(b.minutes, 0))", "SUM(o.minutes)") + `
+	FROM
+		opened o
+	INNER JOIN
+		rooms r
+	ON
+		r.id = o.room_id
+	LEFT JOIN
+		booked b
+	ON
+		b.room_id = o.room_id AND b.period = o.period
+	GROUP BY GROUPING SETS (
+		(r.id, r.name, r.capacity, o.period),
+		(r.id, r.name, r.capacity)
+	)`
+
+// GetUtilization returns the occupancy of the rooms per day or week ($4), each room's
+// totals first, and the occupancy of every hour of the week over all of them.
+func (s *roomRepo) GetUtilization(ctx context.Context, req models.RoomUtilizationRequest) (models.RoomUtilizationResponse, error) {
+	resp := models.RoomUtilizationResponse{Rooms: []models.RoomUtilization{}, Heatmap: []models.HeatmapCell{}}
+	args := []any{req.From, req.To, req.RoomId, req.Interval, req.OpensAt, req.ClosesAt}
+
+	query := roomUsage + roomPeriods + `
+	ORDER BY
+		r.name, r.id, o.period NULLS FIRST;`
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return resp, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var room models.RoomUtilization
+		var p models.RoomOccupancy
+		if err := rows.Scan(
+			&room.RoomId,
+			&room.RoomName,
+			&room.Capacity,
+			&p.Period,
+			&p.Lessons,
+			&p.OpenMinutes,
+			&p.UsedMinutes,
+			&p.Occupancy); err != nil {
+			return resp, err
+		}
+		if p.Period == "" {
+			room.Lessons, room.OpenMinutes, room.UsedMinutes, room.Occupancy = p.Lessons, p.OpenMinutes, p.UsedMinutes, p.Occupancy
+			resp.Rooms = append(resp.Rooms, room)
+			continue
+		}
+		last := &resp.Rooms[len(resp.Rooms)-1]
+		last.Periods = append(last.Periods, p)
+	}
+	if err := rows.Err(); err != nil {
+		return resp, err
+	}
+
+	query = roomUsage + `, opened AS (
+		SELECT
+			EXTRACT(DOW FROM s.h)::int AS weekday,
+			EXTRACT(HOUR FROM s.h)::int AS hour,
+			SUM(LEAST(h.closes, s.h + INTERVAL '1 hour') - GREATEST(h.opens, s.h)) AS minutes
+		FROM
+			hours h
+		CROSS JOIN LATERAL
+			generate_series(DATE_TRUNC('hour', h.opens), h.closes - INTERVAL '1 second', INTERVAL '1 hour') AS s (h)
+		GROUP BY
+			1, 2
+	), booked AS (
+		SELECT
+			EXTRACT(DOW FROM s.h)::int AS weekday,
+			EXTRACT(HOUR FROM s.h)::int AS hour,
+			COUNT(DISTINCT (u.room_id, u.from_date)) AS lessons,
+			SUM(LEAST(u.ends, s.h + INTERVAL '1 hour') - GREATEST(u.starts, s.h)) AS minutes
+		FROM
+			used u
+		CROSS JOIN LATERAL
+			generate_series(DATE_TRUNC('hour', u.starts), u.ends - INTERVAL '1 second', INTERVAL '1 hour') AS s (h)
+		GROUP BY
+			1, 2
+	)
+	SELECT
+		o.weekday,
+		o.hour,
+		COALESCE(b.lessons, 0),
+		(EXTRACT(EPOCH FROM o.minutes) / 60)::bigint,
+		COALESCE((EXTRACT(EPOCH FROM b.minutes) / 60)::bigint, 0),
+		` + occupancy("EXTRACT(EPOCH FROM b.minutes)", "EXTRACT(EPOCH FROM o.minutes)") + `
+	FROM
+		opened o
+	LEFT JOIN
+		booked b
+	ON
+		b.weekday = o.weekday AND b.hour = o.hour
+	ORDER BY
+		o.weekday, o.hour;`
+
+	rows, err = s.db.Query(ctx, query, args...)
+	if err != nil {
+		return resp, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cell models.HeatmapCell
+		if err := rows.Scan(
+			&cell.Weekday,
+			&cell.Hour,
+			&cell.Lessons,
+			&cell.OpenMinutes,
+			&cell.UsedMinutes,
+			&cell.Occupancy); err != nil {
+			return resp, err
+		}
+		resp.Heatmap = append(resp.Heatmap, cell)
+	}
+
+	return resp, rows.Err()
+}
+
+// EachUtilization hands the occupancy of every room per day or week ($4) to fn one at a
+// time as it is read, each room's periods are followed by its total.
+func (s *roomRepo) EachUtilization(ctx context.Context, req models.RoomUtilizationRequest, fn func(models.RoomUtilizationRow) error) error {
+	args := []any{req.From, req.To, req.RoomId, req.Interval, req.OpensAt, req.ClosesAt}
+
+	query := roomUsage + roomPeriods + `
+	ORDER BY
+		r.name, r.id, o.period NULLS LAST;`
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var roomId string
+		var row models.RoomUtilizationRow
+		if err := rows.Scan(
+			&roomId,
+			&row.Room,
+			&row.Capacity,
+			&row.Period,
+			&row.Lessons,
+			&row.OpenMinutes,
+			&row.UsedMinutes,
+			&row.Occupancy); err != nil {
+			return err
+		}
+		if row.Period == "" {
+			row.Period = "total"
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
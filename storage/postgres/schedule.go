@@ -0,0 +1,272 @@
+package postgres
+
+import (
+	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/pkg"
+	"backend_course/lms/pkg/scheduler"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type scheduleRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSchedule(db *pgxpool.Pool) scheduleRepo {
+	return scheduleRepo{
+		db: db,
+	}
+}
+
+func (s *scheduleRepo) GetTeacherSubjects(ctx context.Context) ([]models.TeacherSubjects, error) {
+	query := `
+	SELECT
+		teacher_id,
+		ARRAY_AGG(subject_id::text ORDER BY subject_id)
+	FROM
+		teacher_subjects
+	GROUP BY
+		teacher_id;`
+
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teachers []models.TeacherSubjects
+	for rows.Next() {
+		var teacher models.TeacherSubjects
+		if err := rows.Scan(&teacher.TeacherId, &teacher.SubjectIds); err != nil {
+			return nil, err
+		}
+		teachers = append(teachers, teacher)
+	}
+
+	return teachers, rows.Err()
+}
+
+// GetBusy returns the time_table lessons overlapping [from, to), with the group of every
+// student so that a new schedule can keep clear of them.
+func (s *scheduleRepo) GetBusy(ctx context.Context, from, to string) ([]models.BusyInterval, error) {
+	query := `
+	SELECT DISTINCT
+		tt.teacher_id,
+		gs.group_id,
+		tt.room_name,
+		tt.from_date,
+		tt.to_date
+	FROM
+		time_table tt
+	LEFT JOIN
+		group_students gs
+	ON
+		gs.student_id = tt.student_id
+	WHERE
+		tt.from_date < $2 AND tt.to_date > $1;`
+
+	rows, err := s.db.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var busy []models.BusyInterval
+	for rows.Next() {
+		var (
+			interval models.BusyInterval
+			groupId  sql.NullString
+		)
+		if err := rows.Scan(&interval.TeacherId, &groupId, &interval.RoomName, pkg.TimeText(ctx, &interval.FromDate), pkg.TimeText(ctx, &interval.ToDate)); err != nil {
+			return nil, err
+		}
+		interval.GroupId = pkg.NullStringToString(groupId)
+		busy = append(busy, interval)
+	}
+
+	return busy, rows.Err()
+}
+
+func (s *scheduleRepo) CreateDraft(ctx context.Context, draft models.ScheduleDraft) (string, error) {
+	id := uuid.New()
+
+	query := `
+	INSERT INTO
+		schedule_drafts (id, week_start, weeks, request, lessons, cost) VALUES ($1, $2, $3, $4, $5, $6);`
+
+	_, err := s.db.Exec(ctx, query, id, draft.WeekStart, draft.Weeks, draft.Request, draft.Lessons, draft.Cost)
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func (s *scheduleRepo) GetDraft(ctx context.Context, id string) (models.ScheduleDraft, error) {
+	query := `
+	SELECT
+		id,
+		status,
+		TO_CHAR(week_start,'YYYY-MM-DD'),
+		weeks,
+		request,
+		lessons,
+		cost,
+		created_at,
+		committed_at
+	FROM
+		schedule_drafts
+	WHERE
+		id = $1;`
+
+	var draft models.ScheduleDraft
+
+	err := s.db.QueryRow(ctx, query, id).Scan(
+		&draft.Id,
+		&draft.Status,
+		&draft.WeekStart,
+		&draft.Weeks,
+		&draft.Request,
+		&draft.Lessons,
+		&draft.Cost,
+		pkg.TimeText(ctx, &draft.CreatedAt),
+		pkg.TimeText(ctx, &draft.CommittedAt))
+	if err != nil {
+		return draft, err
+	}
+
+	return draft, nil
+}
+
+// CommitDraft writes the draft's lessons into time_table for every week and every
+// student of the lesson's group in one transaction. The draft is rejected when a lesson
+// clashes with the timetable as it is now.
+func (s *scheduleRepo) CommitDraft(ctx context.Context, id string) (int64, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		status             string
+		weekStart          time.Time
+		weeks              int
+		lessons            []models.ScheduledLesson
+		termStart, termEnd *time.Time
+	)
+
+	query := `
+	SELECT
+		d.status,
+		d.week_start,
+		d.weeks,
+		d.lessons,
+		t.start_date,
+		t.end_date
+	FROM
+		schedule_drafts d
+	LEFT JOIN
+		terms t
+	ON
+		t.id::text = d.request->>'term_id'
+	WHERE
+		d.id = $1
+	FOR UPDATE OF d;`
+
+	err = tx.QueryRow(ctx, query, id).Scan(&status, &weekStart, &weeks, &lessons, &termStart, &termEnd)
+	if err != nil {
+		return 0, err
+	}
+	if status != config.SCHEDULE_DRAFT {
+		return 0, errors.New("schedule draft is already committed")
+	}
+
+	conflictQuery := `
+	SELECT EXISTS (
+		SELECT
+			1
+		FROM
+			time_table
+		WHERE
+			from_date < $2 AND to_date > $1
+			AND (
+				teacher_id = $3
+				OR room_name = $4
+				OR student_id IN (SELECT student_id FROM group_students WHERE group_id = $5)
+			)
+	);`
+
+	insertQuery := `
+	INSERT INTO
+		time_table (id, teacher_id, student_id, subject_id, from_date, to_date, room_name, group_id)
+	SELECT
+		gen_random_uuid(), $1, gs.student_id, $2, $3, $4, $5, gs.group_id
+	FROM
+		group_students gs
+	WHERE
+		gs.group_id = $6;`
+
+	closedQuery := `SELECT EXISTS (SELECT 1 FROM closures WHERE $1::date BETWEEN from_date AND to_date);`
+
+	var inserted int64
+	for week := 0; week < weeks; week++ {
+		for _, lesson := range lessons {
+			day := weekStart.AddDate(0, 0, 7*week+(lesson.Weekday+6)%7)
+
+			// the lessons recur only on open days within the draft's term
+			if termStart != nil && (day.Before(*termStart) || day.After(*termEnd)) {
+				continue
+			}
+			var closed bool
+			if err := tx.QueryRow(ctx, closedQuery, day).Scan(&closed); err != nil {
+				return 0, err
+			}
+			if closed {
+				continue
+			}
+
+			from, err := scheduler.AtClock(day, lesson.StartTime)
+			if err != nil {
+				return 0, err
+			}
+			to, err := scheduler.AtClock(day, lesson.EndTime)
+			if err != nil {
+				return 0, err
+			}
+
+			var conflict bool
+			if err := tx.QueryRow(ctx, conflictQuery, from, to, lesson.TeacherId, lesson.RoomName, lesson.GroupId).Scan(&conflict); err != nil {
+				return 0, err
+			}
+			if conflict {
+				return 0, fmt.Errorf("lesson of group %s at %s clashes with the timetable, generate a new draft",
+					lesson.GroupId, from.Format("2006-01-02 15:04"))
+			}
+
+			tag, err := tx.Exec(ctx, insertQuery, lesson.TeacherId, lesson.SubjectId, from, to, lesson.RoomName, lesson.GroupId)
+			if err != nil {
+				return 0, err
+			}
+			inserted += tag.RowsAffected()
+		}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE schedule_drafts SET status = $2, committed_at = NOW() WHERE id = $1`, id, config.SCHEDULE_COMMITTED)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return inserted, nil
+}
@@ -2,11 +2,10 @@ package postgres
 
 import (
 	"backend_course/lms/api/models"
+	"backend_course/lms/domain"
 	"backend_course/lms/pkg"
 	"context"
 	"database/sql"
-	"fmt"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -22,46 +21,62 @@ func NewStudent(db *pgxpool.Pool) studentRepo {
 	}
 }
 
-func (s *studentRepo) Create(ctx context.Context, student models.AddStudent) (string, error) {
-
+func (s *studentRepo) Create(ctx context.Context, student domain.Student) (uuid.UUID, error) {
 	id := uuid.New()
-	fmt.Println(student)
 	query := `INSERT INTO
-					students (id, first_name, last_name, age, external_id, phone, mail, is_active, password) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);`
+					students (id, first_name, last_name, age, external_id, phone, mail, is_active, password, timezone) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);`
 
-	_, err := s.db.Exec(ctx, query, id, student.FirstName, student.LastName, student.Age, student.ExternalId, student.Phone, student.Email, student.IsActive, student.Password)
+	_, err := s.db.Exec(ctx, query, id, student.FirstName, student.LastName, student.Age, student.ExternalId, student.Phone, student.Email, student.IsActive, student.Password, student.Timezone)
 	if err != nil {
-		return "", err
+		return uuid.Nil, err
 	}
 
-	return id.String(), nil
+	return id, nil
 }
 
+// Taken returns the mails and phones of contacts which students already have.
+func (s *studentRepo) Taken(ctx context.Context, contacts models.ImportContacts) (models.ImportContacts, error) {
+	return takenContacts(ctx, s.db, "students", contacts)
+}
 
-func (s *studentRepo) Update(ctx context.Context, student models.Student) (string, error) {
+// Import stores the students in one transaction and returns how many were stored, it
+// returns pgx.ErrNoRows when a mail or phone of them was taken in the meantime.
+func (s *studentRepo) Import(ctx context.Context, students []domain.Student) (int64, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	contacts := models.ImportContacts{}
+	rows := make([][]any, 0, len(students))
+	for _, student := range students {
+		contacts.Mails = append(contacts.Mails, student.Email)
+		contacts.Phones = append(contacts.Phones, student.Phone)
+		rows = append(rows, []any{uuid.New(), student.FirstName, student.LastName, student.Age, student.ExternalId, student.Phone, student.Email, student.IsActive, student.Password, student.Timezone})
+	}
+
+	columns := []string{"id", "first_name", "last_name", "age", "external_id", "phone", "mail", "is_active", "password", "timezone"}
+	return copyImport(ctx, tx, "students", columns, contacts, rows)
+}
+
+func (s *studentRepo) Update(ctx context.Context, student domain.Student) (uuid.UUID, error) {
 	query := `
 	UPDATE
 		students
 	SET
-		first_name = $2, last_name = $3, age = $4, external_id = $5, phone = $6, mail = $7, updated_at = NOW()
+		first_name = $2, last_name = $3, age = $4, external_id = $5, phone = $6, mail = $7, timezone = $8, updated_at = NOW()
 	WHERE 
 		id = $1; `
 
-	_, err := s.db.Exec(ctx, query, student.Id, student.FirstName, student.LastName, student.Age, student.ExternalId, student.Phone, student.Email)
+	_, err := s.db.Exec(ctx, query, student.Id, student.FirstName, student.LastName, student.Age, student.ExternalId, student.Phone, student.Email, student.Timezone)
 	if err != nil {
-		return "", err
+		return uuid.Nil, err
 	}
 	return student.Id, nil
 }
 
-func (s *studentRepo) UpdateStatus(ctx context.Context, student models.Student) (string, error) {
-	fmt.Println(student.IsActive)
-	if student.IsActive {
-		student.IsActive = false
-	} else {
-		student.IsActive = true
-	}
-	fmt.Println(student.IsActive)
+func (s *studentRepo) UpdateStatus(ctx context.Context, student domain.Student) (uuid.UUID, error) {
 	query := `
 	UPDATE
 		students
@@ -70,14 +85,14 @@ func (s *studentRepo) UpdateStatus(ctx context.Context, student models.Student)
 	WHERE 
 		id = $1;`
 
-	_, err := s.db.Exec(ctx, query, student.Id, student.IsActive)
+	_, err := s.db.Exec(ctx, query, student.Id, !student.IsActive)
 	if err != nil {
-		return "", err
+		return uuid.Nil, err
 	}
 	return student.Id, nil
 }
 
-func (s *studentRepo) Delete(ctx context.Context, id string) error {
+func (s *studentRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `
 	DELETE
 	FROM
@@ -93,115 +108,98 @@ func (s *studentRepo) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (s *studentRepo) GetAll(ctx context.Context, req models.GetAllStudentsRequest) (models.GetAllStudentsResponse, error) {
-	resp := models.GetAllStudentsResponse{}
-	filter := ""
-	offest := (req.Page - 1) * req.Limit
+// studentColumns are scanned by scanStudent, NULL texts are read as empty ones.
+const studentColumns = `
+		id,
+		COALESCE(first_name, ''),
+		COALESCE(last_name, ''),
+		COALESCE(age, 0),
+		COALESCE(external_id, ''),
+		COALESCE(phone, ''),
+		COALESCE(mail, ''),
+		created_at,
+		updated_at,
+		COALESCE(is_active, FALSE),
+		timezone`
+
+func scanStudent(row interface{ Scan(dest ...any) error }) (domain.Student, error) {
+	var student domain.Student
+	err := row.Scan(
+		&student.Id,
+		&student.FirstName,
+		&student.LastName,
+		&student.Age,
+		&student.ExternalId,
+		&student.Phone,
+		&student.Email,
+		&student.CreatedAt,
+		&student.UpdatedAt,
+		&student.IsActive,
+		&student.Timezone)
+	return student, err
+}
+
+func (s *studentRepo) GetAll(ctx context.Context, req models.GetAllStudentsRequest) ([]domain.Student, int64, error) {
+	var students []domain.Student
+	err := s.Each(ctx, req, func(student domain.Student) error {
+		students = append(students, student)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
 
-	if req.Search != "" {
-		filter = ` AND first_name ILIKE '%` + req.Search + `%' `
+	var count int64
+	err = s.db.QueryRow(ctx, `SELECT count(*) from students WHERE $1 = '' OR first_name ILIKE '%' || $1 || '%'`, req.Search).Scan(&count)
+	if err != nil {
+		return nil, 0, err
 	}
 
+	return students, count, nil
+}
+
+// Each hands the students of the page to fn one at a time as they are read.
+func (s *studentRepo) Each(ctx context.Context, req models.GetAllStudentsRequest, fn func(domain.Student) error) error {
+	offest := (req.Page - 1) * req.Limit
+
 	query := `
-	SELECT
-		id,
-		first_name,
-		last_name,
-		age,
-		external_id,
-		phone,
-		mail,
-		TO_CHAR(created_at,'YYYY-MM-DD HH:MM:SS'),
-		TO_CHAR(updated_at,'YYYY-MM-DD HH:MM:SS'),
-		is_active
+	SELECT` + studentColumns + `
 	FROM
 		students
-	WHERE TRUE ` + filter + `
+	WHERE
+		$3 = '' OR first_name ILIKE '%' || $3 || '%'
 	OFFSET
 		$1
 	LIMIT
 		$2;`
 
-	rows, err := s.db.Query(ctx, query, offest, req.Limit)
+	rows, err := s.db.Query(ctx, query, offest, req.Limit, req.Search)
 	if err != nil {
-		return resp, err
+		return err
 	}
+	defer rows.Close()
+
 	for rows.Next() {
-		var (
-			student                                                 models.GetStudent
-			firstName, lastName, externalId, phone, mail, updatedAt sql.NullString
-		)
-		if err := rows.Scan(
-			&student.Id,
-			&firstName,
-			&lastName,
-			&student.Age,
-			&externalId,
-			&phone,
-			&mail,
-			&student.CreatedAt,
-			&updatedAt,
-			&student.IsActive); err != nil {
-			return resp, err
+		student, err := scanStudent(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(student); err != nil {
+			return err
 		}
-		student.FirstName = pkg.NullStringToString(firstName)
-		student.LastName = pkg.NullStringToString(lastName)
-		student.ExternalId = pkg.NullStringToString(externalId)
-		student.Phone = pkg.NullStringToString(phone)
-		student.Email = pkg.NullStringToString(mail)
-		student.UpdatedAt = pkg.NullStringToString(updatedAt)
-
-		resp.Students = append(resp.Students, student)
-	}
-
-	err = s.db.QueryRow(ctx, `SELECT count(*) from students WHERE TRUE `+filter+``).Scan(&resp.Count)
-	if err != nil {
-		return resp, err
 	}
-
-	return resp, nil
+	return rows.Err()
 }
 
-func (s *studentRepo) GetStudent(ctx context.Context, id string) (models.GetStudent, error) {
-
+func (s *studentRepo) GetStudent(ctx context.Context, id uuid.UUID) (domain.Student, error) {
 	query := `
-	SELECT
-		id,
-		first_name,
-		last_name,
-		age,
-		external_id,
-		phone,
-		mail,
-		TO_CHAR(created_at,'YYYY-MM-DD HH:MM:SS'),
-		TO_CHAR(updated_at,'YYYY-MM-DD HH:MM:SS'),
-		is_active
+	SELECT` + studentColumns + `
 	FROM
 		students
 	WHERE
 		id = $1;`
 
-	row := s.db.QueryRow(ctx, query, id)
-
-	var (
-		student                                                 models.GetStudent
-		firstName, lastName, externalId, phone, mail, updatedAt sql.NullString
-	)
-
-	err := row.Scan(&student.Id, &firstName, &lastName, &student.Age, &externalId, &phone, &mail, &student.CreatedAt, &updatedAt, &student.IsActive)
-
-	student.FirstName = pkg.NullStringToString(firstName)
-	student.LastName = pkg.NullStringToString(lastName)
-	student.ExternalId = pkg.NullStringToString(externalId)
-	student.Phone = pkg.NullStringToString(phone)
-	student.Email = pkg.NullStringToString(mail)
-	student.UpdatedAt = pkg.NullStringToString(updatedAt)
-
-	if err != nil {
-		return student, err
-	}
-
-	return student, nil
+	return scanStudent(s.db.QueryRow(ctx, query, id))
 }
 
 func (s *studentRepo) CheckStudentLesson(ctx context.Context, id string) (models.CheckLessonStudent, error) {
@@ -209,140 +207,218 @@ func (s *studentRepo) CheckStudentLesson(ctx context.Context, id string) (models
 	query := `
 	SELECT
 		st.first_name || ' ' || st.last_name AS student_name,
-		st.age,
-		sb.name AS subject_name,
-		ts.first_name || ' ' || ts.last_name AS teacher_name,
-		tt.room_name,
-		tt.to_date
+		st.age,` + lessonStatusColumns + `
 	FROM
 		students st
-	INNER JOIN
-		time_table tt
-	ON
-		st.id = tt.student_id
-	INNER JOIN
-		subjects sb
-	ON
-		sb.id = tt.subject_id
-	INNER JOIN
-		teachers ts
-	ON
-		ts.id = tt.teacher_id
+	LEFT JOIN LATERAL (` + currentLessonQuery("tt.student_id = st.id") + `
+	) l ON TRUE
 	WHERE 
 		st.id = $1;`
 
 	row := s.db.QueryRow(ctx, query, id)
 
 	var (
-		checkStudent                                    models.CheckLessonStudent
-		studentName, subjectName, teacherName, roomName sql.NullString
-		savedTime time.Time
+		checkStudent models.CheckLessonStudent
+		studentName  sql.NullString
+		lesson       lessonRow
 	)
 
-	err := row.Scan(&studentName, &checkStudent.StudentAge, &subjectName, &teacherName, &roomName, &savedTime)
-
+	err := row.Scan(append([]any{
+		&studentName,
+		&checkStudent.StudentAge,
+		&checkStudent.Status,
+		&checkStudent.TimeElapsed,
+		&checkStudent.TimeLeft,
+		&checkStudent.StartsIn,
+	}, lesson.dest(ctx)...)...)
 	if err != nil {
 		return models.CheckLessonStudent{}, err
 	}
 
 	checkStudent.StudentName = pkg.NullStringToString(studentName)
-	checkStudent.SubjectName = pkg.NullStringToString(subjectName)
-	checkStudent.TeacherName = pkg.NullStringToString(teacherName)
-	checkStudent.RoomName = pkg.NullStringToString(roomName)
-
-
-	currentTime := time.Now()
-
-	savedTimePart := savedTime.Format("15:04:05")
-	currentTimePart := currentTime.Format("15:04:05")
-
-	changeFormatSavedTime, err := time.Parse("15:04:05", savedTimePart)
-
-	if err != nil {
-		return models.CheckLessonStudent{}, err
-	}
-
-	changeFormatCurrentTime, err := time.Parse("15:04:05", currentTimePart)
-
-	if err != nil {
-		return models.CheckLessonStudent{}, err
-	}
-
-	difference := changeFormatSavedTime.Sub(changeFormatCurrentTime)
-
-	checkStudent.TimeLeft = difference.Minutes()
+	checkStudent.Lesson = lesson.details()
 
 	return checkStudent, nil
 }
 
+// attendanceLessons is a row per started lesson of a student in the filters, $1 student,
+// $2 teacher, $3 subject, $4 to $5 the period and $6 what the lessons are grouped by.
+// study_time is taken from the recorded attendance: a present student studied the whole
+// lesson, a late one from check-in until the end, absent/unmarked ones nothing.
+var attendanceLessons = `
+	WITH report AS (
+		SELECT
+			s.id AS student_id,
+			COALESCE(s.first_name || ' ' || s.last_name, '') AS student_name,
+			s.created_at AS student_created_at,
+			CASE $6
+				WHEN 'teacher' THEN tt.teacher_id::text
+				WHEN 'subject' THEN tt.subject_id::text
+				WHEN 'week' THEN TO_CHAR(DATE_TRUNC('week', tt.from_date), 'YYYY-MM-DD')
+				ELSE ''
+			END AS group_id,
+			CASE $6
+				WHEN 'teacher' THEN COALESCE(t.first_name || ' ' || t.last_name, '')
+				WHEN 'subject' THEN COALESCE(sb.name, '')
+				WHEN 'week' THEN TO_CHAR(tt.from_date, 'IYYY-"W"IW')
+				ELSE ''
+			END AS group_name,
+			EXTRACT(EPOCH FROM (tt.to_date - tt.from_date)) / 60 AS minutes,
+			a.status,
+			CASE
+				WHEN a.status = 'present' THEN EXTRACT(EPOCH FROM (tt.to_date - tt.from_date)) / 3600
+				WHEN a.status = 'late' THEN EXTRACT(EPOCH FROM (tt.to_date - LEAST(GREATEST(COALESCE(a.check_in_time, tt.from_date), tt.from_date), tt.to_date))) / 3600
+				ELSE 0
+			END AS study_time
+		FROM
+			time_table tt
+			JOIN students s ON tt.student_id = s.id
+			JOIN teachers t ON tt.teacher_id = t.id
+			JOIN subjects sb ON tt.subject_id = sb.id
+			LEFT JOIN attendance a ON a.time_table_id = tt.id AND a.student_id = tt.student_id
+		WHERE
+			tt.from_date <= NOW()
+			AND ($1 = '' OR tt.student_id::text = $1)
+			AND ($2 = '' OR tt.teacher_id::text = $2)
+			AND ($3 = '' OR tt.subject_id::text = $3)
+			AND ($4 = '' OR tt.from_date >= $4::timestamptz)
+			AND ($5 = '' OR tt.from_date <= $5::timestamptz)
+			AND ` + openDay("tt.from_date") + `
+	)`
+
+// attendanceStats sums the rows of report into models.AttendanceStats.
+const attendanceStats = `
+		COUNT(*),
+		COUNT(*) FILTER (WHERE status = 'present'),
+		COUNT(*) FILTER (WHERE status = 'late'),
+		COUNT(*) FILTER (WHERE status = 'absent'),
+		COUNT(*) FILTER (WHERE status = 'excused'),
+		COUNT(*) FILTER (WHERE status IS NULL),
+		COALESCE(ROUND(SUM(minutes) / 60, 2), 0)::float8,
+		COALESCE(ROUND(SUM(study_time), 2), 0)::float8,
+		COALESCE(ROUND(AVG(minutes), 2), 0)::float8,
+		COALESCE(ROUND(AVG(study_time), 2), 0)::float8,
+		COALESCE(ROUND(100.0 * COUNT(*) FILTER (WHERE status IN ('present', 'late')) / NULLIF(COUNT(status), 0), 2), 0)::float8`
+
+func attendanceStatsDest(stats *models.AttendanceStats) []any {
+	return []any{
+		&stats.Lessons,
+		&stats.Present,
+		&stats.Late,
+		&stats.Absent,
+		&stats.Excused,
+		&stats.Unmarked,
+		&stats.TotalHours,
+		&stats.StudyTime,
+		&stats.AvgLessonLength,
+		&stats.AvgStudyTime,
+		&stats.AttendanceRate,
+	}
+}
 
 func (s *studentRepo) GetAllStudentsAttandenceReport(ctx context.Context, req models.GetAllStudentsAttandenceReportRequest) (models.GetAllStudentsAttandenceReportResponse, error) {
-	resp := models.GetAllStudentsAttandenceReportResponse{}
-	filter := ""
-	offest := (req.Page - 1) * req.Limit
+	resp := models.GetAllStudentsAttandenceReportResponse{Students: []models.StudentAttandenceReport{}}
+	args := []any{req.StudentId, req.TeacherId, req.SubjectId, req.StartDate, req.EndDate, req.GroupBy}
 
-	if req.StudentId != "" {
-		filter = ` AND s.id =` + req.StudentId + ` `
+	err := s.db.QueryRow(ctx, attendanceLessons+`
+	SELECT
+		COUNT(DISTINCT student_id),`+attendanceStats+`
+	FROM
+		report;`, args...).Scan(append([]any{&resp.Count}, attendanceStatsDest(&resp.Totals)...)...)
+	if err != nil {
+		return resp, err
 	}
 
-	if req.TeacherId != "" {
-		filter += ` AND t.id =` + req.TeacherId + ` `
+	index := map[string]int{}
+	err = s.EachAttendanceReport(ctx, req, func(student models.StudentAttandenceReport) error {
+		index[student.StudentId] = len(resp.Students)
+		resp.Students = append(resp.Students, student)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+	if req.GroupBy == "" || len(resp.Students) == 0 {
+		return resp, nil
 	}
 
-	if req.StartDate != "" && req.EndDate != "" {
-		filter += ` AND tt.from_date BETWEEN '` + req.StartDate + `' AND '` + req.EndDate + `' `
+	ids := make([]string, 0, len(resp.Students))
+	for _, student := range resp.Students {
+		ids = append(ids, student.StudentId)
 	}
 
-	// 	1. Student name,
-	// 	2. student createdAt,
-	// 	3. o’qituvchi name,
-	// 	4. studying_time,
-	// 	5. avg_studying_time,
-	query := `
+	rows, err := s.db.Query(ctx, attendanceLessons+`
 	SELECT
-        s.id,
-        s.first_name || ' ' || s.last_name AS student_name,
-        TO_CHAR(s.created_at,'YYYY-MM-DD HH24:MI:SS'),
-        t.first_name || ' ' || t.last_name AS teacher_name,
-        EXTRACT(EPOCH FROM (tt.to_date - tt.from_date)) / 3600 AS studing_time
-                
-    FROM 
-        time_table tt
-        JOIN students s on tt.student_id = s.id
-        JOIN teachers t on tt.teacher_id = t.id
-        WHERE
-		TRUE ` + filter + `
-	OFFSET $1 LIMIT $2;`
-
-	rows, err := s.db.Query(ctx, query, offest, req.Limit)
+		student_id,
+		group_id,
+		group_name,`+attendanceStats+`
+	FROM
+		report
+	WHERE
+		student_id::text = ANY($7)
+	GROUP BY
+		student_id, group_id, group_name
+	ORDER BY
+		student_id, CASE $6 WHEN 'week' THEN group_id ELSE group_name END, group_id;`, append(args, ids)...)
 	if err != nil {
 		return resp, err
 	}
-	studentAttandence := models.StudentAttandenceReport{}
-	for rows.Next() {
+	defer rows.Close()
 
-		if err := rows.Scan(
-			&studentAttandence.StudentId,
-			&studentAttandence.StudentName,
-			&studentAttandence.StudentCreatedAt,
-			&studentAttandence.TeacherName,
-			&studentAttandence.StudyTime); err != nil {
+	for rows.Next() {
+		var studentId string
+		group := models.AttendanceGroup{}
+		if err := rows.Scan(append([]any{
+			&studentId,
+			&group.Id,
+			&group.Name,
+		}, attendanceStatsDest(&group.AttendanceStats)...)...); err != nil {
 			return resp, err
 		}
-
-		resp.Students = append(resp.Students, studentAttandence)
+		student := &resp.Students[index[studentId]]
+		student.Groups = append(student.Groups, group)
 	}
+	return resp, rows.Err()
+}
 
-	err = s.db.QueryRow(ctx, `SELECT COUNT(*) from time_table time_table tt
-	JOIN students s on tt.student_id = s.id
-	JOIN teachers t on tt.teacher_id = t.id WHERE TRUE `+filter+``).Scan(&resp.Count)
+// EachAttendanceReport hands the attendance of the page of students to fn one at a time
+// as it is read, without their groups.
+func (s *studentRepo) EachAttendanceReport(ctx context.Context, req models.GetAllStudentsAttandenceReportRequest, fn func(models.StudentAttandenceReport) error) error {
+	offset := (req.Page - 1) * req.Limit
+	args := []any{req.StudentId, req.TeacherId, req.SubjectId, req.StartDate, req.EndDate, req.GroupBy}
+
+	rows, err := s.db.Query(ctx, attendanceLessons+`
+	SELECT
+		student_id,
+		student_name,
+		student_created_at,`+attendanceStats+`
+	FROM
+		report
+	GROUP BY
+		student_id, student_name, student_created_at
+	ORDER BY
+		student_name, student_id
+	OFFSET $7 LIMIT $8;`, append(args, offset, req.Limit)...)
 	if err != nil {
-		return resp, err
+		return err
 	}
-	return resp, nil
-}
-
+	defer rows.Close()
 
+	for rows.Next() {
+		student := models.StudentAttandenceReport{}
+		if err := rows.Scan(append([]any{
+			&student.StudentId,
+			&student.StudentName,
+			pkg.TimeText(ctx, &student.StudentCreatedAt),
+		}, attendanceStatsDest(&student.AttendanceStats)...)...); err != nil {
+			return err
+		}
+		if err := fn(student); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
 
 func (s *studentRepo) UploadImage(ctx context.Context, path models.UploadStudentImage) error {
 	query := `
@@ -358,4 +434,33 @@ func (s *studentRepo) UploadImage(ctx context.Context, path models.UploadStudent
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (s *studentRepo) GetStudentByLogin(ctx context.Context, login string) (domain.Student, error) {
+	query := `
+	SELECT
+		id,
+		COALESCE(first_name, ''),
+		COALESCE(last_name, ''),
+		COALESCE(age, 0),
+		COALESCE(mail, ''),
+		COALESCE(is_active, FALSE),
+		COALESCE(password, ''),
+		timezone
+	FROM
+		students
+	WHERE
+		mail = $1;`
+
+	var student domain.Student
+	err := s.db.QueryRow(ctx, query, login).Scan(
+		&student.Id,
+		&student.FirstName,
+		&student.LastName,
+		&student.Age,
+		&student.Email,
+		&student.IsActive,
+		&student.Password,
+		&student.Timezone)
+	return student, err
+}
@@ -2,17 +2,19 @@ package postgres
 
 import (
 	"backend_course/lms/api/models"
+	"backend_course/lms/domain"
 	"context"
 	"testing"
 
 	"github.com/go-faker/faker/v4"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestCreateStudent(t *testing.T) {
 	studentRepo := NewStudent(db)
 
-	reqStudent := models.AddStudent{
+	reqStudent := domain.Student{
 		FirstName: faker.Name(),
 		Age:       10,
 		LastName:  faker.Word(),
@@ -34,8 +36,8 @@ func TestCreateStudent(t *testing.T) {
 func TestUpdateStudent(t *testing.T) {
 	studentRepo := NewStudent(db)
 
-	reqStudent := models.Student{
-		Id:         "3eccd9c5-c3ed-460d-9b2e-e10b600c3a0e",
+	reqStudent := domain.Student{
+		Id:         uuid.MustParse("3eccd9c5-c3ed-460d-9b2e-e10b600c3a0e"),
 		FirstName:  faker.Name(),
 		LastName:   faker.Word(),
 		Age:        12,
@@ -60,7 +62,7 @@ func TestUpdateStudent(t *testing.T) {
 func TestDeleteStudent(t *testing.T) {
 	studentRepo := NewStudent(db)
 
-	reqStudent := models.AddStudent{
+	reqStudent := domain.Student{
 		FirstName: faker.Name(),
 		Age:       10,
 		LastName:  faker.Word(),
@@ -77,22 +79,19 @@ func TestDeleteStudent(t *testing.T) {
 
 func TestGetAllStudent(t *testing.T) {
 	studentRepo := NewStudent(db)
-	reqStudent := models.AddStudent{
+	reqStudent := domain.Student{
 		FirstName: faker.Name(),
 		Age:       10,
 		LastName:  faker.Word(),
 	}
 
-	response, err := studentRepo.GetAll(context.Background(), models.GetAllStudentsRequest{})
+	_, count, err := studentRepo.GetAll(context.Background(), models.GetAllStudentsRequest{})
 	if assert.NoError(t, err) {
-		count := response.Count
-
 		_, err := studentRepo.Create(context.Background(), reqStudent)
 
 		if assert.NoError(t, err) {
-			testResponse, err := studentRepo.GetAll(context.Background(), models.GetAllStudentsRequest{})
+			_, testCount, err := studentRepo.GetAll(context.Background(), models.GetAllStudentsRequest{})
 			if assert.NoError(t, err) {
-				testCount := testResponse.Count
 				assert.Equal(t, count+1, testCount)
 			} else {
 				return
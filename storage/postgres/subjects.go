@@ -7,9 +7,31 @@ import (
 	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// subjectColumns are scanned by scanSubject.
+const subjectColumns = `
+		sb.id,
+		COALESCE(sb.code, ''),
+		sb.name,
+		sb.type,
+		sb.description,
+		sb.level,
+		sb.credit_hours,
+		sb.created_at,
+		sb.updated_at`
+
+// subjectFilter keeps the subjects matching the search, type and level given in $1, $2 and
+// $3 with credit hours between $4 and $5, empty and NULL ones match everything.
+const subjectFilter = `
+		($1 = '' OR sb.code ILIKE '%' || $1 || '%' OR sb.name ILIKE '%' || $1 || '%' OR sb.description ILIKE '%' || $1 || '%')
+		AND ($2 = '' OR sb.type = $2)
+		AND ($3 = '' OR sb.level = $3)
+		AND ($4::int IS NULL OR sb.credit_hours >= $4)
+		AND ($5::int IS NULL OR sb.credit_hours <= $5)`
+
 type subjectsRepo struct {
 	db *pgxpool.Pool
 }
@@ -20,14 +42,36 @@ func NewSubject(db *pgxpool.Pool) subjectsRepo {
 	}
 }
 
+func scanSubject(ctx context.Context, row interface{ Scan(dest ...any) error }) (models.Subjects, error) {
+	var (
+		subject           models.Subjects
+		name, typeSubject sql.NullString
+	)
+	err := row.Scan(
+		&subject.Id,
+		&subject.Code,
+		&name,
+		&typeSubject,
+		&subject.Description,
+		&subject.Level,
+		&subject.CreditHours,
+		pkg.TimeText(ctx, &subject.CreatedAt),
+		pkg.TimeText(ctx, &subject.UpdatedAt))
+	subject.Name = pkg.NullStringToString(name)
+	subject.Type = pkg.NullStringToString(typeSubject)
+	return subject, err
+}
+
 func (s *subjectsRepo) Create(ctx context.Context, subject models.AddSubject) (string, error) {
 	id := uuid.New()
 
 	query := `
 	INSERT INTO
-		subjects (id, name, type) VALUES ($1, $2, $3);`
+		subjects (id, code, name, type, description, level, credit_hours)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7);`
 
-	_, err := s.db.Exec(ctx, query, id, subject.Name, subject.Type)
+	_, err := s.db.Exec(ctx, query, id, subject.Code, subject.Name, subject.Type, subject.Description,
+		subject.Level, subject.CreditHours)
 	if err != nil {
 		return "", err
 	}
@@ -40,11 +84,18 @@ func (s *subjectsRepo) Update(ctx context.Context, subject models.Subjects) (str
 	UPDATE
 		subjects
 	SET
-		name = $2, type = $3, updated_at = NOW()
+		code = NULLIF($2, ''),
+		name = $3,
+		type = $4,
+		description = $5,
+		level = $6,
+		credit_hours = $7,
+		updated_at = NOW()
 	WHERE 
 		id = $1;`
 
-	_, err := s.db.Exec(ctx, query, subject.Id, subject.Name, subject.Type)
+	_, err := s.db.Exec(ctx, query, subject.Id, subject.Code, subject.Name, subject.Type, subject.Description,
+		subject.Level, subject.CreditHours)
 	if err != nil {
 		return "", err
 	}
@@ -69,89 +120,244 @@ func (s *subjectsRepo) Delete(ctx context.Context, id string) error {
 
 func (s *subjectsRepo) GetAll(ctx context.Context, req models.GetAllSubjectsRequest) (models.GetAllSubjectsResponse, error) {
 	resp := models.GetAllSubjectsResponse{}
-	filter := ""
-	offest := (req.Page - 1) * req.Limit
+	err := s.Each(ctx, req, func(subject models.Subjects) error {
+		resp.Subjects = append(resp.Subjects, subject)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
 
-	if req.Search != "" {
-		filter = ` AND first_name ILIKE '%` + req.Search + `%' `
+	err = s.db.QueryRow(ctx, `SELECT COUNT(*) FROM subjects sb WHERE`+subjectFilter,
+		req.Search, req.Type, req.Level, req.MinCredits, req.MaxCredits).Scan(&resp.Count)
+	if err != nil {
+		return resp, err
 	}
+	return resp, nil
+}
+
+// Each hands the subjects of the page to fn one at a time as they are read.
+func (s *subjectsRepo) Each(ctx context.Context, req models.GetAllSubjectsRequest, fn func(models.Subjects) error) error {
+	offest := (req.Page - 1) * req.Limit
 
 	query := `
-	SELECT
-		id,
-		name,
-		type,
-		TO_CHAR(created_at,'YYYY-MM-DD HH:MM:SS'),
-		TO_CHAR(updated_at,'YYYY-MM-DD HH:MM:SS')
+	SELECT` + subjectColumns + `
 	FROM
-		subjects
-	WHERE TRUE ` + filter + `
+		subjects sb
+	WHERE` + subjectFilter + `
+	ORDER BY
+		sb.code, sb.name
 	OFFSET
-		$1
+		$6
 	LIMIT
-		$2;`
+		$7;`
 
-	rows, err := s.db.Query(ctx, query, offest, req.Limit)
+	rows, err := s.db.Query(ctx, query, req.Search, req.Type, req.Level, req.MinCredits, req.MaxCredits, offest, req.Limit)
 	if err != nil {
-		return resp, err
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		subject, err := scanSubject(ctx, rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(subject); err != nil {
+			return err
+		}
 	}
+	return rows.Err()
+}
+
+func (s *subjectsRepo) GetSubject(ctx context.Context, id string) (models.Subjects, error) {
+	query := `
+	SELECT` + subjectColumns + `
+	FROM
+		subjects sb
+	WHERE
+		sb.id = $1;`
+
+	return scanSubject(ctx, s.db.QueryRow(ctx, query, id))
+}
+
+// GetSyllabus returns the units of the subject's syllabus with their lessons in order.
+func (s *subjectsRepo) GetSyllabus(ctx context.Context, id string) ([]models.SyllabusUnit, error) {
+	query := `
+	SELECT
+		u.id,
+		u.title,
+		u.description,
+		l.title,
+		l.hours::float8
+	FROM
+		syllabus_units u
+	LEFT JOIN
+		syllabus_lessons l
+	ON
+		l.unit_id = u.id
+	WHERE
+		u.subject_id = $1
+	ORDER BY
+		u.position, l.position;`
+
+	rows, err := s.db.Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		units  []models.SyllabusUnit
+		unitId string
+	)
 	for rows.Next() {
 		var (
-			subject models.Subjects
-			name, typeSubject, updatedAt sql.NullString
+			id          string
+			unit        models.SyllabusUnit
+			lessonTitle sql.NullString
+			lessonHours sql.NullFloat64
 		)
-		if err := rows.Scan(
-			&subject.Id,
-			&name,
-			&typeSubject,
-			&subject.CreatedAt,
-			&updatedAt); err != nil {
-			return resp, err
+		if err := rows.Scan(&id, &unit.Title, &unit.Description, &lessonTitle, &lessonHours); err != nil {
+			return nil, err
+		}
+		if id != unitId {
+			unit.Lessons = []models.SyllabusLesson{}
+			units = append(units, unit)
+			unitId = id
+		}
+		if lessonTitle.Valid {
+			last := &units[len(units)-1]
+			last.Lessons = append(last.Lessons, models.SyllabusLesson{Title: lessonTitle.String, Hours: lessonHours.Float64})
 		}
-		subject.Name = pkg.NullStringToString(name)
-		subject.Type = pkg.NullStringToString(typeSubject)
-		subject.UpdatedAt = pkg.NullStringToString(updatedAt)
+	}
 
-		resp.Subjects = append(resp.Subjects, subject)
+	return units, rows.Err()
+}
+
+// SetSyllabus replaces the subject's syllabus with units, positions follow their order.
+func (s *subjectsRepo) SetSyllabus(ctx context.Context, id string, units []models.SyllabusUnit) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback(ctx)
 
-	err = s.db.QueryRow(ctx, `SELECT COUNT(*) from subjects WHERE TRUE `+filter+``).Scan(&resp.Count)
+	if _, err := tx.Exec(ctx, `DELETE FROM syllabus_units WHERE subject_id = $1`, id); err != nil {
+		return err
+	}
+
+	for i, unit := range units {
+		unitId := uuid.New()
+		_, err := tx.Exec(ctx, `
+		INSERT INTO
+			syllabus_units (id, subject_id, position, title, description)
+			VALUES ($1, $2, $3, $4, $5);`, unitId, id, i+1, unit.Title, unit.Description)
+		if err != nil {
+			return err
+		}
+
+		for j, lesson := range unit.Lessons {
+			_, err := tx.Exec(ctx, `
+			INSERT INTO
+				syllabus_lessons (id, unit_id, position, title, hours)
+				VALUES ($1, $2, $3, $4, $5);`, uuid.New(), unitId, j+1, lesson.Title, lesson.Hours)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE subjects SET updated_at = NOW() WHERE id = $1`, id)
 	if err != nil {
-		return resp, err
+		return err
 	}
-	return resp, nil
-}
 
-func (s *subjectsRepo) GetSubject(ctx context.Context, id string) (models.Subjects, error) {
+	return tx.Commit(ctx)
+}
 
+// GetPrerequisites returns the subjects a student must complete before enrolling in the
+// subject.
+func (s *subjectsRepo) GetPrerequisites(ctx context.Context, id string) ([]models.SubjectRef, error) {
 	query := `
 	SELECT
-		id,
-		name,
-		type,
-		TO_CHAR(created_at,'YYYY-MM-DD HH:MM:SS'),
-		TO_CHAR(updated_at,'YYYY-MM-DD HH:MM:SS')
+		sb.id,
+		COALESCE(sb.code, ''),
+		COALESCE(sb.name, '')
 	FROM
-		subjects
+		subject_prerequisites p
+	INNER JOIN
+		subjects sb
+	ON
+		sb.id = p.prerequisite_id
 	WHERE
-		id = $1;`
+		p.subject_id = $1
+	ORDER BY
+		sb.code, sb.name;`
 
-	row := s.db.QueryRow(ctx, query, id)
+	return scanSubjectRefs(s.db.Query(ctx, query, id))
+}
 
-	var (
-		subject models.Subjects
-		name, typeSubject, updatedAt sql.NullString
-)
+// GetPrerequisiteGraph maps every subject having prerequisites to their ids.
+func (s *subjectsRepo) GetPrerequisiteGraph(ctx context.Context) (map[string][]string, error) {
+	rows, err := s.db.Query(ctx, `SELECT subject_id, prerequisite_id FROM subject_prerequisites`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	graph := map[string][]string{}
+	for rows.Next() {
+		var subjectId, prerequisiteId string
+		if err := rows.Scan(&subjectId, &prerequisiteId); err != nil {
+			return nil, err
+		}
+		graph[subjectId] = append(graph[subjectId], prerequisiteId)
+	}
 
-	err := row.Scan(&subject.Id, &name, &typeSubject, &subject.CreatedAt, &updatedAt)
+	return graph, rows.Err()
+}
 
-	subject.Name = pkg.NullStringToString(name)
-	subject.Type = pkg.NullStringToString(typeSubject)
-	subject.UpdatedAt = pkg.NullStringToString(updatedAt)
+// SetPrerequisites replaces the subject's prerequisites with prerequisiteIds.
+func (s *subjectsRepo) SetPrerequisites(ctx context.Context, id string, prerequisiteIds []string) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM subject_prerequisites WHERE subject_id = $1`, id); err != nil {
+		return err
+	}
 
+	query := `
+	INSERT INTO
+		subject_prerequisites (subject_id, prerequisite_id)
+	SELECT
+		$1, UNNEST($2::uuid[])
+	ON CONFLICT DO NOTHING;`
+
+	if _, err := tx.Exec(ctx, query, id, prerequisiteIds); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func scanSubjectRefs(rows pgx.Rows, err error) ([]models.SubjectRef, error) {
 	if err != nil {
-		return subject, err
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subjects []models.SubjectRef
+	for rows.Next() {
+		var subject models.SubjectRef
+		if err := rows.Scan(&subject.Id, &subject.Code, &subject.Name); err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, subject)
 	}
 
-	return subject, nil
+	return subjects, rows.Err()
 }
@@ -2,10 +2,11 @@ package postgres
 
 import (
 	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/domain"
 	"backend_course/lms/pkg"
 	"context"
 	"database/sql"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -21,38 +22,74 @@ func NewTeacher(db *pgxpool.Pool) teacherRepo {
 	}
 }
 
-func (s *teacherRepo) Create(ctx context.Context, teacher models.AddTeacher) (string, error) {
+func (s *teacherRepo) Create(ctx context.Context, teacher domain.Teacher) (uuid.UUID, error) {
 
 	id := uuid.New()
 
 	query := `
 	INSERT INTO
-		teachers (id, first_name, last_name, subject_id, start_working, phone, mail, password) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);`
+		teachers (id, first_name, last_name, start_working, phone, mail, password, max_weekly_lessons, timezone)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);`
 
-	_, err := s.db.Exec(ctx, query, id, teacher.FirstName, teacher.LastName, teacher.SubjectId, teacher.StartWorking, teacher.Phone, teacher.Email, teacher.Password)
+	_, err := s.db.Exec(ctx, query, id, teacher.FirstName, teacher.LastName, teacher.StartWorking, teacher.Phone, teacher.Email, teacher.Password, maxWeeklyLessons(teacher), teacher.Timezone)
 	if err != nil {
-		return "", err
+		return uuid.Nil, err
 	}
-	return id.String(), nil
+	return id, nil
 }
 
-func (s *teacherRepo) Update(ctx context.Context, teacher models.Teacher) (string, error) {
+// Taken returns the mails and phones of contacts which teachers already have.
+func (s *teacherRepo) Taken(ctx context.Context, contacts models.ImportContacts) (models.ImportContacts, error) {
+	return takenContacts(ctx, s.db, "teachers", contacts)
+}
+
+// Import stores the teachers in one transaction and returns how many were stored, it
+// returns pgx.ErrNoRows when a mail or phone of them was taken in the meantime.
+func (s *teacherRepo) Import(ctx context.Context, teachers []domain.Teacher) (int64, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	contacts := models.ImportContacts{}
+	rows := make([][]any, 0, len(teachers))
+	for _, teacher := range teachers {
+		contacts.Mails = append(contacts.Mails, teacher.Email)
+		contacts.Phones = append(contacts.Phones, teacher.Phone)
+		rows = append(rows, []any{uuid.New(), teacher.FirstName, teacher.LastName, teacher.StartWorking, teacher.Phone, teacher.Email, teacher.Password, maxWeeklyLessons(teacher), teacher.Timezone})
+	}
+
+	columns := []string{"id", "first_name", "last_name", "start_working", "phone", "mail", "password", "max_weekly_lessons", "timezone"}
+	return copyImport(ctx, tx, "teachers", columns, contacts, rows)
+}
+
+// maxWeeklyLessons is the cap of a new teacher, the default one unless it is given.
+func maxWeeklyLessons(teacher domain.Teacher) int {
+	if n, ok := teacher.MaxWeeklyLessons.Get(); ok {
+		return n
+	}
+	return config.MaxWeeklyLessons
+}
+
+func (s *teacherRepo) Update(ctx context.Context, teacher domain.Teacher) (uuid.UUID, error) {
 	query := `
 	UPDATE
 		teachers
 	SET
-		first_name = $2, last_name = $3, subject_id = $4, start_working = $5, phone = $6, mail = $7, updated_at = NOW()
+		first_name = $2, last_name = $3, start_working = $4, phone = $5, mail = $6,
+		max_weekly_lessons = COALESCE($7::INT, max_weekly_lessons), timezone = $8, updated_at = NOW()
 	WHERE 
 		id = $1 `
 
-	_, err := s.db.Exec(ctx, query, teacher.Id, teacher.FirstName, teacher.LastName, teacher.SubjectId, teacher.StartWorking, teacher.Phone, teacher.Email)
+	_, err := s.db.Exec(ctx, query, teacher.Id, teacher.FirstName, teacher.LastName, teacher.StartWorking, teacher.Phone, teacher.Email, teacher.MaxWeeklyLessons, teacher.Timezone)
 	if err != nil {
-		return "", err
+		return uuid.Nil, err
 	}
 	return teacher.Id, nil
 }
 
-func (s *teacherRepo) Delete(ctx context.Context, id string) error {
+func (s *teacherRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `
 	DELETE
 	FROM
@@ -68,199 +105,157 @@ func (s *teacherRepo) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (s *teacherRepo) GetAll(ctx context.Context, req models.GetAllTeachersRequest) (models.GetAllTeachersResponse, error) {
-	resp := models.GetAllTeachersResponse{}
-	filter := ""
-	offest := (req.Page - 1) * req.Limit
-
-	if req.Search != "" {
-		filter = ` AND first_name ILIKE '%` + req.Search + `%' `
-	}
-
-	query := `
-	SELECT 
+// teacherColumns are scanned by scanTeacher, NULL texts are read as empty ones.
+const teacherColumns = `
 		id,
-		first_name,
-		last_name,
-		subject_id,
-		TO_CHAR(start_working,'YYYY-MM-DD HH:MM:SS'),
-		phone,
-		mail,
-		TO_CHAR(created_at,'YYYY-MM-DD HH:MM:SS'),
-		TO_CHAR(updated_at,'YYYY-MM-DD HH:MM:SS')
-	FROM 
-		teachers
-	WHERE TRUE ` + filter + `
-	OFFSET
-		$1 
-	LIMIT 
-		$2;`
+		COALESCE(first_name, ''),
+		COALESCE(last_name, ''),
+		ARRAY(SELECT ts.subject_id FROM teacher_subjects ts WHERE ts.teacher_id = teachers.id ORDER BY ts.since),
+		start_working,
+		COALESCE(phone, ''),
+		COALESCE(mail, ''),
+		max_weekly_lessons,
+		timezone,
+		created_at,
+		updated_at`
+
+func scanTeacher(row interface{ Scan(dest ...any) error }, extra ...any) (domain.Teacher, error) {
+	var (
+		teacher    domain.Teacher
+		maxLessons int
+	)
+	err := row.Scan(append([]any{
+		&teacher.Id,
+		&teacher.FirstName,
+		&teacher.LastName,
+		&teacher.SubjectIds,
+		&teacher.StartWorking,
+		&teacher.Phone,
+		&teacher.Email,
+		&maxLessons,
+		&teacher.Timezone,
+		&teacher.CreatedAt,
+		&teacher.UpdatedAt,
+	}, extra...)...)
+	teacher.MaxWeeklyLessons = domain.Some(maxLessons)
+	return teacher, err
+}
 
-	rows, err := s.db.Query(ctx, query, offest, req.Limit)
+func (s *teacherRepo) GetAll(ctx context.Context, req models.GetAllTeachersRequest) ([]domain.Teacher, int64, error) {
+	var teachers []domain.Teacher
+	err := s.Each(ctx, req, func(teacher domain.Teacher) error {
+		teachers = append(teachers, teacher)
+		return nil
+	})
 	if err != nil {
-		return resp, err
-	}
-	for rows.Next() {
-		var (
-			teacher                                                                         models.Teacher
-			firstName, lastName, subjectId, startWorking, phone, mail, createdAt, updatedAt sql.NullString
-		)
-
-		if err := rows.Scan(
-			&teacher.Id,
-			&firstName,
-			&lastName,
-			&subjectId,
-			&startWorking,
-			&phone,
-			&mail,
-			&createdAt,
-			&updatedAt); err != nil {
-			return resp, err
-		}
-		teacher.FirstName = pkg.NullStringToString(firstName)
-		teacher.LastName = pkg.NullStringToString(lastName)
-		teacher.SubjectId = pkg.NullStringToString(subjectId)
-		teacher.StartWorking = pkg.NullStringToString(startWorking)
-		teacher.Phone = pkg.NullStringToString(phone)
-		teacher.Email = pkg.NullStringToString(mail)
-
-		resp.Teachers = append(resp.Teachers, teacher)
+		return nil, 0, err
 	}
 
-	err = s.db.QueryRow(ctx, `SELECT count(*) from teachers WHERE TRUE `+filter+``).Scan(&resp.Count)
+	var count int64
+	err = s.db.QueryRow(ctx, `SELECT count(*) from teachers WHERE $1 = '' OR first_name ILIKE '%' || $1 || '%'`, req.Search).Scan(&count)
 	if err != nil {
-		return resp, err
+		return nil, 0, err
 	}
 
-	return resp, nil
+	return teachers, count, nil
 }
 
-func (s *teacherRepo) GetTeacher(ctx context.Context, id string) (models.Teacher, error) {
+// Each hands the teachers of the page to fn one at a time as they are read.
+func (s *teacherRepo) Each(ctx context.Context, req models.GetAllTeachersRequest, fn func(domain.Teacher) error) error {
+	offest := (req.Page - 1) * req.Limit
 
 	query := `
-	SELECT
-		id,
-		first_name,
-		last_name,
-		subject_id,
-		TO_CHAR(start_working,'YYYY-MM-DD HH:MM:SS'),
-		phone,
-		mail,
-		TO_CHAR(created_at,'YYYY-MM-DD HH:MM:SS'),
-		TO_CHAR(updated_at,'YYYY-MM-DD HH:MM:SS')
+	SELECT` + teacherColumns + `
 	FROM
 		teachers
 	WHERE
-		id = $1;
-`
-	row := s.db.QueryRow(ctx, query, id)
-
-	var (
-		teacher                                                                         models.Teacher
-		firstName, lastName, subjectId, startWorking, phone, mail, createdAt, updatedAt sql.NullString
-	)
-
-	err := row.Scan(&teacher.Id, &firstName, &lastName, &subjectId, &startWorking, &phone, &mail, &createdAt, &updatedAt)
-
-	teacher.FirstName = pkg.NullStringToString(firstName)
-	teacher.LastName = pkg.NullStringToString(lastName)
-	teacher.SubjectId = pkg.NullStringToString(subjectId)
-	teacher.StartWorking = pkg.NullStringToString(startWorking)
-	teacher.Phone = pkg.NullStringToString(phone)
-	teacher.Email = pkg.NullStringToString(mail)
+		$3 = '' OR first_name ILIKE '%' || $3 || '%'
+	OFFSET
+		$1
+	LIMIT
+		$2;`
 
+	rows, err := s.db.Query(ctx, query, offest, req.Limit, req.Search)
 	if err != nil {
-		return teacher, err
+		return err
 	}
-	return teacher, nil
-}
+	defer rows.Close()
 
-func (s *teacherRepo) GetTeacherByLogin(ctx context.Context, login string) (models.Teacher, error) {
+	for rows.Next() {
+		teacher, err := scanTeacher(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(teacher); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
 
+func (s *teacherRepo) GetTeacher(ctx context.Context, id uuid.UUID) (domain.Teacher, error) {
 	query := `
-	SELECT
-		id,
-		first_name,
-		last_name,
-		subject_id,
-		TO_CHAR(start_working,'YYYY-MM-DD HH:MM:SS'),
-		phone,
-		mail,
-		TO_CHAR(created_at,'YYYY-MM-DD HH:MM:SS'),
-		TO_CHAR(updated_at,'YYYY-MM-DD HH:MM:SS'),
-		password
+	SELECT` + teacherColumns + `
 	FROM
 		teachers
 	WHERE
-		mail = $1;
-`
-	row := s.db.QueryRow(ctx, query, login)
-
-	var (
-		teacher                                                                         models.Teacher
-		firstName, lastName, subjectId, startWorking, phone, mail, createdAt, updatedAt sql.NullString
-	)
+		id = $1;`
 
-	err := row.Scan(
-		&teacher.Id,
-		&firstName,
-		&lastName,
-		&subjectId,
-		&startWorking,
-		&phone,
-		&mail,
-		&createdAt,
-		&updatedAt,
-		&teacher.Password,
-	)
+	return scanTeacher(s.db.QueryRow(ctx, query, id))
+}
 
-	teacher.FirstName = pkg.NullStringToString(firstName)
-	teacher.LastName = pkg.NullStringToString(lastName)
-	teacher.SubjectId = pkg.NullStringToString(subjectId)
-	teacher.StartWorking = pkg.NullStringToString(startWorking)
-	teacher.Phone = pkg.NullStringToString(phone)
-	teacher.Email = pkg.NullStringToString(mail)
+func (s *teacherRepo) GetTeacherByLogin(ctx context.Context, login string) (domain.Teacher, error) {
+	query := `
+	SELECT` + teacherColumns + `,
+		COALESCE(password, '')
+	FROM
+		teachers
+	WHERE
+		mail = $1;`
 
-	if err != nil {
-		return teacher, err
-	}
-	return teacher, nil
+	var password string
+	teacher, err := scanTeacher(s.db.QueryRow(ctx, query, login), &password)
+	teacher.Password = password
+	return teacher, err
 }
 
 func (s *teacherRepo) CheckTeacherLesson(ctx context.Context, id string) (models.CheckLessonTeacher, error) {
 	query := `
 	SELECT
-		ts.first_name || ' ' || ts.last_name AS teacher_name,
-		sb.name AS subject_name,
-		tt.room_name,
-		tt.to_date
+		ts.first_name || ' ' || ts.last_name AS teacher_name,` + lessonStatusColumns + `
 	FROM
 		teachers ts
-	INNER JOIN
-		time_table tt
-	ON
-		ts.id = tt.teacher_id
-	INNER JOIN
-		subjects sb
-	ON
-		sb.id = tt.subject_id
+	LEFT JOIN LATERAL (` + currentLessonQuery("(tt.teacher_id = ts.id OR tt.original_teacher_id = ts.id)") + `
+	) l ON TRUE
 	WHERE 
 		ts.id = $1;`
 
 	row := s.db.QueryRow(ctx, query, id)
 
 	var (
-		checkTeacher                       models.CheckLessonTeacher
-		teacherName, subjectName, roomName sql.NullString
-		savedTime                          time.Time
+		checkTeacher models.CheckLessonTeacher
+		teacherName  sql.NullString
+		lesson       lessonRow
 	)
 
-	err := row.Scan(&teacherName, &subjectName, &roomName, &savedTime)
-
+	err := row.Scan(append([]any{
+		&teacherName,
+		&checkTeacher.Status,
+		&checkTeacher.TimeElapsed,
+		&checkTeacher.TimeLeft,
+		&checkTeacher.StartsIn,
+	}, lesson.dest(ctx)...)...)
 	if err != nil {
 		return models.CheckLessonTeacher{}, err
 	}
+
+	checkTeacher.TeacherName = pkg.NullStringToString(teacherName)
+	checkTeacher.Lesson = lesson.details()
+
+	if checkTeacher.Lesson == nil {
+		return checkTeacher, nil
+	}
+
 	query = `
 	SELECT
 		st.first_name || ' ' || st.last_name AS student_name,
@@ -269,23 +264,29 @@ func (s *teacherRepo) CheckTeacherLesson(ctx context.Context, id string) (models
 		st.mail,
 		st.is_active
 	FROM
-		teachers ts
-	INNER JOIN
 		time_table tt
+	INNER JOIN
+		time_table l
 	ON
-		ts.id = tt.teacher_id
+		l.teacher_id = tt.teacher_id
+		AND l.subject_id = tt.subject_id
+		AND l.from_date = tt.from_date
+		AND l.to_date = tt.to_date
 	INNER JOIN
 		students st
 	ON
 		st.id = tt.student_id
 	WHERE 
-		ts.id = $1;`
+		l.id = $1
+	ORDER BY
+		student_name;`
 
-	rows, err := s.db.Query(ctx, query, id)
+	rows, err := s.db.Query(ctx, query, checkTeacher.Lesson.TimeTableId)
 
 	if err != nil {
 		return models.CheckLessonTeacher{}, err
 	}
+	defer rows.Close()
 
 	var students []models.MyStudents
 
@@ -312,31 +313,6 @@ func (s *teacherRepo) CheckTeacherLesson(ctx context.Context, id string) (models
 		students = append(students, student)
 	}
 
-	checkTeacher.TeacherName = pkg.NullStringToString(teacherName)
-	checkTeacher.SubjectName = pkg.NullStringToString(subjectName)
-	checkTeacher.RoomName = pkg.NullStringToString(roomName)
-
-	currentTime := time.Now()
-
-	savedTimePart := savedTime.Format("15:04:05")
-	currentTimePart := currentTime.Format("15:04:05")
-
-	changeFormatSavedTime, err := time.Parse("15:04:05", savedTimePart)
-
-	if err != nil {
-		return models.CheckLessonTeacher{}, err
-	}
-
-	changeFormatCurrentTime, err := time.Parse("15:04:05", currentTimePart)
-
-	if err != nil {
-		return models.CheckLessonTeacher{}, err
-	}
-
-	difference := changeFormatSavedTime.Sub(changeFormatCurrentTime)
-
-	checkTeacher.TimeLeft = difference.Minutes()
-
 	checkTeacher.Students = students
 
 	return checkTeacher, nil
@@ -350,17 +326,87 @@ func (s *teacherRepo) IsTeacherExists(ctx context.Context, email string) bool {
 		teachers
 	WHERE
 		mail = $1;`
-	
+
 	row := s.db.QueryRow(ctx, query, email)
 
 	var (
-		teacher                                                                         models.Teacher
-		mail sql.NullString
+		teacher models.Teacher
+		mail    sql.NullString
 	)
 
 	_ = row.Scan(
-		&mail,)
+		&mail)
 
 	teacher.Email = pkg.NullStringToString(mail)
-	return teacher.Email == "" 
-}
\ No newline at end of file
+	return teacher.Email == ""
+}
+
+// TeachesSubject reports whether the teacher is qualified for the subject.
+func (s *teacherRepo) TeachesSubject(ctx context.Context, teacherId, subjectId string) (bool, error) {
+	var teaches bool
+	err := s.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM teacher_subjects WHERE teacher_id::text = $1 AND subject_id::text = $2);`,
+		teacherId, subjectId).Scan(&teaches)
+	return teaches, err
+}
+
+// GetSubjects returns the subjects the teacher is qualified for, the longest taught first.
+func (s *teacherRepo) GetSubjects(ctx context.Context, teacherId string) ([]models.TeacherSubject, error) {
+	query := `
+	SELECT
+		ts.subject_id,
+		COALESCE(sb.name, ''),
+		ts.proficiency,
+		TO_CHAR(ts.since, 'YYYY-MM-DD')
+	FROM
+		teacher_subjects ts
+	INNER JOIN
+		subjects sb
+	ON
+		sb.id = ts.subject_id
+	WHERE
+		ts.teacher_id = $1
+	ORDER BY
+		ts.since, sb.name;`
+
+	rows, err := s.db.Query(ctx, query, teacherId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subjects := []models.TeacherSubject{}
+	for rows.Next() {
+		var subject models.TeacherSubject
+		if err := rows.Scan(&subject.SubjectId, &subject.SubjectName, &subject.Proficiency, &subject.Since); err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, subject)
+	}
+
+	return subjects, rows.Err()
+}
+
+// SetSubject qualifies the teacher for the subject or updates the qualification.
+func (s *teacherRepo) SetSubject(ctx context.Context, teacherId, subjectId string, req models.SetTeacherSubject) error {
+	query := `
+	INSERT INTO
+		teacher_subjects (teacher_id, subject_id, proficiency, since)
+		VALUES ($1, $2, $3, $4)
+	ON CONFLICT (teacher_id, subject_id) DO UPDATE
+	SET
+		proficiency = EXCLUDED.proficiency,
+		since = EXCLUDED.since;`
+
+	_, err := s.db.Exec(ctx, query, teacherId, subjectId, req.Proficiency, req.Since)
+	return err
+}
+
+// RemoveSubject takes the qualification away, it reports whether the teacher had it.
+func (s *teacherRepo) RemoveSubject(ctx context.Context, teacherId, subjectId string) (bool, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM teacher_subjects WHERE teacher_id = $1 AND subject_id = $2;`,
+		teacherId, subjectId)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -2,12 +2,114 @@ package postgres
 
 import (
 	"backend_course/lms/api/models"
+	"backend_course/lms/config"
+	"backend_course/lms/domain"
+	"backend_course/lms/pkg"
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// lessonStatusColumns selects the status and minutes of the lesson "l" relative to now
+// followed by the lesson details.
+const lessonStatusColumns = `
+		CASE
+			WHEN l.id IS NULL THEN 'none'
+			WHEN l.from_date <= NOW() THEN 'in_progress'
+			ELSE 'upcoming'
+		END AS status,
+		COALESCE(GREATEST(EXTRACT(EPOCH FROM (NOW() - l.from_date)) / 60, 0), 0) AS time_elapsed,
+		COALESCE(EXTRACT(EPOCH FROM (l.to_date - NOW())) / 60, 0) AS time_left,
+		COALESCE(GREATEST(EXTRACT(EPOCH FROM (l.from_date - NOW())) / 60, 0), 0) AS starts_in,
+		l.id,
+		l.subject_id,
+		l.subject_name,
+		l.teacher_id,
+		l.teacher_name,
+		l.room_name,
+		l.from_date,
+		l.to_date,
+		l.original_teacher_id,
+		l.original_teacher_name`
+
+// currentLessonQuery selects the lesson in progress, or else the next upcoming one,
+// among the time_table rows "tt" matching condition, skipping closed days.
+func currentLessonQuery(condition string) string {
+	return `
+		SELECT
+			tt.id,
+			tt.subject_id,
+			sb.name AS subject_name,
+			tt.teacher_id,
+			ts.first_name || ' ' || ts.last_name AS teacher_name,
+			tt.room_name,
+			tt.from_date,
+			tt.to_date,
+			tt.original_teacher_id,
+			ots.first_name || ' ' || ots.last_name AS original_teacher_name
+		FROM
+			time_table tt
+		INNER JOIN
+			subjects sb
+		ON
+			sb.id = tt.subject_id
+		INNER JOIN
+			teachers ts
+		ON
+			ts.id = tt.teacher_id
+		LEFT JOIN
+			teachers ots
+		ON
+			ots.id = tt.original_teacher_id
+		WHERE
+			` + condition + ` AND tt.to_date > NOW() AND ` + openDay("tt.from_date") + `
+		ORDER BY
+			tt.from_date <= NOW() DESC, tt.from_date
+		LIMIT 1`
+}
+
+// lessonRow holds the lesson columns of lessonStatusColumns, they are NULL when there is no lesson.
+type lessonRow struct {
+	id, subjectId, subjectName, teacherId, teacherName, roomName sql.NullString
+	originalTeacherId, originalTeacherName                       sql.NullString
+	fromDate, toDate                                             string
+}
+
+func (l *lessonRow) dest(ctx context.Context) []any {
+	return []any{
+		&l.id,
+		&l.subjectId,
+		&l.subjectName,
+		&l.teacherId,
+		&l.teacherName,
+		&l.roomName,
+		pkg.TimeText(ctx, &l.fromDate),
+		pkg.TimeText(ctx, &l.toDate),
+		&l.originalTeacherId,
+		&l.originalTeacherName,
+	}
+}
+
+func (l lessonRow) details() *models.LessonDetails {
+	if !l.id.Valid {
+		return nil
+	}
+	return &models.LessonDetails{
+		TimeTableId:         l.id.String,
+		SubjectId:           pkg.NullStringToString(l.subjectId),
+		SubjectName:         pkg.NullStringToString(l.subjectName),
+		TeacherId:           pkg.NullStringToString(l.teacherId),
+		TeacherName:         pkg.NullStringToString(l.teacherName),
+		RoomName:            pkg.NullStringToString(l.roomName),
+		FromDate:            l.fromDate,
+		ToDate:              l.toDate,
+		OriginalTeacherId:   pkg.NullStringToString(l.originalTeacherId),
+		OriginalTeacherName: pkg.NullStringToString(l.originalTeacherName),
+	}
+}
+
 type timeRepo struct {
 	db *pgxpool.Pool
 }
@@ -18,7 +120,7 @@ func NewTime(db *pgxpool.Pool) timeRepo {
 	}
 }
 
-func (s *timeRepo) Create(ctx context.Context, time models.Time) (string, error) {
+func (s *timeRepo) Create(ctx context.Context, time domain.TimeTable) (uuid.UUID, error) {
 	id := uuid.New()
 
 	query := `
@@ -27,13 +129,13 @@ func (s *timeRepo) Create(ctx context.Context, time models.Time) (string, error)
 
 	_, err := s.db.Exec(ctx, query, id, time.TeacherId, time.StudentId, time.SubjectId, time.FromDate, time.ToDate, time.RoomName)
 	if err != nil {
-		return "", err
+		return uuid.Nil, err
 	}
 
-	return id.String(), nil
+	return id, nil
 }
 
-func (s *timeRepo) Update(ctx context.Context, time models.Time) (string, error) {
+func (s *timeRepo) Update(ctx context.Context, time domain.TimeTable) (uuid.UUID, error) {
 	query := `
 	UPDATE
 		time_table
@@ -42,14 +144,14 @@ func (s *timeRepo) Update(ctx context.Context, time models.Time) (string, error)
 	WHERE 
 		id = $1; `
 
-	_, err := s.db.Exec(ctx, query, time.Id, time.TeacherId, time.StudentId, time.SubjectId, time.FromDate, time.ToDate)
+	_, err := s.db.Exec(ctx, query, time.Id, time.TeacherId, time.StudentId, time.SubjectId, time.FromDate, time.ToDate, time.RoomName)
 	if err != nil {
-		return "", err
+		return uuid.Nil, err
 	}
 	return time.Id, nil
 }
 
-func (s *timeRepo) Delete(ctx context.Context, id string) error {
+func (s *timeRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `
 	DELETE
 	FROM
@@ -65,82 +167,494 @@ func (s *timeRepo) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (s *timeRepo) GetAll(ctx context.Context, req models.GetAllTimeRequest) (models.GetAllTimeResponse, error) {
-	resp := models.GetAllTimeResponse{}
-	filter := ""
-	offest := (req.Page - 1) * req.Limit
+// timeColumns are scanned by scanTime.
+const timeColumns = `
+		id,
+		teacher_id,
+		student_id,
+		subject_id,
+		from_date,
+		to_date,
+		room_name,
+		original_teacher_id`
 
+func scanTime(row interface{ Scan(dest ...any) error }) (domain.TimeTable, error) {
+	var time domain.TimeTable
+	err := row.Scan(
+		&time.Id,
+		&time.TeacherId,
+		&time.StudentId,
+		&time.SubjectId,
+		&time.FromDate,
+		&time.ToDate,
+		&time.RoomName,
+		&time.OriginalTeacherId)
+	return time, err
+}
+
+func timeFilter(req models.GetAllTimeRequest) string {
+	filter := ` AND ` + openDay("from_date") + ` `
 	if req.Search != "" {
-		filter = ` AND first_name ILIKE '%` + req.Search + `%' `
+		filter += ` AND first_name ILIKE '%` + req.Search + `%' `
+	}
+	return filter
+}
+
+func (s *timeRepo) GetAll(ctx context.Context, req models.GetAllTimeRequest) ([]domain.TimeTable, int64, error) {
+	var times []domain.TimeTable
+	err := s.Each(ctx, req, func(time domain.TimeTable) error {
+		times = append(times, time)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var count int64
+	err = s.db.QueryRow(ctx, `SELECT COUNT(*) from time_table WHERE TRUE `+timeFilter(req)+``).Scan(&count)
+	if err != nil {
+		return nil, 0, err
 	}
+	return times, count, nil
+}
+
+// Each hands the lessons of the page to fn one at a time as they are read.
+func (s *timeRepo) Each(ctx context.Context, req models.GetAllTimeRequest, fn func(domain.TimeTable) error) error {
+	offest := (req.Page - 1) * req.Limit
 
 	query := `
-	SELECT
-		id,
-		teacher_id,
-		student_id,
-		subject_id,
-		TO_CHAR(from_date,'YYYY-MM-DD HH:MM:SS'),
-		TO_CHAR(to_date,'YYYY-MM-DD HH:MM:SS'),
-		room_name
+	SELECT` + timeColumns + `
 	FROM 
 		time_table
 	WHERE 
-		TRUE ` + filter + `
+		TRUE ` + timeFilter(req) + `
 	OFFSET $1 LIMIT $2
 					`
 	rows, err := s.db.Query(ctx, query, offest, req.Limit)
 	if err != nil {
-		return resp, err
+		return err
 	}
+	defer rows.Close()
+
+	for rows.Next() {
+		time, err := scanTime(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(time); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *timeRepo) GetTime(ctx context.Context, id uuid.UUID) (domain.TimeTable, error) {
+	query := `
+	SELECT` + timeColumns + `
+	FROM
+		time_table
+	WHERE
+		id = $1;`
+
+	return scanTime(s.db.QueryRow(ctx, query, id))
+}
+
+// GetSubstitutes lists teachers of the lesson's subject who are free, available and not on
+// leave during the lesson and still under their weekly cap, the least loaded first.
+func (s *timeRepo) GetSubstitutes(ctx context.Context, id uuid.UUID) ([]models.Substitute, error) {
+	query := `
+	WITH lesson AS (
+		SELECT
+			teacher_id,
+			COALESCE(original_teacher_id, teacher_id) AS original_teacher_id,
+			subject_id,
+			from_date,
+			to_date,
+			DATE_TRUNC('week', from_date) AS week_start
+		FROM
+			time_table
+		WHERE
+			id = $1
+	)
+	SELECT
+		ts.id,
+		ts.first_name || ' ' || ts.last_name AS teacher_name,
+		w.lessons,
+		ts.max_weekly_lessons
+	FROM
+		lesson
+	INNER JOIN
+		teacher_subjects q
+	ON
+		q.subject_id = lesson.subject_id
+	INNER JOIN
+		teachers ts
+	ON
+		ts.id = q.teacher_id
+		AND ts.id <> lesson.teacher_id
+		AND ts.id <> lesson.original_teacher_id
+	CROSS JOIN LATERAL (
+		SELECT
+			COUNT(*) AS lessons
+		FROM (
+			SELECT DISTINCT
+				subject_id, from_date, to_date
+			FROM
+				time_table
+			WHERE
+				teacher_id = ts.id
+				AND from_date >= lesson.week_start
+				AND from_date < lesson.week_start + INTERVAL '7 days'
+		) weekly
+	) w
+	WHERE
+		w.lessons < ts.max_weekly_lessons
+		AND NOT EXISTS (
+			SELECT
+				1
+			FROM
+				time_table tt
+			WHERE
+				tt.teacher_id = ts.id
+				AND tt.from_date < lesson.to_date
+				AND tt.to_date > lesson.from_date
+		)
+		AND NOT EXISTS (
+			SELECT
+				1
+			FROM
+				teacher_leaves lv
+			WHERE
+				lv.teacher_id = ts.id
+				AND lv.status = $2
+				AND lv.from_date < lesson.to_date
+				AND lv.to_date > lesson.from_date
+		)
+		AND (
+			NOT EXISTS (SELECT 1 FROM teacher_availability WHERE teacher_id = ts.id)
+			OR EXISTS (
+				SELECT
+					1
+				FROM
+					teacher_availability av
+				WHERE
+					av.teacher_id = ts.id
+					AND lesson.from_date::date = lesson.to_date::date
+					AND av.weekday = EXTRACT(DOW FROM lesson.from_date)
+					AND av.start_time <= lesson.from_date::time
+					AND av.end_time >= lesson.to_date::time
+			)
+		)
+	ORDER BY
+		w.lessons, teacher_name;`
+
+	rows, err := s.db.Query(ctx, query, id, config.LEAVE_APPROVED)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var substitutes []models.Substitute
 	for rows.Next() {
 		var (
-			time models.Time
+			substitute  models.Substitute
+			teacherName sql.NullString
 		)
-		if err := rows.Scan(
-			&time.Id,
-			&time.TeacherId,
-			&time.StudentId,
-			&time.SubjectId,
-			&time.FromDate,
-			&time.ToDate,
-			&time.RoomName); err != nil {
-			return resp, err
+		if err := rows.Scan(&substitute.TeacherId, &teacherName, &substitute.WeeklyLessons, &substitute.MaxWeeklyLessons); err != nil {
+			return nil, err
 		}
+		substitute.TeacherName = pkg.NullStringToString(teacherName)
+		substitutes = append(substitutes, substitute)
+	}
 
-		resp.Time = append(resp.Time, time)
+	return substitutes, rows.Err()
+}
+
+// Substitute hands every row of the lesson to teacherId keeping the first teacher as the
+// original one, handing it back to the original teacher clears the substitution.
+func (s *timeRepo) Substitute(ctx context.Context, id, teacherId uuid.UUID) (string, int64, error) {
+	query := `
+	UPDATE
+		time_table tt
+	SET
+		original_teacher_id = NULLIF(COALESCE(tt.original_teacher_id, tt.teacher_id), $2),
+		teacher_id = $2
+	FROM
+		time_table l
+	WHERE
+		l.id = $1
+		AND tt.teacher_id = l.teacher_id
+		AND tt.subject_id = l.subject_id
+		AND tt.from_date = l.from_date
+		AND tt.to_date = l.to_date
+	RETURNING
+		COALESCE(tt.original_teacher_id::text, '');`
+
+	rows, err := s.db.Query(ctx, query, id, teacherId)
+	if err != nil {
+		return "", 0, err
 	}
+	defer rows.Close()
 
-	err = s.db.QueryRow(ctx, `SELECT COUNT(*) from time_table WHERE TRUE `+filter+``).Scan(&resp.Count)
+	var originalTeacherId string
+	for rows.Next() {
+		if err := rows.Scan(&originalTeacherId); err != nil {
+			return "", 0, err
+		}
+	}
+
+	return originalTeacherId, rows.CommandTag().RowsAffected(), rows.Err()
+}
+
+// GetStudentLessons returns the student's lessons starting between from and to with their
+// attendance, only the ones which have already started when startedOnly is set. Holidays
+// and closures are left out.
+func (s *timeRepo) GetStudentLessons(ctx context.Context, studentId, from, to string, startedOnly bool) ([]models.StudentLesson, error) {
+	query := `
+	SELECT
+		tt.id,
+		COALESCE(sb.name, ''),
+		COALESCE(ts.first_name || ' ' || ts.last_name, ''),
+		COALESCE(tt.room_name, ''),
+		tt.from_date,
+		tt.to_date,
+		COALESCE(a.status, '')
+	FROM
+		time_table tt
+	INNER JOIN
+		subjects sb
+	ON
+		sb.id = tt.subject_id
+	INNER JOIN
+		teachers ts
+	ON
+		ts.id = tt.teacher_id
+	LEFT JOIN
+		attendance a
+	ON
+		a.time_table_id = tt.id AND a.student_id = tt.student_id
+	WHERE
+		tt.student_id = $1
+		AND tt.from_date BETWEEN $2 AND $3
+		AND (NOT $4 OR tt.from_date <= NOW())
+		AND ` + openDay("tt.from_date") + `
+	ORDER BY
+		tt.from_date;`
+
+	rows, err := s.db.Query(ctx, query, studentId, from, to, startedOnly)
 	if err != nil {
-		return resp, err
+		return nil, err
+	}
+	defer rows.Close()
+
+	lessons := []models.StudentLesson{}
+	for rows.Next() {
+		var lesson models.StudentLesson
+		if err := rows.Scan(
+			&lesson.TimeTableId,
+			&lesson.SubjectName,
+			&lesson.TeacherName,
+			&lesson.RoomName,
+			pkg.TimeText(ctx, &lesson.FromDate),
+			pkg.TimeText(ctx, &lesson.ToDate),
+			&lesson.Attendance); err != nil {
+			return nil, err
+		}
+		lessons = append(lessons, lesson)
 	}
-	return resp, nil
+
+	return lessons, rows.Err()
 }
 
-func (s *timeRepo) GetTime(ctx context.Context, id string) (models.Time, error) {
+// workloadLessons has a row per scheduled lesson starting between $1 and $2 of the subject
+// and teacher given in $3 and $4, empty ones match everything, with the first day of its
+// $5 (week or month). time_table has a row per student of a lesson, so a lesson is its
+// teacher, subject and time. Holidays and closures are left out.
+var workloadLessons = `
+	WITH lessons AS (
+		SELECT
+			tt.teacher_id,
+			tt.subject_id,
+			MIN(tt.room_name) AS room_name,
+			MIN(tt.group_id::text) AS group_id,
+			tt.from_date,
+			tt.to_date,
+			DATE_TRUNC($5, tt.from_date)::date AS period,
+			EXTRACT(EPOCH FROM tt.to_date - tt.from_date)::bigint / 60 AS minutes,
+			ARRAY_AGG(tt.student_id) AS students
+		FROM
+			time_table tt
+		WHERE
+			tt.from_date BETWEEN $1 AND $2
+			AND ($3 = '' OR tt.subject_id::text = $3)
+			AND ($4 = '' OR tt.teacher_id::text = $4)
+			AND ` + openDay("tt.from_date") + `
+		GROUP BY
+			tt.teacher_id, tt.subject_id, tt.from_date, tt.to_date
+	)`
 
-	query := `
+// GetTeacherWorkload sums the scheduled lessons of a page of teachers, with the gaps
+// between their lessons of a day and their lessons per period, subject, room and group.
+func (s *timeRepo) GetTeacherWorkload(ctx context.Context, req models.TeacherWorkloadRequest) (models.TeacherWorkloadResponse, error) {
+	resp := models.TeacherWorkloadResponse{Teachers: []models.TeacherWorkload{}}
+	args := []any{req.From, req.To, req.SubjectId, req.TeacherId, req.Interval}
+
+	var ids []string
+	index := map[string]int{}
+	err := s.EachTeacherWorkload(ctx, req, func(w models.TeacherWorkload) error {
+		index[w.TeacherId] = len(resp.Teachers)
+		ids = append(ids, w.TeacherId)
+		resp.Teachers = append(resp.Teachers, w)
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	if err := s.db.QueryRow(ctx, workloadLessons+` SELECT COUNT(DISTINCT teacher_id) FROM lessons;`, args...).
+		Scan(&resp.Count); err != nil {
+		return resp, err
+	}
+	if len(ids) == 0 {
+		return resp, nil
+	}
+
+	query := workloadLessons + `
 	SELECT
-		id,
-		teacher_id,
-		student_id,
-		subject_id,
-		TO_CHAR(from_date,'YYYY-MM-DD HH:MM:SS'),
-		TO_CHAR(to_date,'YYYY-MM-DD HH:MM:SS'),
-		room_name
+		l.teacher_id,
+		CASE
+			WHEN GROUPING(l.period) = 0 THEN 'period'
+			WHEN GROUPING(l.subject_id) = 0 THEN 'subject'
+			WHEN GROUPING(l.room_name) = 0 THEN 'room'
+			ELSE 'group'
+		END,
+		COALESCE(TO_CHAR(l.period, 'YYYY-MM-DD'), l.subject_id::text, l.group_id, ''),
+		COALESCE(
+			CASE $5 WHEN 'week' THEN TO_CHAR(l.period, 'IYYY-"W"IW') ELSE TO_CHAR(l.period, 'YYYY-MM') END,
+			sb.name, l.room_name, g.name, ''),
+		COUNT(*),
+		SUM(l.minutes)::bigint,
+		ROUND(SUM(l.minutes) / 60.0, 2)::float8
 	FROM
-		time_table
+		lessons l
+	LEFT JOIN
+		subjects sb
+	ON
+		sb.id = l.subject_id
+	LEFT JOIN
+		groups g
+	ON
+		g.id::text = l.group_id
 	WHERE
-		id = $1;`
-	row := s.db.QueryRow(ctx, query, id)
+		l.teacher_id = ANY($6::uuid[])
+	GROUP BY GROUPING SETS (
+		(l.teacher_id, l.period),
+		(l.teacher_id, l.subject_id, sb.name),
+		(l.teacher_id, l.room_name),
+		(l.teacher_id, l.group_id, g.name)
+	)
+	ORDER BY
+		l.teacher_id, l.period, SUM(l.minutes) DESC, 4;`
+
+	rows, err := s.db.Query(ctx, query, append(args, ids)...)
+	if err != nil {
+		return resp, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var teacherId, kind string
+		var share models.WorkloadShare
+		if err := rows.Scan(
+			&teacherId,
+			&kind,
+			&share.Id,
+			&share.Name,
+			&share.Lessons,
+			&share.Minutes,
+			&share.Hours); err != nil {
+			return resp, err
+		}
+		w := &resp.Teachers[index[teacherId]]
+		switch kind {
+		case "period":
+			w.Periods = append(w.Periods, share)
+		case "subject":
+			w.Subjects = append(w.Subjects, share)
+		case "room":
+			w.Rooms = append(w.Rooms, share)
+		default:
+			w.Groups = append(w.Groups, share)
+		}
+	}
 
-	var time models.Time
+	return resp, rows.Err()
+}
+
+// EachTeacherWorkload hands the sums of the page of teachers to fn one at a time as they
+// are read, without the breakdowns of GetTeacherWorkload.
+func (s *timeRepo) EachTeacherWorkload(ctx context.Context, req models.TeacherWorkloadRequest, fn func(models.TeacherWorkload) error) error {
+	offest := (req.Page - 1) * req.Limit
+	args := []any{req.From, req.To, req.SubjectId, req.TeacherId, req.Interval}
 
-	err := row.Scan(&time.Id, &time.TeacherId, &time.StudentId, &time.SubjectId, &time.FromDate, &time.ToDate, &time.RoomName)
+	query := workloadLessons + `
+	SELECT
+		l.teacher_id,
+		TRIM(COALESCE(t.first_name, '') || ' ' || COALESCE(t.last_name, '')),
+		COUNT(*),
+		SUM(l.minutes)::bigint,
+		ROUND(SUM(l.minutes) / 60.0, 2)::float8,
+		COUNT(DISTINCT l.from_date::date),
+		(
+			SELECT COUNT(DISTINCT s.student_id)
+			FROM lessons ls CROSS JOIN LATERAL UNNEST(ls.students) AS s (student_id)
+			WHERE ls.teacher_id = l.teacher_id
+		),
+		COALESCE(SUM(GREATEST(l.gap, 0)), 0)::bigint
+	FROM (
+		SELECT
+			lessons.*,
+			EXTRACT(EPOCH FROM from_date - LAG(to_date) OVER (
+				PARTITION BY teacher_id, from_date::date ORDER BY from_date
+			))::bigint / 60 AS gap
+		FROM
+			lessons
+	) l
+	INNER JOIN
+		teachers t
+	ON
+		t.id = l.teacher_id
+	GROUP BY
+		l.teacher_id, t.first_name, t.last_name
+	ORDER BY
+		t.last_name, t.first_name, l.teacher_id
+	OFFSET
+		$6
+	LIMIT
+		$7;`
 
+	rows, err := s.db.Query(ctx, query, append(args, offest, req.Limit)...)
 	if err != nil {
-		return time, err
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var w models.TeacherWorkload
+		if err := rows.Scan(
+			&w.TeacherId,
+			&w.TeacherName,
+			&w.Lessons,
+			&w.Minutes,
+			&w.Hours,
+			&w.Days,
+			&w.Students,
+			&w.GapMinutes); err != nil {
+			return err
+		}
+		if err := fn(w); err != nil {
+			return err
+		}
 	}
-	return time, nil
-}
\ No newline at end of file
+	return rows.Err()
+}
@@ -32,6 +32,15 @@ func (s Store) SetX(ctx context.Context, key string, value interface{}, duration
 	return nil
 }
 
+func (s Store) SetNX(ctx context.Context, key string, value interface{}, duration time.Duration) (bool, error) {
+	boolCmd := s.db.SetNX(ctx, key, value, duration)
+	if boolCmd.Err() != nil {
+		return false, boolCmd.Err()
+	}
+
+	return boolCmd.Val(), nil
+}
+
 func (s Store) Get(ctx context.Context, key string) interface{} {
 	return s.db.Get(ctx, key).Val()
 }
@@ -2,8 +2,11 @@ package storage
 
 import (
 	"backend_course/lms/api/models"
+	"backend_course/lms/domain"
 	"context"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type IStorage interface {
@@ -12,30 +15,56 @@ type IStorage interface {
 	TeacherStorage() TeacherStorage
 	SubjectsStorage() SubjectStorage
 	TimeStorage() TimeStorage
+	AttendanceStorage() AttendanceStorage
+	GroupStorage() GroupStorage
+	RoomStorage() RoomStorage
+	ScheduleStorage() ScheduleStorage
+	AvailabilityStorage() AvailabilityStorage
+	CalendarStorage() CalendarStorage
+	GradebookStorage() GradebookStorage
+	HomeworkStorage() HomeworkStorage
+	QuizStorage() QuizStorage
+	EnrollmentStorage() EnrollmentStorage
+	GuardianStorage() GuardianStorage
+	BillingStorage() BillingStorage
+	PayrollStorage() PayrollStorage
+	DashboardStorage() DashboardStorage
 	Redis() IRedisStorage
 }
 
 type StudentStorage interface {
-	Create(ctx context.Context, student models.AddStudent) (string, error)
-	Update(ctx context.Context, student models.Student) (string, error)
-	UpdateStatus(ctx context.Context, student models.Student) (string, error)
-	Delete(ctx context.Context, id string) error
-	GetStudent(ctx context.Context, id string) (models.GetStudent, error)
-	GetAll(ctx context.Context, req models.GetAllStudentsRequest) (models.GetAllStudentsResponse, error)
+	Create(ctx context.Context, student domain.Student) (uuid.UUID, error)
+	Update(ctx context.Context, student domain.Student) (uuid.UUID, error)
+	UpdateStatus(ctx context.Context, student domain.Student) (uuid.UUID, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetStudent(ctx context.Context, id uuid.UUID) (domain.Student, error)
+	GetAll(ctx context.Context, req models.GetAllStudentsRequest) ([]domain.Student, int64, error)
+	Each(ctx context.Context, req models.GetAllStudentsRequest, fn func(domain.Student) error) error
 	CheckStudentLesson(ctx context.Context, id string) (models.CheckLessonStudent, error)
 	GetAllStudentsAttandenceReport(ctx context.Context, req models.GetAllStudentsAttandenceReportRequest) (models.GetAllStudentsAttandenceReportResponse, error)
+	EachAttendanceReport(ctx context.Context, req models.GetAllStudentsAttandenceReportRequest, fn func(models.StudentAttandenceReport) error) error
 	UploadImage(ctx context.Context, path models.UploadStudentImage) error
+	GetStudentByLogin(ctx context.Context, login string) (domain.Student, error)
+	Taken(ctx context.Context, contacts models.ImportContacts) (models.ImportContacts, error)
+	Import(ctx context.Context, students []domain.Student) (int64, error)
 }
 
 type TeacherStorage interface {
-	Create(ctx context.Context, teacher models.AddTeacher) (string, error)
-	Update(ctx context.Context, teacher models.Teacher) (string, error)
-	Delete(ctx context.Context, id string) error
-	GetTeacher(ctx context.Context, id string) (models.Teacher, error)
-	GetAll(ctx context.Context, req models.GetAllTeachersRequest) (models.GetAllTeachersResponse, error)
-	GetTeacherByLogin(ctx context.Context, login string) (models.Teacher, error)
+	Create(ctx context.Context, teacher domain.Teacher) (uuid.UUID, error)
+	Update(ctx context.Context, teacher domain.Teacher) (uuid.UUID, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetTeacher(ctx context.Context, id uuid.UUID) (domain.Teacher, error)
+	GetAll(ctx context.Context, req models.GetAllTeachersRequest) ([]domain.Teacher, int64, error)
+	Each(ctx context.Context, req models.GetAllTeachersRequest, fn func(domain.Teacher) error) error
+	GetTeacherByLogin(ctx context.Context, login string) (domain.Teacher, error)
 	CheckTeacherLesson(ctx context.Context, id string) (models.CheckLessonTeacher, error)
 	IsTeacherExists(ctx context.Context, email string) bool
+	TeachesSubject(ctx context.Context, teacherId, subjectId string) (bool, error)
+	GetSubjects(ctx context.Context, teacherId string) ([]models.TeacherSubject, error)
+	SetSubject(ctx context.Context, teacherId, subjectId string, req models.SetTeacherSubject) error
+	RemoveSubject(ctx context.Context, teacherId, subjectId string) (bool, error)
+	Taken(ctx context.Context, contacts models.ImportContacts) (models.ImportContacts, error)
+	Import(ctx context.Context, teachers []domain.Teacher) (int64, error)
 }
 
 type SubjectStorage interface {
@@ -44,18 +73,200 @@ type SubjectStorage interface {
 	Delete(ctx context.Context, id string) error
 	GetSubject(ctx context.Context, id string) (models.Subjects, error)
 	GetAll(ctx context.Context, req models.GetAllSubjectsRequest) (models.GetAllSubjectsResponse, error)
+	Each(ctx context.Context, req models.GetAllSubjectsRequest, fn func(models.Subjects) error) error
+	GetSyllabus(ctx context.Context, id string) ([]models.SyllabusUnit, error)
+	SetSyllabus(ctx context.Context, id string, units []models.SyllabusUnit) error
+	GetPrerequisites(ctx context.Context, id string) ([]models.SubjectRef, error)
+	GetPrerequisiteGraph(ctx context.Context) (map[string][]string, error)
+	SetPrerequisites(ctx context.Context, id string, prerequisiteIds []string) error
 }
 
 type TimeStorage interface {
-	Create(ctx context.Context, time models.Time) (string, error)
-	Update(ctx context.Context, time models.Time) (string, error)
+	Create(ctx context.Context, time domain.TimeTable) (uuid.UUID, error)
+	Update(ctx context.Context, time domain.TimeTable) (uuid.UUID, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetTime(ctx context.Context, id uuid.UUID) (domain.TimeTable, error)
+	GetAll(ctx context.Context, req models.GetAllTimeRequest) ([]domain.TimeTable, int64, error)
+	Each(ctx context.Context, req models.GetAllTimeRequest, fn func(domain.TimeTable) error) error
+	GetSubstitutes(ctx context.Context, id uuid.UUID) ([]models.Substitute, error)
+	Substitute(ctx context.Context, id, teacherId uuid.UUID) (string, int64, error)
+	GetStudentLessons(ctx context.Context, studentId, from, to string, startedOnly bool) ([]models.StudentLesson, error)
+	GetTeacherWorkload(ctx context.Context, req models.TeacherWorkloadRequest) (models.TeacherWorkloadResponse, error)
+	EachTeacherWorkload(ctx context.Context, req models.TeacherWorkloadRequest, fn func(models.TeacherWorkload) error) error
+}
+
+type AttendanceStorage interface {
+	MarkBulk(ctx context.Context, req models.MarkAttendanceRequest, teacherId string) error
+	GetByLesson(ctx context.Context, timeTableId string) (models.GetLessonAttendanceResponse, error)
+	GetSummary(ctx context.Context, studentId, from, to string) (models.AttendanceSummary, error)
+}
+
+type GroupStorage interface {
+	Create(ctx context.Context, group models.AddGroup) (string, error)
+	Update(ctx context.Context, group models.Group) (string, error)
 	Delete(ctx context.Context, id string) error
-	GetTime(ctx context.Context, id string) (models.Time, error)
-	GetAll(ctx context.Context, req models.GetAllTimeRequest) (models.GetAllTimeResponse, error)
+	GetGroup(ctx context.Context, id string) (models.Group, error)
+	GetAll(ctx context.Context, req models.GetAllGroupsRequest) (models.GetAllGroupsResponse, error)
+	Each(ctx context.Context, req models.GetAllGroupsRequest, fn func(models.Group) error) error
+	SetStudents(ctx context.Context, id string, studentIds []string) error
+}
+
+type RoomStorage interface {
+	Create(ctx context.Context, room models.AddRoom) (string, error)
+	Update(ctx context.Context, room models.Room) (string, error)
+	Delete(ctx context.Context, id string) error
+	GetRoom(ctx context.Context, id string) (models.Room, error)
+	GetAll(ctx context.Context, req models.GetAllRoomsRequest) (models.GetAllRoomsResponse, error)
+	Each(ctx context.Context, req models.GetAllRoomsRequest, fn func(models.Room) error) error
+	SetHours(ctx context.Context, roomId string, windows []models.OpeningWindow) error
+	GetHours(ctx context.Context, roomId string) ([]models.OpeningWindow, error)
+	GetUtilization(ctx context.Context, req models.RoomUtilizationRequest) (models.RoomUtilizationResponse, error)
+	EachUtilization(ctx context.Context, req models.RoomUtilizationRequest, fn func(models.RoomUtilizationRow) error) error
+}
+
+type ScheduleStorage interface {
+	GetTeacherSubjects(ctx context.Context) ([]models.TeacherSubjects, error)
+	GetBusy(ctx context.Context, from, to string) ([]models.BusyInterval, error)
+	CreateDraft(ctx context.Context, draft models.ScheduleDraft) (string, error)
+	GetDraft(ctx context.Context, id string) (models.ScheduleDraft, error)
+	CommitDraft(ctx context.Context, id string) (int64, error)
+}
+
+type AvailabilityStorage interface {
+	SetWindows(ctx context.Context, teacherId string, windows []models.AvailabilityWindow) error
+	GetWindows(ctx context.Context, teacherId string) ([]models.AvailabilityWindow, error)
+	CreateLeave(ctx context.Context, leave models.AddLeave) (string, error)
+	GetLeave(ctx context.Context, id string) (models.Leave, error)
+	GetLeaves(ctx context.Context, req models.GetAllLeavesRequest) (models.GetAllLeavesResponse, error)
+	EachLeave(ctx context.Context, req models.GetAllLeavesRequest, fn func(models.Leave) error) error
+	GetApprovedLeaves(ctx context.Context, from, to string) ([]models.Leave, error)
+	ReviewLeave(ctx context.Context, id, status string) error
+	GetImpactedLessons(ctx context.Context, leaveId string) ([]models.ImpactedLesson, error)
+	CheckLesson(ctx context.Context, teacherId, from, to string) (models.LessonAvailability, error)
 }
 
 type IRedisStorage interface {
 	SetX(ctx context.Context, key string, value interface{}, duration time.Duration) error
+	SetNX(ctx context.Context, key string, value interface{}, duration time.Duration) (bool, error)
 	Get(ctx context.Context, key string) interface{}
 	Del(ctx context.Context, key string) error
-}
\ No newline at end of file
+}
+
+type CalendarStorage interface {
+	CreateAcademicYear(ctx context.Context, year models.AddAcademicYear) (string, error)
+	DeleteAcademicYear(ctx context.Context, id string) error
+	GetAcademicYears(ctx context.Context) (models.GetAllAcademicYearsResponse, error)
+	CreateTerm(ctx context.Context, term models.AddTerm) (string, error)
+	GetTerm(ctx context.Context, id string) (models.Term, error)
+	DeleteTerm(ctx context.Context, id string) error
+	CreateClosure(ctx context.Context, closure models.AddClosure) (string, error)
+	DeleteClosure(ctx context.Context, id string) error
+	GetClosures(ctx context.Context, from, to string) (models.GetAllClosuresResponse, error)
+	IsClosed(ctx context.Context, from, to string) (bool, error)
+}
+
+type GradebookStorage interface {
+	CreateAssessment(ctx context.Context, teacherId string, assessment models.AddAssessment) (string, error)
+	GetAssessment(ctx context.Context, id string) (models.Assessment, error)
+	DeleteAssessment(ctx context.Context, id string) error
+	GetAssessments(ctx context.Context, req models.GetAllAssessmentsRequest) (models.GetAllAssessmentsResponse, error)
+	EachAssessment(ctx context.Context, req models.GetAllAssessmentsRequest, fn func(models.Assessment) error) error
+	SetScores(ctx context.Context, assessmentId, teacherId string, scores []models.Score) error
+	GetGradeRows(ctx context.Context, req models.GradesRequest) ([]models.GradeRow, error)
+}
+
+type HomeworkStorage interface {
+	CreateAssignment(ctx context.Context, teacherId string, assignment models.AddAssignment) (string, error)
+	GetAssignment(ctx context.Context, id string) (models.Assignment, error)
+	DeleteAssignment(ctx context.Context, id string) error
+	GetAssignments(ctx context.Context, req models.GetAllAssignmentsRequest) (models.GetAllAssignmentsResponse, error)
+	EachAssignment(ctx context.Context, req models.GetAllAssignmentsRequest, fn func(models.Assignment) error) error
+	Submit(ctx context.Context, submission models.AddSubmission) (string, []string, error)
+	GetSubmission(ctx context.Context, id string) (models.Submission, error)
+	GetStudentSubmission(ctx context.Context, assignmentId, studentId string) (models.Submission, error)
+	GetSubmissions(ctx context.Context, assignmentId string) (models.GetAssignmentSubmissionsResponse, error)
+	ReviewSubmission(ctx context.Context, id, teacherId string, review models.ReviewSubmission) error
+	GetComments(ctx context.Context, studentId, from, to string) ([]models.TeacherComment, error)
+}
+
+type QuizStorage interface {
+	CreateQuiz(ctx context.Context, teacherId string, quiz models.AddQuiz, assessment models.AddAssessment) (string, error)
+	GetQuiz(ctx context.Context, id string) (models.Quiz, error)
+	DeleteQuiz(ctx context.Context, id string) error
+	GetQuizzes(ctx context.Context, req models.GetAllQuizzesRequest) (models.GetAllQuizzesResponse, error)
+	EachQuiz(ctx context.Context, req models.GetAllQuizzesRequest, fn func(models.Quiz) error) error
+	CreateAttempt(ctx context.Context, attempt models.QuizAttempt) (string, error)
+	GetAttempt(ctx context.Context, id string) (models.QuizAttempt, error)
+	GetAttempts(ctx context.Context, quizId, studentId string) (models.GetQuizAttemptsResponse, error)
+	SubmitAttempt(ctx context.Context, attemptId string, answers []models.QuizAnswerLog, score float64, expired bool) error
+}
+
+type EnrollmentStorage interface {
+	MissingPrerequisites(ctx context.Context, studentId, subjectId string) ([]models.SubjectRef, error)
+	Enroll(ctx context.Context, studentId, subjectId string) (string, error)
+	GetEnrollment(ctx context.Context, id string) (models.Enrollment, error)
+	GetEnrollments(ctx context.Context, req models.GetAllEnrollmentsRequest) (models.GetAllEnrollmentsResponse, error)
+	EachEnrollment(ctx context.Context, req models.GetAllEnrollmentsRequest, fn func(models.Enrollment) error) error
+	UpdateStatus(ctx context.Context, id, status string) error
+}
+
+type GuardianStorage interface {
+	Create(ctx context.Context, guardian models.AddGuardian) (string, error)
+	Update(ctx context.Context, id string, guardian models.AddGuardian) error
+	Delete(ctx context.Context, id string) error
+	GetGuardian(ctx context.Context, id string) (models.Guardian, error)
+	GetGuardianByLogin(ctx context.Context, login string) (models.Guardian, error)
+	GetAll(ctx context.Context, req models.GetAllGuardiansRequest) (models.GetAllGuardiansResponse, error)
+	Each(ctx context.Context, req models.GetAllGuardiansRequest, fn func(models.Guardian) error) error
+	SetStudents(ctx context.Context, id string, students []models.GuardianStudent) error
+	GetStudents(ctx context.Context, id string) ([]models.GuardianStudent, error)
+	IsLinked(ctx context.Context, guardianId, studentId string) (bool, error)
+}
+
+type BillingStorage interface {
+	CreatePlan(ctx context.Context, plan models.AddPricePlan) (string, error)
+	UpdatePlan(ctx context.Context, id string, plan models.UpdatePricePlan) error
+	GetPlan(ctx context.Context, id string) (models.PricePlan, error)
+	GetPlans(ctx context.Context, req models.GetAllPricePlansRequest) (models.GetAllPricePlansResponse, error)
+	EachPlan(ctx context.Context, req models.GetAllPricePlansRequest, fn func(models.PricePlan) error) error
+	SetDiscount(ctx context.Context, discount models.AddDiscount) (string, error)
+	DeleteDiscount(ctx context.Context, id string) error
+	GetDiscounts(ctx context.Context, req models.GetAllDiscountsRequest) (models.GetAllDiscountsResponse, error)
+	EachDiscount(ctx context.Context, req models.GetAllDiscountsRequest, fn func(models.Discount) error) error
+	GenerateInvoices(ctx context.Context, period string, year int) (int64, error)
+	GetInvoice(ctx context.Context, id, today string) (models.Invoice, error)
+	GetInvoices(ctx context.Context, req models.GetAllInvoicesRequest) (models.GetAllInvoicesResponse, error)
+	EachInvoice(ctx context.Context, req models.GetAllInvoicesRequest, fn func(models.Invoice) error) error
+	CancelInvoice(ctx context.Context, id string) error
+	AddPayment(ctx context.Context, payment models.AddPayment) (string, error)
+	GetPayments(ctx context.Context, req models.GetAllPaymentsRequest) (models.GetAllPaymentsResponse, error)
+	EachPayment(ctx context.Context, req models.GetAllPaymentsRequest, fn func(models.Payment) error) error
+	GetBalance(ctx context.Context, studentId, today string) (models.StudentBalance, error)
+	GetDebtors(ctx context.Context, req models.DebtorsRequest) (models.DebtorsResponse, error)
+	EachDebtor(ctx context.Context, req models.DebtorsRequest, fn func(models.Debtor) error) error
+	CreateTransaction(ctx context.Context, invoiceId, provider string, amount int64) (string, error)
+	GetTransaction(ctx context.Context, id string) (models.PaymentTransaction, error)
+	PrepareTransaction(ctx context.Context, id, providerTxId string) error
+	CancelTransaction(ctx context.Context, id string) error
+	CompleteTransaction(ctx context.Context, id string) (string, error)
+}
+
+type PayrollStorage interface {
+	SetRate(ctx context.Context, teacherId string, rate models.SetRate) error
+	DeleteRate(ctx context.Context, teacherId, subjectId string) error
+	GetRates(ctx context.Context, teacherId string) (models.TeacherRates, error)
+	GetDeliveredLessons(ctx context.Context, from, to string) ([]models.PayrollLine, error)
+	CreateRun(ctx context.Context, run models.PayrollRun) (string, error)
+	GetRun(ctx context.Context, id string) (models.PayrollRun, error)
+	GetRuns(ctx context.Context, req models.GetAllPayrollRunsRequest) (models.GetAllPayrollRunsResponse, error)
+	EachRun(ctx context.Context, req models.GetAllPayrollRunsRequest, fn func(models.PayrollRun) error) error
+}
+
+type DashboardStorage interface {
+	GetStudentCounts(ctx context.Context) (models.StudentCounts, error)
+	GetNewStudents(ctx context.Context, from, to string) ([]models.MonthCount, error)
+	CountLessons(ctx context.Context, from, to string) (int64, error)
+	GetAttendance(ctx context.Context, from, to string) (models.AttendanceRate, error)
+	GetRevenue(ctx context.Context, from, to string) (models.Revenue, error)
+	GetTopSubjects(ctx context.Context, from, to string, limit int) ([]models.SubjectStat, error)
+}